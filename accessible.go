@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// announcement describes the selected item on one plain line for screen
+// readers, e.g. "Request 3 of 20: GET /pets, List pets, collapsed"
+func (m *Model) announcement() string {
+	if m.mode == viewInfo {
+		return "Info"
+	}
+	count := m.getMaxItems() + 1
+	if count == 0 {
+		return m.viewName() + ": nothing to list"
+	}
+	index := min(max(m.cursor, 0), count-1)
+
+	var noun string
+	var parts []string
+	var folded bool
+	var d deprecation
+	switch m.mode {
+	case viewEndpoints:
+		ep := m.endpoints[index]
+		noun, folded, d = "Request", ep.folded, ep.deprecation
+		parts = append(parts, ep.method+" "+ep.path)
+		if ep.op != nil && ep.op.Summary != "" {
+			parts = append(parts, strings.TrimSuffix(ep.op.Summary, "."))
+		}
+	case viewComponents:
+		comp := m.components[index]
+		noun, folded, d = "Component", comp.folded, comp.deprecation
+		parts = append(parts, comp.compType+" "+comp.name)
+	case viewWebhooks:
+		hook := m.webhooks[index]
+		noun, folded, d = "Webhook", hook.folded, hook.deprecation
+		parts = append(parts, hook.method+" "+hook.name)
+		if hook.op != nil && hook.op.Summary != "" {
+			parts = append(parts, strings.TrimSuffix(hook.op.Summary, "."))
+		}
+	}
+
+	if d.deprecated {
+		parts = append(parts, d.badge())
+	}
+	if status := m.selectedChange().status; status != diffUnchanged {
+		parts = append(parts, strings.ToLower(status.String())+" since baseline")
+	}
+	if folded {
+		parts = append(parts, "collapsed")
+	} else {
+		parts = append(parts, "expanded")
+	}
+	return fmt.Sprintf("%s %d of %d: %s", noun, index+1, count, strings.Join(parts, ", "))
+}
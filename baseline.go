@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// baseline is the spec given with --since, the endpoints and schemas being
+// marked in the lists as added, changed or removed since
+type baseline struct {
+	path  string              // Of the baseline, where the removed items are defined
+	items map[string]diffItem // By name like in the diff, e.g. "GET /pets" or "schema Pet", without their details
+}
+
+// setBaseline compares the spec with a baseline and lists the endpoints and
+// schemas removed since along with the others
+func (m *Model) setBaseline(path string, old *v3.Document) {
+	b := &baseline{path: path, items: map[string]diffItem{}}
+	removed := map[string]bool{}
+	for _, item := range diffItems(old, m.doc, diffSpecs(old, m.doc)) {
+		item.before, item.after = "", ""
+		b.items[item.name] = item
+		if item.status == diffRemoved {
+			removed[item.name] = true
+		}
+	}
+	m.baseline = b
+	if len(removed) == 0 {
+		return
+	}
+
+	for _, ep := range extractEndpoints(old) {
+		if removed[endpointName(ep)] {
+			m.endpoints = append(m.endpoints, ep)
+		}
+	}
+	sortEndpoints(m.endpoints)
+	for _, comp := range extractComponents(old) {
+		if removed[componentName(comp)] {
+			m.components = append(m.components, comp)
+		}
+	}
+	sortComponents(m.components)
+}
+
+// summary counts the items that differ from the baseline, e.g. "+2 ~5 -1 since v1.yaml"
+func (b *baseline) summary() string {
+	counts := map[diffStatus]int{}
+	for _, item := range b.items {
+		counts[item.status]++
+	}
+	return fmt.Sprintf("+%d ~%d -%d since %s", counts[diffAdded], counts[diffChanged], counts[diffRemoved], filepath.Base(b.path))
+}
+
+// endpointName names an endpoint like the baseline does
+func endpointName(ep endpoint) string {
+	return ep.method + " " + ep.path
+}
+
+// componentName names a component like the baseline does, only schemas being
+// compared
+func componentName(comp component) string {
+	if comp.compType != "Schema" {
+		return ""
+	}
+	return "schema " + comp.name
+}
+
+// change returns how the item with a name differs from the baseline,
+// unchanged without one
+func (b *baseline) change(name string) diffItem {
+	if b == nil || name == "" {
+		return diffItem{}
+	}
+	return b.items[name]
+}
+
+// selectedChange returns how the selected item differs from the baseline
+func (m *Model) selectedChange() diffItem {
+	if m.cursor < 0 || m.cursor > m.getMaxItems() {
+		return diffItem{}
+	}
+	switch m.mode {
+	case viewEndpoints:
+		return m.baseline.change(endpointName(m.endpoints[m.cursor]))
+	case viewComponents:
+		return m.baseline.change(componentName(m.components[m.cursor]))
+	}
+	return diffItem{}
+}
+
+// selectedFile is the file the selected item is defined in, the baseline for
+// one removed since
+func (m *Model) selectedFile() string {
+	if m.selectedChange().status == diffRemoved {
+		return m.baseline.path
+	}
+	return m.specPath
+}
+
+// baselineMarker is the column before the items of the lists showing how they
+// differ from the baseline, like in the interactive diff, empty without one
+func (m Model) baselineMarker(name string, style lipgloss.Style) string {
+	if m.baseline == nil {
+		return ""
+	}
+	marker, color := diffMarker(m.baseline.change(name).status)
+	return style.Foreground(color).Bold(true).Render(marker) + style.Render(" ")
+}
+
+// details tells at the top of the details of an item how it differs from the
+// baseline, with the changes found when changed, e.g. "response 200: application/xml removed",
+// breaking ones marked
+func (b *baseline) details(name string) string {
+	item := b.change(name)
+	if item.status == diffUnchanged {
+		return ""
+	}
+
+	var s strings.Builder
+	fmt.Fprintf(&s, "Since baseline: %s\n", strings.ToLower(item.status.String()))
+	if item.status != diffChanged {
+		return s.String() // The changes only tell again it was added or removed
+	}
+	for _, c := range item.changes {
+		text := c.Message
+		if where := strings.TrimSpace(strings.TrimPrefix(c.Location, item.name)); where != "" {
+			text = where + ": " + text
+		}
+		bullet := "-"
+		if c.Breaking {
+			bullet = icons.warning + " breaking:"
+		}
+		fmt.Fprintf(&s, "  %s %s\n", bullet, text)
+	}
+	return s.String()
+}
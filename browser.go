@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/url"
+	"os/exec"
+	"runtime"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// openURL opens a URL with the default browser of the platform.
+// It is a variable so tests can intercept it.
+var openURL = func(link string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", link)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", link)
+	default:
+		cmd = exec.Command("xdg-open", link)
+	}
+	return cmd.Start()
+}
+
+// isWebURL reports whether a link is an http or https URL with a host. Links
+// come from the spec, and the opener of the platform would also run local
+// files or take a link starting with - as an option.
+func isWebURL(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func openInBrowser(link string) tea.Cmd {
+	return func() tea.Msg {
+		_ = openURL(link)
+		return nil
+	}
+}
+
+// currentExternalDocsURL returns the external documentation URL of the selected item.
+// Operations without their own docs fall back to the docs of their first documented tag.
+func (m *Model) currentExternalDocsURL() string {
+	switch m.mode {
+	case viewEndpoints:
+		if m.cursor <= m.getMaxItems() {
+			return m.operationDocsURL(m.endpoints[m.cursor].op)
+		}
+	case viewWebhooks:
+		if m.cursor <= m.getMaxItems() {
+			return m.operationDocsURL(m.webhooks[m.cursor].op)
+		}
+	case viewComponents:
+		if m.cursor <= m.getMaxItems() {
+			proxy, ok := m.components[m.cursor].source.(*base.SchemaProxy)
+			if ok && proxy != nil && proxy.Schema() != nil && proxy.Schema().ExternalDocs != nil {
+				return proxy.Schema().ExternalDocs.URL
+			}
+		}
+	case viewInfo:
+		if m.doc.ExternalDocs != nil {
+			return m.doc.ExternalDocs.URL
+		}
+	}
+	return ""
+}
+
+func (m *Model) operationDocsURL(op *v3.Operation) string {
+	if op == nil {
+		return ""
+	}
+	if op.ExternalDocs != nil && op.ExternalDocs.URL != "" {
+		return op.ExternalDocs.URL
+	}
+	for _, name := range op.Tags {
+		for _, tag := range m.doc.Tags {
+			if tag.Name == name && tag.ExternalDocs != nil && tag.ExternalDocs.URL != "" {
+				return tag.ExternalDocs.URL
+			}
+		}
+	}
+	return ""
+}
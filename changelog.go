@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// Sections of a changelog, in the order they are printed
+const (
+	changelogBreaking   = "Breaking changes"
+	changelogAdded      = "New endpoints"
+	changelogDeprecated = "Deprecations"
+	changelogChanged    = "Changed endpoints"
+	changelogSchemas    = "Schemas"
+)
+
+// changelogEntry is an endpoint or schema with what changed in it
+type changelogEntry struct {
+	Item    string   `json:"item"`              // e.g. "GET /pets" or "schema Pet"
+	Summary string   `json:"summary,omitempty"` // Of new endpoints
+	Changes []string `json:"changes,omitempty"`
+}
+
+type changelogSection struct {
+	Title   string           `json:"title"`
+	Entries []changelogEntry `json:"entries"`
+}
+
+// changelog is the output of "oq changelog"
+type changelog struct {
+	Title    string             `json:"title"`
+	From     string             `json:"from"` // Versions of the specs
+	To       string             `json:"to"`
+	Sections []changelogSection `json:"sections"`
+}
+
+func specVersion(doc *v3.Document) (title, version string) {
+	if doc.Info == nil {
+		return "", ""
+	}
+	return doc.Info.Title, doc.Info.Version
+}
+
+// buildChangelog sorts the changes found by diffSpecs into release notes
+// sections, grouping them by endpoint or schema. Breaking changes all go first,
+// whatever they are about.
+func buildChangelog(old, updated *v3.Document, changes []change) changelog {
+	log := changelog{}
+	_, log.From = specVersion(old)
+	log.Title, log.To = specVersion(updated)
+
+	endpoints := map[string]endpoint{}
+	for _, ep := range extractEndpoints(updated) {
+		endpoints[ep.method+" "+ep.path] = ep
+	}
+
+	sections := map[string]*changelogSection{}
+	entries := map[string]*changelogEntry{} // By section and item
+	add := func(title, item string) *changelogEntry {
+		section, ok := sections[title]
+		if !ok {
+			section = &changelogSection{Title: title}
+			sections[title] = section
+		}
+		key := title + "\x00" + item
+		if entry, ok := entries[key]; ok {
+			return entry
+		}
+		section.Entries = append(section.Entries, changelogEntry{Item: item})
+		entry := &section.Entries[len(section.Entries)-1]
+		entries[key] = entry
+		return entry
+	}
+
+	for _, c := range changes {
+		item := changedItem(c.Location)
+		text := c.Message
+		if detail := strings.TrimLeft(strings.TrimPrefix(c.Location, item), ". "); detail != "" {
+			text = detail + ": " + text
+		}
+
+		switch {
+		case c.Breaking:
+			entry := add(changelogBreaking, item)
+			entry.Changes = append(entry.Changes, text)
+		case c.Kind == changeEndpointAdded:
+			entry := add(changelogAdded, item)
+			if ep, ok := endpoints[item]; ok {
+				entry.Summary = firstLine(ep.op.Summary)
+			}
+		case c.Kind == changeEndpointDeprecated:
+			entry := add(changelogDeprecated, item)
+			if ep, ok := endpoints[item]; ok {
+				entry.Changes = append(entry.Changes, ep.deprecation.notes...)
+			}
+		case strings.HasPrefix(item, "schema "):
+			entry := add(changelogSchemas, strings.TrimPrefix(item, "schema "))
+			entry.Changes = append(entry.Changes, text)
+		default:
+			entry := add(changelogChanged, item)
+			entry.Changes = append(entry.Changes, text)
+		}
+	}
+
+	for _, title := range []string{changelogBreaking, changelogAdded, changelogDeprecated, changelogChanged, changelogSchemas} {
+		if section, ok := sections[title]; ok {
+			log.Sections = append(log.Sections, *section)
+		}
+	}
+	return log
+}
+
+// markdown renders the changelog for release notes, an entry with one change
+// on one line and the others with a nested list
+func (log changelog) markdown() string {
+	var md strings.Builder
+
+	heading := log.To
+	if heading == "" {
+		heading = log.Title
+	}
+	if heading == "" {
+		heading = "API changes"
+	}
+	md.WriteString("## " + heading + "\n\n")
+	if log.From != "" && log.From != log.To {
+		name := log.Title
+		if name == "" {
+			name = "the API"
+		}
+		md.WriteString(fmt.Sprintf("Changes to %s since %s.\n\n", name, log.From))
+	}
+	if len(log.Sections) == 0 {
+		md.WriteString("No changes.\n")
+		return md.String()
+	}
+
+	for i, section := range log.Sections {
+		if i > 0 {
+			md.WriteString("\n")
+		}
+		md.WriteString("### " + section.Title + "\n\n")
+		for _, entry := range section.Entries {
+			line := "- `" + entry.Item + "`"
+			switch {
+			case entry.Summary != "":
+				line += ": " + entry.Summary
+			case len(entry.Changes) == 1:
+				line += ": " + entry.Changes[0]
+			}
+			md.WriteString(line + "\n")
+			if len(entry.Changes) > 1 {
+				for _, text := range entry.Changes {
+					md.WriteString("  - " + text + "\n")
+				}
+			}
+		}
+	}
+	return md.String()
+}
+
+// runChangelog implements "oq changelog"
+func runChangelog(fs *flag.FlagSet, args []string) error {
+	format := fs.String("format", exportMarkdown, "output format: markdown or json")
+	out := fs.String("o", "", "file to write to instead of stdout")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errUsage
+	}
+	if *format != exportMarkdown && *format != outputJSON {
+		return fmt.Errorf("unknown format %q, use %s or %s", *format, exportMarkdown, outputJSON)
+	}
+
+	old, err := loadSpec(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	updated, err := loadSpec(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	log := buildChangelog(old, updated, diffSpecs(old, updated))
+
+	if *format == outputJSON {
+		if log.Sections == nil {
+			log.Sections = []changelogSection{}
+		}
+		data, err := json.MarshalIndent(log, "", "  ")
+		if err != nil {
+			return err
+		}
+		return writeResult(*out, append(data, '\n'))
+	}
+	return writeResult(*out, []byte(log.markdown()))
+}
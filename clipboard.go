@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+)
+
+// clipboardCommands are tried in order to copy text on the local machine
+var clipboardCommands = map[string][][]string{
+	"darwin":  {{"pbcopy"}},
+	"windows": {{"clip"}},
+	"linux": {
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+		{"clip.exe"}, // WSL
+	},
+}
+
+var errNoClipboardCommand = errors.New("no clipboard command found")
+
+var errNoClipboard = errors.New("no clipboard command found, and no terminal to copy through")
+
+// clipboardTerminal is the terminal the viewer draws on, nil when its output
+// isn't a terminal. The OSC 52 sequence goes there, not to a piped stdout.
+var clipboardTerminal io.Writer
+
+// copyToClipboard copies text to the system clipboard. Over SSH, or when no
+// clipboard command is available, it falls back to the OSC 52 escape sequence,
+// which asks the terminal emulator to set its clipboard.
+// It is a variable so tests can intercept it.
+var copyToClipboard = func(text string) error {
+	if os.Getenv("SSH_TTY") == "" && os.Getenv("SSH_CONNECTION") == "" {
+		if err := copyWithCommand(text); err == nil {
+			return nil
+		}
+	}
+	return copyWithOSC52(clipboardTerminal, text)
+}
+
+// copyWithOSC52 writes the OSC 52 sequence to the terminal w. termenv writes it
+// at once, so it can't land in the middle of a frame of the viewer.
+func copyWithOSC52(w io.Writer, text string) error {
+	if w == nil {
+		return errNoClipboard
+	}
+	termenv.NewOutput(w).Copy(text)
+	return nil
+}
+
+func copyWithCommand(text string) error {
+	for _, args := range clipboardCommands[runtime.GOOS] {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return errNoClipboardCommand
+}
+
+// clipboardMsg reports the result of copying to the clipboard
+type clipboardMsg struct {
+	what string
+	text string // Copied
+	err  error
+}
+
+func copyCmd(what, text string) tea.Cmd {
+	return func() tea.Msg {
+		return clipboardMsg{what: what, text: text, err: copyToClipboard(text)}
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commandArguments are the commands of the : prompt and what they take
+var commandArguments = map[string]string{
+	"op":        "operationId",
+	"path":      "path",
+	"schema":    "name",
+	"component": "name",
+	"line":      "number",
+}
+
+// pathParameter matches the parameters of a path template, e.g. {petId}
+var pathParameter = regexp.MustCompile(`\{[^}/]*\}`)
+
+// updatePrompt edits the command typed after :, running it on enter
+func (m Model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.prompting = false
+	case tea.KeyEnter:
+		m.prompting = false
+		m.runCommand(m.command)
+	case tea.KeyBackspace:
+		runes := []rune(m.command)
+		if len(runes) == 0 {
+			m.prompting = false
+			break
+		}
+		m.command = string(runes[:len(runes)-1])
+	case tea.KeyRunes, tea.KeySpace:
+		m.command += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// runCommand jumps to the item a command names:
+//
+//	op listPets            the operation or webhook with that operationId
+//	path /pets/{id} [get]  the operation on that path, matching any parameter names
+//	schema Pet             the schema with that name
+//	component Pet          the component of any type with that name
+//	line 120, or 120       the operation or component defined at that line of the spec
+//
+// Problems are shown in the footer.
+func (m *Model) runCommand(command string) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(command), ":")), " ")
+	arg = strings.TrimSpace(arg)
+	if name == "" {
+		return
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		name, arg = "line", name
+	}
+
+	usage, ok := commandArguments[name]
+	if !ok {
+		m.message = fmt.Sprintf("Unknown command %q, use op, path, schema, component or line", name)
+		return
+	}
+	if arg == "" {
+		m.message = fmt.Sprintf("Usage: :%s <%s>", name, usage)
+		return
+	}
+
+	var ref, problem string
+	switch name {
+	case "op":
+		ref, problem = m.operationIDRef(arg), "No operation with operationId "+arg
+	case "path":
+		path, method, _ := strings.Cut(arg, " ")
+		ref, problem = m.pathRef(path, strings.ToUpper(strings.TrimSpace(method))), "No operation on "+arg
+	case "schema":
+		ref, problem = m.componentNameRef("Schema", arg), "No schema named "+arg
+	case "component":
+		ref, problem = m.componentNameRef("", arg), "No component named "+arg
+	case "line":
+		line, err := strconv.Atoi(arg)
+		if err != nil || line < 1 {
+			m.message = fmt.Sprintf("Invalid line %q", arg)
+			return
+		}
+		ref, problem = m.lineRef(line), fmt.Sprintf("Nothing is defined at line %d", line)
+	}
+	if ref == "" || !m.jumpToReference(ref) {
+		m.message = problem
+	}
+}
+
+// operationIDRef returns the reference of the operation or webhook with an
+// operationId, compared regardless of case when no other matches exactly
+func (m *Model) operationIDRef(id string) string {
+	all := m.allItems()
+	for _, exact := range []bool{true, false} {
+		matches := func(opID string) bool {
+			return opID != "" && (opID == id || !exact && strings.EqualFold(opID, id))
+		}
+		for _, ep := range all.endpoints {
+			if ep.op != nil && matches(ep.op.OperationId) {
+				return operationRef(ep.path, ep.method)
+			}
+		}
+		for _, hook := range all.webhooks {
+			if hook.op != nil && matches(hook.op.OperationId) {
+				return webhookRef(hook.name, hook.method)
+			}
+		}
+	}
+	return ""
+}
+
+// pathRef returns the reference of the operation on a path, the GET one or else
+// the first when no method is given. Parameters match whatever their names, so /pets/{id} finds
+// /pets/{petId}, and so do values, like /pets/42.
+func (m *Model) pathRef(path, method string) string {
+	all := m.allItems()
+	template := pathParameter.ReplaceAllString(path, "{}")
+	matchers := []func(string) bool{
+		func(p string) bool { return p == path },
+		func(p string) bool { return pathParameter.ReplaceAllString(p, "{}") == template },
+		func(p string) bool { return pathTemplate(p).MatchString(path) },
+	}
+	for _, matches := range matchers {
+		ref := ""
+		for _, ep := range all.endpoints {
+			if !matches(ep.path) || method != "" && ep.method != method {
+				continue
+			}
+			if ref == "" || method == "" && ep.method == http.MethodGet {
+				ref = operationRef(ep.path, ep.method)
+			}
+		}
+		if ref != "" {
+			return ref
+		}
+	}
+	return ""
+}
+
+// pathTemplate matches the paths a template stands for, e.g. /pets/42 for /pets/{petId}
+func pathTemplate(path string) *regexp.Regexp {
+	parts := pathParameter.Split(path, -1)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, "[^/]+") + "$")
+}
+
+// componentNameRef returns the reference of the component with a name, of the
+// given type unless empty, compared regardless of case when none matches exactly
+func (m *Model) componentNameRef(compType, name string) string {
+	all := m.allItems()
+	for _, exact := range []bool{true, false} {
+		for _, comp := range all.components {
+			if (compType == "" || comp.compType == compType) && (comp.name == name || !exact && strings.EqualFold(comp.name, name)) {
+				return componentRef(comp.compType, comp.name)
+			}
+		}
+	}
+	return ""
+}
+
+// lineRef returns the reference of the operation or component defined at a line
+// of the spec, the one declared last at or before it. Those removed since the
+// baseline are left out, being defined in another file.
+func (m *Model) lineRef(line int) string {
+	all := m.allItems()
+	ref, start := "", 0
+	consider := func(source any, itemRef, name string) {
+		if m.baseline.change(name).status == diffRemoved {
+			return
+		}
+		if pos, ok := sourcePosition(source); ok && pos.line <= line && pos.line > start {
+			ref, start = itemRef, pos.line
+		}
+	}
+	for _, ep := range all.endpoints {
+		consider(ep.source, operationRef(ep.path, ep.method), endpointName(ep))
+	}
+	for _, hook := range all.webhooks {
+		consider(hook.source, webhookRef(hook.name, hook.method), "")
+	}
+	for _, comp := range all.components {
+		consider(comp.source, componentRef(comp.compType, comp.name), componentName(comp))
+	}
+	return ref
+}
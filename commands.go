@@ -0,0 +1,244 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	v3low "github.com/pb33f/libopenapi/datamodel/low/v3"
+)
+
+// command is a subcommand like "oq export", run instead of the viewer
+type command struct {
+	name        string
+	args        string // Arguments after the flags, e.g. "[openapi-file]"
+	description string
+	run         func(fs *flag.FlagSet, args []string) error
+}
+
+// commands are the subcommands, in the order the usage lists them
+var commands []command
+
+func init() {
+	commands = []command{
+		{"diff", "<old-spec> <new-spec>", "Compare two versions of a spec and report breaking changes", runDiff},
+		{"changelog", "<old-spec> <new-spec>", "Write release notes of the changes between two versions of a spec", runChangelog},
+		{"validate", "[openapi-file]", "Check the spec against the OpenAPI specification and report every problem with its location", runValidate},
+		{"lint", "[openapi-file]", "Check the spec for missing operationIds, descriptions, error responses and more", runLint},
+		{"convert", "[openapi-file]", "Convert a spec between YAML and JSON, and between OpenAPI 3.0 and 3.1", runConvert},
+		{"filter", "[openapi-file]", "Write the part of a spec with the operations matching tags or path patterns", runFilter},
+		{"search", "<openapi-file> <query>", "Find operations, schemas and properties, printing their JSON pointers and lines", runSearch},
+		{"schema", "<name> [openapi-file]", "Print a schema of the spec with its references resolved", runSchema},
+		{"get", "<json-pointer> [openapi-file]", "Print the part of the spec at a JSON pointer with its references resolved", runGet},
+		{"curl", "<operation> [openapi-file]", "Print the curl command of an operation, given by operationId or as \"GET /pets\"", runCurl},
+		{"config", "", "Print the configuration in use, from the config file and OQ_* environment variables", runConfig},
+		{"completion", "<bash|zsh|fish>", "Print the shell completion script, completing schema names, operationIds and pointers from the spec", runCompletion},
+		{"test", "[openapi-file]", "Send the requests of the spec to a server and check the responses against it", runTest},
+		{"probe", "[openapi-file]", "Check that a server answers at the documented paths, and find common paths the spec lacks", runProbe},
+		{"coverage", "[openapi-file]", "Report which operations recorded traffic exercised and which requests the spec lacks", runCoverage},
+		{"stats", "[openapi-file]", "Print endpoint counts by method and tag, component counts and documentation coverage", runStats},
+		{"export", "[openapi-file]", "Export the spec as Markdown documentation or a Postman or Insomnia collection", runExport},
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// runCommand parses the flags of a subcommand and runs it
+func runCommand(cmd command, args []string) error {
+	fs := flag.NewFlagSet("oq "+cmd.name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: oq %s [flags] %s\n\n%s.\n\nFlags:\n", cmd.name, cmd.args, cmd.description)
+		fs.PrintDefaults()
+	}
+	return cmd.run(fs, args)
+}
+
+// errUsage is returned by commands whose arguments are invalid, after printing the usage
+var errUsage = errors.New("invalid arguments")
+
+// errFindings is returned by commands that ran fine but found problems, like
+// breaking changes, so that CI jobs fail. It exits with status 1 and no message.
+var errFindings = errors.New("findings reported")
+
+// parseFlags parses the flags of a command. The flag package already printed
+// what is wrong, so errors other than -help are reported as errUsage.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
+
+// stdinPath is the spec argument reading stdin, like giving none
+const stdinPath = "-"
+
+// readSpec reads the spec from path, or from stdin when path is empty or "-"
+func readSpec(path string) ([]byte, error) {
+	if path == "" || path == stdinPath {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("reading from stdin: %w", err)
+		}
+		return content, nil
+	}
+
+	if err := checkSpecFile(path); err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return content, nil
+}
+
+// openSpec opens the spec at path, or stdin when path is empty or "-", for the
+// viewer to read while showing its progress. The size is 0 when unknown, as for
+// a pipe.
+func openSpec(path string) (io.ReadCloser, int64, error) {
+	f := os.Stdin
+	if path != "" && path != stdinPath {
+		if err := checkSpecFile(path); err != nil {
+			return nil, 0, err
+		}
+		var err error
+		if f, err = os.Open(path); err != nil {
+			return nil, 0, fmt.Errorf("reading file: %w", err)
+		}
+	}
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return f, 0, nil
+	}
+	return f, info.Size(), nil
+}
+
+// checkSpecFile tells a missing spec file from a directory, and warns when a
+// spec is piped to stdin as well, as the file is read instead
+func checkSpecFile(path string) error {
+	info, err := os.Stat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("reading file: %s doesn't exist", path)
+	case err != nil:
+		return fmt.Errorf("reading file: %w", err)
+	case info.IsDir():
+		return fmt.Errorf("reading file: %s is a directory, not an OpenAPI file", path)
+	}
+	if stdinPiped() {
+		fmt.Fprintf(os.Stderr, "Warning: reading %s, ignoring the spec piped to stdin\n", path)
+	}
+	return nil
+}
+
+// stdinPiped reports whether something is piped or redirected to stdin, unlike
+// a terminal or /dev/null
+func stdinPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && (info.Mode()&os.ModeNamedPipe != 0 || info.Mode().IsRegular() && info.Size() > 0)
+}
+
+// loadSpec reads and parses the spec at path, or from stdin when path is empty or "-"
+func loadSpec(path string) (*v3.Document, error) {
+	content, err := readSpec(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseSpec(content)
+}
+
+func parseSpec(content []byte) (*v3.Document, error) {
+	doc, problems, err := parseSpecPartially(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("building V3 model: %w", errors.Join(problems...))
+	}
+	return doc, nil
+}
+
+// parseSpecPartially builds the model of a spec despite broken references or
+// parts failing to build, returning those errors alongside, so the viewer can
+// show what could be built. Only a spec that can't be parsed at all is an error.
+func parseSpecPartially(content []byte) (*v3.Document, []error, error) {
+	// The errors are returned, rather than logged over the viewer or the output
+	config := &datamodel.DocumentConfiguration{Logger: slog.New(slog.DiscardHandler)}
+	done := profile.phase("parse")
+	document, err := libopenapi.NewDocumentWithConfiguration(content, config)
+	done()
+	if err != nil {
+		if e := diagnoseSyntax(content); e != nil {
+			return nil, nil, fmt.Errorf("parsing spec: %w", e)
+		}
+		return nil, nil, fmt.Errorf("creating document: %w", err)
+	}
+
+	done = profile.phase("build model")
+	defer done()
+	v3Model, err := document.BuildV3Model()
+	if v3Model != nil {
+		return &v3Model.Model, unwrapErrors(err), nil
+	}
+
+	// libopenapi gives up on references to nothing, while the low level
+	// document has everything else
+	lowDoc, lowErr := v3low.CreateDocumentFromConfig(document.GetSpecInfo(), config)
+	if lowDoc == nil {
+		return nil, nil, fmt.Errorf("building V3 model: %w", err)
+	}
+	doc := v3.NewDocument(lowDoc)
+	doc.Rolodex = lowDoc.Index.GetRolodex()
+	return doc, unwrapErrors(lowErr), nil
+}
+
+// unwrapErrors splits errors joined with errors.Join
+func unwrapErrors(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	if err != nil {
+		return []error{err}
+	}
+	return nil
+}
+
+// specArg returns the spec file given to a command, empty to read stdin
+func specArg(fs *flag.FlagSet) (string, error) {
+	switch {
+	case fs.NArg() == 0:
+		return "", nil
+	case fs.NArg() == 1 && fs.Arg(0) == stdinPath:
+		return "", nil
+	case fs.NArg() == 1:
+		return fs.Arg(0), nil
+	}
+	fs.Usage()
+	return "", errUsage
+}
+
+// writeResult writes the output of a command to file, or to stdout when file is empty
+func writeResult(file string, data []byte) error {
+	if file == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(file, data, 0o644)
+}
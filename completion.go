@@ -0,0 +1,273 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// completeCommand is the hidden command the completion scripts call with the
+// words typed so far, the last one being completed. It prints one candidate
+// per line, with a tab before its description. When it prints nothing the
+// shells complete file names.
+const completeCommand = "__complete"
+
+// Spec files completions read names from when none was typed
+var defaultSpecFiles = []string{"openapi.yaml", "openapi.yml", "openapi.json", "swagger.yaml", "swagger.json"}
+
+type completion struct {
+	value       string
+	description string
+}
+
+// positionalCompletions complete the arguments of commands from the spec
+var positionalCompletions = map[string]func(position int, doc *yaml.Node) []completion{
+	"schema": func(position int, doc *yaml.Node) []completion {
+		if position != 0 || doc == nil {
+			return nil
+		}
+		var out []completion
+		_, components := field(doc, "components")
+		_, schemas := field(components, "schemas")
+		eachField(schemas, func(name, schema *yaml.Node) {
+			_, title := field(schema, "title")
+			c := completion{value: name.Value}
+			if title != nil {
+				c.description = title.Value
+			}
+			out = append(out, c)
+		})
+		return out
+	},
+	"get": func(position int, doc *yaml.Node) []completion {
+		if position != 0 || doc == nil {
+			return nil
+		}
+		var out []completion
+		for _, section := range []string{"paths", "webhooks"} {
+			_, items := field(doc, section)
+			eachField(items, func(path, item *yaml.Node) {
+				for _, method := range httpMethods {
+					if _, op := field(item, method); op != nil {
+						c := completion{value: pointerTo(pointerTo("#/"+section, path.Value), method)}
+						if _, id := field(op, "operationId"); id != nil {
+							c.description = id.Value
+						}
+						out = append(out, c)
+					}
+				}
+			})
+		}
+		_, components := field(doc, "components")
+		eachField(components, func(section, entries *yaml.Node) {
+			eachField(entries, func(name, _ *yaml.Node) {
+				out = append(out, completion{value: pointerTo(pointerTo("#/components", section.Value), name.Value)})
+			})
+		})
+		return out
+	},
+	"curl": func(position int, doc *yaml.Node) []completion {
+		if position != 0 || doc == nil {
+			return nil
+		}
+		var out []completion
+		_, paths := field(doc, "paths")
+		eachField(paths, func(path, item *yaml.Node) {
+			for _, method := range httpMethods {
+				_, op := field(item, method)
+				if _, id := field(op, "operationId"); id != nil {
+					out = append(out, completion{value: id.Value, description: strings.ToUpper(method) + " " + path.Value})
+				}
+			}
+		})
+		return out
+	},
+	"completion": func(position int, _ *yaml.Node) []completion {
+		if position != 0 {
+			return nil
+		}
+		return []completion{{value: "bash"}, {value: "zsh"}, {value: "fish"}}
+	},
+}
+
+// commandFlags returns the flags of a command, which declares them when run.
+// Asking for -help stops it right after.
+func commandFlags(cmd command) *flag.FlagSet {
+	fs := flag.NewFlagSet("oq "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+	_ = cmd.run(fs, []string{"-help"})
+	return fs
+}
+
+func flagCompletions(fs *flag.FlagSet) []completion {
+	var out []completion
+	fs.VisitAll(func(f *flag.Flag) {
+		out = append(out, completion{value: "-" + f.Name, description: f.Usage})
+	})
+	return out
+}
+
+func isBoolFlag(fs *flag.FlagSet, arg string) bool {
+	name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+	f := fs.Lookup(name)
+	if f == nil || hasValue {
+		return true
+	}
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func isSpecFile(arg string) bool {
+	switch strings.ToLower(filepath.Ext(arg)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// completionSpec finds the spec names are completed from: a spec file among
+// the words, or one with a usual name in the current directory
+func completionSpec(words []string) *yaml.Node {
+	candidates := defaultSpecFiles
+	for i := len(words) - 1; i >= 0; i-- {
+		if isSpecFile(words[i]) {
+			candidates = append([]string{words[i]}, candidates...)
+		}
+	}
+	for _, path := range candidates {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if root, err := parseSpecTree(content); err == nil {
+			return root.Content[0]
+		}
+	}
+	return nil
+}
+
+// completions returns the candidates for the last of the words typed after "oq"
+func completions(mainFlags *flag.FlagSet, words []string) []completion {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current, typed := words[len(words)-1], words[:len(words)-1]
+
+	var candidates []completion
+	cmd, isCommand := command{}, false
+	if len(typed) > 0 {
+		cmd, isCommand = findCommand(typed[0])
+	}
+	switch {
+	case !isCommand && strings.HasPrefix(current, "-"):
+		candidates = flagCompletions(mainFlags)
+	case !isCommand && len(typed) == 0:
+		for _, c := range commands {
+			candidates = append(candidates, completion{value: c.name, description: c.description})
+		}
+	case isCommand:
+		fs := commandFlags(cmd)
+		if strings.HasPrefix(current, "-") {
+			candidates = flagCompletions(fs)
+			break
+		}
+		// Count the arguments before the current one, skipping flags and their
+		// values, and spec files typed first to get their names completed
+		position := 0
+		args := typed[1:]
+		for i := 0; i < len(args); i++ {
+			switch {
+			case args[i] == "--", isSpecFile(args[i]):
+			case strings.HasPrefix(args[i], "-"):
+				if !isBoolFlag(fs, args[i]) {
+					i++
+				}
+			default:
+				position++
+			}
+		}
+		if i := len(args) - 1; i >= 0 && strings.HasPrefix(args[i], "-") && !isBoolFlag(fs, args[i]) {
+			return nil // Completing the value of a flag
+		}
+		if complete, ok := positionalCompletions[cmd.name]; ok {
+			candidates = complete(position, completionSpec(typed[1:]))
+		}
+	}
+
+	var out []completion
+	for _, c := range candidates {
+		if strings.HasPrefix(c.value, current) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func writeCompletions(w io.Writer, mainFlags *flag.FlagSet, words []string) error {
+	for _, c := range completions(mainFlags, words) {
+		line := c.value
+		if c.description != "" {
+			line += "\t" + strings.ReplaceAll(firstLine(c.description), "\t", " ")
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const bashCompletion = `# bash completion for oq
+_oq() {
+    local IFS=$'\n'
+    COMPREPLY=($(oq __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null | cut -f1))
+}
+complete -o default -F _oq oq
+`
+
+const zshCompletion = `#compdef oq
+_oq() {
+    local -a candidates
+    local line value description
+    for line in "${(@f)$(oq __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+        [[ -z $line ]] && continue
+        value=${line%%$'\t'*}
+        description=
+        [[ $line == *$'\t'* ]] && description=${line#*$'\t'}
+        candidates+=("${value//:/\\:}:$description")
+    done
+    if (( ${#candidates} )); then
+        _describe 'oq' candidates
+    else
+        _files
+    fi
+}
+compdef _oq oq
+`
+
+const fishCompletion = `# fish completion for oq
+complete -c oq -a '(oq __complete (commandline -opc)[2..-1] (commandline -ct))'
+`
+
+// runCompletion implements "oq completion"
+func runCompletion(fs *flag.FlagSet, args []string) error {
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	scripts := map[string]string{"bash": bashCompletion, "zsh": zshCompletion, "fish": fishCompletion}
+	script, ok := scripts[fs.Arg(0)]
+	if !ok {
+		return fmt.Errorf("unknown shell %q, use bash, zsh or fish", fs.Arg(0))
+	}
+	return writeResult("", []byte(script))
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// composition is one of the allOf, oneOf or anyOf keywords of a schema
+type composition struct {
+	keyword   string
+	separator string // Between branch labels, "&" for allOf and "|" otherwise
+	branches  []*base.SchemaProxy
+}
+
+func schemaCompositions(s *base.Schema) []composition {
+	var compositions []composition
+	for _, c := range []composition{
+		{"allOf", " & ", s.AllOf},
+		{"oneOf", " | ", s.OneOf},
+		{"anyOf", " | ", s.AnyOf},
+	} {
+		if len(c.branches) > 0 {
+			compositions = append(compositions, c)
+		}
+	}
+	return compositions
+}
+
+// branchLabel names a branch by its reference, its title or its type
+func branchLabel(proxy *base.SchemaProxy) string {
+	if proxy == nil {
+		return "unknown"
+	}
+	if proxy.IsReference() {
+		return refName(proxy.GetReference())
+	}
+	if s := proxy.Schema(); s != nil && s.Title != "" {
+		return s.Title
+	}
+	return schemaTypeLabel(proxy)
+}
+
+func (c composition) branchLabels() string {
+	var labels []string
+	for _, branch := range c.branches {
+		labels = append(labels, branchLabel(branch))
+	}
+	return strings.Join(labels, c.separator)
+}
+
+// label describes the composition on one line, e.g. "oneOf: Cat | Dog | Hamster"
+func (c composition) label() string {
+	return c.keyword + ": " + c.branchLabels()
+}
+
+// short describes the composition within a type label, e.g. "oneOf(Cat | Dog)"
+func (c composition) short() string {
+	return c.keyword + "(" + c.branchLabels() + ")"
+}
+
+// writeCompositions lists the composition keywords of a schema with their branches,
+// followed by the discriminator. When depth is greater than the default, the
+// properties of each branch are shown below it.
+func writeCompositions(details *strings.Builder, s *base.Schema, indent string, depth int, ancestors []string) {
+	for _, c := range schemaCompositions(s) {
+		details.WriteString(indent + c.label() + "\n")
+		if depth <= defaultSchemaDepth {
+			continue
+		}
+
+		for _, branch := range c.branches {
+			if branch == nil {
+				continue
+			}
+			ref := branch.GetReference()
+			if ref != "" && slices.Contains(ancestors, ref) {
+				details.WriteString(fmt.Sprintf("%s  - %s %s (circular)\n", indent, branchLabel(branch), icons.circular))
+				continue
+			}
+
+			details.WriteString(fmt.Sprintf("%s  - %s\n", indent, branchLabel(branch)))
+			next := ancestors
+			if ref != "" {
+				next = append(slices.Clone(ancestors), ref)
+			}
+			writeSchemaTree(details, branch.Schema(), indent+"    ", depth-1, next)
+		}
+	}
+
+	if d := s.Discriminator; d != nil && d.PropertyName != "" {
+		details.WriteString(fmt.Sprintf("%sDiscriminator: %s\n", indent, d.PropertyName))
+		if d.Mapping != nil {
+			missing := missingMappings(s)
+			for pair := d.Mapping.First(); pair != nil; pair = pair.Next() {
+				marker := ""
+				if missing[pair.Value()] {
+					marker = fmt.Sprintf(" %s missing", icons.warning)
+				}
+				details.WriteString(fmt.Sprintf("%s  %s %s %s%s\n", indent, pair.Key(), icons.link, refName(pair.Value()), marker))
+			}
+		}
+	}
+}
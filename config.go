@@ -0,0 +1,466 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// config is the user configuration read from ~/.config/oq/config.yaml
+type config struct {
+	// Theme is the name of the color theme, overridden by the --theme flag
+	Theme string `yaml:"theme"`
+
+	// ASCII replaces Unicode icons and borders with plain characters, like --ascii
+	ASCII bool `yaml:"ascii"`
+
+	// Accessible renders the viewer for screen readers, like --accessible
+	Accessible bool `yaml:"accessible"`
+
+	// Columns lists the extra columns of the endpoint list: operationId and summary.
+	// Left out, both are shown, and an empty list shows paths only.
+	Columns *[]string `yaml:"columns"`
+
+	// ComponentColumns lists the extra columns of the component list: description.
+	// Left out, it is shown, and an empty list shows names only.
+	ComponentColumns *[]string `yaml:"component_columns"`
+
+	// HideDetails lists the sections left out of the details of operations and
+	// webhooks, e.g. description and extensions
+	HideDetails []string `yaml:"hide_details"`
+
+	// Keys maps action names to the keys that trigger them, replacing the defaults
+	Keys map[string][]string `yaml:"keys"`
+
+	// Environments are named targets for requests sent with Try-it, e.g. dev and prod
+	Environments map[string]environment `yaml:"environments"`
+
+	// Environment is the environment active at startup, overridden by the --env flag
+	Environment string `yaml:"environment"`
+
+	// Headers are sent with every request, before the headers of the environment
+	Headers map[string]string `yaml:"headers"`
+
+	// Timeout limits how long Try-it requests take, e.g. 10s
+	Timeout string `yaml:"timeout"`
+
+	// Output is the format the commands print in by default: text or json
+	Output string `yaml:"output"`
+
+	// Editor opens the spec, instead of $VISUAL or $EDITOR
+	Editor string `yaml:"editor"`
+
+	// SchemaDepth is how many levels of schema properties the details show at
+	// startup, deeper ones being counted until expanded with +
+	SchemaDepth int `yaml:"schema_depth"`
+
+	// Plugins are external programs adding to the details or run with a key
+	Plugins []plugin `yaml:"plugins"`
+
+	// Hooks run commands with the selected item when it is selected, copied or exported
+	Hooks hooks `yaml:"hooks"`
+
+	path      string   // Where the config was read from
+	overrides []string // The environment variables that replaced settings
+}
+
+// envOverrides are the environment variables that take precedence over the
+// config file, and the settings they replace
+var envOverrides = []struct {
+	name, setting string
+}{
+	{"OQ_THEME", "theme"},
+	{"OQ_ASCII", "ascii"},
+	{"OQ_ACCESSIBLE", "accessible"},
+	{"OQ_ENVIRONMENT", "environment"},
+	{"OQ_TIMEOUT", "timeout"},
+	{"OQ_OUTPUT", "output"},
+	{"OQ_EDITOR", "editor"},
+}
+
+// environment overrides the server and credentials of sample requests.
+// Values may refer to environment variables, e.g. token: $STAGING_TOKEN.
+type environment struct {
+	BaseURL string            `yaml:"base_url"`
+	Token   string            `yaml:"token"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+// configPath returns the location of the config file, following XDG_CONFIG_HOME when set
+func configPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "oq", "config.yaml"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "oq", "config.yaml"), nil
+}
+
+// loadUserConfig reads the config file, from $OQ_CONFIG when set, and applies
+// the OQ_* environment variables on top of it
+func loadUserConfig() (config, error) {
+	path := os.Getenv("OQ_CONFIG")
+	if path == "" {
+		var err error
+		if path, err = configPath(); err != nil {
+			return config{}, fmt.Errorf("locating config: %w", err)
+		}
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.path = path
+	return cfg, cfg.applyEnv(os.Getenv)
+}
+
+func (c *config) applyEnv(getenv func(string) string) error {
+	for _, o := range envOverrides {
+		value := getenv(o.name)
+		if value == "" {
+			continue
+		}
+		switch o.setting {
+		case "theme":
+			c.Theme = value
+		case "ascii":
+			ascii, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%s: %q is not a boolean", o.name, value)
+			}
+			c.ASCII = ascii
+		case "accessible":
+			accessible, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%s: %q is not a boolean", o.name, value)
+			}
+			c.Accessible = accessible
+		case "environment":
+			c.Environment = value
+		case "timeout":
+			c.Timeout = value
+		case "output":
+			c.Output = value
+		case "editor":
+			c.Editor = value
+		}
+		c.overrides = append(c.overrides, o.name)
+	}
+	return nil
+}
+
+// loadConfig reads the config file at path. A missing file is not an error.
+func loadConfig(path string) (config, error) {
+	var cfg config
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// applyDisplay sets the theme and icons from the config, the theme flag taking
+// precedence over the config
+func (c config) applyDisplay(themeName string, ascii, accessible bool) error {
+	if themeName != "" {
+		c.Theme = themeName
+	}
+	accessible = accessible || c.Accessible
+	// NO_COLOR (https://no-color.org) applies unless a theme was chosen explicitly,
+	// and so does the accessible mode, where colors signal nothing text doesn't
+	if c.Theme == "" && (os.Getenv("NO_COLOR") != "" || accessible) {
+		c.Theme = "monochrome"
+	}
+	if c.Theme != "" {
+		if err := setTheme(c.Theme); err != nil {
+			return err
+		}
+	}
+
+	setASCII(c.ASCII || ascii)
+	if accessible {
+		icons = accessibleIcons
+	}
+	return nil
+}
+
+// timeout returns the Try-it request timeout from the config, or the default
+func (c config) timeout() (time.Duration, error) {
+	if c.Timeout == "" {
+		return defaultTimeout, nil
+	}
+	d, err := time.ParseDuration(c.Timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q, use a duration like 10s", c.Timeout)
+	}
+	return d, nil
+}
+
+// applyDefaults sets the request timeout and the output format of the commands
+// from the config
+func (c config) applyDefaults() error {
+	timeout, err := c.timeout()
+	if err != nil {
+		return err
+	}
+	switch c.Output {
+	case "":
+	case outputText, outputJSON:
+		defaultOutput = c.Output
+	default:
+		return fmt.Errorf("unknown output format %q, use %s or %s", c.Output, outputText, outputJSON)
+	}
+	httpClient.Timeout = timeout
+	return nil
+}
+
+// schemaDepth returns the depth schema properties are shown to at startup
+func (c config) schemaDepth() (int, error) {
+	if c.SchemaDepth == 0 {
+		return defaultSchemaDepth, nil
+	}
+	if c.SchemaDepth < 1 || c.SchemaDepth > maxSchemaDepth {
+		return 0, fmt.Errorf("invalid schema_depth %d, use 1 to %d", c.SchemaDepth, maxSchemaDepth)
+	}
+	return c.SchemaDepth, nil
+}
+
+// headers returns the default request headers with environment variables expanded
+func (c config) headers() map[string]string {
+	headers := make(map[string]string)
+	for key, value := range c.Headers {
+		headers[key] = os.ExpandEnv(value)
+	}
+	return headers
+}
+
+// columns returns the endpoint list columns from the config, or the defaults
+func (c config) columns() ([]string, error) {
+	if c.Columns == nil {
+		return defaultColumns, nil
+	}
+
+	for _, column := range *c.Columns {
+		if column != columnOperationID && column != columnSummary {
+			return nil, fmt.Errorf("unknown column %q, available columns: %s, %s", column, columnOperationID, columnSummary)
+		}
+	}
+	return *c.Columns, nil
+}
+
+// componentColumns returns the component list columns from the config, or the defaults
+func (c config) componentColumns() ([]string, error) {
+	if c.ComponentColumns == nil {
+		return defaultComponentColumns, nil
+	}
+
+	for _, column := range *c.ComponentColumns {
+		if column != columnDescription {
+			return nil, fmt.Errorf("unknown component column %q, available columns: %s", column, columnDescription)
+		}
+	}
+	return *c.ComponentColumns, nil
+}
+
+// hiddenDetails returns the detail sections the config hides
+func (c config) hiddenDetails() (detailSection, error) {
+	var hidden detailSection
+	for _, name := range c.HideDetails {
+		found := false
+		for _, s := range detailSectionNames {
+			if s.name == name {
+				hidden |= s.section
+				found = true
+			}
+		}
+		if !found {
+			var names []string
+			for _, s := range detailSectionNames {
+				names = append(names, s.name)
+			}
+			return 0, fmt.Errorf("unknown detail section %q, available sections: %s", name, strings.Join(names, ", "))
+		}
+	}
+	return hidden, nil
+}
+
+// environments returns the configured environments with environment variables
+// expanded, checking that the active one exists
+func (c config) environments() (map[string]environment, error) {
+	if c.Environment != "" {
+		if _, ok := c.Environments[c.Environment]; !ok {
+			return nil, fmt.Errorf("unknown environment %q", c.Environment)
+		}
+	}
+
+	envs := make(map[string]environment)
+	for name, env := range c.Environments {
+		env.BaseURL = os.ExpandEnv(env.BaseURL)
+		env.Token = os.ExpandEnv(env.Token)
+		headers := make(map[string]string)
+		for key, value := range env.Headers {
+			headers[key] = os.ExpandEnv(value)
+		}
+		env.Headers = headers
+		envs[name] = env
+	}
+	return envs, nil
+}
+
+// keyMap returns the default keymap with the bindings from the config applied,
+// and the keys of the plugins, which take them over from the default actions
+func (c config) keyMap() (keyMap, error) {
+	km := defaultKeyMap()
+	overrides := make(map[string][]string)
+	for _, p := range c.Plugins {
+		if p.Key != "" {
+			km.bindings[pluginAction(p.Name)] = nil
+			overrides[string(pluginAction(p.Name))] = []string{p.Key}
+		}
+	}
+	for name, keys := range c.Keys {
+		overrides[name] = keys
+	}
+	return km.withOverrides(overrides)
+}
+
+// plugins returns the plugins of the config, each named and run either for
+// the details or with a key
+func (c config) plugins() ([]plugin, error) {
+	names := make(map[string]bool)
+	for _, p := range c.Plugins {
+		switch {
+		case p.Name == "":
+			return nil, fmt.Errorf("plugin without a name")
+		case names[p.Name]:
+			return nil, fmt.Errorf("two plugins named %q", p.Name)
+		case strings.TrimSpace(p.Command) == "":
+			return nil, fmt.Errorf("plugin %q has no command", p.Name)
+		case !p.Details && p.Key == "":
+			return nil, fmt.Errorf("plugin %q needs details: true or a key", p.Name)
+		}
+		names[p.Name] = true
+	}
+	return c.Plugins, nil
+}
+
+// effective returns the configuration in use, with the defaults filled in
+func (c config) effective() (config, error) {
+	keys, err := c.keyMap()
+	if err != nil {
+		return c, fmt.Errorf("config keys: %w", err)
+	}
+	c.Keys = make(map[string][]string)
+	for act, bound := range keys.bindings {
+		var names []string
+		for _, key := range bound {
+			if key == " " {
+				key = "space"
+			}
+			names = append(names, key)
+		}
+		c.Keys[string(act)] = names
+	}
+
+	columns, err := c.columns()
+	if err != nil {
+		return c, err
+	}
+	c.Columns = &columns
+
+	componentColumns, err := c.componentColumns()
+	if err != nil {
+		return c, err
+	}
+	c.ComponentColumns = &componentColumns
+	if _, err := c.hiddenDetails(); err != nil {
+		return c, err
+	}
+	if _, err := c.plugins(); err != nil {
+		return c, err
+	}
+
+	if c.Theme == "" {
+		c.Theme = defaultThemeName
+		if os.Getenv("NO_COLOR") != "" {
+			c.Theme = "monochrome"
+		}
+	}
+	timeout, err := c.timeout()
+	if err != nil {
+		return c, err
+	}
+	c.Timeout = timeout.String()
+	if c.Output == "" {
+		c.Output = outputText
+	}
+	if c.Editor == "" {
+		c.Editor = cmp.Or(os.Getenv("VISUAL"), os.Getenv("EDITOR"))
+	}
+	if c.SchemaDepth, err = c.schemaDepth(); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// runConfig implements "oq config"
+func runConfig(fs *flag.FlagSet, args []string) error {
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return err
+	}
+	effective, err := cfg.effective()
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if _, err := os.Stat(cfg.path); err == nil {
+		fmt.Fprintf(&out, "# Config file: %s\n", cfg.path)
+	} else {
+		fmt.Fprintf(&out, "# Config file: %s (not found, using the defaults)\n", cfg.path)
+	}
+	if len(cfg.overrides) > 0 {
+		fmt.Fprintf(&out, "# Overridden by %s\n", strings.Join(cfg.overrides, ", "))
+	}
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(effective); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return writeResult("", out.Bytes())
+}
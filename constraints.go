@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
+)
+
+// valueText shows a schema value on one line: scalars as written, objects and
+// arrays as compact JSON
+func valueText(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		if node.Value == "" {
+			return `""`
+		}
+		return node.Value
+	}
+	return strings.Join(strings.Fields(nodeToJSON(node, "")), " ")
+}
+
+// literalText shows a value as it would be in JSON, so that a string constant
+// like "active" doesn't read as a type
+func literalText(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+		return strconv.Quote(node.Value)
+	}
+	return valueText(node)
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// enumText lists the allowed values of a schema, e.g. "available | pending | sold"
+func enumText(s *base.Schema) string {
+	var values []string
+	for _, value := range s.Enum {
+		if value != nil {
+			values = append(values, valueText(value))
+		}
+	}
+	return strings.Join(values, " | ")
+}
+
+// schemaConstraints describes the validation keywords of a schema, e.g.
+// "minimum: 1" or "pattern: ^[a-z]+$". In OpenAPI 3.0 exclusiveMinimum and
+// exclusiveMaximum are flags on minimum and maximum, in 3.1 they are bounds.
+func schemaConstraints(s *base.Schema) []string {
+	var constraints []string
+	add := func(keyword, value string) {
+		constraints = append(constraints, keyword+": "+value)
+	}
+
+	switch {
+	case s.ExclusiveMinimum != nil && s.ExclusiveMinimum.IsB():
+		add("exclusiveMinimum", formatNumber(s.ExclusiveMinimum.B))
+	case s.Minimum != nil && s.ExclusiveMinimum != nil && s.ExclusiveMinimum.A:
+		add("exclusiveMinimum", formatNumber(*s.Minimum))
+	case s.Minimum != nil:
+		add("minimum", formatNumber(*s.Minimum))
+	}
+	switch {
+	case s.ExclusiveMaximum != nil && s.ExclusiveMaximum.IsB():
+		add("exclusiveMaximum", formatNumber(s.ExclusiveMaximum.B))
+	case s.Maximum != nil && s.ExclusiveMaximum != nil && s.ExclusiveMaximum.A:
+		add("exclusiveMaximum", formatNumber(*s.Maximum))
+	case s.Maximum != nil:
+		add("maximum", formatNumber(*s.Maximum))
+	}
+	if s.MultipleOf != nil {
+		add("multipleOf", formatNumber(*s.MultipleOf))
+	}
+	if s.MinLength != nil {
+		add("minLength", strconv.FormatInt(*s.MinLength, 10))
+	}
+	if s.MaxLength != nil {
+		add("maxLength", strconv.FormatInt(*s.MaxLength, 10))
+	}
+	if s.Pattern != "" {
+		add("pattern", s.Pattern)
+	}
+	if s.MinItems != nil {
+		add("minItems", strconv.FormatInt(*s.MinItems, 10))
+	}
+	if s.MaxItems != nil {
+		add("maxItems", strconv.FormatInt(*s.MaxItems, 10))
+	}
+	if s.UniqueItems != nil && *s.UniqueItems {
+		constraints = append(constraints, "uniqueItems")
+	}
+
+	return constraints
+}
+
+// writeSchemaFacts writes the const, enum, default and constraints of a schema one per
+// line, like "Enum: available | sold", below the type of a schema or parameter
+func writeSchemaFacts(details *strings.Builder, s *base.Schema, indent string) {
+	if s.Const != nil {
+		details.WriteString(fmt.Sprintf("%sConst: %s\n", indent, valueText(s.Const)))
+	}
+	if len(s.Enum) > 0 {
+		details.WriteString(fmt.Sprintf("%sEnum: %s\n", indent, enumText(s)))
+	}
+	if s.Default != nil {
+		details.WriteString(fmt.Sprintf("%sDefault: %s\n", indent, valueText(s.Default)))
+	}
+	if constraints := schemaConstraints(s); len(constraints) > 0 {
+		details.WriteString(fmt.Sprintf("%sConstraints: %s\n", indent, strings.Join(constraints, ", ")))
+	}
+}
+
+// schemaNotes summarizes the format, content encoding, enum, default and
+// constraints of a schema in a few words each, for the single line a property
+// or parameter gets
+func schemaNotes(s *base.Schema) []string {
+	var notes []string
+	if s.Format != "" {
+		notes = append(notes, "format: "+s.Format)
+	}
+	// OpenAPI 3.1 tells files and binary strings by these instead of a format
+	if low := s.GoLow(); low != nil {
+		if encoding := low.ContentEncoding.Value; encoding != "" {
+			notes = append(notes, "encoding: "+encoding)
+		}
+		if mediaType := low.ContentMediaType.Value; mediaType != "" {
+			notes = append(notes, "media type: "+mediaType)
+		}
+	}
+	if len(s.Enum) > 0 {
+		notes = append(notes, "enum: "+enumText(s))
+	}
+	if s.Default != nil {
+		notes = append(notes, "default: "+valueText(s.Default))
+	}
+	return append(notes, schemaConstraints(s)...)
+}
+
+// schemaSummary describes a schema with its notes, e.g.
+// "integer (format: int32, default: 20, maximum: 100)". A constant is shown as
+// its value, e.g. `"active" (const)`.
+func schemaSummary(proxy *base.SchemaProxy) string {
+	label := schemaTypeLabel(proxy)
+	if proxy == nil || proxy.Schema() == nil {
+		return label
+	}
+	notes := schemaNotes(proxy.Schema())
+	if c := proxy.Schema().Const; c != nil {
+		label = literalText(c)
+		notes = append([]string{"const"}, notes...)
+	}
+	if len(notes) > 0 {
+		label += " (" + strings.Join(notes, ", ") + ")"
+	}
+	return label
+}
+
+// schemaFlags tells how a property is used: whether it is only sent by the server
+// (read-only) or by the client (write-only), nullable in OpenAPI 3.0 style, sealed
+// against additional properties, or deprecated. OpenAPI 3.1 nullability shows in the type, e.g. "string | null".
+func schemaFlags(s *base.Schema) []string {
+	var flags []string
+	if s.ReadOnly != nil && *s.ReadOnly {
+		flags = append(flags, "read-only")
+	}
+	if s.WriteOnly != nil && *s.WriteOnly {
+		flags = append(flags, "write-only")
+	}
+	if s.Nullable != nil && *s.Nullable {
+		flags = append(flags, "nullable")
+	}
+	sealed := func(v *base.DynamicValue[*base.SchemaProxy, bool]) bool { return v != nil && v.IsB() && !v.B }
+	if sealed(s.AdditionalProperties) || sealed(s.UnevaluatedProperties) {
+		flags = append(flags, "sealed")
+	}
+	if schemaDeprecation(s).deprecated {
+		flags = append(flags, "deprecated")
+	}
+	return flags
+}
+
+// xmlText describes how a schema is serialized as XML, e.g. "name: pet" or
+// "wrapped, items: photoUrl". Item names are included, as they name the elements
+// of a wrapped array.
+func xmlText(s *base.Schema) string {
+	var parts []string
+	if x := s.XML; x != nil {
+		if x.Name != "" {
+			parts = append(parts, "name: "+x.Name)
+		}
+		if x.Namespace != "" {
+			parts = append(parts, "namespace: "+x.Namespace)
+		}
+		if x.Prefix != "" {
+			parts = append(parts, "prefix: "+x.Prefix)
+		}
+		if x.NodeType != "" {
+			parts = append(parts, "nodeType: "+x.NodeType)
+		}
+		if x.Attribute {
+			parts = append(parts, "attribute")
+		}
+		if x.Wrapped {
+			parts = append(parts, "wrapped")
+		}
+	}
+	if s.Items != nil && s.Items.IsA() && s.Items.A != nil {
+		if items := s.Items.A.Schema(); items != nil && items.XML != nil && items.XML.Name != "" {
+			parts = append(parts, "items: "+items.XML.Name)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// safeMethods are sent by "oq test" unless -method says otherwise, as they
+// should not change anything on the server
+var safeMethods = []string{"GET", "HEAD", "OPTIONS"}
+
+// Results of a contract test
+const (
+	contractPass = "pass"
+	contractFail = "fail"
+	contractSkip = "skip"
+)
+
+// contractResult is the outcome of testing one operation against a server
+type contractResult struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	URL        string   `json:"url,omitempty"`
+	Result     string   `json:"result"`
+	Status     int      `json:"status,omitempty"`
+	DurationMS int64    `json:"durationMs,omitempty"`
+	Problems   []string `json:"problems,omitempty"` // Or why the operation was skipped
+}
+
+// contractSuite selects the operations to test and how to send their requests
+type contractSuite struct {
+	methods []string
+	tags    []string
+	paths   []*regexp.Regexp
+	params  map[string]string // Values for parameters without an example
+	env     environment       // Base URL and headers, applied on top of the defaults
+	headers map[string]string
+}
+
+func (s contractSuite) selects(ep endpoint) bool {
+	if !slices.Contains(s.methods, ep.method) {
+		return false
+	}
+	if len(s.paths) > 0 && !slices.ContainsFunc(s.paths, func(re *regexp.Regexp) bool { return re.MatchString(ep.path) }) {
+		return false
+	}
+	return len(s.tags) == 0 || slices.ContainsFunc(ep.op.Tags, func(tag string) bool { return slices.Contains(s.tags, tag) })
+}
+
+// placeholderPattern finds the "<name>" placeholders left in a sample request
+// for parameters without an example
+var placeholderPattern = regexp.MustCompile(`<([^<>/?&=]+)>`)
+
+// request builds the request of an operation, filling the placeholders of its
+// URL and headers with -param values. It returns the parameters still missing
+// a value.
+func (s contractSuite) request(doc *v3.Document, ep endpoint) (sampleRequest, []string) {
+	req := buildSampleRequest(doc, ep)
+	if len(s.headers) > 0 {
+		req = req.withEnvironment(environment{Headers: s.headers})
+	}
+	req = req.withEnvironment(s.env)
+
+	var missing []string
+	fill := func(text string, escape func(string) string) string {
+		return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+			name := placeholder[1 : len(placeholder)-1]
+			if value, ok := s.params[name]; ok {
+				return escape(value)
+			}
+			if !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+			return placeholder
+		})
+	}
+	req.url = fill(req.url, url.PathEscape)
+	headers := make([]requestField, len(req.headers))
+	for i, h := range req.headers {
+		headers[i] = requestField{h.name, fill(h.value, func(value string) string { return value })}
+	}
+	req.headers = headers
+	return req, missing
+}
+
+// testOperation sends the request of an operation and checks that the server
+// answers with a success the spec documents, with a body matching its schema
+func (s contractSuite) testOperation(doc *v3.Document, ep endpoint) contractResult {
+	result := contractResult{Method: ep.method, Path: ep.path}
+	req, missing := s.request(doc, ep)
+	result.URL = req.url
+	if len(missing) > 0 {
+		result.Result = contractSkip
+		for _, name := range missing {
+			result.Problems = append(result.Problems, fmt.Sprintf("no value for %s, give one with -param %s=...", name, name))
+		}
+		return result
+	}
+
+	resp := doRequest(req, ep.op)
+	result.Status = resp.status
+	result.DurationMS = resp.duration.Milliseconds()
+	result.Problems = resp.problems
+	if resp.err != nil {
+		result.Problems = append(result.Problems, resp.err.Error())
+	} else if resp.status >= 400 {
+		result.Problems = append([]string{fmt.Sprintf("status %d is not a success", resp.status)}, result.Problems...)
+	}
+
+	result.Result = contractPass
+	if len(result.Problems) > 0 {
+		result.Result = contractFail
+	}
+	return result
+}
+
+func (s contractSuite) run(doc *v3.Document, endpoints []endpoint) []contractResult {
+	results := []contractResult{}
+	for _, ep := range endpoints {
+		if s.selects(ep) {
+			results = append(results, s.testOperation(doc, ep))
+		}
+	}
+	return results
+}
+
+func countResults(results []contractResult, result string) int {
+	n := 0
+	for _, r := range results {
+		if r.Result == result {
+			n++
+		}
+	}
+	return n
+}
+
+func writeContractText(w io.Writer, results []contractResult) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, r := range results {
+		status := ""
+		if r.Status > 0 {
+			status = fmt.Sprintf("%d\t%dms", r.Status, r.DurationMS)
+		}
+		fmt.Fprintf(tw, "%s\t%s %s\t%s\n", strings.ToUpper(r.Result), r.Method, r.Path, status)
+		for _, problem := range r.Problems {
+			fmt.Fprintf(tw, "\t  %s\n", problem)
+		}
+	}
+	fmt.Fprintf(tw, "\n%d passed, %d failed, %d skipped\n", countResults(results, contractPass), countResults(results, contractFail), countResults(results, contractSkip))
+	return tw.Flush()
+}
+
+// contractJUnit makes a test case of every operation tested
+func contractJUnit(results []contractResult) []junitTestCase {
+	var cases []junitTestCase
+	for _, r := range results {
+		c := junitTestCase{Name: r.Method + " " + r.Path, ClassName: "oq.test"}
+		switch r.Result {
+		case contractFail:
+			c.Failure = &junitFailure{Message: r.Problems[0], Type: "contract", Text: r.URL + "\n" + strings.Join(r.Problems, "\n")}
+		case contractSkip:
+			c.Skipped = &junitSkipped{Message: strings.Join(r.Problems, ", ")}
+		}
+		cases = append(cases, c)
+	}
+	return cases
+}
+
+// requestFlags are the flags of the commands sending requests to a server
+type requestFlags struct {
+	baseURL string
+	envName string
+	params  stringList
+	headers stringList
+}
+
+func addRequestFlags(fs *flag.FlagSet) *requestFlags {
+	f := &requestFlags{}
+	fs.StringVar(&f.baseURL, "base-url", "", "server to send requests to, instead of the first server of the spec")
+	fs.StringVar(&f.envName, "env", "", "environment from the config file to send requests to")
+	fs.Var(&f.params, "param", "value of a parameter without an example, as name=value, can be repeated")
+	fs.Var(&f.headers, "header", "header sent with every request, as 'Name: value', can be repeated")
+	return f
+}
+
+// suite returns how to send requests according to the flags, the config file
+// and its environments
+func (f *requestFlags) suite() (contractSuite, error) {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return contractSuite{}, err
+	}
+	suite := contractSuite{methods: safeMethods, params: map[string]string{}, headers: cfg.headers()}
+	for _, p := range f.params {
+		name, value, ok := strings.Cut(p, "=")
+		if !ok {
+			return suite, fmt.Errorf("invalid -param %q, use name=value", p)
+		}
+		suite.params[name] = value
+	}
+
+	if f.envName != "" {
+		cfg.Environment = f.envName
+	}
+	envs, err := cfg.environments()
+	if err != nil {
+		return suite, err
+	}
+	suite.env = envs[cfg.Environment]
+	if f.baseURL != "" {
+		suite.env.BaseURL = f.baseURL
+	}
+	for _, h := range f.headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return suite, fmt.Errorf("invalid -header %q, use 'Name: value'", h)
+		}
+		if suite.env.Headers == nil {
+			suite.env.Headers = map[string]string{}
+		}
+		suite.env.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return suite, nil
+}
+
+// runTest implements "oq test". It exits with status 1 when an operation fails.
+func runTest(fs *flag.FlagSet, args []string) error {
+	server := addRequestFlags(fs)
+	var methods, tags, paths stringList
+	fs.Var(&methods, "method", "test the operations with this method, can be repeated (default GET, HEAD and OPTIONS)")
+	fs.Var(&tags, "tag", "test the operations with this tag, can be repeated")
+	fs.Var(&paths, "path", "test the operations under paths matching this pattern, e.g. '/v2/**', can be repeated")
+	output := fs.String("output", defaultOutput, "output format: text, json or junit")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	path, err := specArg(fs)
+	if err != nil {
+		return err
+	}
+	if *output != outputText && *output != outputJSON && *output != formatJUnit {
+		return fmt.Errorf("unknown output format %q, use %s, %s or %s", *output, outputText, outputJSON, formatJUnit)
+	}
+
+	suite, err := server.suite()
+	if err != nil {
+		return err
+	}
+	suite.tags = tags
+	if len(methods) > 0 {
+		suite.methods = nil
+		for _, method := range methods {
+			suite.methods = append(suite.methods, strings.ToUpper(method))
+		}
+	}
+	for _, p := range paths {
+		suite.paths = append(suite.paths, pathGlob(p))
+	}
+
+	doc, err := loadSpec(path)
+	if err != nil {
+		return err
+	}
+	results := suite.run(doc, extractEndpoints(doc))
+	if len(results) == 0 {
+		return fmt.Errorf("no operations to test, check -method, -tag and -path")
+	}
+
+	switch *output {
+	case outputJSON:
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		err = writeResult("", append(data, '\n'))
+	case formatJUnit:
+		err = writeJUnit(os.Stdout, "oq test "+path, contractJUnit(results))
+	default:
+		err = writeContractText(os.Stdout, results)
+	}
+	if err != nil {
+		return err
+	}
+
+	if countResults(results, contractFail) > 0 {
+		return errFindings
+	}
+	return nil
+}
@@ -0,0 +1,499 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"go.yaml.in/yaml/v4"
+)
+
+const (
+	formatYAML = "yaml"
+	formatJSON = "json"
+
+	version30 = "3.0"
+	version31 = "3.1"
+)
+
+// Keywords of JSON Schema 2020-12 that OpenAPI 3.0 schemas don't have
+var unsupportedIn30 = []string{
+	"$schema", "$id", "$anchor", "$dynamicAnchor", "$dynamicRef", "$defs", "$comment",
+	"if", "then", "else", "dependentSchemas", "dependentRequired", "prefixItems",
+	"contains", "minContains", "maxContains", "propertyNames", "patternProperties",
+	"unevaluatedItems", "unevaluatedProperties",
+}
+
+// converter rewrites a spec between OpenAPI 3.0 and 3.1 in place, on the YAML
+// tree so that the key order and comments are kept
+type converter struct {
+	to     string
+	losses []conversionLoss
+}
+
+// conversionLoss is a keyword the conversion dropped, or rewrote as one the
+// other version has, so users know the converted spec doesn't say quite the same
+type conversionLoss struct {
+	pointer string
+	keyword string // e.g. "prefixItems"
+	as      string // What the keyword was rewritten as, empty when dropped
+	message string
+}
+
+func (l conversionLoss) String() string {
+	return l.message + " (" + l.pointer + ")"
+}
+
+// drop records a keyword removed from the spec
+func (c *converter) drop(pointer, keyword, format string, args ...any) {
+	c.losses = append(c.losses, conversionLoss{pointer: pointer, keyword: keyword, message: fmt.Sprintf(format, args...)})
+}
+
+// rewrite records a keyword replaced by another, which may not mean exactly the same
+func (c *converter) rewrite(pointer, keyword, as, format string, args ...any) {
+	c.losses = append(c.losses, conversionLoss{pointer: pointer, keyword: keyword, as: as, message: fmt.Sprintf(format, args...)})
+}
+
+// convertSpec parses a spec and converts it to the OpenAPI version to, "3.0" or
+// "3.1". An empty to keeps the version. The losses list what was dropped or
+// rewritten as something the other version has.
+func convertSpec(content []byte, to string) (*yaml.Node, []conversionLoss, error) {
+	root, err := parseSpecTree(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc := root.Content[0]
+
+	_, versionNode := field(doc, "openapi")
+	if versionNode == nil {
+		return nil, nil, fmt.Errorf("only OpenAPI 3.0 and 3.1 specs can be converted")
+	}
+	from := ""
+	for _, v := range []string{version30, version31} {
+		if versionNode.Value == v || strings.HasPrefix(versionNode.Value, v+".") {
+			from = v
+		}
+	}
+	if from == "" {
+		return nil, nil, fmt.Errorf("unsupported OpenAPI version %q, only 3.0 and 3.1 specs can be converted", versionNode.Value)
+	}
+
+	c := &converter{to: to}
+	switch {
+	case to == "" || to == from:
+	case to == version30:
+		versionNode.Value = "3.0.3"
+		c.downgradeDocument(doc)
+		walkSchemas(doc, c.schema)
+	case to == version31:
+		versionNode.Value = "3.1.0"
+		walkSchemas(doc, c.schema)
+	default:
+		return nil, nil, fmt.Errorf("unknown OpenAPI version %q, use %s or %s", to, version30, version31)
+	}
+	return root, c.losses, nil
+}
+
+// parseSpecTree parses a spec into a YAML tree whose document holds a mapping
+func parseSpecTree(content []byte) (*yaml.Node, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("parsing spec: %w", err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the spec is not a YAML or JSON object")
+	}
+	return &root, nil
+}
+
+// downgradeDocument drops the fields OpenAPI 3.1 added outside of schemas
+func (c *converter) downgradeDocument(doc *yaml.Node) {
+	for _, name := range []string{"jsonSchemaDialect", "webhooks"} {
+		if removeField(doc, name) != nil {
+			c.drop("#/"+name, name, "dropped %s, not supported by OpenAPI 3.0", name)
+		}
+	}
+	if _, info := field(doc, "info"); info != nil {
+		if removeField(info, "summary") != nil {
+			c.drop("#/info/summary", "summary", "dropped the info summary, not supported by OpenAPI 3.0")
+		}
+		if _, license := field(info, "license"); license != nil && removeField(license, "identifier") != nil {
+			c.drop("#/info/license/identifier", "identifier", "dropped the license identifier, not supported by OpenAPI 3.0")
+		}
+	}
+	if _, components := field(doc, "components"); components != nil && removeField(components, "pathItems") != nil {
+		c.drop("#/components/pathItems", "pathItems", "dropped pathItems components, not supported by OpenAPI 3.0")
+	}
+	// Paths are optional since 3.1
+	if _, paths := field(doc, "paths"); paths == nil {
+		setField(doc, "paths", &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+	}
+}
+
+func (c *converter) schema(node *yaml.Node, pointer string) {
+	if c.to == version30 {
+		c.downgradeSchema(node, pointer)
+	} else {
+		c.upgradeSchema(node, pointer)
+	}
+}
+
+// downgradeSchema rewrites a JSON Schema 2020-12 schema as an OpenAPI 3.0 one
+func (c *converter) downgradeSchema(node *yaml.Node, pointer string) {
+	// Siblings of $ref are ignored in 3.0, so the reference moves into an allOf
+	if _, ref := field(node, "$ref"); ref != nil && len(node.Content) > 2 {
+		removeField(node, "$ref")
+		setField(node, "allOf", sequenceNode(mappingNode("$ref", ref)))
+		c.rewrite(pointer, "$ref", "allOf", "rewrote $ref with siblings as an allOf, as 3.0 ignores the siblings")
+	}
+
+	nullable := false
+	if _, typ := field(node, "type"); typ != nil {
+		var types []string
+		if typ.Kind == yaml.SequenceNode {
+			for _, t := range typ.Content {
+				types = append(types, t.Value)
+			}
+		} else {
+			types = []string{typ.Value}
+		}
+		if i := slices.Index(types, "null"); i >= 0 {
+			nullable = true
+			types = slices.Delete(types, i, i+1)
+			c.rewrite(pointer, "type null", "nullable", "rewrote the null type as nullable")
+		}
+		switch {
+		case len(types) == 1:
+			setField(node, "type", stringNode(types[0]))
+		case len(types) == 0:
+			removeField(node, "type")
+		default:
+			removeField(node, "type")
+			if _, oneOf := field(node, "oneOf"); oneOf != nil {
+				c.drop(pointer, "type", "kept only the type %s, OpenAPI 3.0 schemas have a single type", types[0])
+				setField(node, "type", stringNode(types[0]))
+				break
+			}
+			var alternatives []*yaml.Node
+			for _, t := range types {
+				alternatives = append(alternatives, mappingNode("type", stringNode(t)))
+			}
+			setField(node, "oneOf", sequenceNode(alternatives...))
+			c.rewrite(pointer, "type", "oneOf", "rewrote the types %s as a oneOf", strings.Join(types, ", "))
+		}
+	}
+
+	// anyOf: [{$ref: ...}, {type: "null"}] is how 3.1 makes a reference nullable
+	for _, name := range []string{"anyOf", "oneOf"} {
+		_, list := field(node, name)
+		if list == nil || list.Kind != yaml.SequenceNode {
+			continue
+		}
+		list.Content = slices.DeleteFunc(list.Content, func(alt *yaml.Node) bool {
+			_, typ := field(alt, "type")
+			isNull := typ != nil && typ.Value == "null" && len(unalias(alt).Content) == 2
+			if isNull {
+				nullable = true
+				c.rewrite(pointer, name+" null", "nullable", "rewrote the null alternative of %s as nullable", name)
+			}
+			return isNull
+		})
+		if len(list.Content) == 1 {
+			removeField(node, name)
+			setField(node, "allOf", sequenceNode(list.Content[0]))
+		}
+	}
+	if nullable {
+		setField(node, "nullable", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+	}
+
+	// Arrays need items in 3.0
+	if _, typ := field(node, "type"); typ != nil && typ.Value == "array" {
+		if _, items := field(node, "items"); items == nil {
+			setField(node, "items", &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+		}
+	}
+
+	if value := removeField(node, "const"); value != nil {
+		setField(node, "enum", sequenceNode(value))
+		c.rewrite(pointerTo(pointer, "const"), "const", "enum", "rewrote const as an enum of one value")
+	}
+	if examples := removeField(node, "examples"); examples != nil {
+		if _, example := field(node, "example"); example == nil && examples.Kind == yaml.SequenceNode && len(examples.Content) > 0 {
+			setField(node, "example", examples.Content[0])
+			if len(examples.Content) > 1 {
+				c.rewrite(pointerTo(pointer, "examples"), "examples", "example", "kept only the first of %d examples", len(examples.Content))
+			}
+		} else {
+			c.drop(pointerTo(pointer, "examples"), "examples", "dropped examples, the schema has an example already")
+		}
+	}
+	for _, bound := range []struct{ exclusive, inclusive string }{
+		{"exclusiveMinimum", "minimum"},
+		{"exclusiveMaximum", "maximum"},
+	} {
+		if _, value := field(node, bound.exclusive); value != nil && value.Tag != "!!bool" {
+			setField(node, bound.inclusive, value)
+			setField(node, bound.exclusive, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+			c.rewrite(pointerTo(pointer, bound.exclusive), bound.exclusive, bound.inclusive, "rewrote the %s number as %s and a boolean", bound.exclusive, bound.inclusive)
+		}
+	}
+
+	// Binary and base64 strings are told apart by their format in 3.0
+	encoding, mediaType := removeField(node, "contentEncoding"), removeField(node, "contentMediaType")
+	if _, format := field(node, "format"); format == nil {
+		switch {
+		case encoding != nil && encoding.Value == "base64":
+			setField(node, "format", stringNode("byte"))
+			c.rewrite(pointerTo(pointer, "contentEncoding"), "contentEncoding", "format", "rewrote contentEncoding base64 as the byte format")
+			encoding = nil
+		case mediaType != nil:
+			setField(node, "format", stringNode("binary"))
+			c.rewrite(pointerTo(pointer, "contentMediaType"), "contentMediaType", "format", "rewrote contentMediaType %s as the binary format", mediaType.Value)
+			mediaType = nil
+		}
+	}
+	if encoding != nil {
+		c.drop(pointerTo(pointer, "contentEncoding"), "contentEncoding", "dropped contentEncoding, not supported by OpenAPI 3.0")
+	}
+	if mediaType != nil {
+		c.drop(pointerTo(pointer, "contentMediaType"), "contentMediaType", "dropped contentMediaType, not supported by OpenAPI 3.0")
+	}
+
+	for _, name := range unsupportedIn30 {
+		if removeField(node, name) != nil {
+			c.drop(pointerTo(pointer, name), name, "dropped %s, not supported by OpenAPI 3.0", name)
+		}
+	}
+}
+
+// upgradeSchema rewrites an OpenAPI 3.0 schema as a JSON Schema 2020-12 one
+func (c *converter) upgradeSchema(node *yaml.Node, pointer string) {
+	if nullable := removeField(node, "nullable"); nullable != nil && nullable.Value == "true" {
+		_, typ := field(node, "type")
+		switch {
+		case typ != nil && typ.Kind == yaml.ScalarNode:
+			setField(node, "type", sequenceNode(stringNode(typ.Value), stringNode("null")))
+			if _, enum := field(node, "enum"); enum != nil && enum.Kind == yaml.SequenceNode {
+				enum.Content = append(enum.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"})
+			}
+		case typ == nil:
+			// A nullable reference or composition becomes one of it and null,
+			// keeping the title and description on the outer schema
+			inner := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			var outer []*yaml.Node
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				switch node.Content[i].Value {
+				case "title", "description":
+					outer = append(outer, node.Content[i], node.Content[i+1])
+				default:
+					inner.Content = append(inner.Content, node.Content[i], node.Content[i+1])
+				}
+			}
+			node.Content = outer
+			setField(node, "anyOf", sequenceNode(inner, mappingNode("type", stringNode("null"))))
+		default:
+			c.drop(pointerTo(pointer, "nullable"), "nullable", "could not add null to the types of a nullable schema")
+		}
+	}
+
+	for _, bound := range []struct{ exclusive, inclusive string }{
+		{"exclusiveMinimum", "minimum"},
+		{"exclusiveMaximum", "maximum"},
+	} {
+		_, exclusive := field(node, bound.exclusive)
+		if exclusive == nil || exclusive.Tag != "!!bool" {
+			continue
+		}
+		removeField(node, bound.exclusive)
+		if _, value := field(node, bound.inclusive); value != nil && exclusive.Value == "true" {
+			removeField(node, bound.inclusive)
+			setField(node, bound.exclusive, value)
+		}
+	}
+
+	if example := removeField(node, "example"); example != nil {
+		if _, examples := field(node, "examples"); examples == nil {
+			setField(node, "examples", sequenceNode(example))
+		}
+	}
+}
+
+// removeField removes a field from a mapping and returns its value
+func removeField(node *yaml.Node, name string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == name {
+			value := node.Content[i+1]
+			node.Content = slices.Delete(node.Content, i, i+2)
+			return unalias(value)
+		}
+	}
+	return nil
+}
+
+// setField replaces the value of a field of a mapping, or adds the field
+func setField(node *yaml.Node, name string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == name {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, stringNode(name), value)
+}
+
+func stringNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func mappingNode(name string, value *yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{stringNode(name), value}}
+}
+
+func sequenceNode(items ...*yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: items}
+}
+
+// blockStyle clears the flow style and quoting JSON is parsed with, so that a
+// JSON spec is written as ordinary YAML
+func blockStyle(node *yaml.Node) {
+	if node == nil || node.Kind == yaml.AliasNode {
+		return
+	}
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// encodeSpec writes the spec tree as YAML or JSON
+func encodeSpec(root *yaml.Node, format string) ([]byte, error) {
+	if format == formatJSON {
+		return []byte(nodeToJSON(root, "") + "\n"), nil
+	}
+
+	if isJSONDocument(root) {
+		blockStyle(root)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// specFormat picks the format a spec is written in: the one asked for, else
+// the one the extension of the output file implies, else the one it was read in
+func specFormat(format, out string, root *yaml.Node) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(out)) {
+		case ".json":
+			format = formatJSON
+		case ".yaml", ".yml":
+			format = formatYAML
+		default:
+			format = formatYAML
+			if isJSONDocument(root) {
+				format = formatJSON
+			}
+		}
+	}
+	if format != formatYAML && format != formatJSON {
+		return "", fmt.Errorf("unknown output format %q, use %s or %s", format, formatYAML, formatJSON)
+	}
+	return format, nil
+}
+
+// runConvert implements "oq convert"
+func runConvert(fs *flag.FlagSet, args []string) error {
+	to := fs.String("to", "", "OpenAPI version to convert to: 3.0 or 3.1")
+	format := fs.String("format", "", "output format: yaml or json, by default the extension of -o or the format of the spec")
+	out := fs.String("o", "", "file to write to instead of stdout")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	path, err := specArg(fs)
+	if err != nil {
+		return err
+	}
+
+	content, err := readSpec(path)
+	if err != nil {
+		return err
+	}
+	root, losses, err := convertSpec(content, *to)
+	if err != nil {
+		return err
+	}
+
+	if *format, err = specFormat(*format, *out, root); err != nil {
+		return err
+	}
+
+	data, err := encodeSpec(root, *format)
+	if err != nil {
+		return err
+	}
+	// The converted spec has to load, or the conversion is broken
+	if _, err := parseSpec(data); err != nil {
+		return fmt.Errorf("converted spec does not load: %w", err)
+	}
+
+	for _, loss := range losses {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", loss)
+	}
+	if err := writeLossSummary(os.Stderr, *to, losses); err != nil {
+		return err
+	}
+	return writeResult(*out, data)
+}
+
+// writeLossSummary counts what the conversion dropped and rewrote by keyword,
+// after the warnings listing each place, which are many for big specs
+func writeLossSummary(w io.Writer, to string, losses []conversionLoss) error {
+	if len(losses) == 0 {
+		return nil
+	}
+
+	type kind struct{ keyword, as string }
+	var kinds []kind
+	counts := map[kind]int{}
+	dropped := 0
+	for _, loss := range losses {
+		k := kind{loss.keyword, loss.as}
+		if counts[k] == 0 {
+			kinds = append(kinds, k)
+		}
+		counts[k]++
+		if loss.as == "" {
+			dropped++
+		}
+	}
+	// Dropped keywords first, as what they said is gone
+	slices.SortStableFunc(kinds, func(a, b kind) int {
+		return cmp.Compare(min(len(a.as), 1), min(len(b.as), 1))
+	})
+
+	fmt.Fprintf(w, "\nConverting to OpenAPI %s dropped %d and rewrote %d keywords:\n", to, dropped, len(losses)-dropped)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, k := range kinds {
+		if k.as == "" {
+			fmt.Fprintf(tw, "  dropped\t%s\t%d\n", k.keyword, counts[k])
+		} else {
+			fmt.Fprintf(tw, "  rewrote\t%s %s %s\t%d\n", k.keyword, icons.link, k.as, counts[k])
+		}
+	}
+	return tw.Flush()
+}
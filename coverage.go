@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// observedRequest is a request seen in recorded traffic
+type observedRequest struct {
+	method string
+	path   string
+	status int // 0 when unknown
+}
+
+// harFile is the part of an HTTP Archive read by "oq coverage"
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method string `json:"method"`
+				URL    string `json:"url"`
+			} `json:"request"`
+			Response struct {
+				Status int `json:"status"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+func readHAR(r io.Reader) ([]observedRequest, error) {
+	var har harFile
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, fmt.Errorf("invalid HAR file: %w", err)
+	}
+	var requests []observedRequest
+	for _, entry := range har.Log.Entries {
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil {
+			continue
+		}
+		requests = append(requests, observedRequest{method: strings.ToUpper(entry.Request.Method), path: u.Path, status: entry.Response.Status})
+	}
+	return requests, nil
+}
+
+// accessLogPattern matches the request and status of the common and combined
+// log formats of nginx and Apache: "GET /pets?limit=10 HTTP/1.1" 200
+var accessLogPattern = regexp.MustCompile(`"([A-Za-z]+) (\S+)(?: HTTP/[^"]*)?" (\d{3})`)
+
+func readAccessLog(r io.Reader) ([]observedRequest, error) {
+	var requests []observedRequest
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		m := accessLogPattern.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		u, err := url.Parse(m[2])
+		if err != nil {
+			continue
+		}
+		status, _ := strconv.Atoi(m[3])
+		requests = append(requests, observedRequest{method: strings.ToUpper(m[1]), path: u.Path, status: status})
+	}
+	return requests, scanner.Err()
+}
+
+// operationMatcher matches request paths to the path template of an operation
+type operationMatcher struct {
+	ep       endpoint
+	pattern  *regexp.Regexp
+	literals int // Characters outside parameters, to prefer /pets/mine over /pets/{id}
+}
+
+func newOperationMatcher(ep endpoint) operationMatcher {
+	var expr strings.Builder
+	literals := 0
+	last := 0
+	for _, loc := range pathParamPattern.FindAllStringIndex(ep.path, -1) {
+		expr.WriteString(regexp.QuoteMeta(ep.path[last:loc[0]]))
+		expr.WriteString(`[^/]+`)
+		literals += loc[0] - last
+		last = loc[1]
+	}
+	expr.WriteString(regexp.QuoteMeta(ep.path[last:]))
+	literals += len(ep.path) - last
+	return operationMatcher{
+		ep:       ep,
+		pattern:  regexp.MustCompile("^" + strings.TrimSuffix(expr.String(), "/") + "/?$"),
+		literals: literals,
+	}
+}
+
+// serverBasePaths returns the path parts of the server URLs, like "/v1", which
+// recorded requests start with. Server variables are replaced by their defaults.
+func serverBasePaths(doc *v3.Document, endpoints []endpoint) []string {
+	servers := slices.Clone(doc.Servers)
+	for _, ep := range endpoints {
+		servers = append(servers, ep.op.Servers...)
+	}
+
+	bases := []string{""}
+	for _, server := range servers {
+		if server == nil {
+			continue
+		}
+		raw := server.URL
+		if server.Variables != nil {
+			for pair := server.Variables.First(); pair != nil; pair = pair.Next() {
+				if v := pair.Value(); v != nil {
+					raw = strings.ReplaceAll(raw, "{"+pair.Key()+"}", v.Default)
+				}
+			}
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			continue
+		}
+		if base := strings.TrimSuffix(u.Path, "/"); !slices.Contains(bases, base) {
+			bases = append(bases, base)
+		}
+	}
+	// Longest first, so /api/v1 is tried before /api
+	sort.SliceStable(bases, func(i, j int) bool { return len(bases[i]) > len(bases[j]) })
+	return bases
+}
+
+// operationTraffic is how a documented operation was exercised
+type operationTraffic struct {
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Requests int    `json:"requests"`
+	Statuses []int  `json:"statuses"`
+}
+
+// undocumentedTraffic groups the requests no operation of the spec matches
+type undocumentedTraffic struct {
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Requests int    `json:"requests"`
+}
+
+// trafficReport is the output of "oq coverage"
+type trafficReport struct {
+	Requests     int                   `json:"requests"`
+	Coverage     coverage              `json:"coverage"` // Operations exercised
+	Operations   []operationTraffic    `json:"operations"`
+	Undocumented []undocumentedTraffic `json:"undocumented"`
+}
+
+// measureTraffic matches recorded requests to the operations of the spec. A
+// request matching several path templates counts for the one with the most
+// literal characters, as servers route /pets/mine before /pets/{id}.
+func measureTraffic(doc *v3.Document, endpoints []endpoint, requests []observedRequest) trafficReport {
+	matchers := make([]operationMatcher, len(endpoints))
+	for i, ep := range endpoints {
+		matchers[i] = newOperationMatcher(ep)
+	}
+	bases := serverBasePaths(doc, endpoints)
+
+	report := trafficReport{Requests: len(requests), Operations: make([]operationTraffic, len(endpoints)), Undocumented: []undocumentedTraffic{}}
+	for i, ep := range endpoints {
+		report.Operations[i] = operationTraffic{Method: ep.method, Path: ep.path, Statuses: []int{}}
+	}
+	undocumented := map[string]int{}
+
+	for _, req := range requests {
+		best := -1
+		for _, base := range bases {
+			rest, ok := strings.CutPrefix(req.path, base)
+			if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+				continue
+			}
+			for i, m := range matchers {
+				if m.ep.method == req.method && m.pattern.MatchString(rest) && (best < 0 || m.literals > matchers[best].literals) {
+					best = i
+				}
+			}
+			if best >= 0 {
+				break
+			}
+		}
+
+		if best < 0 {
+			key := req.method + " " + req.path
+			if _, ok := undocumented[key]; !ok {
+				report.Undocumented = append(report.Undocumented, undocumentedTraffic{Method: req.method, Path: req.path})
+			}
+			undocumented[key]++
+			continue
+		}
+		op := &report.Operations[best]
+		op.Requests++
+		if req.status > 0 && !slices.Contains(op.Statuses, req.status) {
+			op.Statuses = append(op.Statuses, req.status)
+			slices.Sort(op.Statuses)
+		}
+	}
+
+	exercised := 0
+	for _, op := range report.Operations {
+		if op.Requests > 0 {
+			exercised++
+		}
+	}
+	report.Coverage = newCoverage("operations", exercised, len(endpoints))
+
+	for i := range report.Undocumented {
+		u := &report.Undocumented[i]
+		u.Requests = undocumented[u.Method+" "+u.Path]
+	}
+	sort.SliceStable(report.Undocumented, func(i, j int) bool {
+		return report.Undocumented[i].Requests > report.Undocumented[j].Requests
+	})
+	return report
+}
+
+func writeTrafficText(w io.Writer, report trafficReport) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	c := report.Coverage
+	fmt.Fprintf(tw, "Operations exercised:\t%d/%d\t%5.1f%%\n", c.Covered, c.Total, c.Percent)
+	fmt.Fprintf(tw, "Requests:\t%d\n", report.Requests)
+
+	missed := false
+	for _, op := range report.Operations {
+		if op.Requests > 0 {
+			continue
+		}
+		if !missed {
+			fmt.Fprintf(tw, "\nNot exercised:\n")
+			missed = true
+		}
+		fmt.Fprintf(tw, "  %s %s\n", op.Method, op.Path)
+	}
+
+	exercised := false
+	for _, op := range report.Operations {
+		if op.Requests == 0 {
+			continue
+		}
+		if !exercised {
+			fmt.Fprintf(tw, "\nExercised:\n")
+			exercised = true
+		}
+		var statuses []string
+		for _, status := range op.Statuses {
+			statuses = append(statuses, strconv.Itoa(status))
+		}
+		fmt.Fprintf(tw, "  %s %s\t%d\t%s\n", op.Method, op.Path, op.Requests, strings.Join(statuses, ", "))
+	}
+
+	if len(report.Undocumented) > 0 {
+		fmt.Fprintf(tw, "\nNot in the spec:\n")
+		for _, u := range report.Undocumented {
+			fmt.Fprintf(tw, "  %s %s\t%d\n", u.Method, u.Path, u.Requests)
+		}
+	}
+	return tw.Flush()
+}
+
+// runCoverage implements "oq coverage"
+func runCoverage(fs *flag.FlagSet, args []string) error {
+	var hars, logs stringList
+	fs.Var(&hars, "har", "HAR file recorded by a browser or proxy, can be repeated")
+	fs.Var(&logs, "log", "access log in the common or combined format, can be repeated")
+	output := fs.String("output", defaultOutput, "output format: text or json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	path, err := specArg(fs)
+	if err != nil {
+		return err
+	}
+	if *output != outputText && *output != outputJSON {
+		return fmt.Errorf("unknown output format %q, use %s or %s", *output, outputText, outputJSON)
+	}
+	if len(hars) == 0 && len(logs) == 0 {
+		return fmt.Errorf("give at least one -har or -log file with the traffic")
+	}
+
+	doc, err := loadSpec(path)
+	if err != nil {
+		return err
+	}
+
+	var requests []observedRequest
+	for _, files := range []struct {
+		names []string
+		read  func(io.Reader) ([]observedRequest, error)
+	}{
+		{hars, readHAR},
+		{logs, readAccessLog},
+	} {
+		for _, name := range files.names {
+			f, err := os.Open(name)
+			if err != nil {
+				return err
+			}
+			observed, err := files.read(f)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			requests = append(requests, observed...)
+		}
+	}
+
+	report := measureTraffic(doc, extractEndpoints(doc), requests)
+	if *output == outputJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		return writeResult("", append(data, '\n'))
+	}
+	return writeTrafficText(os.Stdout, report)
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"go.yaml.in/yaml/v4"
+)
+
+// deprecation tells whether an item is deprecated, with the dates and notes
+// given by vendor extensions
+type deprecation struct {
+	deprecated bool
+	sunset     string   // When the item is removed, e.g. "2025-12-31"
+	notes      []string // e.g. "deprecated 2025-01-01" or "use /v2/pets instead"
+}
+
+// deprecationExtensions are the extensions APIs use to announce a deprecation,
+// with the label their value is shown with
+var deprecationExtensions = []struct{ name, label string }{
+	{"x-deprecated", ""},
+	{"x-deprecation", ""},
+	{"x-deprecated-at", "deprecated"},
+	{"x-deprecation-date", "deprecated"},
+	{"x-sunset", "sunset"},
+	{"x-sunset-date", "sunset"},
+}
+
+// newDeprecation combines the deprecated flag of an item with its extensions.
+// A boolean extension sets the flag, any other value also adds a note.
+func newDeprecation(flag bool, extensions *orderedmap.Map[string, *yaml.Node]) deprecation {
+	d := deprecation{deprecated: flag}
+	if extensions == nil {
+		return d
+	}
+
+	for _, ext := range deprecationExtensions {
+		node, ok := extensions.Get(ext.name)
+		if !ok || node == nil {
+			continue
+		}
+		if node.Kind == yaml.ScalarNode && node.Tag == "!!bool" {
+			d.deprecated = d.deprecated || node.Value == "true"
+			continue
+		}
+
+		text := extensionText(node)
+		if text == "" {
+			continue
+		}
+		d.deprecated = true
+		if ext.label == "sunset" && d.sunset == "" {
+			d.sunset = text
+		}
+		if ext.label != "" {
+			text = ext.label + " " + text
+		}
+		d.notes = append(d.notes, text)
+	}
+
+	return d
+}
+
+// extensionText renders an extension value on one line. Objects like
+// {date: 2025-01-01, replacement: /v2/pets} become "date 2025-01-01, replacement /v2/pets".
+func extensionText(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return strings.TrimSpace(node.Value)
+	case yaml.MappingNode:
+		var parts []string
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if value := extensionText(node.Content[i+1]); value != "" {
+				parts = append(parts, node.Content[i].Value+" "+value)
+			}
+		}
+		return strings.Join(parts, ", ")
+	}
+	return nodeToJSON(node, "")
+}
+
+// badge is the short marker shown next to a deprecated item in the lists
+func (d deprecation) badge() string {
+	if d.sunset != "" {
+		return "deprecated, sunset " + d.sunset
+	}
+	return "deprecated"
+}
+
+// details is the "Deprecated:" line at the top of an item's details
+func (d deprecation) details() string {
+	if !d.deprecated {
+		return ""
+	}
+	if len(d.notes) == 0 {
+		return "Deprecated: true\n"
+	}
+	return "Deprecated: " + strings.Join(d.notes, ", ") + "\n"
+}
+
+func operationDeprecation(op *v3.Operation) deprecation {
+	if op == nil {
+		return deprecation{}
+	}
+	return newDeprecation(op.Deprecated != nil && *op.Deprecated, op.Extensions)
+}
+
+func schemaDeprecation(s *base.Schema) deprecation {
+	if s == nil {
+		return deprecation{}
+	}
+	return newDeprecation(s.Deprecated != nil && *s.Deprecated, s.Extensions)
+}
+
+func componentDeprecation(source renderable) deprecation {
+	switch s := source.(type) {
+	case *base.SchemaProxy:
+		if s != nil {
+			return schemaDeprecation(s.Schema())
+		}
+	case *v3.Parameter:
+		if s != nil {
+			return newDeprecation(s.Deprecated, s.Extensions)
+		}
+	case *v3.Header:
+		if s != nil {
+			return newDeprecation(s.Deprecated, s.Extensions)
+		}
+	case *v3.SecurityScheme:
+		if s != nil {
+			return newDeprecation(s.Deprecated, s.Extensions)
+		}
+	case *v3.RequestBody:
+		if s != nil {
+			return newDeprecation(false, s.Extensions)
+		}
+	case *v3.Response:
+		if s != nil {
+			return newDeprecation(false, s.Extensions)
+		}
+	}
+	return deprecation{}
+}
+
+// itemLists holds the items of the list views
+type itemLists struct {
+	endpoints  []endpoint
+	components []component
+	webhooks   []webhook
+}
+
+// allItems returns every item, including those hidden by the deprecated filter
+func (m *Model) allItems() itemLists {
+	if m.unfiltered != nil {
+		return *m.unfiltered
+	}
+	return itemLists{endpoints: m.endpoints, components: m.components, webhooks: m.webhooks}
+}
+
+// toggleDeprecatedOnly switches between listing every item and only the deprecated ones
+func (m *Model) toggleDeprecatedOnly() {
+	if m.unfiltered != nil {
+		m.showAllItems()
+		return
+	}
+
+	all := m.allItems()
+	var filtered itemLists
+	for _, ep := range all.endpoints {
+		if ep.deprecation.deprecated {
+			filtered.endpoints = append(filtered.endpoints, ep)
+		}
+	}
+	for _, comp := range all.components {
+		if comp.deprecation.deprecated {
+			filtered.components = append(filtered.components, comp)
+		}
+	}
+	for _, hook := range all.webhooks {
+		if hook.deprecation.deprecated {
+			filtered.webhooks = append(filtered.webhooks, hook)
+		}
+	}
+
+	if len(filtered.endpoints)+len(filtered.components)+len(filtered.webhooks) == 0 {
+		m.message = "Nothing is deprecated"
+		return
+	}
+
+	m.unfiltered = &all
+	m.endpoints, m.components, m.webhooks = filtered.endpoints, filtered.components, filtered.webhooks
+	if m.mode == viewWebhooks && !m.hasWebhooks() {
+		m.mode = viewEndpoints
+	}
+	m.resetListPosition()
+}
+
+// showAllItems turns the deprecated filter off, keeping the fold state of the
+// filtered items and the selection
+func (m *Model) showAllItems() {
+	filtered := itemLists{endpoints: m.endpoints, components: m.components, webhooks: m.webhooks}
+	selected := m.cursor
+	m.endpoints, m.components, m.webhooks = m.unfiltered.endpoints, m.unfiltered.components, m.unfiltered.webhooks
+	m.unfiltered = nil
+
+	cursor := -1
+	for i, ep := range filtered.endpoints {
+		if j := m.findEndpoint(ep.path, ep.method); j >= 0 {
+			m.endpoints[j].folded = ep.folded
+			if m.mode == viewEndpoints && i == selected {
+				cursor = j
+			}
+		}
+	}
+	for i, comp := range filtered.components {
+		if j := m.findComponent(comp.compType, comp.name); j >= 0 {
+			m.components[j].folded = comp.folded
+			if m.mode == viewComponents && i == selected {
+				cursor = j
+			}
+		}
+	}
+	for i, hook := range filtered.webhooks {
+		if j := m.findWebhook(hook.name, hook.method); j >= 0 {
+			m.webhooks[j].folded = hook.folded
+			if m.mode == viewWebhooks && i == selected {
+				cursor = j
+			}
+		}
+	}
+
+	m.resetListPosition()
+	if cursor >= 0 {
+		m.cursor = cursor
+		m.ensureCursorVisible()
+	}
+}
+
+// resetListPosition moves to the top of the current view. The history is
+// dropped, as its positions refer to the lists before filtering.
+func (m *Model) resetListPosition() {
+	m.cursor = 0
+	m.scrollOffset = 0
+	m.backStack = nil
+	m.forwardStack = nil
+}
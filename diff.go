@@ -0,0 +1,515 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// Kinds of changes found by diffSpecs
+const (
+	changeEndpointAdded      = "endpoint-added"
+	changeEndpointRemoved    = "endpoint-removed"
+	changeEndpointDeprecated = "endpoint-deprecated"
+	changeParameterAdded     = "parameter-added"
+	changeParameterRemoved   = "parameter-removed"
+	changeParameterRequired  = "parameter-required"
+	changeRequestBodyAdded   = "request-body-added"
+	changeRequestBodyRemoved = "request-body-removed"
+	changeMediaTypeAdded     = "media-type-added"
+	changeMediaTypeRemoved   = "media-type-removed"
+	changeResponseAdded      = "response-added"
+	changeResponseRemoved    = "response-removed"
+	changeSchemaAdded        = "schema-added"
+	changeSchemaRemoved      = "schema-removed"
+	changePropertyAdded      = "property-added"
+	changePropertyRemoved    = "property-removed"
+	changeRequiredAdded      = "required-added"
+	changeRequiredRemoved    = "required-removed"
+	changeTypeChanged        = "type-changed"
+	changeEnumNarrowed       = "enum-narrowed"
+	changeEnumWidened        = "enum-widened"
+)
+
+// maxDiffDepth limits how deep inline schemas are compared
+const maxDiffDepth = 10
+
+// schemaUse tells whether a schema describes what clients send or what they
+// receive, which decides the changes breaking them
+type schemaUse int
+
+const (
+	usedBothWays schemaUse = iota // Like the component schemas, breaking when either way breaks
+	usedInRequest
+	usedInResponse
+)
+
+// breaking tells whether a change breaks clients, given whether it does in a
+// request and in a response
+func (use schemaUse) breaking(inRequest, inResponse bool) bool {
+	switch use {
+	case usedInRequest:
+		return inRequest
+	case usedInResponse:
+		return inResponse
+	}
+	return inRequest || inResponse
+}
+
+// change is a difference between two versions of a spec
+type change struct {
+	Kind     string `json:"kind"`
+	Location string `json:"location"` // e.g. "GET /pets" or "schema Pet.name"
+	Message  string `json:"message"`
+	Breaking bool   `json:"breaking"`
+}
+
+type specDiff struct {
+	changes []change
+}
+
+func (d *specDiff) add(kind, location, message string, breaking bool) {
+	d.changes = append(d.changes, change{Kind: kind, Location: location, Message: message, Breaking: breaking})
+}
+
+// breakingCount returns how many of the changes break existing clients
+func breakingCount(changes []change) int {
+	n := 0
+	for _, c := range changes {
+		if c.Breaking {
+			n++
+		}
+	}
+	return n
+}
+
+var pathParamPattern = regexp.MustCompile(`\{[^}]*\}`)
+
+// endpointKey identifies an endpoint across versions. Path parameters are
+// compared by position, so renaming {id} to {petId} is not a change.
+func endpointKey(ep endpoint) string {
+	return ep.method + " " + pathParamPattern.ReplaceAllString(ep.path, "{}")
+}
+
+// diffSpecs lists what changed from old to updated: endpoints, their parameters,
+// bodies and responses, and the component schemas. Changes that break existing
+// clients, like a removed endpoint or a narrowed enum, are flagged.
+func diffSpecs(old, updated *v3.Document) []change {
+	var d specDiff
+
+	oldEndpoints := map[string]endpoint{}
+	for _, ep := range extractEndpoints(old) {
+		oldEndpoints[endpointKey(ep)] = ep
+	}
+	newKeys := map[string]bool{}
+	for _, ep := range extractEndpoints(updated) {
+		key := endpointKey(ep)
+		newKeys[key] = true
+		location := ep.method + " " + ep.path
+		before, ok := oldEndpoints[key]
+		if !ok {
+			d.add(changeEndpointAdded, location, "endpoint added", false)
+			continue
+		}
+		d.diffOperation(location, before, ep)
+	}
+	for _, ep := range extractEndpoints(old) {
+		if !newKeys[endpointKey(ep)] {
+			d.add(changeEndpointRemoved, ep.method+" "+ep.path, "endpoint removed", true)
+		}
+	}
+
+	d.diffSchemas(componentSchemas(old), componentSchemas(updated))
+
+	return d.changes
+}
+
+func componentSchemas(doc *v3.Document) *orderedmap.Map[string, *base.SchemaProxy] {
+	if doc.Components == nil {
+		return nil
+	}
+	return doc.Components.Schemas
+}
+
+func (d *specDiff) diffOperation(location string, oldEndpoint, newEndpoint endpoint) {
+	old, updated := oldEndpoint.op, newEndpoint.op
+	if !operationDeprecation(old).deprecated && operationDeprecation(updated).deprecated {
+		d.add(changeEndpointDeprecated, location, "endpoint deprecated", false)
+	}
+
+	d.diffParameters(location, oldEndpoint.path, old.Parameters, newEndpoint.path, updated.Parameters)
+
+	switch {
+	case old.RequestBody == nil && updated.RequestBody != nil:
+		required := updated.RequestBody.Required != nil && *updated.RequestBody.Required
+		d.add(changeRequestBodyAdded, location, requiredText("request body added", required), required)
+	case old.RequestBody != nil && updated.RequestBody == nil:
+		d.add(changeRequestBodyRemoved, location, "request body removed", true)
+	case old.RequestBody != nil:
+		wasRequired := old.RequestBody.Required != nil && *old.RequestBody.Required
+		if isRequired := updated.RequestBody.Required != nil && *updated.RequestBody.Required; isRequired && !wasRequired {
+			d.add(changeRequiredAdded, location+" request body", "request body became required", true)
+		}
+		d.diffContent(location+" request body", old.RequestBody.Content, updated.RequestBody.Content, usedInRequest)
+	}
+
+	oldResponses, newResponses := operationResponses(old), operationResponses(updated)
+	for _, code := range sortedKeys(newResponses) {
+		if _, ok := oldResponses[code]; !ok {
+			d.add(changeResponseAdded, location, "response "+code+" added", false)
+		}
+	}
+	for _, code := range sortedKeys(oldResponses) {
+		resp, ok := newResponses[code]
+		if !ok {
+			d.add(changeResponseRemoved, location, "response "+code+" removed", true)
+			continue
+		}
+		d.diffContent(location+" response "+code, oldResponses[code].Content, resp.Content, usedInResponse)
+	}
+}
+
+func requiredText(text string, required bool) string {
+	if required {
+		return text + " (required)"
+	}
+	return text
+}
+
+// parameterKey identifies a parameter across versions, path parameters by their
+// position in the path
+func parameterKey(path string, p *v3.Parameter) string {
+	if p.In == "path" {
+		for i, match := range pathParamPattern.FindAllString(path, -1) {
+			if match == "{"+p.Name+"}" {
+				return fmt.Sprintf("path:%d", i)
+			}
+		}
+	}
+	return p.In + ":" + p.Name
+}
+
+func (d *specDiff) diffParameters(location, oldPath string, old []*v3.Parameter, newPath string, updated []*v3.Parameter) {
+	find := func(params []*v3.Parameter, path, key string) *v3.Parameter {
+		for _, candidate := range params {
+			if candidate != nil && parameterKey(path, candidate) == key {
+				return candidate
+			}
+		}
+		return nil
+	}
+	isRequired := func(p *v3.Parameter) bool {
+		return p.Required != nil && *p.Required
+	}
+
+	for _, p := range updated {
+		if p == nil {
+			continue
+		}
+		name := p.In + " parameter " + p.Name
+		before := find(old, oldPath, parameterKey(newPath, p))
+		if before == nil {
+			d.add(changeParameterAdded, location, requiredText(name+" added", isRequired(p)), isRequired(p))
+			continue
+		}
+		if isRequired(p) && !isRequired(before) {
+			d.add(changeParameterRequired, location, name+" became required", true)
+		}
+		if before.Schema != nil && p.Schema != nil {
+			d.diffSchema(location+" "+name, before.Schema, p.Schema, usedInRequest, 0)
+		}
+	}
+	for _, p := range old {
+		if p != nil && find(updated, newPath, parameterKey(oldPath, p)) == nil {
+			d.add(changeParameterRemoved, location, p.In+" parameter "+p.Name+" removed", false)
+		}
+	}
+}
+
+func (d *specDiff) diffContent(location string, old, updated *orderedmap.Map[string, *v3.MediaType], use schemaUse) {
+	oldTypes, newTypes := mediaTypes(old), mediaTypes(updated)
+	for _, mediaType := range sortedKeys(newTypes) {
+		if _, ok := oldTypes[mediaType]; !ok {
+			d.add(changeMediaTypeAdded, location, mediaType+" added", false)
+		}
+	}
+	for _, mediaType := range sortedKeys(oldTypes) {
+		mt, ok := newTypes[mediaType]
+		if !ok {
+			d.add(changeMediaTypeRemoved, location, mediaType+" removed", true)
+			continue
+		}
+		if before := oldTypes[mediaType]; before.Schema != nil && mt.Schema != nil {
+			d.diffSchema(location+" "+mediaType, before.Schema, mt.Schema, use, 0)
+		}
+	}
+}
+
+// diffSchemas compares the component schemas by name
+func (d *specDiff) diffSchemas(old, updated *orderedmap.Map[string, *base.SchemaProxy]) {
+	oldSchemas, newSchemas := schemaMap(old), schemaMap(updated)
+	for _, name := range sortedKeys(newSchemas) {
+		if _, ok := oldSchemas[name]; !ok {
+			d.add(changeSchemaAdded, "schema "+name, "schema added", false)
+		}
+	}
+	for _, name := range sortedKeys(oldSchemas) {
+		proxy, ok := newSchemas[name]
+		if !ok {
+			d.add(changeSchemaRemoved, "schema "+name, "schema removed", true)
+			continue
+		}
+		d.diffSchema("schema "+name, oldSchemas[name], proxy, usedBothWays, 0)
+	}
+}
+
+// diffSchema compares two versions of a schema. Referenced schemas are compared
+// with the components, so only the reference itself is compared here. A
+// property becoming required breaks the clients sending it, and one no longer
+// required or removed those receiving it.
+func (d *specDiff) diffSchema(location string, oldProxy, newProxy *base.SchemaProxy, use schemaUse, depth int) {
+	if depth > maxDiffDepth {
+		return
+	}
+	if before, after := schemaTypeLabel(oldProxy), schemaTypeLabel(newProxy); before != after {
+		d.add(changeTypeChanged, location, fmt.Sprintf("type changed from %s to %s", before, after), true)
+		return
+	}
+	if oldProxy.IsReference() || newProxy.IsReference() {
+		return
+	}
+
+	old, updated := oldProxy.Schema(), newProxy.Schema()
+	if old == nil || updated == nil {
+		return
+	}
+
+	d.diffEnum(location, old, updated, use)
+
+	for _, name := range updated.Required {
+		if !slices.Contains(old.Required, name) {
+			d.add(changeRequiredAdded, location+"."+name, "became required", use.breaking(true, false))
+		}
+	}
+	for _, name := range old.Required {
+		if !slices.Contains(updated.Required, name) {
+			d.add(changeRequiredRemoved, location+"."+name, "no longer required", use.breaking(false, true))
+		}
+	}
+
+	oldProps, newProps := schemaMap(old.Properties), schemaMap(updated.Properties)
+	for _, name := range sortedKeys(newProps) {
+		if _, ok := oldProps[name]; !ok {
+			d.add(changePropertyAdded, location+"."+name, "property added", false)
+		}
+	}
+	for _, name := range sortedKeys(oldProps) {
+		prop, ok := newProps[name]
+		if !ok {
+			d.add(changePropertyRemoved, location+"."+name, "property removed", use.breaking(false, true))
+			continue
+		}
+		d.diffSchema(location+"."+name, oldProps[name], prop, use, depth+1)
+	}
+
+	if old.Items != nil && updated.Items != nil && old.Items.IsA() && updated.Items.IsA() && old.Items.A != nil && updated.Items.A != nil {
+		d.diffSchema(location+"[]", old.Items.A, updated.Items.A, use, depth+1)
+	}
+}
+
+// diffEnum reports enum values that were removed, which clients may still send,
+// and values that were added, which clients receiving them may not expect
+func (d *specDiff) diffEnum(location string, old, updated *base.Schema, use schemaUse) {
+	if len(old.Enum) == 0 || len(updated.Enum) == 0 {
+		return
+	}
+
+	values := func(s *base.Schema) []string {
+		var out []string
+		for _, node := range s.Enum {
+			if node != nil {
+				out = append(out, valueText(node))
+			}
+		}
+		return out
+	}
+	oldValues, newValues := values(old), values(updated)
+
+	var removed, added []string
+	for _, v := range oldValues {
+		if !slices.Contains(newValues, v) {
+			removed = append(removed, v)
+		}
+	}
+	for _, v := range newValues {
+		if !slices.Contains(oldValues, v) {
+			added = append(added, v)
+		}
+	}
+
+	if len(removed) > 0 {
+		d.add(changeEnumNarrowed, location, "enum values removed: "+strings.Join(removed, ", "), use.breaking(true, false))
+	}
+	if len(added) > 0 {
+		// Component schemas would flag every value added, so only responses do
+		d.add(changeEnumWidened, location, "enum values added: "+strings.Join(added, ", "), use == usedInResponse)
+	}
+}
+
+func operationResponses(op *v3.Operation) map[string]*v3.Response {
+	responses := map[string]*v3.Response{}
+	if op.Responses == nil {
+		return responses
+	}
+	if op.Responses.Codes != nil {
+		for pair := op.Responses.Codes.First(); pair != nil; pair = pair.Next() {
+			if pair.Value() != nil {
+				responses[pair.Key()] = pair.Value()
+			}
+		}
+	}
+	if op.Responses.Default != nil {
+		responses["default"] = op.Responses.Default
+	}
+	return responses
+}
+
+func mediaTypes(content *orderedmap.Map[string, *v3.MediaType]) map[string]*v3.MediaType {
+	types := map[string]*v3.MediaType{}
+	if content != nil {
+		for pair := content.First(); pair != nil; pair = pair.Next() {
+			if pair.Value() != nil {
+				types[pair.Key()] = pair.Value()
+			}
+		}
+	}
+	return types
+}
+
+func schemaMap(schemas *orderedmap.Map[string, *base.SchemaProxy]) map[string]*base.SchemaProxy {
+	out := map[string]*base.SchemaProxy{}
+	if schemas != nil {
+		for pair := schemas.First(); pair != nil; pair = pair.Next() {
+			if pair.Value() != nil {
+				out[pair.Key()] = pair.Value()
+			}
+		}
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeDiffText prints the breaking changes first, then the others
+func writeDiffText(w io.Writer, changes []change) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "No changes")
+		return err
+	}
+
+	breaking := breakingCount(changes)
+	fmt.Fprintf(w, "%s, %s\n", pluralize(breaking, "breaking change"), pluralize(len(changes)-breaking, "other change"))
+
+	for _, section := range []struct {
+		title    string
+		breaking bool
+	}{
+		{"Breaking changes", true},
+		{"Other changes", false},
+	} {
+		first := true
+		for _, c := range changes {
+			if c.Breaking != section.breaking {
+				continue
+			}
+			if first {
+				fmt.Fprintf(w, "\n%s:\n", section.title)
+				first = false
+			}
+			fmt.Fprintf(w, "  - %s: %s\n", c.Location, c.Message)
+		}
+	}
+	return nil
+}
+
+// diffReport is the JSON output of the diff command
+type diffReport struct {
+	Breaking int      `json:"breaking"`
+	Changes  []change `json:"changes"`
+}
+
+// runDiff implements "oq diff". It exits with status 1 when there are breaking
+// changes, so it can gate CI jobs, unless the changes are browsed interactively.
+func runDiff(fs *flag.FlagSet, args []string) error {
+	format := fs.String("format", defaultOutput, "output format: text, json or junit")
+	fs.StringVar(format, "output", defaultOutput, "same as -format")
+	interactive := fs.Bool("interactive", false, "browse the changes and both versions side by side")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errUsage
+	}
+	if *format != outputText && *format != outputJSON && *format != formatJUnit {
+		return fmt.Errorf("unknown output format %q, use %s, %s or %s", *format, outputText, outputJSON, formatJUnit)
+	}
+
+	old, err := loadSpec(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	updated, err := loadSpec(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	changes := diffSpecs(old, updated)
+	if *interactive {
+		return runDiffInteractive(fs.Arg(0), fs.Arg(1), old, updated, changes)
+	}
+
+	switch *format {
+	case outputJSON:
+		if changes == nil {
+			changes = []change{}
+		}
+		data, err := json.MarshalIndent(diffReport{Breaking: breakingCount(changes), Changes: changes}, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := writeResult("", append(data, '\n')); err != nil {
+			return err
+		}
+	case formatJUnit:
+		if err := writeJUnit(os.Stdout, "oq diff "+fs.Arg(0)+" "+fs.Arg(1), changesJUnit(changes)); err != nil {
+			return err
+		}
+	default:
+		if err := writeDiffText(os.Stdout, changes); err != nil {
+			return err
+		}
+	}
+
+	if breakingCount(changes) > 0 {
+		return errFindings
+	}
+	return nil
+}
@@ -0,0 +1,480 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// diffStatus tells how an item differs between the two specs
+type diffStatus int
+
+const (
+	diffUnchanged diffStatus = iota
+	diffChanged
+	diffAdded
+	diffRemoved
+)
+
+func (s diffStatus) String() string {
+	switch s {
+	case diffChanged:
+		return "Changed"
+	case diffAdded:
+		return "Added"
+	case diffRemoved:
+		return "Removed"
+	}
+	return "Unchanged"
+}
+
+// diffItem is an endpoint or schema of the interactive diff, with its details in
+// both versions of the spec
+type diffItem struct {
+	name     string // e.g. "GET /pets" or "schema Pet", like the locations of changes
+	status   diffStatus
+	breaking bool
+	before   string // Details in the old spec, empty when the item was added
+	after    string // Details in the new spec, empty when the item was removed
+	changes  []change
+}
+
+// diffItems pairs the endpoints and schemas of both specs, endpoints first, and
+// attaches the changes found by diffSpecs to them. Items whose details differ
+// without a reported change, e.g. in a description, are marked as changed too.
+func diffItems(old, updated *v3.Document, changes []change) []diffItem {
+	var items []diffItem
+
+	oldEndpoints := map[string]endpoint{}
+	for _, ep := range extractEndpoints(old) {
+		oldEndpoints[endpointKey(ep)] = ep
+	}
+	newKeys := map[string]bool{}
+	for _, ep := range extractEndpoints(updated) {
+		key := endpointKey(ep)
+		newKeys[key] = true
+		item := diffItem{name: ep.method + " " + ep.path, status: diffAdded, after: formatEndpointDetails(ep)}
+		if before, ok := oldEndpoints[key]; ok {
+			item.status = diffUnchanged
+			item.before = formatEndpointDetails(before)
+		}
+		items = append(items, item)
+	}
+	for _, ep := range extractEndpoints(old) {
+		if !newKeys[endpointKey(ep)] {
+			items = append(items, diffItem{name: ep.method + " " + ep.path, status: diffRemoved, before: formatEndpointDetails(ep)})
+		}
+	}
+
+	oldSchemas, newSchemas := schemaMap(componentSchemas(old)), schemaMap(componentSchemas(updated))
+	for _, name := range sortedKeys(newSchemas) {
+		item := diffItem{name: "schema " + name, status: diffAdded, after: formatSchemaDetails(newSchemas[name])}
+		if before, ok := oldSchemas[name]; ok {
+			item.status = diffUnchanged
+			item.before = formatSchemaDetails(before)
+		}
+		items = append(items, item)
+	}
+	for _, name := range sortedKeys(oldSchemas) {
+		if _, ok := newSchemas[name]; !ok {
+			items = append(items, diffItem{name: "schema " + name, status: diffRemoved, before: formatSchemaDetails(oldSchemas[name])})
+		}
+	}
+
+	for _, c := range changes {
+		for i := range items {
+			if changeBelongsTo(c, items[i].name) {
+				items[i].changes = append(items[i].changes, c)
+				items[i].breaking = items[i].breaking || c.Breaking
+				break
+			}
+		}
+	}
+	for i := range items {
+		if items[i].status == diffUnchanged && (len(items[i].changes) > 0 || items[i].before != items[i].after) {
+			items[i].status = diffChanged
+		}
+	}
+
+	return items
+}
+
+// changeBelongsTo reports whether a change is about the item with the given name,
+// e.g. "schema Pet.name" is about "schema Pet" but not about "schema PetList"
+func changeBelongsTo(c change, name string) bool {
+	rest, ok := strings.CutPrefix(c.Location, name)
+	return ok && (rest == "" || strings.HasPrefix(rest, " ") || strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "["))
+}
+
+// diffModel is the interactive diff started by "oq diff --interactive": the items
+// on the left, and the changes and both versions of the selected one on the right
+type diffModel struct {
+	oldName       string
+	newName       string
+	items         []diffItem
+	visible       []int // Indexes of the listed items
+	showUnchanged bool
+	cursor        int // Index in visible
+	scrollOffset  int
+	top           int // First line of the details
+	keys          keyMap
+	pending       string
+	width         int
+	height        int
+}
+
+func newDiffModel(oldName, newName string, items []diffItem, keys keyMap) diffModel {
+	m := diffModel{
+		oldName: oldName,
+		newName: newName,
+		items:   items,
+		keys:    keys,
+	}
+	m.filter()
+	return m
+}
+
+// filter lists the changed items, or all of them, keeping the selection
+func (m *diffModel) filter() {
+	selected := -1
+	if m.cursor < len(m.visible) {
+		selected = m.visible[m.cursor]
+	}
+
+	m.visible = nil
+	m.cursor = 0
+	for i, item := range m.items {
+		if m.showUnchanged || item.status != diffUnchanged {
+			if i == selected {
+				m.cursor = len(m.visible)
+			}
+			m.visible = append(m.visible, i)
+		}
+	}
+	m.top = 0
+	m.ensureCursorVisible()
+}
+
+func (m diffModel) listHeight() int {
+	// Header line, an empty line and the footer
+	return max(1, m.height-4)
+}
+
+func (m *diffModel) ensureCursorVisible() {
+	height := m.listHeight()
+	if m.cursor < m.scrollOffset {
+		m.scrollOffset = m.cursor
+	}
+	if m.cursor >= m.scrollOffset+height {
+		m.scrollOffset = m.cursor - height + 1
+	}
+}
+
+func (m *diffModel) moveTo(cursor int) {
+	cursor = max(0, min(cursor, len(m.visible)-1))
+	if cursor != m.cursor {
+		m.cursor = cursor
+		m.top = 0
+	}
+	m.ensureCursorVisible()
+}
+
+// nextBreaking selects the next item with breaking changes in the given direction
+func (m *diffModel) nextBreaking(step int) {
+	for i := m.cursor + step; i >= 0 && i < len(m.visible); i += step {
+		if m.items[m.visible[i]].breaking {
+			m.moveTo(i)
+			return
+		}
+	}
+}
+
+func (m diffModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m diffModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.ensureCursorVisible()
+
+	case tea.KeyMsg:
+		// Keys of the diff view, which the main keymap doesn't bind by default
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "n":
+			m.nextBreaking(1)
+			return m, nil
+		case "N":
+			m.nextBreaking(-1)
+			return m, nil
+		case "u":
+			m.showUnchanged = !m.showUnchanged
+			m.filter()
+			return m, nil
+		}
+
+		act, pending := m.keys.resolve(m.pending, msg.String())
+		m.pending = pending
+		switch act {
+		case actionQuit, actionClose:
+			return m, tea.Quit
+		case actionUp:
+			m.moveTo(m.cursor - 1)
+		case actionDown:
+			m.moveTo(m.cursor + 1)
+		case actionTop:
+			m.moveTo(0)
+		case actionBottom:
+			m.moveTo(len(m.visible) - 1)
+		case actionHalfPageUp:
+			m.top = max(0, m.top-m.listHeight()/2)
+		case actionHalfPageDown:
+			m.top = min(m.top+m.listHeight()/2, max(0, len(m.detailLines(m.detailWidth()))-1))
+		case actionPageUp:
+			m.top = max(0, m.top-m.listHeight())
+		case actionPageDown:
+			m.top = min(m.top+m.listHeight(), max(0, len(m.detailLines(m.detailWidth()))-1))
+		}
+	}
+
+	return m, nil
+}
+
+func (m diffModel) listWidth() int {
+	return min(40, max(20, m.width/3))
+}
+
+func (m diffModel) detailWidth() int {
+	// The separator between the list and the details takes 3 columns
+	return max(10, m.width-m.listWidth()-3)
+}
+
+func (m diffModel) View() string {
+	if m.width == 0 {
+		return ""
+	}
+
+	height := m.listHeight()
+	list := m.listLines(m.listWidth(), height)
+	details := m.detailLines(m.detailWidth())
+	details = details[min(m.top, len(details)):]
+
+	separator := lipgloss.NewStyle().Foreground(currentTheme.gray).Render(" " + icons.separator + " ")
+
+	var s strings.Builder
+	s.WriteString(m.renderHeader())
+	for i := range height {
+		left, right := "", ""
+		if i < len(list) {
+			left = list[i]
+		}
+		if i < len(details) {
+			right = details[i]
+		}
+		s.WriteString(fitLine(left, m.listWidth()) + separator + ansi.Truncate(right, m.detailWidth(), icons.ellipsis))
+		s.WriteString("\n")
+	}
+	s.WriteString(m.renderFooter())
+
+	return s.String()
+}
+
+// fitLine truncates or pads a rendered line to exactly width columns
+func fitLine(line string, width int) string {
+	line = ansi.Truncate(line, width, icons.ellipsis)
+	return line + strings.Repeat(" ", max(0, width-ansi.StringWidth(line)))
+}
+
+func (m diffModel) renderHeader() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(currentTheme.accent)
+	mutedStyle := lipgloss.NewStyle().Foreground(currentTheme.gray)
+
+	return titleStyle.Render("oq diff") + " " + mutedStyle.Render(m.oldName+" "+icons.link+" "+m.newName) + "\n\n"
+}
+
+func (m diffModel) renderFooter() string {
+	breaking, other := 0, 0
+	for _, item := range m.items {
+		for _, c := range item.changes {
+			if c.Breaking {
+				breaking++
+			} else {
+				other++
+			}
+		}
+	}
+	summary := pluralize(breaking, "breaking change") + " " + icons.dot + " " + pluralize(other, "other change")
+
+	unchanged := "u show unchanged"
+	if m.showUnchanged {
+		unchanged = "u hide unchanged"
+	}
+	hints := joinNonEmpty([]string{
+		m.keys.keysFor(actionUp) + " " + m.keys.keysFor(actionDown) + " move",
+		"n/N next/previous breaking",
+		unchanged,
+		m.keys.keysFor(actionHalfPageDown) + " scroll",
+		m.keys.keysFor(actionQuit) + " quit",
+	}, "  "+icons.separator+"  ")
+	if lipgloss.Width(hints) > m.width-lipgloss.Width(summary)-4 {
+		hints = ""
+	}
+
+	footerStyle := withBackground(lipgloss.NewStyle(), currentTheme.footer).
+		Foreground(currentTheme.footerText).
+		Padding(0, 1).
+		Width(m.width)
+
+	content := hints + strings.Repeat(" ", max(0, m.width-lipgloss.Width(hints)-lipgloss.Width(summary)-2)) + summary
+	return "\n" + footerStyle.Render(content)
+}
+
+// diffMarker is the symbol and color showing the status of an item in the list
+func diffMarker(status diffStatus) (string, lipgloss.TerminalColor) {
+	switch status {
+	case diffAdded:
+		return "+", currentTheme.green
+	case diffRemoved:
+		return "-", currentTheme.red
+	case diffChanged:
+		return "~", currentTheme.yellow
+	}
+	return " ", currentTheme.gray
+}
+
+func (m diffModel) listLines(width, height int) []string {
+	if len(m.visible) == 0 {
+		return []string{lipgloss.NewStyle().Foreground(currentTheme.gray).Render("No changes")}
+	}
+
+	var lines []string
+	end := min(m.scrollOffset+height, len(m.visible))
+	for i := m.scrollOffset; i < end; i++ {
+		item := m.items[m.visible[i]]
+		marker, color := diffMarker(item.status)
+
+		style := lipgloss.NewStyle()
+		if item.status == diffUnchanged {
+			style = style.Foreground(currentTheme.gray)
+		}
+		if item.breaking {
+			style = style.Foreground(currentTheme.red)
+		}
+		markerStyle := lipgloss.NewStyle().Foreground(color).Bold(true)
+		if i == m.cursor {
+			style = withBackground(style, currentTheme.selection)
+			markerStyle = withBackground(markerStyle, currentTheme.selection)
+		}
+
+		name := ansi.Truncate(item.name, width-2, icons.ellipsis)
+		padding := strings.Repeat(" ", max(0, width-2-ansi.StringWidth(name)))
+		lines = append(lines, markerStyle.Render(marker+" ")+style.Render(name+padding))
+	}
+	return lines
+}
+
+// detailLines shows the changes of the selected item, then its details in the
+// old and new spec side by side. Lines only found on one side are highlighted.
+func (m diffModel) detailLines(width int) []string {
+	if m.cursor >= len(m.visible) {
+		return nil
+	}
+	item := m.items[m.visible[m.cursor]]
+
+	nameStyle := lipgloss.NewStyle().Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(currentTheme.gray)
+	breakingStyle := lipgloss.NewStyle().Foreground(currentTheme.red)
+	otherStyle := lipgloss.NewStyle().Foreground(currentTheme.yellow)
+	removedStyle := lipgloss.NewStyle().Foreground(currentTheme.red)
+	addedStyle := lipgloss.NewStyle().Foreground(currentTheme.green)
+	detailStyle := lipgloss.NewStyle().Foreground(currentTheme.detail)
+
+	lines := []string{nameStyle.Render(item.name) + " " + mutedStyle.Render(item.status.String())}
+	if len(item.changes) > 0 {
+		lines = append(lines, "")
+	}
+	for _, c := range item.changes {
+		text := c.Message
+		if c.Location != item.name {
+			text = c.Location + ": " + c.Message
+		}
+		if c.Breaking {
+			lines = append(lines, breakingStyle.Render(icons.cross+" "+text))
+		} else {
+			lines = append(lines, otherStyle.Render(icons.bullet+" "+text))
+		}
+	}
+	lines = append(lines, "")
+
+	columnWidth := max(1, (width-3)/2)
+	separator := mutedStyle.Render(" " + icons.separator + " ")
+	lines = append(lines, fitLine(nameStyle.Render(filepath.Base(m.oldName)), columnWidth)+separator+nameStyle.Render(filepath.Base(m.newName)))
+
+	before, after := detailTextLines(item.before), detailTextLines(item.after)
+	inBefore, inAfter := map[string]bool{}, map[string]bool{}
+	for _, line := range before {
+		inBefore[line] = true
+	}
+	for _, line := range after {
+		inAfter[line] = true
+	}
+
+	for i := range max(len(before), len(after)) {
+		left, right := "", ""
+		if i < len(before) {
+			style := detailStyle
+			if !inAfter[before[i]] {
+				style = removedStyle
+			}
+			left = style.Render(ansi.Truncate(before[i], columnWidth, icons.ellipsis))
+		}
+		if i < len(after) {
+			style := detailStyle
+			if !inBefore[after[i]] {
+				style = addedStyle
+			}
+			right = style.Render(after[i])
+		}
+		lines = append(lines, fitLine(left, columnWidth)+separator+right)
+	}
+
+	return lines
+}
+
+func detailTextLines(details string) []string {
+	details = strings.TrimRight(details, "\n")
+	if details == "" {
+		return nil
+	}
+	return strings.Split(details, "\n")
+}
+
+// runDiffInteractive opens the interactive diff of two specs
+func runDiffInteractive(oldPath, newPath string, old, updated *v3.Document, changes []change) error {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+	if err := cfg.applyDisplay("", false, false); err != nil {
+		return err
+	}
+	keys, err := cfg.keyMap()
+	if err != nil {
+		return fmt.Errorf("config keys: %w", err)
+	}
+
+	m := newDiffModel(oldPath, newPath, diffItems(old, updated, changes), keys)
+	_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
+)
+
+// mappingPointer returns the JSON pointer of the schema a discriminator mapping
+// value names, either a reference or a schema name, e.g. "Cat" for
+// "#/components/schemas/Cat". It is empty for schemas in other files.
+func mappingPointer(value string) string {
+	switch {
+	case strings.HasPrefix(value, "#"):
+		return value
+	case strings.ContainsAny(value, "#/") || strings.HasSuffix(value, ".yaml") || strings.HasSuffix(value, ".yml") || strings.HasSuffix(value, ".json"):
+		return ""
+	}
+	return "#/components/schemas/" + escapePointerToken(value)
+}
+
+// missingMappings returns the mapping values of a discriminator naming schemas
+// that don't exist in the spec it is written in
+func missingMappings(s *base.Schema) map[string]bool {
+	if s == nil || s.Discriminator == nil || s.Discriminator.Mapping == nil || s.GoLow() == nil || s.GoLow().GetIndex() == nil {
+		return nil
+	}
+	root := s.GoLow().GetIndex().GetRootNode()
+	if root != nil && root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+
+	missing := map[string]bool{}
+	for pair := s.Discriminator.Mapping.First(); pair != nil; pair = pair.Next() {
+		if pointer := mappingPointer(pair.Value()); pointer != "" && resolvePointer(root, pointer) == nil {
+			missing[pair.Value()] = true
+		}
+	}
+	return missing
+}
+
+// hasSchemaProperty reports whether a schema declares a property, itself, in
+// one of its allOf parts or in all of its oneOf or anyOf branches. Schemas that
+// can't be looked into, like those in other files, are taken to have it.
+func hasSchemaProperty(root, schema *yaml.Node, name string, depth int) bool {
+	schema = unalias(schema)
+	if schema == nil || depth > maxValidationDepth {
+		return true
+	}
+	if _, ref := field(schema, "$ref"); ref != nil {
+		if !strings.HasPrefix(ref.Value, "#") {
+			return true
+		}
+		return hasSchemaProperty(root, resolvePointer(root, ref.Value), name, depth+1)
+	}
+
+	_, props := field(schema, "properties")
+	if _, prop := field(props, name); prop != nil {
+		return true
+	}
+	if _, allOf := field(schema, "allOf"); allOf != nil && allOf.Kind == yaml.SequenceNode {
+		for _, part := range allOf.Content {
+			if hasSchemaProperty(root, part, name, depth+1) {
+				return true
+			}
+		}
+	}
+	// Or in every oneOf or anyOf branch
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		_, branches := field(schema, keyword)
+		if branches == nil || branches.Kind != yaml.SequenceNode || len(branches.Content) == 0 {
+			continue
+		}
+		if !slices.ContainsFunc(branches.Content, func(branch *yaml.Node) bool { return !hasSchemaProperty(root, branch, name, depth+1) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// lintDiscriminators reports discriminator mappings to schemas that don't
+// exist, and the schemas a discriminator picks from, its mapping targets and
+// oneOf or anyOf branches, missing the discriminator property
+func lintDiscriminators(m *Model) []finding {
+	if m.doc == nil || m.doc.Index == nil {
+		return nil
+	}
+	root := m.doc.Index.GetRootNode()
+	if root != nil && root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+
+	var findings []finding
+	walkSchemas(root, func(schema *yaml.Node, pointer string) {
+		_, d := field(schema, "discriminator")
+		_, property := field(d, "propertyName")
+		if property == nil || property.Kind != yaml.ScalarNode {
+			return
+		}
+		discriminator := pointerTo(pointer, "discriminator")
+
+		// A schema picked several ways is reported once
+		checked := map[*yaml.Node]bool{}
+		checkChild := func(child *yaml.Node, childPointer string) {
+			if _, ref := field(child, "$ref"); ref != nil && strings.HasPrefix(ref.Value, "#") {
+				childPointer = ref.Value
+				child = resolvePointer(root, ref.Value)
+			}
+			if child == nil || checked[child] {
+				return
+			}
+			checked[child] = true
+			if !hasSchemaProperty(root, child, property.Value, 0) {
+				findings = append(findings, finding{
+					Location: childPointer,
+					Pointer:  childPointer,
+					Message:  fmt.Sprintf("schema has no %q property, the discriminator of %s", property.Value, pointer),
+				})
+			}
+		}
+
+		_, mapping := field(d, "mapping")
+		eachField(mapping, func(key, value *yaml.Node) {
+			target := mappingPointer(value.Value)
+			if target == "" {
+				return
+			}
+			if schema := resolvePointer(root, target); schema == nil {
+				mappingAt := pointerTo(pointerTo(discriminator, "mapping"), key.Value)
+				findings = append(findings, finding{
+					Location: mappingAt,
+					Pointer:  mappingAt,
+					Message:  fmt.Sprintf("mapping %q points to %s, which doesn't exist", key.Value, target),
+				})
+			} else {
+				checkChild(schema, target)
+			}
+		})
+		for _, keyword := range []string{"oneOf", "anyOf"} {
+			_, branches := field(schema, keyword)
+			if branches == nil || branches.Kind != yaml.SequenceNode {
+				continue
+			}
+			for i, branch := range branches.Content {
+				checkChild(unalias(branch), pointerTo(pointerTo(pointer, keyword), strconv.Itoa(i)))
+			}
+		}
+	})
+	return findings
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"go.yaml.in/yaml/v4"
+)
+
+// position is a location in the spec file, 1-based like editors show it
+type position struct {
+	line   int
+	column int
+}
+
+// sourcePosition returns where an operation or component is defined in the spec,
+// from the key it is declared with, e.g. "get:" or "Pet:". ok is false when the
+// object has no source, like the merged operations built for display.
+func sourcePosition(source any) (position, bool) {
+	var node *yaml.Node
+	switch s := source.(type) {
+	case *v3.Operation:
+		if s != nil && s.GoLow() != nil {
+			node = s.GoLow().KeyNode
+		}
+	case *base.SchemaProxy:
+		if s != nil && s.GoLow() != nil {
+			node = s.GoLow().GetKeyNode()
+		}
+	case *v3.RequestBody:
+		if s != nil && s.GoLow() != nil {
+			node = s.GoLow().KeyNode
+		}
+	case *v3.Response:
+		if s != nil && s.GoLow() != nil {
+			node = s.GoLow().KeyNode
+		}
+	case *v3.Parameter:
+		if s != nil && s.GoLow() != nil {
+			node = s.GoLow().GetKeyNode()
+		}
+	case *v3.Header:
+		if s != nil && s.GoLow() != nil {
+			node = s.GoLow().GetKeyNode()
+		}
+	case *v3.SecurityScheme:
+		if s != nil && s.GoLow() != nil {
+			node = s.GoLow().GetKeyNode()
+		}
+	}
+
+	if node == nil || node.Line == 0 {
+		return position{}, false
+	}
+	return position{line: node.Line, column: node.Column}, true
+}
+
+// currentPosition returns where the selected item is defined in the spec
+func (m *Model) currentPosition() (position, bool) {
+	if m.cursor < 0 || m.cursor > m.getMaxItems() {
+		return position{}, false
+	}
+
+	switch m.mode {
+	case viewEndpoints:
+		return sourcePosition(m.endpoints[m.cursor].source)
+	case viewComponents:
+		return sourcePosition(m.components[m.cursor].source)
+	case viewWebhooks:
+		return sourcePosition(m.webhooks[m.cursor].source)
+	}
+	return position{}, false
+}
+
+// locationText shows where the selected item is defined, e.g. "petstore.yaml:40:5",
+// or "line 40" when the spec was read from stdin
+func (m Model) locationText() string {
+	pos, ok := m.currentPosition()
+	if !ok {
+		return ""
+	}
+	path := m.selectedFile()
+	if path == "" {
+		return fmt.Sprintf("line %d", pos.line)
+	}
+	return fmt.Sprintf("%s:%d:%d", filepath.Base(path), pos.line, pos.column)
+}
+
+// editorCommand builds the command opening path at line in editor, which may
+// include arguments like "code --wait". Most editors take the line as "+N";
+// those that don't get "path:N" instead.
+func editorCommand(editor, path string, line int) *exec.Cmd {
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+
+	switch name := filepath.Base(args[0]); {
+	case line <= 0:
+		args = append(args, path)
+	case name == "code" || name == "code-insiders" || name == "codium" || name == "cursor":
+		args = append(args, "--goto", fmt.Sprintf("%s:%d", path, line))
+	case name == "subl" || name == "zed" || name == "hx" || name == "helix":
+		args = append(args, fmt.Sprintf("%s:%d", path, line))
+	default:
+		args = append(args, fmt.Sprintf("+%d", line), path)
+	}
+
+	return exec.Command(args[0], args[1:]...)
+}
+
+// editorMsg reports that the editor was closed
+type editorMsg struct {
+	err error
+}
+
+// openInEditor suspends the TUI and opens the spec at the selected item, in
+// the configured editor or else $VISUAL or $EDITOR
+func (m *Model) openInEditor() tea.Cmd {
+	if m.specPath == "" {
+		m.message = "The spec was read from stdin and can't be edited"
+		return nil
+	}
+
+	editor := m.editor
+	if editor == "" {
+		editor = os.Getenv("VISUAL")
+	}
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+
+	line := 0
+	if pos, ok := m.currentPosition(); ok {
+		line = pos.line
+	}
+
+	return tea.ExecProcess(editorCommand(editor, m.selectedFile(), line), func(err error) tea.Msg {
+		return editorMsg{err}
+	})
+}
+
+// pagerCommand builds the command paging text in pager, which may include
+// arguments like "less -S". less is asked to show the colors of the raw
+// source, unless $LESS says otherwise.
+func pagerCommand(pager string) *exec.Cmd {
+	args := strings.Fields(pager)
+	if len(args) == 0 {
+		args = []string{"less"}
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(os.Environ(), "LESS=R")
+	}
+	return cmd
+}
+
+// pagerMsg reports that the pager was closed
+type pagerMsg struct {
+	err error
+}
+
+// openInPager suspends the TUI and pipes the selected item with its details,
+// or its raw source, into $PAGER, for details much longer than the screen
+func (m *Model) openInPager() tea.Cmd {
+	text := m.selectionText()
+	if text == "" {
+		m.message = "Select an operation or component to page its details"
+		return nil
+	}
+
+	return pageText(text)
+}
+
+// pageText suspends the TUI and pipes text into $PAGER
+func pageText(text string) tea.Cmd {
+	cmd := pagerCommand(os.Getenv("PAGER"))
+	cmd.Stdin = strings.NewReader(text)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return pagerMsg{err}
+	})
+}
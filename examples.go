@@ -0,0 +1,353 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"go.yaml.in/yaml/v4"
+)
+
+// maxExampleLines is how many lines of an example are shown before it gets truncated
+const maxExampleLines = 8
+
+// firstExample picks the example to display: the inline example if present, otherwise
+// the first of the named examples that has a value, with its name
+func firstExample(example *yaml.Node, examples *orderedmap.Map[string, *base.Example]) (string, *yaml.Node) {
+	if example != nil {
+		return "", example
+	}
+
+	if examples == nil {
+		return "", nil
+	}
+
+	for pair := examples.First(); pair != nil; pair = pair.Next() {
+		if value := exampleValue(pair.Value()); value != nil {
+			return pair.Key(), value
+		}
+	}
+
+	return "", nil
+}
+
+// exampleValue returns the value of a named example, from the OpenAPI 3.2
+// dataValue field when value is not set
+func exampleValue(ex *base.Example) *yaml.Node {
+	if ex == nil {
+		return nil
+	}
+	if ex.Value != nil {
+		return ex.Value
+	}
+	return ex.DataValue
+}
+
+// exampleTitle builds a heading like "Example (cat)"
+func exampleTitle(name string) string {
+	if name == "" {
+		return "Example"
+	}
+	return "Example (" + name + ")"
+}
+
+// exampleLabel describes a named example in a list, e.g. "cat: A fluffy cat" or
+// "large: Many pets (external: https://example.com/pets.json)"
+func exampleLabel(name string, ex *base.Example) string {
+	label := name
+	if ex == nil {
+		return label
+	}
+	if ex.Summary != "" {
+		label += ": " + firstLine(ex.Summary)
+	}
+	if exampleValue(ex) == nil && ex.ExternalValue != "" {
+		label += " (external: " + ex.ExternalValue + ")"
+	}
+	return label
+}
+
+// writeExamples shows the example of a parameter or media type. Named examples
+// are listed first when there are several of them, or when none has a value
+// that can be shown inline.
+func writeExamples(details *strings.Builder, example *yaml.Node, examples *orderedmap.Map[string, *base.Example], indent string, expand bool) {
+	name, node := firstExample(example, examples)
+
+	if examples != nil && (examples.Len() > 1 || (node == nil && examples.Len() > 0)) {
+		details.WriteString(indent + "Examples (press v to open):\n")
+		for pair := examples.First(); pair != nil; pair = pair.Next() {
+			details.WriteString(fmt.Sprintf("%s  - %s\n", indent, exampleLabel(pair.Key(), pair.Value())))
+		}
+	}
+
+	details.WriteString(formatExample(exampleTitle(name), node, indent, expand))
+}
+
+// exampleText pretty-prints an example value. Scalars (including XML or plain text
+// payloads) are shown as-is, structured values as indented JSON.
+func exampleText(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+	return highlightJSON(nodeToJSON(node, ""))
+}
+
+// formatExample renders an example below title. Single line examples are shown inline,
+// longer ones are indented below the title and truncated unless expand is set.
+func formatExample(title string, node *yaml.Node, indent string, expand bool) string {
+	if node == nil {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimRight(exampleText(node), "\n"), "\n")
+	if len(lines) == 1 {
+		return fmt.Sprintf("%s%s: %s\n", indent, title, lines[0])
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s%s:\n", indent, title))
+
+	hidden := 0
+	if !expand && len(lines) > maxExampleLines {
+		hidden = len(lines) - maxExampleLines
+		lines = lines[:maxExampleLines]
+	}
+
+	for _, line := range lines {
+		b.WriteString(indent + "  " + line + "\n")
+	}
+
+	if hidden > 0 {
+		b.WriteString(fmt.Sprintf("%s  %s %d more lines (press x to expand)\n", indent, icons.ellipsis, hidden))
+	}
+
+	return b.String()
+}
+
+// namedExample is an example of the selected item that can be opened in the
+// example viewer
+type namedExample struct {
+	location string // Where the example is given, e.g. "query limit" or "200 application/json"
+	name     string // Empty for an inline example
+	example  *base.Example
+}
+
+// label describes the example in the picker, e.g. "200 application/json: cat: A fluffy cat"
+func (e namedExample) label() string {
+	name := e.name
+	if name == "" {
+		name = "example"
+	}
+	return e.location + ": " + exampleLabel(name, e.example)
+}
+
+// exampleViewer shows the full value of an example in a modal
+type exampleViewer struct {
+	item namedExample
+	top  int // First visible line
+}
+
+func appendExamples(list []namedExample, location string, example *yaml.Node, examples *orderedmap.Map[string, *base.Example]) []namedExample {
+	if example != nil {
+		list = append(list, namedExample{location: location, example: &base.Example{Value: example}})
+	}
+	if examples != nil {
+		for pair := examples.First(); pair != nil; pair = pair.Next() {
+			if pair.Value() != nil {
+				list = append(list, namedExample{location: location, name: pair.Key(), example: pair.Value()})
+			}
+		}
+	}
+	return list
+}
+
+func appendContentExamples(list []namedExample, location string, content *orderedmap.Map[string, *v3.MediaType]) []namedExample {
+	if content == nil {
+		return list
+	}
+
+	var mediaTypes []string
+	for pair := content.First(); pair != nil; pair = pair.Next() {
+		mediaTypes = append(mediaTypes, pair.Key())
+	}
+	sort.Strings(mediaTypes)
+
+	for _, mediaType := range mediaTypes {
+		if mt, ok := content.Get(mediaType); ok && mt != nil {
+			list = appendExamples(list, strings.TrimSpace(location+" "+mediaType), mt.Example, mt.Examples)
+		}
+	}
+	return list
+}
+
+func appendResponseExamples(list []namedExample, code string, resp *v3.Response) []namedExample {
+	if resp == nil {
+		return list
+	}
+	list = appendContentExamples(list, code, resp.Content)
+	if resp.Headers != nil {
+		for pair := resp.Headers.First(); pair != nil; pair = pair.Next() {
+			if header := pair.Value(); header != nil {
+				list = appendExamples(list, strings.TrimSpace(code+" header "+pair.Key()), header.Example, header.Examples)
+			}
+		}
+	}
+	return list
+}
+
+// operationExamples collects the examples of the parameters, request body and
+// responses of an operation, in the order they are shown in its details
+func operationExamples(op *v3.Operation) []namedExample {
+	if op == nil {
+		return nil
+	}
+
+	var list []namedExample
+	for _, param := range op.Parameters {
+		if param == nil {
+			continue
+		}
+		location := param.In + " " + param.Name
+		list = appendExamples(list, location, param.Example, param.Examples)
+		list = appendContentExamples(list, location, param.Content)
+	}
+
+	if op.RequestBody != nil {
+		list = appendContentExamples(list, "request body", op.RequestBody.Content)
+	}
+
+	if op.Responses != nil {
+		var codes []string
+		if op.Responses.Codes != nil {
+			for pair := op.Responses.Codes.First(); pair != nil; pair = pair.Next() {
+				codes = append(codes, pair.Key())
+			}
+		}
+		sortResponseCodes(codes)
+
+		for _, code := range codes {
+			resp, _ := op.Responses.Codes.Get(code)
+			list = appendResponseExamples(list, code, resp)
+		}
+		list = appendResponseExamples(list, "default", op.Responses.Default)
+	}
+
+	return list
+}
+
+func componentExamples(source renderable) []namedExample {
+	switch s := source.(type) {
+	case *v3.Parameter:
+		if s != nil {
+			return appendContentExamples(appendExamples(nil, s.In+" "+s.Name, s.Example, s.Examples), s.In+" "+s.Name, s.Content)
+		}
+	case *v3.Header:
+		if s != nil {
+			return appendContentExamples(appendExamples(nil, "header", s.Example, s.Examples), "header", s.Content)
+		}
+	case *v3.RequestBody:
+		if s != nil {
+			return appendContentExamples(nil, "", s.Content)
+		}
+	case *v3.Response:
+		if s != nil {
+			return appendResponseExamples(nil, "", s)
+		}
+	}
+	return nil
+}
+
+// currentExamples returns the examples of the selected item
+func (m *Model) currentExamples() []namedExample {
+	if m.cursor > m.getMaxItems() {
+		return nil
+	}
+
+	switch m.mode {
+	case viewEndpoints:
+		return operationExamples(m.endpoints[m.cursor].op)
+	case viewComponents:
+		return componentExamples(m.components[m.cursor].source)
+	case viewWebhooks:
+		return operationExamples(m.webhooks[m.cursor].op)
+	}
+	return nil
+}
+
+// openExample shows an example of the selected item, asking which one when
+// there are several
+func (m *Model) openExample() {
+	examples := m.currentExamples()
+	switch len(examples) {
+	case 0:
+		m.message = "No examples"
+	case 1:
+		m.example = &exampleViewer{item: examples[0]}
+	default:
+		var items []pickerItem
+		for i, ex := range examples {
+			items = append(items, pickerItem{label: ex.label(), value: strconv.Itoa(i)})
+		}
+		m.picker = &picker{
+			title:  "Open example",
+			items:  items,
+			action: pickerOpenExample,
+		}
+	}
+}
+
+// exampleLines returns the summary and description of the example in the viewer,
+// followed by its pretty-printed value
+func (m Model) exampleLines() []string {
+	ex := m.example.item.example
+
+	var lines []string
+	if ex.Summary != "" {
+		lines = append(lines, ex.Summary)
+	}
+	if ex.Description != "" {
+		lines = append(lines, strings.Split(strings.TrimRight(ex.Description, "\n"), "\n")...)
+	}
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+
+	switch value := exampleValue(ex); {
+	case value != nil:
+		lines = append(lines, strings.Split(strings.TrimRight(exampleText(value), "\n"), "\n")...)
+	case ex.ExternalValue != "":
+		lines = append(lines, "External value: "+ex.ExternalValue)
+	case ex.SerializedValue != "":
+		lines = append(lines, strings.Split(strings.TrimRight(ex.SerializedValue, "\n"), "\n")...)
+	default:
+		lines = append(lines, "No value")
+	}
+
+	return lines
+}
+
+// updateExample handles keys while an example is shown
+func (m Model) updateExample(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+
+	act, _ := m.keys.resolve("", msg.String())
+	switch act {
+	case actionClose, actionQuit, actionOpenExample:
+		m.example = nil
+
+	case actionUp:
+		m.example.top = max(0, m.example.top-1)
+
+	case actionDown:
+		m.example.top = min(m.example.top+1, max(0, len(m.exampleLines())-1))
+	}
+
+	return m, nil
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Export formats offered by the export picker. The export command also
+// writes Markdown documentation.
+const (
+	exportPostman  = "postman"
+	exportInsomnia = "insomnia"
+	exportMarkdown = "markdown"
+)
+
+const postmanSchemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// exportRequest is a sample request named for display in another tool
+type exportRequest struct {
+	name string
+	req  sampleRequest
+}
+
+type postmanCollection struct {
+	Info postmanInfo   `json:"info"`
+	Item []postmanItem `json:"item"`
+}
+
+type postmanInfo struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+type postmanItem struct {
+	Name    string         `json:"name"`
+	Request postmanRequest `json:"request"`
+}
+
+type postmanRequest struct {
+	Method string          `json:"method"`
+	Header []postmanHeader `json:"header"`
+	URL    string          `json:"url"`
+	Body   *postmanBody    `json:"body,omitempty"`
+}
+
+type postmanHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type postmanBody struct {
+	Mode     string              `json:"mode"`
+	Raw      string              `json:"raw,omitempty"`
+	FormData []postmanFormField  `json:"formdata,omitempty"`
+	Options  *postmanBodyOptions `json:"options,omitempty"`
+}
+
+type postmanFormField struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+	Src   string `json:"src,omitempty"`
+	Type  string `json:"type"`
+}
+
+type postmanBodyOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+// postmanCollectionJSON builds a Postman v2.1 collection holding the requests
+func postmanCollectionJSON(name string, requests []exportRequest) ([]byte, error) {
+	collection := postmanCollection{
+		Info: postmanInfo{Name: name, Schema: postmanSchemaURL},
+		Item: []postmanItem{},
+	}
+
+	for _, r := range requests {
+		item := postmanItem{
+			Name: r.name,
+			Request: postmanRequest{
+				Method: r.req.method,
+				Header: []postmanHeader{},
+				URL:    r.req.url,
+			},
+		}
+
+		for _, h := range r.req.headers {
+			item.Request.Header = append(item.Request.Header, postmanHeader{h.name, h.value})
+		}
+		if r.req.contentType != "" {
+			item.Request.Header = append(item.Request.Header, postmanHeader{"Content-Type", r.req.contentType})
+		}
+
+		switch {
+		case r.req.body != "":
+			item.Request.Body = &postmanBody{Mode: "raw", Raw: r.req.body}
+			if r.req.json != nil {
+				item.Request.Body.Options = &postmanBodyOptions{}
+				item.Request.Body.Options.Raw.Language = "json"
+			}
+		case r.req.bodyFile != "":
+			item.Request.Body = &postmanBody{Mode: "file"}
+		case len(r.req.form) > 0:
+			item.Request.Body = &postmanBody{Mode: "formdata"}
+			for _, f := range r.req.form {
+				if f.file {
+					item.Request.Body.FormData = append(item.Request.Body.FormData, postmanFormField{Key: f.name, Src: f.value, Type: "file"})
+				} else {
+					item.Request.Body.FormData = append(item.Request.Body.FormData, postmanFormField{Key: f.name, Value: f.value, Type: "text"})
+				}
+			}
+		}
+
+		collection.Item = append(collection.Item, item)
+	}
+
+	return json.MarshalIndent(collection, "", "  ")
+}
+
+type insomniaExport struct {
+	Type         string             `json:"_type"`
+	ExportFormat int                `json:"__export_format"`
+	ExportSource string             `json:"__export_source"`
+	Resources    []insomniaResource `json:"resources"`
+}
+
+// insomniaResource is either the workspace or one of its requests
+type insomniaResource struct {
+	ID       string           `json:"_id"`
+	Type     string           `json:"_type"`
+	ParentID string           `json:"parentId,omitempty"`
+	Name     string           `json:"name"`
+	Method   string           `json:"method,omitempty"`
+	URL      string           `json:"url,omitempty"`
+	Headers  []insomniaHeader `json:"headers,omitempty"`
+	Body     *insomniaBody    `json:"body,omitempty"`
+}
+
+type insomniaHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type insomniaBody struct {
+	MimeType string          `json:"mimeType"`
+	Text     string          `json:"text,omitempty"`
+	Params   []insomniaParam `json:"params,omitempty"`
+}
+
+type insomniaParam struct {
+	Name     string `json:"name"`
+	Value    string `json:"value,omitempty"`
+	Type     string `json:"type,omitempty"`
+	FileName string `json:"fileName,omitempty"`
+}
+
+// insomniaExportJSON builds an Insomnia v4 export with a workspace holding the requests
+func insomniaExportJSON(name string, requests []exportRequest) ([]byte, error) {
+	const workspaceID = "wrk_oq"
+
+	export := insomniaExport{
+		Type:         "export",
+		ExportFormat: 4,
+		ExportSource: "oq",
+		Resources:    []insomniaResource{{ID: workspaceID, Type: "workspace", Name: name}},
+	}
+
+	for i, r := range requests {
+		res := insomniaResource{
+			ID:       fmt.Sprintf("req_oq_%d", i+1),
+			Type:     "request",
+			ParentID: workspaceID,
+			Name:     r.name,
+			Method:   r.req.method,
+			URL:      r.req.url,
+		}
+
+		for _, h := range r.req.headers {
+			res.Headers = append(res.Headers, insomniaHeader{h.name, h.value})
+		}
+
+		switch {
+		case r.req.body != "":
+			res.Body = &insomniaBody{MimeType: r.req.contentType, Text: r.req.body}
+		case r.req.bodyFile != "":
+			res.Body = &insomniaBody{MimeType: r.req.contentType}
+		case len(r.req.form) > 0:
+			res.Body = &insomniaBody{MimeType: "multipart/form-data"}
+			for _, f := range r.req.form {
+				if f.file {
+					res.Body.Params = append(res.Body.Params, insomniaParam{Name: f.name, Type: "file", FileName: f.value})
+				} else {
+					res.Body.Params = append(res.Body.Params, insomniaParam{Name: f.name, Value: f.value})
+				}
+			}
+		}
+		if res.Body != nil && res.Body.MimeType != "" {
+			res.Headers = append(res.Headers, insomniaHeader{"Content-Type", res.Body.MimeType})
+		}
+
+		export.Resources = append(export.Resources, res)
+	}
+
+	return json.MarshalIndent(export, "", "  ")
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// exportFileName derives a file name like "getPetById.postman_collection.json",
+// or "getPetById-2.postman_collection.json" for n above 1
+func exportFileName(name, format string, n int) string {
+	base := strings.Trim(unsafeFileChars.ReplaceAllString(name, "-"), "-")
+	if base == "" {
+		base = "oq"
+	}
+	if n > 1 {
+		base += "-" + strconv.Itoa(n)
+	}
+	if format == exportPostman {
+		return base + ".postman_collection.json"
+	}
+	return base + ".insomnia.json"
+}
+
+// writeExport writes requests in the given format to a file named after fileBase
+// in the current directory and returns the file name. Existing files are kept,
+// numbering the new one instead, like "pet-2.insomnia.json".
+func writeExport(format, title, fileBase string, requests []exportRequest) (string, error) {
+	var data []byte
+	var err error
+	if format == exportPostman {
+		data, err = postmanCollectionJSON(title, requests)
+	} else {
+		data, err = insomniaExportJSON(title, requests)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	for n := 1; ; n++ {
+		file := exportFileName(fileBase, format, n)
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.Write(append(data, '\n'))
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		return file, err
+	}
+}
+
+// endpointTitle names an endpoint by its summary, falling back to method and path
+func endpointTitle(ep endpoint) string {
+	if ep.op.Summary != "" {
+		return ep.op.Summary
+	}
+	return ep.method + " " + ep.path
+}
+
+// runExport implements "oq export", writing the whole spec in one of the export formats
+func runExport(fs *flag.FlagSet, args []string) error {
+	format := fs.String("format", exportMarkdown, "export format: markdown, postman or insomnia")
+	out := fs.String("o", "", "file to write to instead of stdout")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	path, err := specArg(fs)
+	if err != nil {
+		return err
+	}
+
+	doc, err := loadSpec(path)
+	if err != nil {
+		return err
+	}
+	endpoints := extractEndpoints(doc)
+
+	var data []byte
+	switch *format {
+	case exportMarkdown:
+		data = []byte(markdownReference(doc, endpoints, extractComponents(doc)))
+	case exportPostman, exportInsomnia:
+		var requests []exportRequest
+		for _, ep := range endpoints {
+			requests = append(requests, exportRequest{name: endpointTitle(ep), req: buildSampleRequest(doc, ep)})
+		}
+		title := ""
+		if doc.Info != nil {
+			title = doc.Info.Title
+		}
+		if *format == exportPostman {
+			data, err = postmanCollectionJSON(title, requests)
+		} else {
+			data, err = insomniaExportJSON(title, requests)
+		}
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+	default:
+		return fmt.Errorf("unknown export format %q, use %s, %s or %s", *format, exportMarkdown, exportPostman, exportInsomnia)
+	}
+
+	return writeResult(*out, data)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pb33f/libopenapi/orderedmap"
+	"go.yaml.in/yaml/v4"
+)
+
+// formatExtensions lists the x- extensions of an object. Scalars are shown inline;
+// objects and arrays, like gateway configuration, are collapsed to a placeholder
+// unless expand is set, in which case they are pretty-printed in full.
+func formatExtensions(extensions *orderedmap.Map[string, *yaml.Node], indent string, expand bool) string {
+	if extensions == nil || extensions.Len() == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(indent + "Extensions:\n")
+
+	collapsed := 0
+	for pair := extensions.First(); pair != nil; pair = pair.Next() {
+		node := pair.Value()
+		if node == nil {
+			continue
+		}
+		for node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+			node = node.Content[0]
+		}
+
+		switch {
+		case node.Kind == yaml.ScalarNode:
+			b.WriteString(fmt.Sprintf("%s  %s: %s\n", indent, pair.Key(), firstLine(node.Value)))
+		case expand:
+			b.WriteString(formatExample(pair.Key(), node, indent+"  ", true))
+		default:
+			collapsed++
+			b.WriteString(fmt.Sprintf("%s  %s: %s\n", indent, pair.Key(), collapsedValue(node)))
+		}
+	}
+
+	if collapsed > 0 {
+		b.WriteString(fmt.Sprintf("%s  (press z e to expand)\n", indent))
+	}
+
+	return b.String()
+}
+
+// collapsedValue summarizes an object or array, e.g. "{…} 3 keys" or "[…] 2 items"
+func collapsedValue(node *yaml.Node) string {
+	if node.Kind == yaml.SequenceNode {
+		return fmt.Sprintf("[%s] %s", icons.ellipsis, pluralize(len(node.Content), "item"))
+	}
+	if node.Kind == yaml.MappingNode {
+		return fmt.Sprintf("{%s} %s", icons.ellipsis, pluralize(len(node.Content)/2, "key"))
+	}
+	return icons.ellipsis
+}
+
+// mergeExtensions combines extension maps, the first one winning on duplicate names
+func mergeExtensions(maps ...*orderedmap.Map[string, *yaml.Node]) *orderedmap.Map[string, *yaml.Node] {
+	merged := orderedmap.New[string, *yaml.Node]()
+	for _, m := range maps {
+		if m == nil {
+			continue
+		}
+		for pair := m.First(); pair != nil; pair = pair.Next() {
+			if _, ok := merged.Get(pair.Key()); !ok {
+				merged.Set(pair.Key(), pair.Value())
+			}
+		}
+	}
+	return merged
+}
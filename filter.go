@@ -0,0 +1,283 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// stringList is a flag that can be repeated or given comma-separated values
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// pathGlob compiles a path pattern where "*" matches within a path segment and
+// "**" across segments, so "/v2/**" matches "/v2" and everything below it
+func pathGlob(pattern string) *regexp.Regexp {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `/\*\*`, `(/.*)?`)
+	expr = strings.ReplaceAll(expr, `\*\*`, `.*`)
+	expr = strings.ReplaceAll(expr, `\*`, `[^/]*`)
+	return regexp.MustCompile("^" + expr + "$")
+}
+
+// specFilter selects operations by tag and path. An operation has to match one
+// of the tags and one of the paths, an empty list matching everything.
+type specFilter struct {
+	tags  []string
+	paths []*regexp.Regexp
+}
+
+func (f specFilter) matches(path string, op *yaml.Node) bool {
+	if len(f.paths) > 0 && !slices.ContainsFunc(f.paths, func(re *regexp.Regexp) bool { return re.MatchString(path) }) {
+		return false
+	}
+	if len(f.tags) == 0 {
+		return true
+	}
+	_, tags := field(op, "tags")
+	return tags != nil && slices.ContainsFunc(tags.Content, func(tag *yaml.Node) bool {
+		return slices.Contains(f.tags, unalias(tag).Value)
+	})
+}
+
+// filterSpec removes the operations the filter doesn't match from the spec,
+// then the paths, tags and components nothing left uses. It returns how many
+// operations are kept.
+func filterSpec(doc *yaml.Node, f specFilter) int {
+	kept := 0
+	usedTags := map[string]bool{}
+	for _, section := range []string{"paths", "webhooks"} {
+		_, items := field(doc, section)
+		if items == nil {
+			continue
+		}
+		var remaining []*yaml.Node
+		eachField(items, func(key, item *yaml.Node) {
+			operations := 0
+			for _, method := range httpMethods {
+				_, op := field(item, method)
+				if op == nil {
+					continue
+				}
+				if !f.matches(key.Value, op) {
+					removeField(item, method)
+					continue
+				}
+				operations++
+				if _, tags := field(op, "tags"); tags != nil {
+					for _, tag := range tags.Content {
+						usedTags[unalias(tag).Value] = true
+					}
+				}
+			}
+			// Path items only made of a reference are kept when the filter has no tags
+			if _, ref := field(item, "$ref"); operations > 0 || (ref != nil && len(f.tags) == 0 && f.matches(key.Value, item)) {
+				kept += operations
+				remaining = append(remaining, key, item)
+			}
+		})
+		items.Content = remaining
+		if len(remaining) == 0 && section == "webhooks" {
+			removeField(doc, section)
+		}
+	}
+
+	if _, tags := field(doc, "tags"); tags != nil && tags.Kind == yaml.SequenceNode {
+		tags.Content = slices.DeleteFunc(tags.Content, func(tag *yaml.Node) bool {
+			_, name := field(tag, "name")
+			return name == nil || !usedTags[name.Value]
+		})
+		if len(tags.Content) == 0 {
+			removeField(doc, "tags")
+		}
+	}
+
+	pruneComponents(doc)
+	inlineDanglingAliases(doc, map[*yaml.Node]bool{})
+	return kept
+}
+
+// inlineDanglingAliases replaces the YAML aliases whose anchor was filtered out,
+// or now comes after them, with a copy of the node they stand for
+func inlineDanglingAliases(node *yaml.Node, anchored map[*yaml.Node]bool) {
+	if node.Kind == yaml.AliasNode {
+		if anchored[node.Alias] {
+			return
+		}
+		*node = *node.Alias
+		node.Anchor = ""
+	}
+	if node.Anchor != "" {
+		anchored[node] = true
+	}
+	for _, child := range node.Content {
+		inlineDanglingAliases(child, anchored)
+	}
+}
+
+// pruneComponents removes the components that the rest of the spec doesn't
+// reference, directly or through other components
+func pruneComponents(doc *yaml.Node) {
+	_, components := field(doc, "components")
+	if components == nil {
+		return
+	}
+
+	used := map[string]bool{} // "<section>/<name>"
+	var queue []*yaml.Node
+	var collect func(node *yaml.Node, depth int)
+	use := func(section, name string) {
+		key := section + "/" + name
+		if used[key] {
+			return
+		}
+		used[key] = true
+		_, sectionNode := field(components, section)
+		if _, component := field(sectionNode, name); component != nil {
+			queue = append(queue, component)
+		}
+	}
+	useRef := func(ref string) {
+		ref, ok := strings.CutPrefix(ref, "#/components/")
+		if !ok {
+			return
+		}
+		parts := strings.SplitN(ref, "/", 3)
+		if len(parts) >= 2 {
+			use(parts[0], strings.ReplaceAll(strings.ReplaceAll(parts[1], "~1", "/"), "~0", "~"))
+		}
+	}
+	collect = func(node *yaml.Node, depth int) {
+		node = unalias(node)
+		if node == nil || depth > maxSpecValidationDepth {
+			return
+		}
+		switch node.Kind {
+		case yaml.SequenceNode:
+			for _, item := range node.Content {
+				collect(item, depth+1)
+			}
+		case yaml.MappingNode:
+			eachField(node, func(key, value *yaml.Node) {
+				switch {
+				case key.Value == "$ref" && value.Kind == yaml.ScalarNode:
+					useRef(value.Value)
+				case key.Value == "security" && value.Kind == yaml.SequenceNode:
+					// Security requirements name the schemes instead of referencing them
+					for _, requirement := range value.Content {
+						eachField(requirement, func(scheme, _ *yaml.Node) {
+							use("securitySchemes", scheme.Value)
+						})
+					}
+				case key.Value == "mapping" && value.Kind == yaml.MappingNode:
+					// Discriminator mappings hold references or plain schema names
+					eachField(value, func(_, target *yaml.Node) {
+						if strings.Contains(target.Value, "/") {
+							useRef(target.Value)
+						} else {
+							use("schemas", target.Value)
+						}
+					})
+				default:
+					collect(value, depth+1)
+				}
+			})
+		}
+	}
+
+	eachField(doc, func(key, value *yaml.Node) {
+		if key.Value != "components" {
+			collect(value, 0)
+		}
+	})
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		collect(next, 0)
+	}
+
+	var sections []*yaml.Node
+	eachField(components, func(section, entries *yaml.Node) {
+		if entries.Kind != yaml.MappingNode {
+			return
+		}
+		var remaining []*yaml.Node
+		eachField(entries, func(name, component *yaml.Node) {
+			if used[section.Value+"/"+name.Value] {
+				remaining = append(remaining, name, component)
+			}
+		})
+		if len(remaining) > 0 {
+			entries.Content = remaining
+			sections = append(sections, section, entries)
+		}
+	})
+	components.Content = sections
+	if len(sections) == 0 {
+		removeField(doc, "components")
+	}
+}
+
+// runFilter implements "oq filter"
+func runFilter(fs *flag.FlagSet, args []string) error {
+	var tags, paths stringList
+	fs.Var(&tags, "tag", "keep the operations with this tag, can be repeated")
+	fs.Var(&paths, "path", "keep the operations under paths matching this pattern, e.g. '/v2/**', can be repeated")
+	format := fs.String("format", "", "output format: yaml or json, by default the extension of -o or the format of the spec")
+	out := fs.String("o", "", "file to write to instead of stdout")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	path, err := specArg(fs)
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 && len(paths) == 0 {
+		return fmt.Errorf("give at least one -tag or -path to filter by")
+	}
+
+	content, err := readSpec(path)
+	if err != nil {
+		return err
+	}
+	root, err := parseSpecTree(content)
+	if err != nil {
+		return err
+	}
+
+	f := specFilter{tags: tags}
+	for _, p := range paths {
+		f.paths = append(f.paths, pathGlob(p))
+	}
+	if filterSpec(root.Content[0], f) == 0 {
+		return fmt.Errorf("no operations match the filter")
+	}
+
+	if *format, err = specFormat(*format, *out, root); err != nil {
+		return err
+	}
+	data, err := encodeSpec(root, *format)
+	if err != nil {
+		return err
+	}
+	if _, err := parseSpec(data); err != nil {
+		return fmt.Errorf("filtered spec does not load: %w", err)
+	}
+	return writeResult(*out, data)
+}
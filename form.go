@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// isFormMediaType reports whether a body is sent as form fields
+func isFormMediaType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "multipart/") || mediaType == "application/x-www-form-urlencoded"
+}
+
+// writeFormFields lists the fields of a form body in the order they are declared,
+// each with its schema and the encoding it is sent with: the content type of a
+// multipart part, its headers, or how a urlencoded value is serialized
+func writeFormFields(details *strings.Builder, mt *v3.MediaType, indent string, opts detailOptions) {
+	if mt.Schema == nil || mt.Schema.Schema() == nil {
+		return
+	}
+	s := mt.Schema.Schema()
+	if s.Properties == nil || s.Properties.Len() == 0 {
+		return
+	}
+
+	details.WriteString(indent + "Fields:\n")
+	for pair := s.Properties.First(); pair != nil; pair = pair.Next() {
+		name, prop := pair.Key(), pair.Value()
+		if prop == nil || prop.Schema() == nil {
+			continue
+		}
+
+		var flags []string
+		if slices.Contains(s.Required, name) {
+			flags = append(flags, "required")
+		}
+		flags = append(flags, schemaFlags(prop.Schema())...)
+		label := name
+		if len(flags) > 0 {
+			label += " (" + strings.Join(flags, ", ") + ")"
+		}
+		details.WriteString(fmt.Sprintf("%s  - %s: %s\n", indent, label, schemaSummary(prop)))
+
+		if mt.Encoding != nil {
+			if enc, ok := mt.Encoding.Get(name); ok && enc != nil {
+				writeEncoding(details, enc, indent+"    ")
+			}
+		}
+
+		var ancestors []string
+		if ref := schemaRef(prop); ref != "" {
+			ancestors = []string{ref}
+		}
+		writeSchemaTree(details, prop.Schema(), indent+"    ", opts.schemaDepth-1, ancestors)
+	}
+}
+
+func writeEncoding(details *strings.Builder, enc *v3.Encoding, indent string) {
+	if enc.ContentType != "" {
+		details.WriteString(fmt.Sprintf("%sContent Type: %s\n", indent, enc.ContentType))
+	}
+
+	if enc.Style != "" || enc.Explode != nil {
+		style := enc.Style
+		if style == "" {
+			style = "form"
+		}
+		explode := style == "form"
+		if enc.Explode != nil {
+			explode = *enc.Explode
+		}
+		details.WriteString(fmt.Sprintf("%sStyle: %s, explode: %t\n", indent, style, explode))
+	}
+
+	if enc.Headers != nil && enc.Headers.Len() > 0 {
+		details.WriteString(indent + "Headers:\n")
+		for pair := enc.Headers.First(); pair != nil; pair = pair.Next() {
+			line := fmt.Sprintf("%s  - %s", indent, pair.Key())
+			if header := pair.Value(); header != nil && header.Schema != nil {
+				line += ": " + schemaSummary(header.Schema)
+			}
+			details.WriteString(line + "\n")
+		}
+	}
+}
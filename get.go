@@ -0,0 +1,173 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// resolveRefs copies node with its local references replaced by what they
+// point to. A reference to a node already being expanded is kept as is, so
+// recursive schemas end at the first repetition.
+func resolveRefs(doc, node *yaml.Node, expanding map[string]bool, depth int) *yaml.Node {
+	node = unalias(node)
+	if node == nil || depth > maxSpecValidationDepth {
+		return node
+	}
+
+	if _, ref := field(node, "$ref"); ref != nil && strings.HasPrefix(ref.Value, "#") && !expanding[ref.Value] {
+		if target := resolvePointer(doc, ref.Value); target != nil {
+			expanding[ref.Value] = true
+			resolved := resolveRefs(doc, target, expanding, depth+1)
+			delete(expanding, ref.Value)
+
+			// Siblings of the reference, like a description, override the target
+			if len(node.Content) > 2 && resolved.Kind == yaml.MappingNode {
+				eachField(node, func(key, value *yaml.Node) {
+					if key.Value != "$ref" {
+						setField(resolved, key.Value, resolveRefs(doc, value, expanding, depth+1))
+					}
+				})
+			}
+			return resolved
+		}
+	}
+
+	copied := *node
+	copied.Anchor = ""
+	copied.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		copied.Content[i] = resolveRefs(doc, child, expanding, depth+1)
+	}
+	return &copied
+}
+
+// keyAndSpec returns the argument naming what a command looks for and the
+// optional spec file, which may also come first as in "oq schema openapi.yaml Pet"
+func keyAndSpec(fs *flag.FlagSet) (string, string, error) {
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		return "", "", errUsage
+	}
+	key, spec := fs.Arg(0), fs.Arg(1)
+	if spec != "" && isSpecFile(key) && !isSpecFile(spec) {
+		key, spec = spec, key
+	}
+	return key, spec, nil
+}
+
+// specNode loads the spec given with the key of a node and finds the node in it
+func specNode(fs *flag.FlagSet, format string, find func(doc *yaml.Node, key string) (*yaml.Node, error)) error {
+	key, spec, err := keyAndSpec(fs)
+	if err != nil {
+		return err
+	}
+	content, err := readSpec(spec)
+	if err != nil {
+		return err
+	}
+	root, err := parseSpecTree(content)
+	if err != nil {
+		return err
+	}
+	if format, err = specFormat(format, "", root); err != nil {
+		return err
+	}
+
+	doc := root.Content[0]
+	node, err := find(doc, key)
+	if err != nil {
+		return err
+	}
+	data, err := encodeSpec(resolveRefs(doc, node, map[string]bool{}, 0), format)
+	if err != nil {
+		return err
+	}
+	return writeResult("", data)
+}
+
+// runSchema implements "oq schema"
+func runSchema(fs *flag.FlagSet, args []string) error {
+	format := fs.String("format", "", "output format: yaml or json, by default the format of the spec")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	return specNode(fs, *format, func(doc *yaml.Node, name string) (*yaml.Node, error) {
+		_, components := field(doc, "components")
+		_, schemas := field(components, "schemas")
+		if _, schema := field(schemas, name); schema != nil {
+			return schema, nil
+		}
+
+		var names []string
+		eachField(schemas, func(key, _ *yaml.Node) {
+			names = append(names, key.Value)
+		})
+		if similar := closest(name, names); similar != "" {
+			return nil, fmt.Errorf("schema %q not found, did you mean %q?", name, similar)
+		}
+		return nil, fmt.Errorf("schema %q not found", name)
+	})
+}
+
+// runGet implements "oq get"
+func runGet(fs *flag.FlagSet, args []string) error {
+	format := fs.String("format", "", "output format: yaml or json, by default the format of the spec")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	return specNode(fs, *format, func(doc *yaml.Node, pointer string) (*yaml.Node, error) {
+		if !strings.HasPrefix(pointer, "#") {
+			pointer = "#" + pointer
+		}
+		if node := resolvePointer(doc, pointer); node != nil {
+			return node, nil
+		}
+		return nil, fmt.Errorf("nothing at %q in the spec", pointer)
+	})
+}
+
+// runCurl implements "oq curl", printing the curl command of an operation found
+// by operationId or as "GET /pets"
+func runCurl(fs *flag.FlagSet, args []string) error {
+	server := addRequestFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	name, path, err := keyAndSpec(fs)
+	if err != nil {
+		return err
+	}
+	suite, err := server.suite()
+	if err != nil {
+		return err
+	}
+	doc, err := loadSpec(path)
+	if err != nil {
+		return err
+	}
+
+	endpoints := extractEndpoints(doc)
+	i := slices.IndexFunc(endpoints, func(ep endpoint) bool {
+		return ep.op.OperationId == name || strings.EqualFold(ep.method+" "+ep.path, name)
+	})
+	if i < 0 {
+		var ids []string
+		for _, ep := range endpoints {
+			if ep.op.OperationId != "" {
+				ids = append(ids, ep.op.OperationId)
+			}
+		}
+		if similar := closest(name, ids); similar != "" {
+			return fmt.Errorf("operation %q not found, did you mean %q?", name, similar)
+		}
+		return fmt.Errorf("operation %q not found", name)
+	}
+
+	// Parameters without a value stay placeholders, like <petId>
+	req, _ := suite.request(doc, endpoints[i])
+	return writeResult("", []byte(curlCommand(req)+"\n"))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// helpRow is a line of the help: the title of a section, a key with what it
+// does, or a blank line between sections
+type helpRow struct {
+	title       string
+	keys        string
+	description string
+}
+
+// helpRows lists the bound keys by section, only those matching the filter
+// when one is typed
+func (m Model) helpRows() []helpRow {
+	filter := strings.ToLower(m.helpFilter)
+
+	var rows []helpRow
+	for _, section := range append(slices.Clip(keySections), m.pluginSection()) {
+		var matching []helpRow
+		for _, ka := range section.actions {
+			keys := m.keys.keysFor(ka.action)
+			if keys == "" {
+				continue
+			}
+			text := strings.ToLower(section.title + " " + keys + " " + ka.description + " " + string(ka.action))
+			if strings.Contains(text, filter) {
+				matching = append(matching, helpRow{keys: keys, description: ka.description})
+			}
+		}
+		if len(matching) == 0 {
+			continue
+		}
+		if len(rows) > 0 {
+			rows = append(rows, helpRow{})
+		}
+		rows = append(rows, helpRow{title: section.title})
+		rows = append(rows, matching...)
+	}
+	return rows
+}
+
+// helpHeight is how many rows of the help fit, leaving room for the border,
+// padding, title, filter and the indicators of hidden rows
+func (m Model) helpHeight() int {
+	return max(3, m.height-10)
+}
+
+// updateHelp scrolls the help with the arrows and filters it by what is typed.
+// The help key closes it until something is typed, esc at any time.
+func (m Model) updateHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if act, _ := m.keys.resolve("", msg.String()); act == actionHelp && m.helpFilter == "" {
+		m.showHelp = false
+		return m, nil
+	}
+
+	height := m.helpHeight()
+	bottom := max(0, len(m.helpRows())-height)
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.showHelp = false
+	case tea.KeyUp:
+		m.helpTop = max(0, m.helpTop-1)
+	case tea.KeyDown:
+		m.helpTop = min(m.helpTop+1, bottom)
+	case tea.KeyPgUp:
+		m.helpTop = max(0, m.helpTop-height)
+	case tea.KeyPgDown:
+		m.helpTop = min(m.helpTop+height, bottom)
+	case tea.KeyBackspace:
+		if runes := []rune(m.helpFilter); len(runes) > 0 {
+			m.helpFilter, m.helpTop = string(runes[:len(runes)-1]), 0
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.helpFilter, m.helpTop = m.helpFilter+string(msg.Runes), 0
+	}
+	return m, nil
+}
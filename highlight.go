@@ -0,0 +1,335 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"go.yaml.in/yaml/v4"
+)
+
+var (
+	syntaxKeyStyle     lipgloss.Style
+	syntaxStringStyle  lipgloss.Style
+	syntaxNumberStyle  lipgloss.Style
+	syntaxLiteralStyle lipgloss.Style
+	syntaxPunctStyle   lipgloss.Style
+	syntaxCommentStyle lipgloss.Style
+)
+
+func setSyntaxStyles(t theme) {
+	syntaxKeyStyle = lipgloss.NewStyle().Foreground(t.blue)
+	syntaxStringStyle = lipgloss.NewStyle().Foreground(t.green)
+	syntaxNumberStyle = lipgloss.NewStyle().Foreground(t.yellow)
+	syntaxLiteralStyle = lipgloss.NewStyle().Foreground(t.purple)
+	syntaxPunctStyle = lipgloss.NewStyle().Foreground(t.gray)
+	syntaxCommentStyle = lipgloss.NewStyle().Foreground(t.gray).Italic(true)
+}
+
+type renderable interface {
+	Render() ([]byte, error)
+}
+
+// formatRawSource renders an OpenAPI object back to its source representation.
+// JSON documents are shown as JSON and everything else as YAML, both highlighted.
+func formatRawSource(r renderable, asJSON bool) string {
+	if r == nil || reflect.ValueOf(r).IsNil() {
+		return "No source available"
+	}
+
+	src, err := r.Render()
+	if err != nil {
+		return "Unable to render source: " + err.Error()
+	}
+
+	if asJSON {
+		var node yaml.Node
+		if err := yaml.Unmarshal(src, &node); err == nil {
+			return highlightJSON(nodeToJSON(&node, "")) + "\n"
+		}
+	}
+
+	return highlightYAML(strings.TrimRight(string(src), "\n")) + "\n"
+}
+
+// isJSONDocument reports whether the document was originally written as JSON.
+// JSON is parsed as flow-style YAML, so the root mapping style gives it away.
+func isJSONDocument(root *yaml.Node) bool {
+	if root == nil {
+		return false
+	}
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	return root.Style&yaml.FlowStyle != 0
+}
+
+// nodeToJSON converts a YAML node into indented JSON, keeping the original key order
+func nodeToJSON(node *yaml.Node, indent string) string {
+	if node == nil {
+		return "null"
+	}
+
+	const step = "  "
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return "null"
+		}
+		return nodeToJSON(node.Content[0], indent)
+
+	case yaml.AliasNode:
+		return nodeToJSON(node.Alias, indent)
+
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			return "{}"
+		}
+		var b strings.Builder
+		b.WriteString("{\n")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, _ := json.Marshal(node.Content[i].Value)
+			b.WriteString(indent + step)
+			b.Write(key)
+			b.WriteString(": ")
+			b.WriteString(nodeToJSON(node.Content[i+1], indent+step))
+			if i+2 < len(node.Content) {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "}")
+		return b.String()
+
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			return "[]"
+		}
+		var b strings.Builder
+		b.WriteString("[\n")
+		for i, item := range node.Content {
+			b.WriteString(indent + step)
+			b.WriteString(nodeToJSON(item, indent+step))
+			if i < len(node.Content)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "]")
+		return b.String()
+
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!null":
+			return "null"
+		case "!!bool":
+			if v, err := strconv.ParseBool(node.Value); err == nil {
+				return strconv.FormatBool(v)
+			}
+		case "!!int", "!!float":
+			if _, err := strconv.ParseFloat(node.Value, 64); err == nil {
+				return node.Value
+			}
+		}
+		value, _ := json.Marshal(node.Value)
+		return string(value)
+	}
+
+	return "null"
+}
+
+// highlightYAML colors keys, scalars, comments and list markers of a YAML snippet.
+// It is line based and intentionally forgiving: unknown constructs are left as-is.
+func highlightYAML(src string) string {
+	lines := strings.Split(src, "\n")
+
+	// Indentation of the key that opened a block scalar (| or >), -1 when not inside one
+	blockIndent := -1
+
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+
+		if blockIndent >= 0 {
+			if trimmed == "" || indent > blockIndent {
+				lines[i] = syntaxStringStyle.Render(line)
+				continue
+			}
+			blockIndent = -1
+		}
+
+		if trimmed == "" {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "#") {
+			lines[i] = line[:indent] + syntaxCommentStyle.Render(trimmed)
+			continue
+		}
+
+		var b strings.Builder
+		b.WriteString(line[:indent])
+
+		rest := trimmed
+		for strings.HasPrefix(rest, "- ") || rest == "-" {
+			b.WriteString(syntaxPunctStyle.Render("-"))
+			rest = strings.TrimPrefix(rest, "-")
+			if rest != "" {
+				b.WriteString(" ")
+				rest = rest[1:]
+			}
+		}
+
+		if key, value, ok := splitYAMLKey(rest); ok {
+			b.WriteString(syntaxKeyStyle.Render(key))
+			b.WriteString(syntaxPunctStyle.Render(":"))
+			if value != "" {
+				b.WriteString(" ")
+				b.WriteString(highlightYAMLScalar(value))
+				if isBlockScalarIndicator(value) {
+					blockIndent = indent
+				}
+			}
+		} else {
+			b.WriteString(highlightYAMLScalar(rest))
+			if isBlockScalarIndicator(rest) {
+				blockIndent = indent
+			}
+		}
+
+		lines[i] = b.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// splitYAMLKey splits "key: value" into its parts, respecting quoted keys
+func splitYAMLKey(s string) (string, string, bool) {
+	if s == "" {
+		return "", "", false
+	}
+
+	end := -1
+	if s[0] == '"' || s[0] == '\'' {
+		closing := strings.IndexByte(s[1:], s[0])
+		if closing < 0 {
+			return "", "", false
+		}
+		end = closing + 2
+		if end >= len(s) || s[end] != ':' {
+			return "", "", false
+		}
+	} else {
+		if s[0] == '{' || s[0] == '[' {
+			return "", "", false
+		}
+		for i := 0; i < len(s); i++ {
+			if s[i] == ':' && (i == len(s)-1 || s[i+1] == ' ') {
+				end = i
+				break
+			}
+		}
+		if end <= 0 {
+			return "", "", false
+		}
+	}
+
+	return s[:end], strings.TrimSpace(s[end+1:]), true
+}
+
+func isBlockScalarIndicator(value string) bool {
+	return strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">")
+}
+
+func highlightYAMLScalar(value string) string {
+	// Trailing comments are only recognized after whitespace
+	comment := ""
+	if idx := strings.Index(value, " #"); idx >= 0 && value[0] != '"' && value[0] != '\'' {
+		comment = value[idx:]
+		value = value[:idx]
+	}
+
+	var rendered string
+	switch {
+	case value == "":
+		rendered = ""
+	case value[0] == '{' || value[0] == '[':
+		rendered = highlightJSON(value)
+	case isBlockScalarIndicator(value), value[0] == '&', value[0] == '*':
+		rendered = syntaxPunctStyle.Render(value)
+	default:
+		rendered = highlightLiteral(value)
+	}
+
+	if comment != "" {
+		rendered += syntaxCommentStyle.Render(comment)
+	}
+
+	return rendered
+}
+
+// highlightLiteral picks a style for a single scalar token
+func highlightLiteral(value string) string {
+	switch value {
+	case "true", "false", "null", "~":
+		return syntaxLiteralStyle.Render(value)
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return syntaxNumberStyle.Render(value)
+	}
+	return syntaxStringStyle.Render(value)
+}
+
+// highlightJSON colors a JSON (or YAML flow) snippet token by token.
+// Strings followed by a colon are treated as object keys.
+func highlightJSON(src string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(src) && src[end] != c {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(src))
+			token := src[i:end]
+
+			next := end
+			for next < len(src) && (src[next] == ' ' || src[next] == '\t') {
+				next++
+			}
+			if next < len(src) && src[next] == ':' {
+				b.WriteString(syntaxKeyStyle.Render(token))
+			} else {
+				b.WriteString(syntaxStringStyle.Render(token))
+			}
+			i = end
+
+		case strings.ContainsRune("{}[],:", rune(c)):
+			b.WriteString(syntaxPunctStyle.Render(string(c)))
+			i++
+
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			b.WriteByte(c)
+			i++
+
+		default:
+			end := i
+			for end < len(src) && !strings.ContainsRune("{}[],: \t\n\r", rune(src[end])) {
+				end++
+			}
+			b.WriteString(highlightLiteral(src[i:end]))
+			i = end
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,54 @@
+package main
+
+// maxHistoryEntries limits how many locations are remembered in each direction
+const maxHistoryEntries = 100
+
+// location is a position in one of the views that can be returned to
+type location struct {
+	mode         viewMode
+	cursor       int
+	scrollOffset int
+}
+
+func (m *Model) currentLocation() location {
+	return location{mode: m.mode, cursor: m.cursor, scrollOffset: m.scrollOffset}
+}
+
+// pushHistory remembers the current location before a jump.
+// Like in an editor, a new jump discards the forward history.
+func (m *Model) pushHistory() {
+	m.backStack = append(m.backStack, m.currentLocation())
+	if len(m.backStack) > maxHistoryEntries {
+		m.backStack = m.backStack[len(m.backStack)-maxHistoryEntries:]
+	}
+	m.forwardStack = nil
+}
+
+func (m *Model) navigateBack() {
+	if len(m.backStack) == 0 {
+		return
+	}
+
+	loc := m.backStack[len(m.backStack)-1]
+	m.backStack = m.backStack[:len(m.backStack)-1]
+	m.forwardStack = append(m.forwardStack, m.currentLocation())
+	m.restoreLocation(loc)
+}
+
+func (m *Model) navigateForward() {
+	if len(m.forwardStack) == 0 {
+		return
+	}
+
+	loc := m.forwardStack[len(m.forwardStack)-1]
+	m.forwardStack = m.forwardStack[:len(m.forwardStack)-1]
+	m.backStack = append(m.backStack, m.currentLocation())
+	m.restoreLocation(loc)
+}
+
+func (m *Model) restoreLocation(loc location) {
+	m.mode = loc.mode
+	m.cursor = max(0, min(loc.cursor, m.getMaxItems()))
+	m.scrollOffset = min(loc.scrollOffset, m.cursor)
+	m.ensureCursorVisible()
+}
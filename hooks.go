@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// hooks are commands run when something happens to the selected item, e.g.
+// to send it to a ticketing system. Like plugins, they receive JSON on stdin.
+type hooks struct {
+	OnSelect string `yaml:"on_select"` // Once another item stays selected for a moment
+	OnCopy   string `yaml:"on_copy"`   // After something about the item is copied
+	OnExport string `yaml:"on_export"` // After requests are exported to a file
+}
+
+// Events the hooks run on, named like in the config
+const (
+	hookSelect = "on_select"
+	hookCopy   = "on_copy"
+	hookExport = "on_export"
+)
+
+// selectSettleDelay is how long an item stays selected before on_select runs,
+// so moving through the list doesn't run it for every item passed
+const selectSettleDelay = 300 * time.Millisecond
+
+// hookInput is the JSON hooks receive on stdin
+type hookInput struct {
+	Event  string          `json:"event"`
+	Item   json.RawMessage `json:"item,omitempty"`   // The selected item, as plugins receive it
+	Copied string          `json:"copied,omitempty"` // The text on_copy is about
+	Export string          `json:"export,omitempty"` // The file on_export is about
+	Format string          `json:"format,omitempty"` // Of the export: postman or insomnia
+}
+
+// hookMsg reports how a hook ran
+type hookMsg struct {
+	event  string
+	output string
+	err    error
+}
+
+// selectSettledMsg is sent a moment after the selection changed, to run
+// on_select if it didn't change again since
+type selectSettledMsg struct {
+	selections int
+}
+
+func (h hooks) command(event string) string {
+	switch event {
+	case hookSelect:
+		return h.OnSelect
+	case hookCopy:
+		return h.OnCopy
+	case hookExport:
+		return h.OnExport
+	}
+	return ""
+}
+
+// runHook runs the hook of an event, if any, with the selected item
+func (m *Model) runHook(event string, in hookInput) tea.Cmd {
+	command := m.hooks.command(event)
+	if command == "" {
+		return nil
+	}
+	in.Event = event
+	if item, ok := m.itemInput(m.cursor); ok {
+		in.Item = item
+	}
+	input, err := json.Marshal(in)
+	if err != nil {
+		return nil
+	}
+	return func() tea.Msg {
+		out, err := runPlugin(command, input)
+		return hookMsg{event: event, output: out, err: err}
+	}
+}
+
+// showHookResult tells when a hook failed, or the line it printed
+func (m *Model) showHookResult(msg hookMsg) {
+	switch {
+	case msg.err != nil:
+		m.message = fmt.Sprintf("%s hook failed: %v", msg.event, msg.err)
+	case msg.output != "":
+		m.message, _, _ = strings.Cut(msg.output, "\n")
+	}
+}
+
+// selectedItem identifies the selected item, to tell when another one is
+// selected. It is empty in the info view.
+func (m *Model) selectedItem() string {
+	if m.mode == viewInfo || m.cursor < 0 || m.cursor > m.getMaxItems() {
+		return ""
+	}
+	var ref string
+	switch m.mode {
+	case viewEndpoints:
+		ref = operationRef(m.endpoints[m.cursor].path, m.endpoints[m.cursor].method)
+	case viewWebhooks:
+		ref = webhookRef(m.webhooks[m.cursor].name, m.webhooks[m.cursor].method)
+	case viewComponents:
+		ref = componentRef(m.components[m.cursor].compType, m.components[m.cursor].name)
+	}
+	return m.specPath + ref
+}
@@ -0,0 +1,120 @@
+package main
+
+import "github.com/charmbracelet/lipgloss"
+
+// iconSet holds the symbols drawn by the UI, so they can be swapped for plain
+// ASCII on terminals and fonts that can't render them
+type iconSet struct {
+	folded       string
+	unfolded     string
+	pointer      string
+	above        string
+	below        string
+	separator    string
+	dot          string
+	dash         string
+	link         string
+	circular     string
+	ellipsis     string
+	bullet       string
+	quote        string
+	check        string
+	cross        string
+	warning      string
+	keyUp        string
+	keyDown      string
+	keyLeft      string
+	keyRight     string
+	spinner      []string
+	progressDone string
+	progressLeft string
+	border       lipgloss.Border
+}
+
+var unicodeIcons = iconSet{
+	folded:       "▶",
+	unfolded:     "▼",
+	pointer:      "▶",
+	above:        "⬆",
+	below:        "⬇",
+	separator:    "│",
+	dot:          "·",
+	dash:         "—",
+	link:         "→",
+	circular:     "↻",
+	ellipsis:     "…",
+	bullet:       "•",
+	quote:        "│",
+	check:        "✓",
+	cross:        "✗",
+	warning:      "⚠",
+	keyUp:        "↑",
+	keyDown:      "↓",
+	keyLeft:      "←",
+	keyRight:     "→",
+	spinner:      []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+	progressDone: "█",
+	progressLeft: "░",
+	border:       lipgloss.RoundedBorder(),
+}
+
+var asciiIcons = iconSet{
+	folded:       ">",
+	unfolded:     "v",
+	pointer:      ">",
+	above:        "^",
+	below:        "v",
+	separator:    "|",
+	dot:          "-",
+	dash:         "-",
+	link:         "->",
+	circular:     "@",
+	ellipsis:     "...",
+	bullet:       "*",
+	quote:        "|",
+	check:        "ok",
+	cross:        "x",
+	warning:      "!",
+	keyUp:        "Up",
+	keyDown:      "Down",
+	keyLeft:      "Left",
+	keyRight:     "Right",
+	spinner:      []string{"|", "/", "-", "\\"},
+	progressDone: "#",
+	progressLeft: ".",
+	border: lipgloss.Border{
+		Top:          "-",
+		Bottom:       "-",
+		Left:         "|",
+		Right:        "|",
+		TopLeft:      "+",
+		TopRight:     "+",
+		BottomLeft:   "+",
+		BottomRight:  "+",
+		MiddleLeft:   "+",
+		MiddleRight:  "+",
+		Middle:       "+",
+		MiddleTop:    "+",
+		MiddleBottom: "+",
+	},
+}
+
+// accessibleIcons are the ASCII icons without box drawing, for screen
+// readers, with a spinner that doesn't change the screen on every tick
+var accessibleIcons = func() iconSet {
+	set := asciiIcons
+	set.spinner = []string{"-"}
+	set.border = lipgloss.HiddenBorder()
+	return set
+}()
+
+// icons is the icon set the UI is rendered with
+var icons = unicodeIcons
+
+func setASCII(ascii bool) {
+	if ascii {
+		icons = asciiIcons
+	} else {
+		icons = unicodeIcons
+	}
+}
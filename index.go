@@ -0,0 +1,107 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// maxSearchResults is how many results the search lists, the best first
+const maxSearchResults = 200
+
+// indexEntry is an item found by the search, with the texts matched to the query
+type indexEntry struct {
+	label string // Shown in the results, e.g. "GET /pets" or "Schema Pet.name"
+	ref   string // Jumped to when the result is selected
+	name  string // Lowercased label, matches on it come first
+	text  string // Lowercased path, operationId, summary and names
+}
+
+// searchIndex holds what the search looks at, so it doesn't walk the spec on
+// every key press
+type searchIndex struct {
+	entries []indexEntry
+}
+
+// searchIndexMsg delivers the index built in the background after load
+type searchIndexMsg struct {
+	index *searchIndex
+}
+
+func buildIndexCmd(items itemLists, properties bool) tea.Cmd {
+	return func() tea.Msg {
+		return searchIndexMsg{index: buildSearchIndex(items, properties)}
+	}
+}
+
+// buildSearchIndex indexes the operations, webhooks and components and, with
+// properties, the properties of the schemas, which builds every schema
+func buildSearchIndex(items itemLists, properties bool) *searchIndex {
+	ix := &searchIndex{}
+	add := func(label, ref string, texts ...string) {
+		ix.entries = append(ix.entries, indexEntry{
+			label: label,
+			ref:   ref,
+			name:  strings.ToLower(label),
+			text:  strings.ToLower(strings.Join(texts, "\n")),
+		})
+	}
+
+	for _, ep := range items.endpoints {
+		add(ep.method+" "+ep.path, operationRef(ep.path, ep.method), ep.path, ep.op.OperationId, ep.op.Summary)
+	}
+	for _, hook := range items.webhooks {
+		add(hook.method+" "+hook.name, webhookRef(hook.name, hook.method), hook.name, hook.op.OperationId, hook.op.Summary)
+	}
+	for _, comp := range items.components {
+		ref := componentRef(comp.compType, comp.name)
+		add(comp.compType+" "+comp.name, ref, comp.name)
+
+		proxy, ok := comp.source.(*base.SchemaProxy)
+		if !ok || !properties {
+			continue
+		}
+		schema := proxy.Schema()
+		if schema == nil || schema.Properties == nil {
+			continue
+		}
+		for pair := schema.Properties.First(); pair != nil; pair = pair.Next() {
+			add(comp.compType+" "+comp.name+"."+pair.Key(), ref, pair.Key())
+		}
+	}
+	return ix
+}
+
+// search returns the entries containing every word of the query, those whose
+// label matches first
+func (ix *searchIndex) search(query string) []indexEntry {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil
+	}
+
+	var results []indexEntry
+	for _, entry := range ix.entries {
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(entry.name, word) && !strings.Contains(entry.text, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			results = append(results, entry)
+		}
+	}
+
+	rank := func(entry indexEntry) int {
+		if strings.Contains(entry.name, words[0]) {
+			return 0
+		}
+		return 1
+	}
+	sort.SliceStable(results, func(i, j int) bool { return rank(results[i]) < rank(results[j]) })
+	return results[:min(len(results), maxSearchResults)]
+}
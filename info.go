@@ -0,0 +1,248 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+var (
+	infoTitleStyle   lipgloss.Style
+	infoLabelStyle   lipgloss.Style
+	infoWarningStyle lipgloss.Style
+	markdownBold     lipgloss.Style
+	markdownItalic   lipgloss.Style
+	markdownCode     lipgloss.Style
+	markdownLink     lipgloss.Style
+	markdownHeading  lipgloss.Style
+
+	markdownInlineRe = regexp.MustCompile("`[^`]+`|\\*\\*[^*]+\\*\\*|__[^_]+__|\\*[^*\\s][^*]*\\*|!?\\[[^\\]]*\\]\\([^)\\s]+\\)")
+	markdownOrderRe  = regexp.MustCompile(`^\d+[.)] `)
+)
+
+func setInfoStyles(t theme) {
+	infoTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(t.accent)
+	infoLabelStyle = lipgloss.NewStyle().Bold(true).Foreground(t.blue)
+	infoWarningStyle = lipgloss.NewStyle().Bold(true).Foreground(t.red)
+	markdownBold = lipgloss.NewStyle().Bold(true)
+	markdownItalic = lipgloss.NewStyle().Italic(true)
+	markdownCode = lipgloss.NewStyle().Foreground(t.yellow)
+	markdownLink = lipgloss.NewStyle().Foreground(t.blue).Underline(true)
+	markdownHeading = lipgloss.NewStyle().Bold(true).Foreground(t.accent)
+}
+
+// formatInfoDetails renders the document metadata that doesn't belong to any
+// endpoint or component: info, servers, external docs, tags and extensions
+func formatInfoDetails(doc *v3.Document, width int, expandExtensions bool) string {
+	var details strings.Builder
+
+	info := doc.Info
+	if info == nil {
+		details.WriteString("No info section in this document\n")
+		return details.String()
+	}
+
+	title := info.Title
+	if title == "" {
+		title = "Untitled API"
+	}
+	details.WriteString(infoTitleStyle.Render(title) + "\n")
+
+	writeInfoField(&details, "Version", info.Version)
+	writeInfoField(&details, "OpenAPI", doc.Version)
+	writeInfoField(&details, "Summary", info.Summary)
+
+	if info.Description != "" {
+		details.WriteString("\n")
+		details.WriteString(renderMarkdown(info.Description, width))
+		details.WriteString("\n")
+	}
+
+	details.WriteString("\n")
+	writeInfoField(&details, "Terms of Service", info.TermsOfService)
+
+	if c := info.Contact; c != nil {
+		var parts []string
+		if c.Name != "" {
+			parts = append(parts, c.Name)
+		}
+		switch {
+		case c.Email != "" && c.Name != "":
+			parts = append(parts, "<"+c.Email+">")
+		case c.Email != "":
+			parts = append(parts, c.Email)
+		}
+		if c.URL != "" {
+			parts = append(parts, c.URL)
+		}
+		writeInfoField(&details, "Contact", strings.Join(parts, " "))
+	}
+
+	if l := info.License; l != nil {
+		license := l.Name
+		switch {
+		case l.Identifier != "":
+			license += " (" + l.Identifier + ")"
+		case l.URL != "":
+			license += " (" + l.URL + ")"
+		}
+		writeInfoField(&details, "License", license)
+	}
+
+	if docs := doc.ExternalDocs; docs != nil {
+		writeInfoField(&details, "External Docs", externalDocsText(docs))
+	}
+
+	if len(doc.Servers) > 0 {
+		details.WriteString("\n" + infoLabelStyle.Render("Servers:") + "\n")
+		for _, server := range doc.Servers {
+			line := "  - " + server.URL
+			if server.Description != "" {
+				line += " - " + server.Description
+			}
+			details.WriteString(line + "\n")
+			details.WriteString(formatExtensions(server.Extensions, "    ", expandExtensions))
+		}
+	}
+
+	if len(doc.Tags) > 0 {
+		details.WriteString("\n" + infoLabelStyle.Render("Tags:") + "\n")
+		for _, tag := range doc.Tags {
+			line := "  - " + tag.Name
+			if tag.Description != "" {
+				line += " - " + renderMarkdownInline(firstLine(tag.Description))
+			}
+			details.WriteString(line + "\n")
+			if tag.ExternalDocs != nil {
+				details.WriteString("    External Docs: " + externalDocsText(tag.ExternalDocs) + "\n")
+			}
+		}
+	}
+
+	if extensions := formatExtensions(mergeExtensions(info.Extensions, doc.Extensions), "", expandExtensions); extensions != "" {
+		label, rest, _ := strings.Cut(extensions, "\n")
+		details.WriteString("\n" + infoLabelStyle.Render(label) + "\n" + rest)
+	}
+
+	return details.String()
+}
+
+func writeInfoField(details *strings.Builder, label, value string) {
+	if value == "" {
+		return
+	}
+	details.WriteString(infoLabelStyle.Render(label+":") + " " + value + "\n")
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+		return s[:idx]
+	}
+	return s
+}
+
+// renderMarkdown renders the subset of Markdown commonly found in API
+// descriptions: headings, lists, code blocks, emphasis, inline code and links.
+// Paragraphs are wrapped to width, anything else is shown as written.
+func renderMarkdown(src string, width int) string {
+	var out []string
+	var paragraph []string
+	inCode := false
+
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		text := strings.Join(paragraph, " ")
+		out = append(out, wrapText(renderMarkdownInline(text), width))
+		paragraph = nil
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(src), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			flush()
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out = append(out, "  "+markdownCode.Render(line))
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flush()
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+
+		case strings.HasPrefix(trimmed, "#"):
+			flush()
+			heading := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			out = append(out, markdownHeading.Render(heading))
+
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "), strings.HasPrefix(trimmed, "+ "):
+			flush()
+			indent := strings.Repeat(" ", len(line)-len(strings.TrimLeft(line, " ")))
+			item := renderMarkdownInline(strings.TrimSpace(trimmed[2:]))
+			out = append(out, indent+icons.bullet+" "+item)
+
+		case markdownOrderRe.MatchString(trimmed):
+			flush()
+			indent := strings.Repeat(" ", len(line)-len(strings.TrimLeft(line, " ")))
+			out = append(out, indent+renderMarkdownInline(trimmed))
+
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			quote := strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
+			out = append(out, icons.quote+" "+markdownItalic.Render(renderMarkdownInline(quote)))
+
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	return strings.TrimRight(strings.Join(out, "\n"), "\n")
+}
+
+// renderMarkdownInline styles emphasis, inline code and links within a single line
+func renderMarkdownInline(text string) string {
+	return markdownInlineRe.ReplaceAllStringFunc(text, func(token string) string {
+		switch {
+		case strings.HasPrefix(token, "`"):
+			return markdownCode.Render(strings.Trim(token, "`"))
+		case strings.HasPrefix(token, "**"), strings.HasPrefix(token, "__"):
+			return markdownBold.Render(token[2 : len(token)-2])
+		case strings.HasPrefix(token, "*"):
+			return markdownItalic.Render(token[1 : len(token)-1])
+		}
+
+		// Links and images: [text](url)
+		token = strings.TrimPrefix(token, "!")
+		closing := strings.Index(token, "](")
+		label, url := token[1:closing], token[closing+2:len(token)-1]
+		if label == "" || label == url {
+			return markdownLink.Render(url)
+		}
+		return label + " (" + markdownLink.Render(url) + ")"
+	})
+}
+
+// wrapText wraps text at spaces so that no line is wider than width
+func wrapText(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+	lines := strings.Split(lipgloss.NewStyle().Width(width).Render(text), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
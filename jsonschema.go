@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// subschemaSummary sums up a schema applied by a keyword. Those often have no
+// type, like "if" checking a property or "then" requiring one, so what they
+// check is listed instead.
+func subschemaSummary(proxy *base.SchemaProxy) string {
+	if proxy == nil || proxy.IsReference() || proxy.Schema() == nil || len(proxy.Schema().Type) > 0 || proxy.Schema().Const != nil {
+		return schemaSummary(proxy)
+	}
+	s := proxy.Schema()
+	var parts []string
+	if s.Properties != nil && s.Properties.Len() > 0 {
+		var names []string
+		for pair := s.Properties.First(); pair != nil; pair = pair.Next() {
+			names = append(names, pair.Key())
+		}
+		parts = append(parts, "properties: "+strings.Join(names, ", "))
+	}
+	if len(s.Required) > 0 {
+		parts = append(parts, "required: "+strings.Join(s.Required, ", "))
+	}
+	parts = append(parts, schemaNotes(s)...)
+	if len(parts) == 0 {
+		return schemaSummary(proxy)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writeJSONSchemaKeywords lists the JSON Schema keywords OpenAPI 3.1 schemas can
+// use beyond those of 3.0: pattern properties, tuples, conditionals and
+// dependencies, with the schemas they apply summed up on one line each
+func writeJSONSchemaKeywords(details *strings.Builder, s *base.Schema, indent string) {
+	if s.PatternProperties != nil && s.PatternProperties.Len() > 0 {
+		details.WriteString(indent + "Pattern Properties:\n")
+		for pair := s.PatternProperties.First(); pair != nil; pair = pair.Next() {
+			details.WriteString(fmt.Sprintf("%s  - %s: %s\n", indent, pair.Key(), subschemaSummary(pair.Value())))
+		}
+	}
+	if s.PropertyNames != nil {
+		details.WriteString(fmt.Sprintf("%sProperty Names: %s\n", indent, subschemaSummary(s.PropertyNames)))
+	}
+	if u := s.UnevaluatedProperties; u != nil {
+		text := fmt.Sprint(u.B)
+		if u.IsA() {
+			text = subschemaSummary(u.A)
+		}
+		details.WriteString(fmt.Sprintf("%sUnevaluated Properties: %s\n", indent, text))
+	}
+
+	if len(s.PrefixItems) > 0 {
+		details.WriteString(indent + "Prefix Items:\n")
+		for i, item := range s.PrefixItems {
+			details.WriteString(fmt.Sprintf("%s  - %d: %s\n", indent, i, subschemaSummary(item)))
+		}
+	}
+	if s.Contains != nil {
+		text := subschemaSummary(s.Contains)
+		var bounds []string
+		if s.MinContains != nil {
+			bounds = append(bounds, fmt.Sprintf("minContains: %d", *s.MinContains))
+		}
+		if s.MaxContains != nil {
+			bounds = append(bounds, fmt.Sprintf("maxContains: %d", *s.MaxContains))
+		}
+		if len(bounds) > 0 {
+			text += " [" + strings.Join(bounds, ", ") + "]"
+		}
+		details.WriteString(fmt.Sprintf("%sContains: %s\n", indent, text))
+	}
+	if s.UnevaluatedItems != nil {
+		details.WriteString(fmt.Sprintf("%sUnevaluated Items: %s\n", indent, subschemaSummary(s.UnevaluatedItems)))
+	}
+
+	for _, keyword := range []struct {
+		label  string
+		schema *base.SchemaProxy
+	}{{"If", s.If}, {"Then", s.Then}, {"Else", s.Else}, {"Not", s.Not}} {
+		if keyword.schema != nil {
+			details.WriteString(fmt.Sprintf("%s%s: %s\n", indent, keyword.label, subschemaSummary(keyword.schema)))
+		}
+	}
+
+	if s.DependentRequired != nil && s.DependentRequired.Len() > 0 {
+		details.WriteString(indent + "Dependent Required:\n")
+		for pair := s.DependentRequired.First(); pair != nil; pair = pair.Next() {
+			details.WriteString(fmt.Sprintf("%s  - %s %s %s\n", indent, pair.Key(), icons.link, strings.Join(pair.Value(), ", ")))
+		}
+	}
+	if s.DependentSchemas != nil && s.DependentSchemas.Len() > 0 {
+		details.WriteString(indent + "Dependent Schemas:\n")
+		for pair := s.DependentSchemas.First(); pair != nil; pair = pair.Next() {
+			details.WriteString(fmt.Sprintf("%s  - %s %s %s\n", indent, pair.Key(), icons.link, subschemaSummary(pair.Value())))
+		}
+	}
+}
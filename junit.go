@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// formatJUnit prints the results of lint, validate and diff as a JUnit XML
+// report, which Jenkins, GitLab and other CI systems show as test results
+const formatJUnit = "junit"
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"` // Details of tests that passed anyway
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// writeJUnit writes the test cases as a single suite named after the command
+// and the spec it checked
+func writeJUnit(w io.Writer, name string, cases []junitTestCase) error {
+	suite := junitTestSuite{Name: name, Tests: len(cases), TestCases: cases}
+	for _, c := range cases {
+		if c.Failure != nil {
+			suite.Failures++
+		}
+		if c.Skipped != nil {
+			suite.Skipped++
+		}
+	}
+	report := junitTestSuites{Name: name, Tests: suite.Tests, Failures: suite.Failures, Suites: []junitTestSuite{suite}}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, data)
+	return err
+}
+
+// lintJUnit makes a test case of every rule turned on. Rules with error or
+// warning findings fail, info findings are only reported.
+func lintJUnit(rs ruleset, findings []finding) []junitTestCase {
+	var cases []junitTestCase
+	for _, rule := range lintRules {
+		if rs.severity(rule) == severityOff {
+			continue
+		}
+		c := junitTestCase{Name: rule.name, ClassName: "oq.lint"}
+
+		var lines []string
+		failed := false
+		for _, f := range findings {
+			if f.Rule != rule.name {
+				continue
+			}
+			line := fmt.Sprintf("%s: %s: %s", f.Location, f.Severity, f.Message)
+			if f.Line > 0 {
+				line += fmt.Sprintf(" (line %d)", f.Line)
+			}
+			lines = append(lines, line)
+			failed = failed || f.Severity != severityInfo
+		}
+		switch {
+		case failed:
+			c.Failure = &junitFailure{Message: rule.description + ": " + pluralize(len(lines), "problem"), Type: rs.severity(rule), Text: strings.Join(lines, "\n")}
+		case len(lines) > 0:
+			c.SystemOut = strings.Join(lines, "\n")
+		}
+		cases = append(cases, c)
+	}
+	return cases
+}
+
+// issuesJUnit makes a test case of every validation rule, failing with the
+// problems found by it
+func issuesJUnit(issues []issue) []junitTestCase {
+	var cases []junitTestCase
+	for _, rule := range validationRules {
+		c := junitTestCase{Name: rule.id, ClassName: "oq.validate"}
+		var lines []string
+		for _, is := range issues {
+			if is.Rule != rule.id {
+				continue
+			}
+			line := fmt.Sprintf("%s:%d:%d: %s (%s)", is.File, is.Line, is.Column, is.Message, is.Pointer)
+			if is.Fix != "" {
+				line += "\n  fix: " + is.Fix
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) > 0 {
+			c.Failure = &junitFailure{Message: rule.description + ": " + pluralize(len(lines), "problem"), Type: "error", Text: strings.Join(lines, "\n")}
+		}
+		cases = append(cases, c)
+	}
+	return cases
+}
+
+// changedItem returns the endpoint or schema a change is about, e.g. "GET /pets"
+// for "GET /pets response 200" and "schema Pet" for "schema Pet.name"
+func changedItem(location string) string {
+	fields := strings.Fields(location)
+	if len(fields) < 2 {
+		return location
+	}
+	item := fields[0] + " " + fields[1]
+	if fields[0] == "schema" {
+		if i := strings.IndexAny(fields[1], ".["); i > 0 {
+			item = fields[0] + " " + fields[1][:i]
+		}
+	}
+	return strings.TrimSuffix(item, ":")
+}
+
+// changesJUnit makes a test case of every changed endpoint and schema, failing
+// when one of its changes is breaking
+func changesJUnit(changes []change) []junitTestCase {
+	var items []string
+	byItem := map[string][]change{}
+	for _, c := range changes {
+		item := changedItem(c.Location)
+		if _, ok := byItem[item]; !ok {
+			items = append(items, item)
+		}
+		byItem[item] = append(byItem[item], c)
+	}
+
+	var cases []junitTestCase
+	for _, item := range items {
+		c := junitTestCase{Name: item, ClassName: "oq.diff"}
+		var lines []string
+		breaking := 0
+		for _, ch := range byItem[item] {
+			line := ch.Location + ": " + ch.Message
+			if ch.Breaking {
+				breaking++
+				line += " (breaking)"
+			}
+			lines = append(lines, line)
+		}
+		if breaking > 0 {
+			c.Failure = &junitFailure{Message: pluralize(breaking, "breaking change"), Type: "breaking", Text: strings.Join(lines, "\n")}
+		} else {
+			c.SystemOut = strings.Join(lines, "\n")
+		}
+		cases = append(cases, c)
+	}
+	return cases
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// action is a named command that keys are bound to. The names are used as-is
+// in the "keys" section of the config file.
+type action string
+
+const (
+	actionQuit          action = "quit"
+	actionHelp          action = "help"
+	actionClose         action = "close"
+	actionUp            action = "up"
+	actionDown          action = "down"
+	actionStepUp        action = "step_up"
+	actionStepDown      action = "step_down"
+	actionPrevGroup     action = "prev_group"
+	actionNextGroup     action = "next_group"
+	actionCenter        action = "center"
+	actionTop           action = "top"
+	actionBottom        action = "bottom"
+	actionHalfPageUp    action = "half_page_up"
+	actionHalfPageDown  action = "half_page_down"
+	actionPageUp        action = "page_up"
+	actionPageDown      action = "page_down"
+	actionScrollLeft    action = "scroll_left"
+	actionScrollRight   action = "scroll_right"
+	actionNextView      action = "next_view"
+	actionPrevView      action = "prev_view"
+	actionToggle        action = "toggle"
+	actionExpandAll     action = "expand_all"
+	actionCollapseAll   action = "collapse_all"
+	actionDeeper        action = "deeper"
+	actionShallower     action = "shallower"
+	actionExamples      action = "examples"
+	actionOpenExample   action = "open_example"
+	actionExtensions    action = "extensions"
+	actionRawSource     action = "raw"
+	actionDeprecated    action = "deprecated_only"
+	actionGoToReference action = "definition"
+	actionWhereUsed     action = "where_used"
+	actionProblems      action = "problems"
+	actionOpenDocs      action = "open_docs"
+	actionEdit          action = "edit"
+	actionPager         action = "pager"
+	actionBack          action = "back"
+	actionForward       action = "forward"
+	actionYankPath      action = "yank_path"
+	actionYankID        action = "yank_operation_id"
+	actionYankPointer   action = "yank_pointer"
+	actionCurl          action = "curl"
+	actionSnippet       action = "snippet"
+	actionTry           action = "try"
+	actionEnvironment   action = "environment"
+	actionExport        action = "export"
+	actionSearch        action = "search"
+	actionCommand       action = "command"
+	actionSpecs         action = "specs"
+)
+
+// keyAction describes an action with its default keys, in the order shown in the help.
+// Key sequences are written space separated, e.g. "g g" or "z R".
+type keyAction struct {
+	action      action
+	description string
+	keys        []string
+}
+
+// keySection groups related actions under a title in the help
+type keySection struct {
+	title   string
+	actions []keyAction
+}
+
+var keySections = []keySection{
+	{"Move", []keyAction{
+		{actionUp, "Move up", []string{"up", "k"}},
+		{actionDown, "Move down", []string{"down", "j"}},
+		{actionStepUp, "Move up 10 items", []string{"K"}},
+		{actionStepDown, "Move down 10 items", []string{"J"}},
+		{actionPrevGroup, "Move to the previous path or component type", []string{"{"}},
+		{actionNextGroup, "Move to the next path or component type", []string{"}"}},
+		{actionTop, "Move to the top, or the Nth item with a count", []string{"g g", "home"}},
+		{actionBottom, "Move to the bottom, or the Nth item with a count", []string{"G", "end"}},
+		{actionCenter, "Center the selected item", []string{"z z"}},
+		{actionHalfPageUp, "Scroll up by half a screen", []string{"ctrl+u"}},
+		{actionHalfPageDown, "Scroll down by half a screen", []string{"ctrl+d"}},
+		{actionPageUp, "Scroll up by a screen", []string{"pgup"}},
+		{actionPageDown, "Scroll down by a screen", []string{"pgdown"}},
+		{actionScrollLeft, "Scroll left", []string{"left", "z h"}},
+		{actionScrollRight, "Scroll right", []string{"right", "z l"}},
+		{actionNextView, "Cycle forward through views", []string{"tab", "L"}},
+		{actionPrevView, "Cycle backward through views", []string{"shift+tab", "H"}},
+		{actionBack, "Go back to previous location", []string{"ctrl+o"}},
+		// Ctrl+I is indistinguishable from Tab in terminals, so Ctrl+N moves forward
+		{actionForward, "Go forward to next location", []string{"ctrl+n"}},
+	}},
+	{"Find", []keyAction{
+		{actionSearch, "Search paths, operation IDs, summaries and schemas", []string{"/"}},
+		{actionCommand, "Jump with a command, e.g. :op listPets or :schema Pet", []string{":"}},
+		{actionSpecs, "Switch to another spec of the workspace", []string{"S"}},
+		{actionGoToReference, "Go to referenced component or link", []string{"g d"}},
+		{actionWhereUsed, "List operations using a component", []string{"g r"}},
+		{actionProblems, "List lint problems", []string{"P"}},
+		{actionDeprecated, "Show only deprecated items", []string{"D"}},
+	}},
+	{"Details", []keyAction{
+		{actionToggle, "Toggle details", []string{"enter", " "}},
+		{actionExpandAll, "Expand all items", []string{"E", "z R"}},
+		{actionCollapseAll, "Collapse all items", []string{"C", "z M"}},
+		{actionDeeper, "Show more nested schema levels", []string{"+", "="}},
+		{actionShallower, "Show less nested schema levels", []string{"-"}},
+		{actionExamples, "Expand/truncate examples", []string{"x"}},
+		{actionOpenExample, "Open a named example", []string{"v"}},
+		{actionExtensions, "Expand/collapse extensions", []string{"z e"}},
+		{actionRawSource, "Toggle raw source view", []string{"r"}},
+	}},
+	{"Open and copy", []keyAction{
+		{actionOpenDocs, "Open external docs in browser", []string{"o"}},
+		{actionEdit, "Open the spec in $EDITOR at the selected item", []string{"g e"}},
+		{actionPager, "Show the details in $PAGER", []string{"|"}},
+		{actionYankPath, "Copy path or name", []string{"y p"}},
+		{actionYankID, "Copy operationId", []string{"y i"}},
+		{actionYankPointer, "Copy JSON pointer", []string{"y r"}},
+		{actionCurl, "Copy endpoint as curl command", []string{"c"}},
+		{actionSnippet, "Copy endpoint as code snippet", []string{"s"}},
+		{actionExport, "Export to Postman or Insomnia", []string{"X"}},
+	}},
+	{"Requests", []keyAction{
+		{actionTry, "Send request and check the response", []string{"t"}},
+		{actionEnvironment, "Switch environment", []string{"e"}},
+	}},
+	{"General", []keyAction{
+		{actionHelp, "Toggle help", []string{"?"}},
+		{actionClose, "Close help or dialog", []string{"esc"}},
+		{actionQuit, "Quit", []string{"q", "ctrl+c"}},
+	}},
+}
+
+// defaultKeyActions are the actions of every section, in order
+var defaultKeyActions = func() []keyAction {
+	var actions []keyAction
+	for _, section := range keySections {
+		actions = append(actions, section.actions...)
+	}
+	return actions
+}()
+
+// keyMap resolves key sequences to actions
+type keyMap struct {
+	bindings map[action][]string
+	actions  map[string]action
+	prefixes map[string]bool
+}
+
+func defaultKeyMap() keyMap {
+	bindings := make(map[action][]string)
+	for _, ka := range defaultKeyActions {
+		bindings[ka.action] = ka.keys
+	}
+	return newKeyMap(bindings)
+}
+
+func newKeyMap(bindings map[action][]string) keyMap {
+	km := keyMap{
+		bindings: bindings,
+		actions:  make(map[string]action),
+		prefixes: make(map[string]bool),
+	}
+
+	for act, keys := range bindings {
+		for _, key := range keys {
+			km.actions[key] = act
+			if first, _, ok := strings.Cut(key, " "); ok {
+				km.prefixes[first] = true
+			}
+		}
+	}
+
+	return km
+}
+
+// withOverrides returns a keymap where the given actions use the given keys instead
+// of the defaults. Keys taken over from another action are removed from it, so that
+// e.g. binding ctrl+n to "down" doesn't require rebinding "forward" as well.
+func (km keyMap) withOverrides(overrides map[string][]string) (keyMap, error) {
+	bindings := make(map[action][]string)
+	for act, keys := range km.bindings {
+		bindings[act] = keys
+	}
+
+	claimed := make(map[string]action)
+	for name, keys := range overrides {
+		act := action(name)
+		if _, ok := km.bindings[act]; !ok {
+			return keyMap{}, fmt.Errorf("unknown action %q", name)
+		}
+
+		var normalized []string
+		for _, key := range keys {
+			key = normalizeKey(key)
+			if key == "" {
+				return keyMap{}, fmt.Errorf("empty key for action %q", name)
+			}
+			if other, ok := claimed[key]; ok {
+				return keyMap{}, fmt.Errorf("key %q is bound to both %q and %q", key, other, act)
+			}
+			claimed[key] = act
+			normalized = append(normalized, key)
+		}
+		bindings[act] = normalized
+	}
+
+	for act, keys := range bindings {
+		if _, overridden := overrides[string(act)]; overridden {
+			continue
+		}
+		var kept []string
+		for _, key := range keys {
+			if _, ok := claimed[key]; !ok {
+				kept = append(kept, key)
+			}
+		}
+		bindings[act] = kept
+	}
+
+	return newKeyMap(bindings), nil
+}
+
+// normalizeKey converts a key from the config file into the form bubbletea reports,
+// e.g. "Ctrl+N" to "ctrl+n", "space" to " " and "g  g" to "g g"
+func normalizeKey(key string) string {
+	var parts []string
+	for _, part := range strings.Fields(key) {
+		switch lower := strings.ToLower(part); {
+		case lower == "space":
+			part = " "
+		case len([]rune(part)) > 1:
+			// Named keys and modifiers are lowercase, single characters keep their case
+			part = lower
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 && strings.Contains(key, " ") {
+		return " "
+	}
+	return strings.Join(parts, " ")
+}
+
+// keyLabels names the keys whose bubbletea names don't read well in the help
+var keyLabels = map[string]string{
+	" ":         "Space",
+	"enter":     "Enter",
+	"esc":       "Esc",
+	"tab":       "Tab",
+	"shift+tab": "Shift+Tab",
+	"pgup":      "PgUp",
+	"pgdown":    "PgDn",
+	"home":      "Home",
+	"end":       "End",
+}
+
+// formatKey renders a key sequence for display, e.g. "g g" as "gg" and "ctrl+u" as "Ctrl-U"
+func formatKey(key string) string {
+	if key == " " {
+		return keyLabels[key]
+	}
+
+	arrows := map[string]string{
+		"up":    icons.keyUp,
+		"down":  icons.keyDown,
+		"left":  icons.keyLeft,
+		"right": icons.keyRight,
+	}
+
+	var b strings.Builder
+	for _, part := range strings.Split(key, " ") {
+		if label, ok := arrows[part]; ok {
+			b.WriteString(label)
+			continue
+		}
+		if label, ok := keyLabels[part]; ok {
+			b.WriteString(label)
+			continue
+		}
+		if rest, ok := strings.CutPrefix(part, "ctrl+"); ok {
+			b.WriteString("Ctrl-" + strings.Map(unicode.ToUpper, rest))
+			continue
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// keysFor returns the display form of the keys bound to an action, e.g. "↑/k"
+func (km keyMap) keysFor(act action) string {
+	var labels []string
+	for _, key := range km.bindings[act] {
+		labels = append(labels, formatKey(key))
+	}
+	return strings.Join(labels, "/")
+}
+
+// resolve maps a key press to an action, taking a pending key sequence into account.
+// It returns the pending prefix to remember when the key starts a sequence.
+func (km keyMap) resolve(pending, key string) (action, string) {
+	if pending != "" {
+		if act, ok := km.actions[pending+" "+key]; ok {
+			return act, ""
+		}
+	}
+
+	if km.prefixes[key] {
+		return "", key
+	}
+
+	return km.actions[key], ""
+}
@@ -0,0 +1,524 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
+)
+
+// Severities of lint findings. Rules set to severityOff are not run.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+	severityInfo    = "info"
+	severityOff     = "off"
+)
+
+// defaultRulesetFile is read from the current directory when no ruleset is given
+const defaultRulesetFile = ".oq-lint.yaml"
+
+// finding is a problem reported by a lint rule
+type finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Location string `json:"location"` // e.g. "GET /pets" or "Schema Pet"
+	Pointer  string `json:"pointer"`  // JSON pointer of the item, used to jump to it
+	Message  string `json:"message"`
+	Line     int    `json:"line,omitempty"` // Where the item is declared, when linting a file
+	Column   int    `json:"column,omitempty"`
+}
+
+// lintRule checks the spec for one kind of problem
+type lintRule struct {
+	name        string
+	description string
+	severity    string // Used unless the ruleset overrides it
+	check       func(m *Model) []finding
+}
+
+// lintRules are the built-in rules, in the order their findings are listed
+var lintRules = []lintRule{
+	{"operation-operationid", "Operations have an operationId", severityWarning, lintOperationID},
+	{"operation-description", "Operations have a summary or description", severityWarning, lintOperationDescription},
+	{"operation-4xx-response", "Operations document at least one 4xx response", severityWarning, lintClientErrorResponse},
+	{"schema-description", "Schemas have a description", severityInfo, lintSchemaDescription},
+	{"naming-consistency", "operationIds and property names use one casing style", severityWarning, lintNaming},
+	{"unused-component", "Components are used by an operation", severityWarning, lintUnusedComponents},
+	{"schema-pattern", "Schema patterns are RE2 regular expressions, checkable by Go tools", severityWarning, lintSchemaPatterns},
+	{"example-valid", "Examples match their schema", severityWarning, lintExamples},
+	{"discriminator", "Discriminator mappings name existing schemas that have the discriminator property", severityWarning, lintDiscriminators},
+}
+
+// ruleset overrides the severity of lint rules, e.g.
+//
+//	rules:
+//	  operation-4xx-response: error
+//	  schema-description: off
+type ruleset struct {
+	Rules map[string]string `yaml:"rules"`
+}
+
+// loadRuleset reads a ruleset file. A missing file is only an error when the
+// path was given explicitly rather than being the default.
+func loadRuleset(path string, explicit bool) (ruleset, error) {
+	var rs ruleset
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return rs, nil
+	}
+	if err != nil {
+		return rs, err
+	}
+
+	if err := yaml.Unmarshal(data, &rs); err != nil {
+		return rs, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for name, severity := range rs.Rules {
+		if _, ok := findLintRule(name); !ok {
+			return rs, fmt.Errorf("%s: unknown rule %q", path, name)
+		}
+		switch severity {
+		case severityError, severityWarning, severityInfo, severityOff:
+		default:
+			return rs, fmt.Errorf("%s: unknown severity %q for %s, use error, warning, info or off", path, severity, name)
+		}
+	}
+
+	return rs, nil
+}
+
+func findLintRule(name string) (lintRule, bool) {
+	for _, rule := range lintRules {
+		if rule.name == name {
+			return rule, true
+		}
+	}
+	return lintRule{}, false
+}
+
+func (rs ruleset) severity(rule lintRule) string {
+	if severity, ok := rs.Rules[rule.name]; ok {
+		return severity
+	}
+	return rule.severity
+}
+
+// lint runs the enabled rules on every item, including those hidden by filters
+func (m *Model) lint() []finding {
+	var findings []finding
+	for _, rule := range lintRules {
+		severity := m.ruleset.severity(rule)
+		if severity == severityOff {
+			continue
+		}
+		for _, f := range rule.check(m) {
+			f.Rule = rule.name
+			f.Severity = severity
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// lintOperation is an endpoint or webhook operation checked by the rules
+type lintOperation struct {
+	location string
+	pointer  string
+	ep       endpoint
+}
+
+// lintOperations returns the endpoints and webhooks, webhooks as endpoints
+// without a path
+func (m *Model) lintOperations() []lintOperation {
+	var ops []lintOperation
+	all := m.allItems()
+	for _, ep := range all.endpoints {
+		ops = append(ops, lintOperation{ep.method + " " + ep.path, operationRef(ep.path, ep.method), ep})
+	}
+	for _, hook := range all.webhooks {
+		ep := endpoint{method: hook.method, op: hook.op, source: hook.source}
+		ops = append(ops, lintOperation{hook.method + " " + hook.name + " (webhook)", webhookRef(hook.name, hook.method), ep})
+	}
+	return ops
+}
+
+func lintOperationID(m *Model) []finding {
+	var findings []finding
+	for _, op := range m.lintOperations() {
+		if op.ep.op.OperationId == "" {
+			findings = append(findings, finding{Location: op.location, Pointer: op.pointer, Message: "operation has no operationId"})
+		}
+	}
+	return findings
+}
+
+func lintOperationDescription(m *Model) []finding {
+	var findings []finding
+	for _, op := range m.lintOperations() {
+		if strings.TrimSpace(op.ep.op.Summary) == "" && strings.TrimSpace(op.ep.op.Description) == "" {
+			findings = append(findings, finding{Location: op.location, Pointer: op.pointer, Message: "operation has no summary or description"})
+		}
+	}
+	return findings
+}
+
+func lintClientErrorResponse(m *Model) []finding {
+	var findings []finding
+	for _, op := range m.lintOperations() {
+		responses := op.ep.op.Responses
+		found := false
+		if responses != nil && responses.Codes != nil {
+			for pair := responses.Codes.First(); pair != nil; pair = pair.Next() {
+				if strings.HasPrefix(strings.ToUpper(pair.Key()), "4") {
+					found = true
+					break
+				}
+			}
+		}
+		// A default response covers errors as well
+		if !found && (responses == nil || responses.Default == nil) {
+			findings = append(findings, finding{Location: op.location, Pointer: op.pointer, Message: "operation documents no 4xx response"})
+		}
+	}
+	return findings
+}
+
+func lintSchemaDescription(m *Model) []finding {
+	var findings []finding
+	for _, comp := range m.allItems().components {
+		if comp.compType == "Schema" && strings.TrimSpace(comp.description) == "" {
+			findings = append(findings, finding{
+				Location: "Schema " + comp.name,
+				Pointer:  componentRef(comp.compType, comp.name),
+				Message:  "schema has no description",
+			})
+		}
+	}
+	return findings
+}
+
+// Casing styles of names, as told by namingStyle
+const (
+	styleCamel  = "camelCase"
+	stylePascal = "PascalCase"
+	styleSnake  = "snake_case"
+	styleKebab  = "kebab-case"
+)
+
+// namingStyle tells the casing style of a name, or "" when it fits several, like
+// "pets", or none
+func namingStyle(name string) string {
+	hasUpper := strings.IndexFunc(name, unicode.IsUpper) >= 0
+	hasLower := strings.IndexFunc(name, unicode.IsLower) >= 0
+	underscore := strings.Contains(name, "_")
+	hyphen := strings.Contains(name, "-")
+
+	switch {
+	case name == "":
+		return ""
+	case underscore && !hyphen && !hasUpper:
+		return styleSnake
+	case hyphen && !underscore && !hasUpper:
+		return styleKebab
+	case underscore || hyphen || !hasLower:
+		return ""
+	case unicode.IsUpper([]rune(name)[0]):
+		return stylePascal
+	case hasUpper:
+		return styleCamel
+	}
+	return ""
+}
+
+// namedItem is a name checked for consistency with its siblings
+type namedItem struct {
+	name     string
+	location string
+	pointer  string
+}
+
+// inconsistentNames reports the names whose style differs from the most common
+// one. Ties are broken alphabetically, so the output is stable.
+func inconsistentNames(kind string, names []namedItem) []finding {
+	counts := map[string]int{}
+	for _, n := range names {
+		if style := namingStyle(n.name); style != "" {
+			counts[style]++
+		}
+	}
+	if len(counts) < 2 {
+		return nil
+	}
+
+	styles := make([]string, 0, len(counts))
+	for style := range counts {
+		styles = append(styles, style)
+	}
+	sort.Slice(styles, func(i, j int) bool {
+		if counts[styles[i]] != counts[styles[j]] {
+			return counts[styles[i]] > counts[styles[j]]
+		}
+		return styles[i] < styles[j]
+	})
+	common := styles[0]
+
+	var findings []finding
+	for _, n := range names {
+		if style := namingStyle(n.name); style != "" && style != common {
+			findings = append(findings, finding{
+				Location: n.location,
+				Pointer:  n.pointer,
+				Message:  fmt.Sprintf("%s %q is %s while most are %s", kind, n.name, style, common),
+			})
+		}
+	}
+	return findings
+}
+
+func lintNaming(m *Model) []finding {
+	var operationIDs []namedItem
+	for _, op := range m.lintOperations() {
+		if id := op.ep.op.OperationId; id != "" {
+			operationIDs = append(operationIDs, namedItem{id, op.location, op.pointer})
+		}
+	}
+	findings := inconsistentNames("operationId", operationIDs)
+
+	var properties []namedItem
+	for _, comp := range m.allItems().components {
+		proxy, ok := comp.source.(*base.SchemaProxy)
+		if !ok || comp.compType != "Schema" || proxy.Schema() == nil || proxy.Schema().Properties == nil {
+			continue
+		}
+		for pair := proxy.Schema().Properties.First(); pair != nil; pair = pair.Next() {
+			properties = append(properties, namedItem{pair.Key(), "Schema " + comp.name + "." + pair.Key(), componentRef(comp.compType, comp.name)})
+		}
+	}
+	return append(findings, inconsistentNames("property", properties)...)
+}
+
+func lintUnusedComponents(m *Model) []finding {
+	var findings []finding
+	for _, comp := range m.allItems().components {
+		if len(m.componentUsages(comp)) == 0 {
+			findings = append(findings, finding{
+				Location: comp.compType + " " + comp.name,
+				Pointer:  componentRef(comp.compType, comp.name),
+				Message:  "component is not used by any operation",
+			})
+		}
+	}
+	return findings
+}
+
+// lintSchemaPatterns reports the patterns Go's RE2 engine can't compile, like
+// those with look-arounds or backreferences. oq shows them as written, but
+// can't check values against them.
+func lintSchemaPatterns(m *Model) []finding {
+	if m.doc == nil || m.doc.Index == nil {
+		return nil
+	}
+	root := m.doc.Index.GetRootNode()
+	if root != nil && root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+
+	var findings []finding
+	check := func(pattern, pointer string) {
+		_, err := regexp.Compile(pattern)
+		var syntaxErr *syntax.Error
+		if !errors.As(err, &syntaxErr) {
+			return
+		}
+		findings = append(findings, finding{
+			Location: pointer,
+			Pointer:  pointer,
+			Message:  fmt.Sprintf("pattern %q is not RE2 syntax (%s: `%s`)", pattern, syntaxErr.Code, syntaxErr.Expr),
+		})
+	}
+	walkSchemas(root, func(schema *yaml.Node, pointer string) {
+		if _, pattern := field(schema, "pattern"); pattern != nil && pattern.Kind == yaml.ScalarNode {
+			check(pattern.Value, pointerTo(pointer, "pattern"))
+		}
+		_, props := field(schema, "patternProperties")
+		eachField(props, func(key, _ *yaml.Node) {
+			check(key.Value, pointerTo(pointerTo(pointer, "patternProperties"), key.Value))
+		})
+	})
+	return findings
+}
+
+// severityCounts summarizes findings, e.g. "2 errors, 1 warning, 3 info"
+func severityCounts(findings []finding) string {
+	counts := map[string]int{}
+	for _, f := range findings {
+		counts[f.Severity]++
+	}
+	var parts []string
+	for _, severity := range []string{severityError, severityWarning, severityInfo} {
+		switch n := counts[severity]; {
+		case n == 0:
+		case severity == severityInfo:
+			parts = append(parts, fmt.Sprintf("%d info", n))
+		default:
+			parts = append(parts, pluralize(n, severity))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// locateFindings sets the line and column of the findings from their pointers
+func locateFindings(doc *yaml.Node, findings []finding) {
+	for i := range findings {
+		findings[i].Line, findings[i].Column = pointerPosition(doc, findings[i].Pointer)
+	}
+}
+
+func writeFindingsText(w io.Writer, findings []finding) error {
+	if len(findings) == 0 {
+		_, err := fmt.Fprintln(w, "No problems found")
+		return err
+	}
+	for _, f := range findings {
+		fmt.Fprintf(w, "%s: %s: %s (%s)\n", f.Location, f.Severity, f.Message, f.Rule)
+	}
+	_, err := fmt.Fprintf(w, "\n%s (%s)\n", pluralize(len(findings), "problem"), severityCounts(findings))
+	return err
+}
+
+// runLint implements "oq lint". It exits with status 1 when a rule set to error
+// reports a finding.
+func runLint(fs *flag.FlagSet, args []string) error {
+	rulesetPath := fs.String("ruleset", defaultRulesetFile, "file setting the severity of rules: error, warning, info or off")
+	format := fs.String("format", defaultOutput, "output format: text, json, sarif or junit")
+	fs.StringVar(format, "output", defaultOutput, "same as -format")
+	listRules := fs.Bool("rules", false, "list the rules and their default severity")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *listRules {
+		for _, rule := range lintRules {
+			fmt.Printf("%-24s %-8s %s\n", rule.name, rule.severity, rule.description)
+		}
+		return nil
+	}
+
+	path, err := specArg(fs)
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case outputText, outputJSON, formatSARIF, formatJUnit:
+	default:
+		return fmt.Errorf("unknown output format %q, use %s, %s, %s or %s", *format, outputText, outputJSON, formatSARIF, formatJUnit)
+	}
+
+	explicit := false
+	fs.Visit(func(f *flag.Flag) {
+		explicit = explicit || f.Name == "ruleset"
+	})
+	rs, err := loadRuleset(*rulesetPath, explicit)
+	if err != nil {
+		return fmt.Errorf("reading ruleset: %w", err)
+	}
+
+	content, err := readSpec(path)
+	if err != nil {
+		return err
+	}
+	doc, err := parseSpec(content)
+	if err != nil {
+		return err
+	}
+
+	m := NewModel(doc)
+	m.ruleset = rs
+	findings := m.lint()
+	if root, err := parseSpecTree(content); err == nil {
+		locateFindings(root.Content[0], findings)
+	}
+
+	file := path
+	if file == "" {
+		file = "<stdin>"
+	}
+	switch *format {
+	case formatSARIF:
+		if err := writeSARIF(os.Stdout, lintSARIFRules(rs), findingsSARIF(file, findings)); err != nil {
+			return err
+		}
+	case formatJUnit:
+		if err := writeJUnit(os.Stdout, "oq lint "+file, lintJUnit(rs, findings)); err != nil {
+			return err
+		}
+	case outputJSON:
+		if findings == nil {
+			findings = []finding{}
+		}
+		data, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := writeResult("", append(data, '\n')); err != nil {
+			return err
+		}
+	default:
+		if err := writeFindingsText(os.Stdout, findings); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range findings {
+		if f.Severity == severityError {
+			return errFindings
+		}
+	}
+	return nil
+}
+
+// itemPointer shortens a pointer into an operation, webhook or component, like
+// "#/components/schemas/Pet/properties/name", to the item itself
+func itemPointer(pointer string) string {
+	tokens := strings.SplitN(pointer, "/", 5)
+	if len(tokens) < 5 {
+		return pointer
+	}
+	return strings.Join(tokens[:4], "/")
+}
+
+// showProblems lists the problems met loading the spec and the lint findings,
+// to jump to the item of one of them
+func (m *Model) showProblems() {
+	findings := append(slices.Clone(m.loadProblems), m.lint()...)
+	if len(findings) == 0 {
+		m.message = "No problems found"
+		return
+	}
+
+	var items []pickerItem
+	for _, f := range findings {
+		items = append(items, pickerItem{label: fmt.Sprintf("%s %s: %s", f.Severity, f.Location, f.Message), value: itemPointer(f.Pointer)})
+	}
+
+	m.picker = &picker{
+		title:  fmt.Sprintf("%s (%s)", pluralize(len(findings), "problem"), severityCounts(findings)),
+		items:  items,
+		action: pickerGoToReference,
+	}
+}
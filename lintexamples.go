@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"go.yaml.in/yaml/v4"
+)
+
+// exampleChecker collects the examples that don't match their schema. Each is
+// checked once, as components are met again through the operations using them.
+type exampleChecker struct {
+	findings []finding
+	seen     map[*yaml.Node]bool
+}
+
+// lintExamples reports the examples of schemas, parameters, headers and media
+// types that don't validate against their schema. Components come first, so a
+// referenced example is reported where it is written.
+func lintExamples(m *Model) []finding {
+	c := &exampleChecker{seen: map[*yaml.Node]bool{}}
+	for _, comp := range m.allItems().components {
+		location, pointer := comp.compType+" "+comp.name, componentRef(comp.compType, comp.name)
+		switch source := comp.source.(type) {
+		case *base.SchemaProxy:
+			c.schema(location, pointer, source, 0)
+		case *v3.Parameter:
+			c.parameter(location, pointer, source)
+		case *v3.Header:
+			c.header(location, pointer, source)
+		case *v3.RequestBody:
+			if source != nil {
+				c.content(location, pointer, source.Content)
+			}
+		case *v3.Response:
+			c.response(location, pointer, source)
+		}
+	}
+
+	// The parameters shared by the operations of a path are reported where
+	// written, those of the operations by their index in the operation
+	if m.doc.Paths != nil && m.doc.Paths.PathItems != nil {
+		for pair := m.doc.Paths.PathItems.First(); pair != nil; pair = pair.Next() {
+			if pair.Value() != nil {
+				c.parameters(pair.Key(), pointerTo("#/paths", pair.Key()), pair.Value().Parameters)
+			}
+		}
+	}
+	if m.doc.Webhooks != nil {
+		for pair := m.doc.Webhooks.First(); pair != nil; pair = pair.Next() {
+			if pair.Value() != nil {
+				c.parameters(pair.Key()+" (webhook)", pointerTo("#/webhooks", pair.Key()), pair.Value().Parameters)
+			}
+		}
+	}
+
+	for _, op := range m.lintOperations() {
+		if op.ep.source != nil {
+			c.parameters(op.location, op.pointer, op.ep.source.Parameters)
+		}
+		if op.ep.op.RequestBody != nil {
+			c.content(op.location, pointerTo(op.pointer, "requestBody"), op.ep.op.RequestBody.Content)
+		}
+		if responses := op.ep.op.Responses; responses != nil {
+			if responses.Codes != nil {
+				for pair := responses.Codes.First(); pair != nil; pair = pair.Next() {
+					c.response(op.location, pointerTo(pointerTo(op.pointer, "responses"), pair.Key()), pair.Value())
+				}
+			}
+			c.response(op.location, pointerTo(pointerTo(op.pointer, "responses"), "default"), responses.Default)
+		}
+	}
+	return c.findings
+}
+
+// parameters checks the examples of the parameters of a path item or an
+// operation, pointed to by their index
+func (c *exampleChecker) parameters(location, pointer string, params []*v3.Parameter) {
+	for i, param := range params {
+		c.parameter(location, pointerTo(pointerTo(pointer, "parameters"), strconv.Itoa(i)), param)
+	}
+}
+
+// check validates one example, reporting the first mismatch
+func (c *exampleChecker) check(location, pointer, name string, schema *base.SchemaProxy, example *yaml.Node) {
+	if schema == nil || example == nil || c.seen[example] {
+		return
+	}
+	c.seen[example] = true
+
+	problems := validateValue(schema, example)
+	if len(problems) == 0 {
+		return
+	}
+	label := "example"
+	if name != "" {
+		label = fmt.Sprintf("example %q", name)
+	}
+	message := fmt.Sprintf("%s doesn't match its schema: %s", label, problems[0])
+	if len(problems) > 1 {
+		message += fmt.Sprintf(" (and %d more)", len(problems)-1)
+	}
+	c.findings = append(c.findings, finding{Location: location, Pointer: pointer, Message: message})
+}
+
+// examples checks the example and the named examples of a parameter, header
+// or media type
+func (c *exampleChecker) examples(location, pointer string, schema *base.SchemaProxy, example *yaml.Node, examples *orderedmap.Map[string, *base.Example]) {
+	c.check(location, pointerTo(pointer, "example"), "", schema, example)
+	if examples == nil {
+		return
+	}
+	for pair := examples.First(); pair != nil; pair = pair.Next() {
+		c.check(location, pointerTo(pointerTo(pointerTo(pointer, "examples"), pair.Key()), "value"), pair.Key(), schema, exampleValue(pair.Value()))
+	}
+}
+
+// schema checks the examples of a schema and of its inline properties and
+// items. Referenced schemas are checked as components.
+func (c *exampleChecker) schema(location, pointer string, proxy *base.SchemaProxy, depth int) {
+	if proxy == nil || depth > maxValidationDepth {
+		return
+	}
+	s := proxy.Schema()
+	if s == nil {
+		return
+	}
+
+	c.check(location, pointerTo(pointer, "example"), "", proxy, s.Example)
+	for i, example := range s.Examples {
+		c.check(location, pointerTo(pointerTo(pointer, "examples"), strconv.Itoa(i)), "", proxy, example)
+	}
+	if s.Properties != nil {
+		for pair := s.Properties.First(); pair != nil; pair = pair.Next() {
+			if !pair.Value().IsReference() {
+				c.schema(location+"."+pair.Key(), pointerTo(pointerTo(pointer, "properties"), pair.Key()), pair.Value(), depth+1)
+			}
+		}
+	}
+	if s.Items != nil && s.Items.IsA() && !s.Items.A.IsReference() {
+		c.schema(location+"[]", pointerTo(pointer, "items"), s.Items.A, depth+1)
+	}
+}
+
+// inlineSchema checks the examples of a schema written in place
+func (c *exampleChecker) inlineSchema(location, pointer string, proxy *base.SchemaProxy) {
+	if proxy != nil && !proxy.IsReference() {
+		c.schema(location, pointerTo(pointer, "schema"), proxy, 0)
+	}
+}
+
+func (c *exampleChecker) parameter(location, pointer string, param *v3.Parameter) {
+	if param == nil {
+		return
+	}
+	c.examples(location, pointer, param.Schema, param.Example, param.Examples)
+	c.inlineSchema(location, pointer, param.Schema)
+	c.content(location, pointer, param.Content)
+}
+
+func (c *exampleChecker) header(location, pointer string, header *v3.Header) {
+	if header == nil {
+		return
+	}
+	c.examples(location, pointer, header.Schema, header.Example, header.Examples)
+	c.inlineSchema(location, pointer, header.Schema)
+	c.content(location, pointer, header.Content)
+}
+
+func (c *exampleChecker) response(location, pointer string, resp *v3.Response) {
+	if resp == nil {
+		return
+	}
+	c.content(location, pointer, resp.Content)
+	if resp.Headers != nil {
+		for pair := resp.Headers.First(); pair != nil; pair = pair.Next() {
+			c.header(location, pointerTo(pointerTo(pointer, "headers"), pair.Key()), pair.Value())
+		}
+	}
+}
+
+func (c *exampleChecker) content(location, pointer string, content *orderedmap.Map[string, *v3.MediaType]) {
+	if content == nil {
+		return
+	}
+	for pair := content.First(); pair != nil; pair = pair.Next() {
+		mt := pair.Value()
+		if mt == nil {
+			continue
+		}
+		mtPointer := pointerTo(pointerTo(pointer, "content"), pair.Key())
+		c.examples(location, mtPointer, mt.Schema, mt.Example, mt.Examples)
+		c.inlineSchema(location, mtPointer, mt.Schema)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// isTerminal reports whether f is attached to a terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// writeEndpointList prints one endpoint per line as tab separated method, path
+// and summary, for grep, cut or fzf
+func writeEndpointList(w io.Writer, endpoints []endpoint) error {
+	out := bufio.NewWriter(w)
+	for _, ep := range endpoints {
+		summary := ""
+		if ep.op != nil {
+			summary = strings.ReplaceAll(firstLine(ep.op.Summary), "\t", " ")
+		}
+		fmt.Fprintf(out, "%s\t%s\t%s\n", ep.method, ep.path, summary)
+	}
+	return out.Flush()
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/index"
+	"go.yaml.in/yaml/v4"
+)
+
+// spinnerInterval is how often the loading spinner moves
+const spinnerInterval = 100 * time.Millisecond
+
+// progressThreshold is the size from which reading the spec shows a progress
+// bar, smaller ones being read too fast for it to be seen
+const progressThreshold = 4 << 20
+
+// progressWidth is the number of cells of the progress bar
+const progressWidth = 30
+
+// loader is the program model while the spec is read, parsed and the viewer
+// built in the background, so big specs don't leave a blank terminal. The
+// viewer replaces it once ready.
+type loader struct {
+	name     string // What is loaded, e.g. the file name
+	source   io.Reader
+	size     int64 // Of the source, 0 when unknown like for a pipe
+	progress *loadProgress
+	specs    []workspaceSpec // Loaded one after the other in place of source for a workspace
+	build    func(doc *v3.Document) Model
+	frame    int
+	width    int
+	height   int
+	err      error // Why the spec didn't load, printed once the program exits
+}
+
+// loadProgress is updated by the background load and shown by the loader at
+// every spinner tick
+type loadProgress struct {
+	read    atomic.Int64
+	parsing atomic.Bool
+	spec    atomic.Int64 // Of the workspace being loaded
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// ruleLoad is the rule of the problems met building the model of the spec
+const ruleLoad = "load"
+
+// Where libopenapi errors place the problem, as "[12:7]" or "line 12, col 7",
+// or the reference they are about
+var (
+	loadErrorPosition  = regexp.MustCompile(`\[(\d+):(\d+)\]|line (\d+), col (\d+)`)
+	loadErrorReference = regexp.MustCompile("`(#[^`]*)`")
+)
+
+// setLoadProblems keeps the problems met loading the spec for the problems
+// panel and the details of the items they affect, telling about them
+func (m *Model) setLoadProblems(root *yaml.Node, errs []error) {
+	if root != nil && root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	unresolved := findUnresolvedRefs(root, errs)
+	m.unresolved = map[string]bool{}
+	for _, u := range unresolved {
+		m.unresolved[u.ref] = true
+	}
+	m.loadProblems = loadProblems(root, errs, unresolved)
+	m.message = fmt.Sprintf("Loaded with %s, press %s to list them", pluralize(len(m.loadProblems), "problem"), m.keys.keysFor(actionProblems))
+}
+
+// loadProblems makes findings of the unresolved references and the other
+// errors met building the model, so the problems panel lists them with the
+// spec still shown. Errors point at the position or the $ref they mention.
+// Circular references are warnings, as only the schemas involved can't be
+// expanded.
+func loadProblems(root *yaml.Node, errs []error, unresolved []unresolvedRef) []finding {
+	var findings []finding
+	reported := map[string]bool{}
+	for _, u := range unresolved {
+		reported[u.ref], reported[refFragment(u.ref)] = true, true
+		findings = append(findings, finding{Rule: ruleLoad, Severity: severityError, Location: u.pointer, Pointer: u.pointer, Message: "unresolved reference " + u.ref})
+	}
+
+	for _, err := range errs {
+		message, _, _ := strings.Cut(err.Error(), "\n")
+		// Errors about a reference listed already say less than its entry
+		if match := loadErrorReference.FindStringSubmatch(message); match != nil && reported[match[1]] {
+			continue
+		}
+		f := finding{Rule: ruleLoad, Severity: severityError, Pointer: "#", Message: message}
+		var refErr *index.ResolvingError
+		if errors.As(err, &refErr) && refErr.CircularReference != nil {
+			f.Severity = severityWarning
+		}
+
+		if match := loadErrorPosition.FindStringSubmatch(message); match != nil {
+			line, _ := strconv.Atoi(cmp.Or(match[1], match[3]))
+			column, _ := strconv.Atoi(cmp.Or(match[2], match[4]))
+			at := func(node *yaml.Node) bool { return node != nil && node.Line == line && node.Column == column }
+			f.Pointer = cmp.Or(findPointer(root, func(key, value *yaml.Node) bool { return at(key) || at(value) }), f.Pointer)
+		} else if match := loadErrorReference.FindStringSubmatch(message); match != nil {
+			f.Pointer = cmp.Or(findPointer(root, func(key, value *yaml.Node) bool {
+				return key != nil && key.Value == "$ref" && value.Value == match[1]
+			}), f.Pointer)
+		}
+		f.Location = f.Pointer
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// specLoadedMsg delivers the viewer, or the error that prevented it
+type specLoadedMsg struct {
+	model Model
+	err   error
+}
+
+type spinnerTickMsg struct{}
+
+func newLoader(name string, source io.Reader, size int64, build func(doc *v3.Document) Model) loader {
+	if name == "" {
+		name = "stdin"
+	}
+	return loader{name: name, source: source, size: size, progress: &loadProgress{}, build: build}
+}
+
+func spinnerTick() tea.Cmd {
+	return tea.Tick(spinnerInterval, func(time.Time) tea.Msg { return spinnerTickMsg{} })
+}
+
+func (l loader) Init() tea.Cmd {
+	load := func() tea.Msg {
+		done := profile.phase("read")
+		var content bytes.Buffer
+		content.Grow(int(l.size))
+		_, err := content.ReadFrom(countingReader{r: l.source, n: &l.progress.read})
+		done()
+		if err != nil {
+			return specLoadedMsg{err: fmt.Errorf("reading %s: %w", l.name, err)}
+		}
+
+		l.progress.parsing.Store(true)
+		m, err := buildViewer(content.Bytes(), l.build)
+		return specLoadedMsg{model: m, err: err}
+	}
+	if l.specs != nil {
+		load = func() tea.Msg {
+			m, err := loadWorkspace(l.specs, l.build, l.progress)
+			return specLoadedMsg{model: m, err: err}
+		}
+	}
+	return tea.Batch(load, spinnerTick())
+}
+
+// buildViewer parses a spec and builds its viewer, keeping the problems met
+// for the problems panel
+func buildViewer(content []byte, build func(doc *v3.Document) Model) (Model, error) {
+	doc, problems, err := parseSpecPartially(content)
+	if err != nil {
+		return Model{}, err
+	}
+	m := build(doc)
+	m.specHash = specHash(content)
+	if len(problems) > 0 {
+		m.setLoadProblems(doc.Index.GetRootNode(), problems)
+	}
+	return m, nil
+}
+
+// prepare gives a loaded viewer the size of the terminal, which it missed as
+// sent when the program started, then restores its session
+func (l loader) prepare(m Model) Model {
+	if l.width > 0 {
+		updated, _ := m.Update(tea.WindowSizeMsg{Width: l.width, Height: l.height})
+		m = updated.(Model)
+	}
+	// Once the size is known, for the selection to be scrolled into view
+	if m.restore {
+		m.restoreSession()
+	}
+	return m
+}
+
+func (l loader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case specLoadedMsg:
+		if msg.err != nil {
+			l.err = msg.err
+			return l, tea.Quit
+		}
+		m := l.prepare(msg.model)
+		if ws := m.workspace; ws != nil {
+			for i := range ws.specs {
+				if i != ws.active {
+					ws.specs[i].model = l.prepare(ws.specs[i].model)
+				}
+			}
+		}
+		if m.startCommand != "" {
+			m.runCommand(m.startCommand)
+		}
+		return m, m.Init()
+	case tea.WindowSizeMsg:
+		l.width, l.height = msg.Width, msg.Height
+	case spinnerTickMsg:
+		l.frame++
+		return l, spinnerTick()
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return l, tea.Quit
+		}
+	}
+	return l, nil
+}
+
+// View shows the bytes read so far, with a progress bar for big inputs of a
+// known size, then a spinner while the spec is parsed
+func (l loader) View() string {
+	frames := icons.spinner
+	frame := frames[l.frame%len(frames)]
+	read := l.progress.read.Load()
+	size := max(l.size, read)
+
+	var text string
+	switch {
+	case l.specs != nil:
+		i := min(int(l.progress.spec.Load()), len(l.specs)-1)
+		text = fmt.Sprintf("%s Loading %s (%d of %d)", frame, l.specs[i].name, i+1, len(l.specs))
+	case size < progressThreshold:
+		text = fmt.Sprintf("%s Loading %s (%s)", frame, l.name, formatBytes(int(size)))
+	case l.progress.parsing.Load():
+		text = fmt.Sprintf("%s Parsing %s (%s)", frame, l.name, formatBytes(int(size)))
+	case l.size > 0:
+		text = fmt.Sprintf("%s Reading %s %s %3d%%  %s / %s", frame, l.name, progressBar(read, l.size), read*100/l.size, formatBytes(int(read)), formatBytes(int(l.size)))
+	default:
+		text = fmt.Sprintf("%s Reading %s (%s)", frame, l.name, formatBytes(int(read)))
+	}
+	text = lipgloss.NewStyle().Foreground(currentTheme.accent).Render(text)
+	if l.width == 0 {
+		return text
+	}
+	return lipgloss.Place(l.width, l.height, lipgloss.Center, lipgloss.Center, ansi.Truncate(text, l.width, icons.ellipsis))
+}
+
+// progressBar draws how much of total is done
+func progressBar(done, total int64) string {
+	filled := int(min(done, total) * progressWidth / total)
+	return strings.Repeat(icons.progressDone, filled) + strings.Repeat(icons.progressLeft, progressWidth-filled)
+}
+
+// formatBytes renders a size like 1.5 MB
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
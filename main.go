@@ -1,49 +1,310 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"io"
 	"os"
+	"runtime/debug"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/pb33f/libopenapi"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 )
 
+// lowMemoryGCPercent is the GOGC used with -low-memory, half the default
+const lowMemoryGCPercent = 50
+
 func main() {
-	var content []byte
-	var err error
+	cfg, err := loadUserConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := cfg.applyDefaults(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error in config: %v\n", err)
+		os.Exit(1)
+	}
 
 	if len(os.Args) > 1 {
-		content, err = os.ReadFile(os.Args[1])
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
-			os.Exit(1)
+		if cmd, ok := findCommand(os.Args[1]); ok {
+			err := runCommand(cmd, os.Args[2:])
+			switch {
+			case err == nil, errors.Is(err, flag.ErrHelp):
+				return
+			case errors.Is(err, errFindings):
+				os.Exit(1)
+			case errors.Is(err, errUsage):
+				os.Exit(2)
+			}
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
 		}
-	} else {
-		content, err = io.ReadAll(os.Stdin)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading from stdin: %v\n", err)
+	}
+
+	themeName := flag.String("theme", "", "color theme: dark, light, high-contrast or monochrome")
+	ascii := flag.Bool("ascii", false, "use plain ASCII characters instead of Unicode icons")
+	accessible := flag.Bool("accessible", false, "render for screen readers: no box drawing or colors, and the selected item announced on one line")
+	envName := flag.String("env", "", "environment from the config file to send requests to")
+	list := flag.Bool("list", false, "print the endpoints instead of starting the viewer (default when stdout is not a terminal)")
+	output := flag.String("output", "", "print the spec instead of starting the viewer: text (the endpoint list) or json")
+	profileDir := flag.String("profile", "", "write CPU and heap profiles to this directory and print how long each startup phase took")
+	lowMemory := flag.Bool("low-memory", false, "keep less in memory for very large specs, building schemas only when shown")
+	inline := flag.Bool("inline", false, "run without the alternate screen, leaving the last view in the terminal scrollback")
+	printSelection := flag.Bool("print-selection", false, "print the selected item and its details to stdout on exit")
+	command := flag.String("command", "", "jump to an item once the spec is loaded, like the : prompt, e.g. \"op listPets\"")
+	since := flag.String("since", "", "mark the endpoints and schemas added, changed or removed since this baseline spec")
+	fresh := flag.Bool("fresh", false, "don't restore the view, selection and unfolded items of the last time the spec was opened")
+	if len(os.Args) > 1 && os.Args[1] == completeCommand {
+		if err := writeCompletions(os.Stdout, flag.CommandLine, os.Args[2:]); err != nil {
 			os.Exit(1)
 		}
+		return
+	}
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: oq [flags] [openapi-file...]\n       oq <command> [flags] [openapi-file]\n\nReads the spec from stdin when no file, or -, is given. Several files, a\ndirectory or a workspace file listing specs open them all at once.\n\nCommands:\n")
+		for _, cmd := range commands {
+			fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.description)
+		}
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := cfg.applyDisplay(*themeName, *ascii, *accessible); err != nil {
+		fmt.Fprintf(os.Stderr, "Error setting theme: %v\n", err)
+		os.Exit(1)
+	}
+
+	keys, err := cfg.keyMap()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error in config keys: %v\n", err)
+		os.Exit(1)
+	}
+
+	columns, err := cfg.columns()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error in config columns: %v\n", err)
+		os.Exit(1)
+	}
+
+	componentColumns, err := cfg.componentColumns()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error in config component_columns: %v\n", err)
+		os.Exit(1)
+	}
+
+	hiddenDetails, err := cfg.hiddenDetails()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error in config hide_details: %v\n", err)
+		os.Exit(1)
+	}
+
+	plugins, err := cfg.plugins()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error in config plugins: %v\n", err)
+		os.Exit(1)
+	}
+
+	schemaDepth, err := cfg.schemaDepth()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error in config: %v\n", err)
+		os.Exit(1)
 	}
 
-	document, err := libopenapi.NewDocument(content)
+	if *envName != "" {
+		cfg.Environment = *envName
+	}
+	envs, err := cfg.environments()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating document: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error in config environments: %v\n", err)
 		os.Exit(1)
 	}
 
-	v3Model, err := document.BuildV3Model()
+	rs, err := loadRuleset(defaultRulesetFile, false)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error building V3 model: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error reading ruleset: %v\n", err)
 		os.Exit(1)
 	}
 
-	m := NewModel(&v3Model.Model)
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	specPath := ""
+	if flag.NArg() > 0 && flag.Arg(0) != stdinPath {
+		specPath = flag.Arg(0)
+	}
+	specs, err := findWorkspace(flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error %v\n", err)
+		os.Exit(1)
+	}
 
-	if _, err := p.Run(); err != nil {
+	if *profileDir != "" {
+		if profile, err = startProfile(*profileDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting profile: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// With --print-selection, stdout is for the selection even when piped
+	if *output == "" && (*list || !isTerminal(os.Stdout) && !*printSelection) {
+		*output = defaultOutput
+	}
+	var baselineDoc *v3.Document
+	if *since != "" {
+		if specs != nil {
+			fmt.Fprintf(os.Stderr, "Error: --since compares a single spec, not a workspace\n")
+			exit(2)
+		}
+		if baselineDoc, err = loadSpec(*since); err != nil {
+			fmt.Fprintf(os.Stderr, "Error in baseline %v\n", err)
+			exit(1)
+		}
+	}
+
+	if *output != "" && specs != nil {
+		fmt.Fprintf(os.Stderr, "Error: --list and --output print a single spec, not a workspace\n")
+		exit(2)
+	}
+	if *output != "" {
+		done := profile.phase("read")
+		content, err := readSpec(specPath)
+		done()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error %v\n", err)
+			exit(1)
+		}
+		doc, err := parseSpec(content)
+		if err != nil {
+			exitLoadError(specPath, err)
+		}
+		if err := writeOutput(os.Stdout, *output, doc); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+			exit(1)
+		}
+		stopProfile()
+		return
+	}
+
+	newViewer := NewModel
+	if *lowMemory {
+		// The garbage collector runs more often, so the heap stays closer to what is in use
+		debug.SetGCPercent(lowMemoryGCPercent)
+		newViewer = NewLowMemoryModel
+	}
+	build := func(doc *v3.Document) Model {
+		m := newViewer(doc)
+		m.keys = keys
+		m.columns = columns
+		m.componentColumns = componentColumns
+		m.detailOpts.schemaDepth = schemaDepth
+		m.detailOpts.hidden = hiddenDetails
+		m.environments = envs
+		m.environment = cfg.Environment
+		m.headers = cfg.headers()
+		m.editor = cfg.Editor
+		m.specPath = specPath
+		m.ruleset = rs
+		m.restore = !*fresh
+		m.startCommand = *command
+		m.accessible = *accessible || cfg.Accessible
+		m.plugins = plugins
+		m.hooks = cfg.Hooks
+		if baselineDoc != nil {
+			m.setBaseline(*since, baselineDoc)
+		}
+		return m
+	}
+
+	// The viewer reads the spec itself, showing the progress of big ones
+	var l loader
+	if specs != nil {
+		l = newWorkspaceLoader(specPath, specs, build)
+	} else {
+		source, size, err := openSpec(specPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error %v\n", err)
+			exit(1)
+		}
+		defer source.Close()
+		l = newLoader(specPath, source, size, build)
+	}
+	p := tea.NewProgram(l, viewerOptions(*inline, *printSelection)...)
+
+	final, err := p.Run()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
+		exit(1)
+	}
+	if l, ok := final.(loader); ok && l.err != nil {
+		if l.specs != nil {
+			specPath = "" // The error names the spec of the workspace
+		}
+		exitLoadError(specPath, l.err)
+	}
+	if m, ok := final.(Model); ok {
+		if err := m.saveSessions(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: saving the session: %v\n", err)
+		}
+	}
+	if m, ok := final.(Model); ok && *printSelection {
+		selection := m.selectionText()
+		if !isTerminal(os.Stdout) {
+			selection = ansi.Strip(selection)
+		}
+		fmt.Print(selection)
+	}
+	stopProfile()
+}
+
+// viewerOptions runs the viewer in the alternate screen, unless inline, and on
+// stderr when stdout is piped for --print-selection, as fzf does. The clipboard
+// falls back to that output when it is a terminal.
+func viewerOptions(inline, printSelection bool) []tea.ProgramOption {
+	var opts []tea.ProgramOption
+	if !inline {
+		opts = append(opts, tea.WithAltScreen())
+	}
+	output := os.Stdout
+	if printSelection && !isTerminal(os.Stdout) {
+		output = os.Stderr
+		opts = append(opts, tea.WithOutput(output))
+		lipgloss.SetColorProfile(lipgloss.NewRenderer(output).ColorProfile())
+	}
+	if isTerminal(output) {
+		clipboardTerminal = output
+	}
+	return opts
+}
+
+// stopProfile ends the -profile profiles and prints the startup phases
+func stopProfile() {
+	if err := profile.stop(os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing profile: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// exit stops the -profile profiles before exiting, so that they are written
+// when the spec fails to load as well
+func exit(code int) {
+	stopProfile()
+	os.Exit(code)
+}
+
+// exitLoadError reports a spec that doesn't load and exits
+func exitLoadError(specPath string, err error) {
+	fmt.Fprintf(os.Stderr, "Error %v\n", err)
+	var syntaxErr *syntaxError
+	if errors.As(err, &syntaxErr) {
+		fmt.Fprintf(os.Stderr, "\n%s", syntaxErr.snippet())
+		if syntaxErr.hint != "" {
+			fmt.Fprintf(os.Stderr, "Hint: %s\n", syntaxErr.hint)
+		}
+		exit(1)
+	}
+	if specPath != "" {
+		fmt.Fprintf(os.Stderr, "Run 'oq validate %s' to list every problem with its location\n", specPath)
+	}
+	exit(1)
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// untaggedSection is the heading of the operations without tags
+const untaggedSection = "Other operations"
+
+// markdownReference renders the spec as a Markdown API reference: one section per
+// tag with its operations, followed by the schemas
+func markdownReference(doc *v3.Document, endpoints []endpoint, components []component) string {
+	var md strings.Builder
+
+	if doc.Info != nil {
+		title := doc.Info.Title
+		if title == "" {
+			title = "API Reference"
+		}
+		md.WriteString("# " + title + "\n\n")
+		if doc.Info.Version != "" {
+			md.WriteString(fmt.Sprintf("Version: %s\n\n", doc.Info.Version))
+		}
+		if doc.Info.Description != "" {
+			md.WriteString(strings.TrimSpace(doc.Info.Description) + "\n\n")
+		}
+	}
+
+	if len(doc.Servers) > 0 {
+		md.WriteString("Servers:\n\n")
+		for _, server := range doc.Servers {
+			line := fmt.Sprintf("- `%s`", server.URL)
+			if server.Description != "" {
+				line += ": " + firstLine(server.Description)
+			}
+			md.WriteString(line + "\n")
+		}
+		md.WriteString("\n")
+	}
+
+	for _, section := range markdownSections(doc, endpoints) {
+		md.WriteString("## " + section.name + "\n\n")
+		if section.description != "" {
+			md.WriteString(strings.TrimSpace(section.description) + "\n\n")
+		}
+		for _, ep := range section.endpoints {
+			writeMarkdownOperation(&md, ep)
+		}
+	}
+
+	var schemas []component
+	for _, comp := range components {
+		if comp.compType == "Schema" {
+			schemas = append(schemas, comp)
+		}
+	}
+	if len(schemas) > 0 {
+		md.WriteString("## Schemas\n\n")
+		for _, comp := range schemas {
+			if proxy, ok := comp.source.(*base.SchemaProxy); ok {
+				writeMarkdownSchema(&md, comp.name, proxy)
+			}
+		}
+	}
+
+	return strings.TrimRight(md.String(), "\n") + "\n"
+}
+
+// markdownSection holds the operations of a tag
+type markdownSection struct {
+	name        string
+	description string
+	endpoints   []endpoint
+}
+
+// markdownSections groups the operations by tag, declared tags first and in their
+// order, then tags only used by operations. Operations with several tags are listed
+// in each section.
+func markdownSections(doc *v3.Document, endpoints []endpoint) []markdownSection {
+	var sections []markdownSection
+	index := map[string]int{}
+	add := func(name, description string) {
+		if _, ok := index[name]; !ok {
+			index[name] = len(sections)
+			sections = append(sections, markdownSection{name: name, description: description})
+		}
+	}
+
+	for _, tag := range doc.Tags {
+		if tag != nil {
+			add(tag.Name, tag.Description)
+		}
+	}
+
+	var untagged []endpoint
+	for _, ep := range endpoints {
+		if len(ep.op.Tags) == 0 {
+			untagged = append(untagged, ep)
+			continue
+		}
+		for _, tag := range ep.op.Tags {
+			add(tag, "")
+			sections[index[tag]].endpoints = append(sections[index[tag]].endpoints, ep)
+		}
+	}
+	if len(untagged) > 0 {
+		sections = append(sections, markdownSection{name: untaggedSection, endpoints: untagged})
+	}
+
+	return slices.DeleteFunc(sections, func(s markdownSection) bool { return len(s.endpoints) == 0 })
+}
+
+func writeMarkdownOperation(md *strings.Builder, ep endpoint) {
+	op := ep.op
+
+	heading := fmt.Sprintf("### `%s %s`", ep.method, ep.path)
+	if op.Summary != "" {
+		heading += " " + firstLine(op.Summary)
+	}
+	md.WriteString(heading + "\n\n")
+
+	if ep.deprecation.deprecated {
+		md.WriteString("> **Deprecated**")
+		if len(ep.deprecation.notes) > 0 {
+			md.WriteString(": " + strings.Join(ep.deprecation.notes, ", "))
+		}
+		md.WriteString("\n\n")
+	}
+
+	if op.OperationId != "" {
+		md.WriteString(fmt.Sprintf("Operation ID: `%s`\n\n", op.OperationId))
+	}
+
+	if op.Description != "" {
+		md.WriteString(strings.TrimSpace(op.Description) + "\n\n")
+	}
+
+	if len(op.Parameters) > 0 {
+		md.WriteString("**Parameters**\n\n")
+		md.WriteString("| Name | In | Type | Required | Description |\n")
+		md.WriteString("| --- | --- | --- | --- | --- |\n")
+		for _, param := range op.Parameters {
+			if param == nil {
+				continue
+			}
+			paramType := ""
+			if param.Schema != nil {
+				paramType = schemaSummary(param.Schema)
+			}
+			required := "no"
+			if param.Required != nil && *param.Required {
+				required = "yes"
+			}
+			name := "`" + param.Name + "`"
+			if newDeprecation(param.Deprecated, param.Extensions).deprecated {
+				name += " (deprecated)"
+			}
+			md.WriteString(markdownRow(name, param.In, paramType, required, param.Description))
+		}
+		md.WriteString("\n")
+	}
+
+	if body := op.RequestBody; body != nil {
+		md.WriteString("**Request body**")
+		if body.Required != nil && *body.Required {
+			md.WriteString(" (required)")
+		}
+		md.WriteString("\n\n")
+		if body.Description != "" {
+			md.WriteString(strings.TrimSpace(body.Description) + "\n\n")
+		}
+		writeMarkdownContent(md, body.Content)
+	}
+
+	if op.Responses != nil {
+		md.WriteString("**Responses**\n\n")
+		md.WriteString("| Code | Description | Content |\n")
+		md.WriteString("| --- | --- | --- |\n")
+
+		var codes []string
+		if op.Responses.Codes != nil {
+			for pair := op.Responses.Codes.First(); pair != nil; pair = pair.Next() {
+				codes = append(codes, pair.Key())
+			}
+		}
+		sortResponseCodes(codes)
+		for _, code := range codes {
+			if resp, ok := op.Responses.Codes.Get(code); ok && resp != nil {
+				md.WriteString(markdownRow(code, resp.Description, markdownContentText(resp.Content)))
+			}
+		}
+		if resp := op.Responses.Default; resp != nil {
+			md.WriteString(markdownRow("default", resp.Description, markdownContentText(resp.Content)))
+		}
+		md.WriteString("\n")
+	}
+}
+
+// writeMarkdownContent lists the media types of a body with their schemas
+func writeMarkdownContent(md *strings.Builder, content *orderedmap.Map[string, *v3.MediaType]) {
+	if content == nil || content.Len() == 0 {
+		return
+	}
+	for _, mediaType := range sortedMediaTypes(content) {
+		mt, _ := content.Get(mediaType)
+		line := fmt.Sprintf("- `%s`", mediaType)
+		if mt != nil && mt.Schema != nil {
+			line += ": " + schemaSummary(mt.Schema)
+		}
+		md.WriteString(line + "\n")
+	}
+	md.WriteString("\n")
+}
+
+// markdownContentText describes the media types of a response in a table cell
+func markdownContentText(content *orderedmap.Map[string, *v3.MediaType]) string {
+	if content == nil {
+		return ""
+	}
+	var parts []string
+	for _, mediaType := range sortedMediaTypes(content) {
+		mt, _ := content.Get(mediaType)
+		part := "`" + mediaType + "`"
+		if mt != nil && mt.Schema != nil {
+			part += ": " + schemaTypeLabel(mt.Schema)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func sortedMediaTypes(content *orderedmap.Map[string, *v3.MediaType]) []string {
+	var mediaTypes []string
+	for pair := content.First(); pair != nil; pair = pair.Next() {
+		mediaTypes = append(mediaTypes, pair.Key())
+	}
+	sort.Strings(mediaTypes)
+	return mediaTypes
+}
+
+func writeMarkdownSchema(md *strings.Builder, name string, proxy *base.SchemaProxy) {
+	md.WriteString("### " + name + "\n\n")
+
+	s := proxy.Schema()
+	if s == nil {
+		return
+	}
+
+	if d := schemaDeprecation(s); d.deprecated {
+		md.WriteString("> **Deprecated**\n\n")
+	}
+	if s.Description != "" {
+		md.WriteString(strings.TrimSpace(s.Description) + "\n\n")
+	}
+
+	if compositions := schemaCompositions(s); len(compositions) > 0 {
+		for _, c := range compositions {
+			md.WriteString(fmt.Sprintf("- %s\n", c.label()))
+		}
+		md.WriteString("\n")
+	}
+
+	if s.Properties == nil || s.Properties.Len() == 0 {
+		md.WriteString(fmt.Sprintf("Type: %s\n\n", schemaSummary(proxy)))
+		return
+	}
+
+	md.WriteString("| Property | Type | Required | Description |\n")
+	md.WriteString("| --- | --- | --- | --- |\n")
+	for pair := s.Properties.First(); pair != nil; pair = pair.Next() {
+		prop := pair.Value()
+		if prop == nil || prop.Schema() == nil {
+			continue
+		}
+		propType := schemaSummary(prop)
+		if flags := schemaFlags(prop.Schema()); len(flags) > 0 {
+			propType += " (" + strings.Join(flags, ", ") + ")"
+		}
+		required := "no"
+		if slices.Contains(s.Required, pair.Key()) {
+			required = "yes"
+		}
+		md.WriteString(markdownRow("`"+pair.Key()+"`", propType, required, prop.Schema().Description))
+	}
+	md.WriteString("\n")
+}
+
+// markdownRow renders a table row, keeping each cell on one line
+func markdownRow(cells ...string) string {
+	for i, cell := range cells {
+		cells[i] = markdownEscape(strings.Join(strings.Fields(cell), " "))
+	}
+	return "| " + strings.Join(cells, " | ") + " |\n"
+}
+
+// markdownEscape escapes the pipes of union types like "string | null", which
+// would otherwise split table cells
+func markdownEscape(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
@@ -1,10 +1,18 @@
 package main
 
 import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 )
 
@@ -14,12 +22,43 @@ const (
 	viewEndpoints viewMode = iota
 	viewComponents
 	viewWebhooks
+	viewInfo
 )
 
 const keySequenceThreshold = 500 * time.Millisecond
 
 const scrollHalfScreenLines = 21
 
+// navigationStep is how many items J and K move by, a step between a single
+// item and half a screen
+const navigationStep = 10
+
+// maxCount caps the count typed before a motion, e.g. the 10 of 10j
+const maxCount = 99999
+
+// horizontalScrollStep is the number of columns scrolled left or right at once
+const horizontalScrollStep = 8
+
+// Schema property trees in details are this many levels deep by default and can
+// be expanded up to maxSchemaDepth
+const (
+	defaultSchemaDepth = 1
+	maxSchemaDepth     = 10
+)
+
+// Optional columns of the endpoint list
+const (
+	columnOperationID = "operationId"
+	columnSummary     = "summary"
+)
+
+var defaultColumns = []string{columnOperationID, columnSummary}
+
+// columnDescription is the optional column of the component list
+const columnDescription = "description"
+
+var defaultComponentColumns = []string{columnDescription}
+
 // Layout constants (shared with view.go)
 const (
 	// Height
@@ -30,7 +69,7 @@ const (
 
 	// Width
 
-	leftPaddingChars = 2 // "▶" + space
+	leftPaddingChars = 2 // Fold icon + space
 )
 
 // calculateContentHeight returns the available height for content given the total viewport height
@@ -43,78 +82,279 @@ func calculateContentWidth(totalWidth int) int {
 }
 
 type webhook struct {
-	name   string
-	method string
-	op     *v3.Operation
-	folded bool
+	name        string
+	method      string
+	op          *v3.Operation // Includes the parameters of the path item
+	source      *v3.Operation // The operation as written
+	security    security
+	deprecation deprecation
+	folded      bool
 }
 
 type endpoint struct {
-	path   string
-	method string
-	op     *v3.Operation
-	folded bool
+	path        string
+	method      string
+	op          *v3.Operation // Includes the parameters of the path item
+	source      *v3.Operation // The operation as written
+	security    security
+	deprecation deprecation
+	folded      bool
+}
+
+// security holds the requirements that apply to an operation, which are either
+// its own or inherited from the document
+type security struct {
+	requirements []*base.SecurityRequirement
+	inherited    bool
 }
 
 type component struct {
 	name        string
 	compType    string
 	description string
-	details     string
+	source      renderable
+	deprecation deprecation
 	folded      bool
 }
 
+type pickerAction int
+
+const (
+	pickerGoToReference pickerAction = iota
+	pickerCopySnippet
+	pickerSelectEnvironment
+	pickerExport
+	pickerOpenExample
+	pickerSearch
+	pickerSwitchSpec
+)
+
+type pickerItem struct {
+	label string
+	value string
+}
+
+// picker is a small modal list the user selects a single entry from
+type picker struct {
+	title  string
+	items  []pickerItem
+	cursor int
+	action pickerAction
+	query  string // Typed in the search
+}
+
 type Model struct {
-	doc          *v3.Document
-	endpoints    []endpoint
-	components   []component
-	webhooks     []webhook
-	cursor       int
-	mode         viewMode
-	width        int
-	height       int
-	showHelp     bool
-	lastKey      string
-	lastKeyAt    time.Time
-	scrollOffset int
+	doc              *v3.Document
+	endpoints        []endpoint
+	components       []component
+	webhooks         []webhook
+	cursor           int
+	mode             viewMode
+	width            int
+	height           int
+	showHelp         bool
+	helpFilter       string // Typed in the help to list only the matching keys
+	helpTop          int    // First visible line of the help
+	showRaw          bool
+	jsonSource       bool
+	detailOpts       detailOptions
+	keys             keyMap
+	columns          []string
+	componentColumns []string
+	picker           *picker
+	backStack        []location
+	forwardStack     []location
+	lastKey          string
+	lastKeyAt        time.Time
+	scrollOffset     int
+	count            int    // Typed before a motion, e.g. the 10 of 10j
+	prompting        bool   // Typing a command after :
+	command          string // Typed after :
+	startCommand     string // Run once the spec is loaded, from --command
+	hOffset          int
+	message          string // Shown in the footer until the next key press
+	response         *tryResponse
+	responseTop      int // First visible line of the response body
+	environments     map[string]environment
+	environment      string            // Active environment, empty to use the servers of the spec
+	headers          map[string]string // Sent with every request, from the config
+	editor           string            // Opens the spec instead of $VISUAL or $EDITOR
+	unfiltered       *itemLists        // Every item while only deprecated ones are listed
+	example          *exampleViewer
+	specPath         string // File the spec was read from, empty for stdin
+	ruleset          ruleset
+	loadProblems     []finding                       // Met building the model of the spec, the rest of which is shown
+	unresolved       map[string]bool                 // References leading nowhere, marked in the details of the items using them
+	details          map[detailKey]*formattedDetails // Of the items unfolded so far
+	layout           *listLayout
+	index            *searchIndex // Nil until built in the background
+	lowMemory        bool
+	wrapWidth        int        // Width the details are wrapped at, following m.width once resizing settles
+	resizes          int        // Counts the size changes, to act on the last one only
+	specHash         string     // Of the spec content, keying the session saved on quit; empty to save none
+	restore          bool       // Restore the saved session once loaded, unless started with --fresh
+	accessible       bool       // Announce the selected item in plain text, for screen readers
+	workspace        *workspace // The specs opened with this one, nil when it is alone
+	baseline         *baseline  // Compared with, from --since
+	plugins          []plugin
+	pluginRuns       map[string]*pluginRun // Of the detail plugins, by item like the details
+	hooks            hooks
+	selections       int // Counts the changes of the selected item, to run on_select on the last one only
+}
+
+// detailKey identifies the details of an item formatted with some options
+type detailKey struct {
+	item any // The operation or the source of the component
+	name string
+	raw  bool
+	opts detailOptions
 }
 
 func (m *Model) getItemHeight(index int) int {
+	if index > m.getMaxItems() || m.isFolded(index) {
+		return 1 // Just the main line when folded
+	}
+	// When unfolded, count main line + detail lines
+	return 1 + len(m.detailLines(index))
+}
+
+func (m *Model) isFolded(index int) bool {
+	switch m.mode {
+	case viewEndpoints:
+		return m.endpoints[index].folded
+	case viewComponents:
+		return m.components[index].folded
+	case viewWebhooks:
+		return m.webhooks[index].folded
+	}
+	return true
+}
+
+// resizeSettleDelay is how long the terminal size must stay the same before
+// the details are wrapped at the new width
+const resizeSettleDelay = 100 * time.Millisecond
+
+// resizeSettledMsg is sent after a resize, acted on when no other resize followed
+type resizeSettledMsg struct {
+	resizes int
+}
+
+// lowMemoryDetails is how many items have their details kept in low memory
+// mode, enough for those on the screen
+const lowMemoryDetails = 64
+
+// formattedDetails are the details of an item, and their lines as shown in the list
+type formattedDetails struct {
+	text  string
+	lines []string // Rendered when the item is first drawn
+}
+
+// itemDetails returns the detail section of the item at index in the current view,
+// either as formatted details or as highlighted raw source when raw mode is on.
+// Details are formatted when first shown and kept for the next frames.
+func (m *Model) itemDetails(index int) string {
+	if d := m.formattedDetails(index); d != nil {
+		return d.text
+	}
+	return ""
+}
+
+// selectionText is the item at the cursor and its details, as shown with the
+// current options, e.g. "GET /pets" followed by its parameters and responses.
+// It is empty in the info view and for empty lists.
+func (m *Model) selectionText() string {
+	if m.mode == viewInfo || m.cursor < 0 || m.cursor > m.getMaxItems() {
+		return ""
+	}
+	var name string
+	switch m.mode {
+	case viewEndpoints:
+		name = m.endpoints[m.cursor].method + " " + m.endpoints[m.cursor].path
+	case viewComponents:
+		name = m.components[m.cursor].compType + " " + m.components[m.cursor].name
+	case viewWebhooks:
+		name = m.webhooks[m.cursor].method + " " + m.webhooks[m.cursor].name
+	}
+	return name + "\n" + strings.TrimRight(m.itemDetails(m.cursor), "\n") + "\n"
+}
+
+// detailLines returns the details of the item at index styled for the list,
+// one string per line
+func (m *Model) detailLines(index int) []string {
+	d := m.formattedDetails(index)
+	if d == nil {
+		return nil
+	}
+	if d.lines == nil {
+		detailStyle := lipgloss.NewStyle().
+			PaddingLeft(2).
+			Foreground(currentTheme.detail)
+		d.lines = strings.Split(detailStyle.Render(d.text), "\n")
+	}
+	return d.lines
+}
+
+func (m *Model) formattedDetails(index int) *formattedDetails {
+	opts := m.detailOpts
+	opts.width = calculateContentWidth(cmp.Or(m.wrapWidth, m.width))
+
+	var key detailKey
+	var format func() string
 	switch m.mode {
 	case viewEndpoints:
-		if index >= len(m.endpoints) {
-			return 1
-		}
 		ep := m.endpoints[index]
-		if ep.folded {
-			return 1 // Just the main line when folded
+		key = detailKey{item: ep.op, name: ep.method + " " + ep.path}
+		format = func() string {
+			if m.showRaw {
+				return formatRawSource(ep.source, m.jsonSource)
+			}
+			return m.baseline.details(endpointName(ep)) + m.unresolvedMarkers(ep.source) + formatEndpointDetailsWithOptions(ep, opts) + m.pluginDetails(key.name, ep.source)
 		}
-		// When unfolded, count main line + detail lines
-		details := formatEndpointDetails(ep)
-		return 1 + strings.Count(details, "\n") + 1 // +1 for main line, +1 for the detail section
 	case viewComponents:
-		if index >= len(m.components) {
-			return 1
-		}
 		comp := m.components[index]
-		if comp.folded {
-			return 1 // Just the main line when folded
+		key = detailKey{item: comp.source, name: comp.compType + " " + comp.name}
+		format = func() string {
+			if m.showRaw {
+				return formatRawSource(comp.source, m.jsonSource)
+			}
+			return m.baseline.details(componentName(comp)) + m.unresolvedMarkers(comp.source) + formatComponentDetails(comp, opts) + m.pluginDetails(key.name, comp.source)
 		}
-		// When unfolded, count main line + detail lines
-		return 1 + strings.Count(comp.details, "\n") + 1 // +1 for main line, +1 for the detail section
 	case viewWebhooks:
-		if index >= len(m.webhooks) {
-			return 1
-		}
 		hook := m.webhooks[index]
-		if hook.folded {
-			return 1 // Just the main line when folded
+		key = detailKey{item: hook.op, name: hook.method + " " + hook.name}
+		format = func() string {
+			if m.showRaw {
+				return formatRawSource(hook.source, m.jsonSource)
+			}
+			return m.unresolvedMarkers(hook.source) + formatWebhookDetailsWithOptions(hook, opts) + m.pluginDetails(key.name, hook.source)
 		}
-		// When unfolded, count main line + detail lines
-		details := formatWebhookDetails(hook)
-		return 1 + strings.Count(details, "\n") + 1 // +1 for main line, +1 for the detail section
+	default:
+		return nil
+	}
+	key.raw, key.opts = m.showRaw, opts
+
+	if d, ok := m.details[key]; ok {
+		return d
+	}
+	d := &formattedDetails{text: format()}
+	if m.details == nil {
+		m.details = make(map[detailKey]*formattedDetails)
 	}
-	return 1
+	if m.lowMemory && len(m.details) >= lowMemoryDetails {
+		clear(m.details)
+	}
+	m.details[key] = d
+	return d
+}
+
+// settleSize wraps the details at the current width and keeps the cursor on
+// the screen, as the heights of the items changed
+func (m *Model) settleSize() {
+	if m.wrapWidth != m.width {
+		clear(m.details) // Wrapped at the old width
+		m.wrapWidth = m.width
+	}
+	m.ensureCursorVisible()
 }
 
 func (m *Model) getMaxItems() int {
@@ -125,6 +365,9 @@ func (m *Model) getMaxItems() int {
 		return len(m.components) - 1
 	case viewWebhooks:
 		return len(m.webhooks) - 1
+	case viewInfo:
+		// The info page scrolls by line, the cursor being the first visible line
+		return max(0, len(m.infoLines())-calculateContentHeight(m.height))
 	default:
 		return -1
 	}
@@ -134,30 +377,19 @@ func (m *Model) ensureCursorVisible() {
 	// Calculate available content height using shared function
 	contentHeight := calculateContentHeight(m.height)
 
+	if m.mode == viewInfo {
+		m.scrollOffset = m.cursor
+		return
+	}
+
 	// Special case: if cursor is at 0, ensure we scroll to the very top
 	if m.cursor == 0 {
 		m.scrollOffset = 0
 		return
 	}
 
-	// Calculate the actual rendered height of items to properly handle viewport
-	var items []interface{}
-	switch m.mode {
-	case viewEndpoints:
-		for i := range m.endpoints {
-			items = append(items, m.endpoints[i])
-		}
-	case viewComponents:
-		for i := range m.components {
-			items = append(items, m.components[i])
-		}
-	case viewWebhooks:
-		for i := range m.webhooks {
-			items = append(items, m.webhooks[i])
-		}
-	}
-
-	if len(items) == 0 {
+	itemCount := m.getMaxItems() + 1
+	if itemCount == 0 {
 		return
 	}
 
@@ -171,7 +403,7 @@ func (m *Model) ensureCursorVisible() {
 	}
 
 	// Calculate how many lines are used from scrollOffset to cursor (inclusive)
-	for i := m.scrollOffset; i <= m.cursor && i < len(items); i++ {
+	for i := m.scrollOffset; i <= m.cursor && i < itemCount; i++ {
 		linesUsed += m.getItemHeight(i)
 	}
 
@@ -192,7 +424,7 @@ func (m *Model) ensureCursorVisible() {
 			}
 
 			// Calculate lines from new scroll offset to cursor
-			for i := newScrollOffset; i <= m.cursor && i < len(items); i++ {
+			for i := newScrollOffset; i <= m.cursor && i < itemCount; i++ {
 				testLinesUsed += m.getItemHeight(i)
 			}
 
@@ -209,176 +441,1156 @@ func (m *Model) ensureCursorVisible() {
 	}
 }
 
+// pageDown scrolls to the first item that isn't fully visible and selects it
+func (m *Model) pageDown() {
+	maxItems := m.getMaxItems()
+	if maxItems < 0 {
+		return
+	}
+
+	contentHeight := calculateContentHeight(m.height)
+	if m.mode == viewInfo {
+		m.cursor = min(m.cursor+contentHeight, maxItems)
+		m.ensureCursorVisible()
+		return
+	}
+
+	linesUsed := 0
+	if m.scrollOffset > 0 {
+		linesUsed++ // "More items above" indicator
+	}
+
+	next := m.scrollOffset
+	for next <= maxItems && linesUsed+m.getItemHeight(next) <= contentHeight {
+		linesUsed += m.getItemHeight(next)
+		next++
+	}
+
+	// An item taller than the screen still moves the page by one item
+	next = max(next, m.scrollOffset+1)
+	if next > maxItems {
+		m.cursor = maxItems
+		m.ensureCursorVisible()
+		return
+	}
+
+	m.cursor = next
+	m.scrollOffset = next
+	m.ensureCursorVisible()
+}
+
+// pageUp scrolls back so that the previous screen of items ends right above the
+// current top item and selects the new top item
+func (m *Model) pageUp() {
+	contentHeight := calculateContentHeight(m.height)
+	if m.mode == viewInfo {
+		m.cursor = max(0, m.cursor-contentHeight)
+		m.ensureCursorVisible()
+		return
+	}
+
+	if m.scrollOffset == 0 {
+		m.cursor = 0
+		m.ensureCursorVisible()
+		return
+	}
+
+	// Reserve a line for the "More items above" indicator
+	linesUsed := 1
+	prev := m.scrollOffset
+	for prev > 0 && linesUsed+m.getItemHeight(prev-1) <= contentHeight {
+		linesUsed += m.getItemHeight(prev - 1)
+		prev--
+	}
+	prev = min(prev, m.scrollOffset-1)
+
+	m.cursor = prev
+	m.scrollOffset = prev
+	m.ensureCursorVisible()
+}
+
+// groupOf names the group of the item at index for { and }: the first segment
+// of the path of an operation, e.g. "pets" of /pets/{id}, the type of a component
+// or the name of a webhook
+func (m *Model) groupOf(index int) string {
+	switch m.mode {
+	case viewEndpoints:
+		first, _, _ := strings.Cut(strings.TrimPrefix(m.endpoints[index].path, "/"), "/")
+		return first
+	case viewComponents:
+		return m.components[index].compType
+	case viewWebhooks:
+		return m.webhooks[index].name
+	}
+	return ""
+}
+
+// nextGroup returns the first item of the group after the selected one, or the
+// last item when there is none
+func (m *Model) nextGroup() int {
+	maxItems := m.getMaxItems()
+	if m.mode == viewInfo || m.cursor >= maxItems {
+		return max(0, min(m.cursor, maxItems))
+	}
+	group := m.groupOf(m.cursor)
+	next := m.cursor + 1
+	for next < maxItems && m.groupOf(next) == group {
+		next++
+	}
+	return next
+}
+
+// prevGroup returns the first item of the selected group, or of the group before
+// when the first one is selected already
+func (m *Model) prevGroup() int {
+	if m.mode == viewInfo || m.cursor <= 0 || m.cursor > m.getMaxItems() {
+		return max(0, m.cursor)
+	}
+	prev := m.cursor
+	if m.groupOf(prev-1) != m.groupOf(prev) {
+		prev--
+	}
+	for prev > 0 && m.groupOf(prev-1) == m.groupOf(prev) {
+		prev--
+	}
+	return prev
+}
+
+// centerCursor scrolls the list so that the selected item starts in the middle
+// of the screen, as far as the items above allow
+func (m *Model) centerCursor() {
+	if m.mode == viewInfo || m.cursor > m.getMaxItems() {
+		return
+	}
+	half := calculateContentHeight(m.height) / 2
+	linesAbove := 1 // "More items above" indicator
+	offset := m.cursor
+	for offset > 0 && linesAbove+m.getItemHeight(offset-1) <= half {
+		offset--
+		linesAbove += m.getItemHeight(offset)
+	}
+	m.scrollOffset = offset
+}
+
 func NewModel(doc *v3.Document) Model {
-	endpoints := extractEndpoints(doc)
-	components := extractComponents(doc)
-	webhooks := extractWebhooks(doc)
+	return newModel(doc, false)
+}
+
+// NewLowMemoryModel builds a viewer for very large specs, keeping less in
+// memory: schemas are only built when shown, the details of a few items are
+// kept and the search leaves out schema properties
+func NewLowMemoryModel(doc *v3.Document) Model {
+	return newModel(doc, true)
+}
+
+func newModel(doc *v3.Document, lowMemory bool) Model {
+	// The lists don't depend on each other, so they are built at the same time
+	done := profile.phase("extract")
+	var endpoints []endpoint
+	var components []component
+	var webhooks []webhook
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		endpoints = extractEndpoints(doc)
+	}()
+	go func() {
+		defer wg.Done()
+		if lowMemory {
+			components = extractComponentsLazily(doc)
+		} else {
+			components = extractComponents(doc)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		webhooks = extractWebhooks(doc)
+	}()
+	wg.Wait()
+	done()
+
+	jsonSource := false
+	if doc.Index != nil {
+		jsonSource = isJSONDocument(doc.Index.GetRootNode())
+	}
 
 	return Model{
-		doc:          doc,
-		endpoints:    endpoints,
-		components:   components,
-		webhooks:     webhooks,
-		cursor:       0,
-		mode:         viewEndpoints,
-		width:        80,
-		height:       24,
-		showHelp:     false,
-		scrollOffset: 0,
+		doc:              doc,
+		endpoints:        endpoints,
+		components:       components,
+		webhooks:         webhooks,
+		cursor:           0,
+		mode:             viewEndpoints,
+		width:            80,
+		height:           24,
+		showHelp:         false,
+		jsonSource:       jsonSource,
+		detailOpts:       defaultDetailOptions,
+		keys:             defaultKeyMap(),
+		columns:          defaultColumns,
+		componentColumns: defaultComponentColumns,
+		scrollOffset:     0,
+		details:          make(map[detailKey]*formattedDetails),
+		pluginRuns:       make(map[string]*pluginRun),
+		layout:           &listLayout{},
+		lowMemory:        lowMemory,
 	}
 }
 
+// infoLines returns the info page split into lines, wrapped to the current width
+func (m *Model) infoLines() []string {
+	details := formatInfoDetails(m.doc, calculateContentWidth(m.width), m.detailOpts.expandExtensions) + formatSecurityReport(m.doc, m.allItems().endpoints)
+	return strings.Split(strings.TrimRight(details, "\n"), "\n")
+}
+
 func (m *Model) hasWebhooks() bool {
 	return len(m.webhooks) > 0
 }
 
 func (m Model) Init() tea.Cmd {
-	return nil
+	if m.workspace != nil {
+		return m.workspace.buildIndexCmd(!m.lowMemory)
+	}
+	return buildIndexCmd(m.allItems(), !m.lowMemory)
 }
 
+// Update handles a message, then runs the detail plugins on the items shown
+// and schedules on_select when another item is selected, if that hook is set
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	selected := m.selectedItem()
+	updated, cmd := m.update(msg)
+	next, ok := updated.(Model)
+	if !ok {
+		return updated, cmd
+	}
+	cmds := []tea.Cmd{cmd, next.runVisiblePlugins()}
+	if next.hooks.OnSelect != "" && next.selectedItem() != selected {
+		next.selections++
+		selections := next.selections
+		cmds = append(cmds, tea.Tick(selectSettleDelay, func(time.Time) tea.Msg { return selectSettledMsg{selections: selections} }))
+	}
+	return next, tea.Batch(cmds...)
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		// While the terminal is being resized, the details stay wrapped at the
+		// old width and are only wrapped again once the size settles
+		m.resizes++
+		if m.wrapWidth == 0 {
+			m.settleSize()
+		} else {
+			resizes := m.resizes
+			cmd = tea.Tick(resizeSettleDelay, func(time.Time) tea.Msg { return resizeSettledMsg{resizes: resizes} })
+		}
+
+	case resizeSettledMsg:
+		if msg.resizes == m.resizes {
+			m.settleSize()
+		}
+
+	case clipboardMsg:
+		if msg.err != nil {
+			m.message = "Copy failed: " + msg.err.Error()
+		} else {
+			m.message = "Copied " + msg.what
+			cmd = m.runHook(hookCopy, hookInput{Copied: msg.text})
+		}
+
+	case selectSettledMsg:
+		if msg.selections == m.selections {
+			cmd = m.runHook(hookSelect, hookInput{})
+		}
+
+	case hookMsg:
+		m.showHookResult(msg)
+
+	case editorMsg:
+		if msg.err != nil {
+			m.message = "Editor failed: " + msg.err.Error()
+		}
+
+	case pagerMsg:
+		if msg.err != nil {
+			m.message = "Pager failed: " + msg.err.Error()
+		}
+
+	case searchIndexMsg:
+		if m.workspace != nil {
+			m.workspace.index = msg.index
+		} else {
+			m.index = msg.index
+		}
+		if m.picker != nil && m.picker.action == pickerSearch {
+			m.picker.items = m.searchResults(m.picker.query)
+		}
+
+	case pluginMsg:
+		cmd = m.showPluginOutput(msg)
+
+	case pluginDetailsMsg:
+		m.showPluginDetails(msg)
+
+	case tryResponseMsg:
+		m.message = ""
+		m.response = &msg.response
+		m.responseTop = 0
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
-			if m.showHelp {
-				m.showHelp = false
-			} else {
-				return m, tea.Quit
-			}
+		m.message = ""
+		if m.picker != nil {
+			return m.updatePicker(msg)
+		}
+		if m.response != nil {
+			return m.updateResponse(msg)
+		}
+		if m.example != nil {
+			return m.updateExample(msg)
+		}
+		if m.showHelp {
+			return m.updateHelp(msg)
+		}
+		if m.prompting {
+			return m.updatePrompt(msg)
+		}
 
-		case "?":
-			m.showHelp = !m.showHelp
+		pending := ""
+		if time.Since(m.lastKeyAt) < keySequenceThreshold {
+			pending = m.lastKey
+		}
 
-		case "esc":
-			if m.showHelp {
-				m.showHelp = false
-			}
+		// Digits typed before a motion repeat it, e.g. 10j, unless bound to an action
+		if key := msg.String(); pending == "" && len(key) == 1 && key[0] >= '0' && key[0] <= '9' &&
+			(key != "0" || m.count > 0) && m.keys.actions[key] == "" {
+			m.count = min(m.count*10+int(key[0]-'0'), maxCount)
+			m.message = strconv.Itoa(m.count)
+			return m, nil
+		}
 
-		case "tab", "L":
-			if !m.showHelp {
-				// Cycle forward through available views
-				switch m.mode {
-				case viewEndpoints:
-					if m.hasWebhooks() {
-						m.mode = viewWebhooks
-					} else {
-						m.mode = viewComponents
-					}
-				case viewWebhooks:
+		// A key starting a sequence like "g d" is remembered until the next key press
+		act, prefix := m.keys.resolve(pending, msg.String())
+		m.lastKey = prefix
+		if prefix != "" {
+			m.lastKeyAt = time.Now()
+		}
+
+		// The count is kept across the keys of a sequence, e.g. 5gg
+		count, counted := max(1, m.count), m.count > 0
+		if prefix == "" {
+			m.count = 0
+		}
+
+		switch act {
+		case actionQuit:
+			return m, tea.Quit
+
+		case actionHelp:
+			m.showHelp = true
+			m.helpFilter, m.helpTop = "", 0
+
+		case actionCommand:
+			m.prompting, m.command = true, ""
+
+		case actionNextView:
+			m.pushHistory()
+
+			// Cycle forward through available views
+			switch m.mode {
+			case viewEndpoints:
+				if m.hasWebhooks() {
+					m.mode = viewWebhooks
+				} else {
 					m.mode = viewComponents
-				case viewComponents:
-					m.mode = viewEndpoints
 				}
-				m.cursor = 0
-				m.scrollOffset = 0
+			case viewWebhooks:
+				m.mode = viewComponents
+			case viewComponents:
+				m.mode = viewInfo
+			case viewInfo:
+				m.mode = viewEndpoints
 			}
-
-		case "shift+tab", "H":
-			if !m.showHelp {
-				// Cycle backwards through available views
-				switch m.mode {
-				case viewEndpoints:
-					m.mode = viewComponents
-				case viewWebhooks:
+			m.cursor = 0
+			m.scrollOffset = 0
+			m.hOffset = 0
+
+		case actionPrevView:
+			m.pushHistory()
+
+			// Cycle backwards through available views
+			switch m.mode {
+			case viewEndpoints:
+				m.mode = viewInfo
+			case viewInfo:
+				m.mode = viewComponents
+			case viewWebhooks:
+				m.mode = viewEndpoints
+			case viewComponents:
+				if m.hasWebhooks() {
+					m.mode = viewWebhooks
+				} else {
 					m.mode = viewEndpoints
-				case viewComponents:
-					if m.hasWebhooks() {
-						m.mode = viewWebhooks
-					} else {
-						m.mode = viewEndpoints
-					}
 				}
-				m.cursor = 0
-				m.scrollOffset = 0
 			}
+			m.cursor = 0
+			m.scrollOffset = 0
+			m.hOffset = 0
 
-		case "up", "k":
-			if !m.showHelp && m.cursor > 0 {
-				m.cursor--
+		case actionUp:
+			if m.cursor > 0 {
+				m.cursor = max(0, m.cursor-count)
 				m.ensureCursorVisible()
 			}
 
-		case "down", "j":
-			if !m.showHelp {
-				if m.cursor < m.getMaxItems() {
-					m.cursor++
-					m.ensureCursorVisible()
-				}
+		case actionDown:
+			if m.cursor < m.getMaxItems() {
+				m.cursor = min(m.cursor+count, m.getMaxItems())
+				m.ensureCursorVisible()
 			}
 
-		case "ctrl+d":
-			if !m.showHelp {
-				maxItems := m.getMaxItems()
-				newCursorPos := m.cursor + scrollHalfScreenLines
+		case actionPrevGroup:
+			for range count {
+				m.cursor = m.prevGroup()
+			}
+			m.ensureCursorVisible()
 
-				if newCursorPos > maxItems {
-					m.cursor = maxItems
-				} else {
-					m.cursor += scrollHalfScreenLines
-				}
+		case actionNextGroup:
+			for range count {
+				m.cursor = m.nextGroup()
+			}
+			m.ensureCursorVisible()
 
-				m.ensureCursorVisible()
+		case actionCenter:
+			m.centerCursor()
+
+		case actionStepUp:
+			m.cursor = max(0, m.cursor-navigationStep)
+			m.ensureCursorVisible()
+
+		case actionStepDown:
+			m.cursor = max(0, min(m.cursor+navigationStep, m.getMaxItems()))
+			m.ensureCursorVisible()
+
+		case actionHalfPageDown:
+			m.cursor = max(0, min(m.cursor+scrollHalfScreenLines, m.getMaxItems()))
+			m.ensureCursorVisible()
+
+		case actionHalfPageUp:
+			halfLines := max(1, calculateContentHeight(m.height)/2)
+			if m.cursor < halfLines {
+				m.cursor = 0
+			} else {
+				m.cursor -= halfLines
 			}
 
-		case "ctrl+u":
-			if !m.showHelp {
-				halfLines := max(1, calculateContentHeight(m.height)/2)
-				if m.cursor < halfLines {
-					m.cursor = 0
-				} else {
-					m.cursor -= halfLines
+			m.ensureCursorVisible()
+
+		case actionScrollLeft:
+			m.hOffset = max(0, m.hOffset-horizontalScrollStep)
+
+		case actionScrollRight:
+			maxOffset := max(0, lipgloss.Width(m.renderContent(m.height))-m.width)
+			m.hOffset = min(m.hOffset+horizontalScrollStep, maxOffset)
+
+		case actionPageUp:
+			m.pageUp()
+
+		case actionPageDown:
+			m.pageDown()
+
+		case actionBottom:
+			maxItems := m.getMaxItems()
+			if maxItems >= 0 {
+				m.pushHistory()
+				m.cursor = maxItems
+				// With a count, e.g. 5G, to that item instead
+				if counted {
+					m.cursor = min(count-1, maxItems)
 				}
+				m.ensureCursorVisible()
+			}
+
+		case actionTop:
+			m.pushHistory()
+			m.cursor = 0
+			if counted {
+				m.cursor = max(0, min(count-1, m.getMaxItems()))
+			}
+			m.ensureCursorVisible()
 
+		case actionGoToReference:
+			m.goToDefinition()
+
+		case actionExpandAll:
+			m.setAllFolded(false)
+
+		case actionCollapseAll:
+			m.setAllFolded(true)
+
+		case actionDeeper:
+			if m.detailOpts.schemaDepth < maxSchemaDepth {
+				m.detailOpts.schemaDepth++
 				m.ensureCursorVisible()
 			}
 
-		case "G":
-			if !m.showHelp {
-				maxItems := m.getMaxItems()
-				if maxItems >= 0 {
-					m.cursor = maxItems
-					m.ensureCursorVisible()
-				}
+		case actionShallower:
+			if m.detailOpts.schemaDepth > defaultSchemaDepth {
+				m.detailOpts.schemaDepth--
+				m.ensureCursorVisible()
 			}
 
-		case "g":
-			now := time.Now()
-			if m.lastKey == "g" && now.Sub(m.lastKeyAt) < keySequenceThreshold {
-				if !m.showHelp {
-					m.cursor = 0
-					m.ensureCursorVisible()
-				}
+		case actionExamples:
+			m.detailOpts.expandExamples = !m.detailOpts.expandExamples
+			m.ensureCursorVisible()
 
-				// reset, so "ggg" wouldn't be triggered
-				m.lastKey = ""
-				m.lastKeyAt = time.Time{}
+		case actionOpenExample:
+			m.openExample()
 
-			} else {
-				m.lastKey = "g"
-				m.lastKeyAt = now
+		case actionExtensions:
+			m.detailOpts.expandExtensions = !m.detailOpts.expandExtensions
+			m.ensureCursorVisible()
+
+		case actionBack:
+			m.navigateBack()
+
+		case actionForward:
+			m.navigateForward()
+
+		case actionEdit:
+			cmd = m.openInEditor()
+
+		case actionPager:
+			cmd = m.openInPager()
+
+		case actionOpenDocs:
+			switch link := m.currentExternalDocsURL(); {
+			case link == "":
+			case !isWebURL(link):
+				m.message = "Not opening " + link + ", only http and https links are opened"
+			default:
+				cmd = openInBrowser(link)
+			}
+
+		case actionYankPath, actionYankID, actionYankPointer:
+			cmd = m.yank(act)
+
+		case actionCurl:
+			if m.mode == viewEndpoints && m.cursor <= m.getMaxItems() {
+				cmd = copyCmd("curl command", curlCommand(m.sampleRequest(m.endpoints[m.cursor])))
 			}
 
-		case "enter", " ":
-			if !m.showHelp {
-				if m.mode == viewEndpoints && m.cursor < len(m.endpoints) {
-					m.endpoints[m.cursor].folded = !m.endpoints[m.cursor].folded
-				} else if m.mode == viewComponents && m.cursor < len(m.components) {
-					m.components[m.cursor].folded = !m.components[m.cursor].folded
-				} else if m.mode == viewWebhooks && m.cursor < len(m.webhooks) {
-					m.webhooks[m.cursor].folded = !m.webhooks[m.cursor].folded
+		case actionSnippet:
+			if m.mode == viewEndpoints && m.cursor <= m.getMaxItems() {
+				var items []pickerItem
+				for _, lang := range snippetLanguages {
+					items = append(items, pickerItem{label: lang.name, value: lang.name})
 				}
+				m.picker = &picker{
+					title:  "Copy as",
+					items:  items,
+					action: pickerCopySnippet,
+				}
+			}
+
+		case actionTry:
+			if m.mode == viewEndpoints && m.cursor <= m.getMaxItems() {
+				ep := m.endpoints[m.cursor]
+				req := m.sampleRequest(ep)
+				m.message = fmt.Sprintf("Sending %s %s%s", req.method, req.url, icons.ellipsis)
+				cmd = sendRequest(req, ep.op)
 			}
+
+		case actionEnvironment:
+			m.pickEnvironment()
+
+		case actionWhereUsed:
+			m.showUsages()
+
+		case actionProblems:
+			m.showProblems()
+
+		case actionExport:
+			if m.mode == viewEndpoints && m.cursor <= m.getMaxItems() {
+				m.pickExport(m.endpoints[m.cursor])
+			}
+
+		case actionSearch:
+			m.picker = &picker{title: "Search", action: pickerSearch}
+
+		case actionSpecs:
+			m.pickSpec()
+
+		case actionRawSource:
+			m.showRaw = !m.showRaw
+			m.ensureCursorVisible()
+
+		case actionDeprecated:
+			m.toggleDeprecatedOnly()
+
+		case actionToggle:
+			if m.mode == viewEndpoints && m.cursor < len(m.endpoints) {
+				m.endpoints[m.cursor].folded = !m.endpoints[m.cursor].folded
+			} else if m.mode == viewComponents && m.cursor < len(m.components) {
+				m.components[m.cursor].folded = !m.components[m.cursor].folded
+			} else if m.mode == viewWebhooks && m.cursor < len(m.webhooks) {
+				m.webhooks[m.cursor].folded = !m.webhooks[m.cursor].folded
+			}
+
+		default:
+			cmd = m.runPluginAction(act)
+		}
+	}
+
+	return m, cmd
+}
+
+// yank copies the path, operationId or JSON pointer of the selected item to the clipboard
+func (m *Model) yank(act action) tea.Cmd {
+	if m.cursor > m.getMaxItems() {
+		return nil
+	}
+
+	var name, operationID, pointer string
+	switch m.mode {
+	case viewEndpoints:
+		ep := m.endpoints[m.cursor]
+		name, operationID, pointer = ep.path, ep.op.OperationId, operationRef(ep.path, ep.method)
+	case viewWebhooks:
+		hook := m.webhooks[m.cursor]
+		name, operationID, pointer = hook.name, hook.op.OperationId, webhookRef(hook.name, hook.method)
+	case viewComponents:
+		comp := m.components[m.cursor]
+		name, pointer = comp.name, componentRef(comp.compType, comp.name)
+	default:
+		return nil
+	}
+
+	var text string
+	switch act {
+	case actionYankPath:
+		text = name
+	case actionYankID:
+		text = operationID
+	case actionYankPointer:
+		text = pointer
+	}
+
+	if text == "" {
+		m.message = "Nothing to copy"
+		return nil
+	}
+	return copyCmd(text, text)
+}
+
+// setAllFolded folds or unfolds every item in the current view
+func (m *Model) setAllFolded(folded bool) {
+	switch m.mode {
+	case viewEndpoints:
+		for i := range m.endpoints {
+			m.endpoints[i].folded = folded
+		}
+	case viewComponents:
+		for i := range m.components {
+			m.components[i].folded = folded
+		}
+	case viewWebhooks:
+		for i := range m.webhooks {
+			m.webhooks[i].folded = folded
+		}
+	}
+	m.ensureCursorVisible()
+}
+
+func (m Model) updatePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+
+	if m.picker.action == pickerSearch {
+		return m.updateSearch(msg)
+	}
+
+	var cmd tea.Cmd
+
+	act, _ := m.keys.resolve("", msg.String())
+	switch act {
+	case actionClose, actionQuit:
+		m.picker = nil
+
+	case actionUp:
+		if m.picker.cursor > 0 {
+			m.picker.cursor--
+		}
+
+	case actionDown:
+		if m.picker.cursor < len(m.picker.items)-1 {
+			m.picker.cursor++
+		}
+
+	case actionToggle:
+		p := m.picker
+		m.picker = nil
+		if p.cursor < len(p.items) {
+			cmd = m.runPickerAction(p.action, p.items[p.cursor].value)
 		}
 	}
 
+	return m, cmd
+}
+
+// updateSearch edits the query of the search, the arrows moving through the
+// results as letters like j and k are typed
+func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.picker
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.picker = nil
+	case tea.KeyUp:
+		p.cursor = max(0, p.cursor-1)
+	case tea.KeyDown:
+		p.cursor = max(0, min(p.cursor+1, len(p.items)-1))
+	case tea.KeyEnter:
+		m.picker = nil
+		if p.cursor < len(p.items) {
+			return m, m.runPickerAction(p.action, p.items[p.cursor].value)
+		}
+	case tea.KeyBackspace:
+		if runes := []rune(p.query); len(runes) > 0 {
+			p.query = string(runes[:len(runes)-1])
+			p.items, p.cursor = m.searchResults(p.query), 0
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		p.query += string(msg.Runes)
+		p.items, p.cursor = m.searchResults(p.query), 0
+	}
+	return m, nil
+}
+
+// searchResults lists the items matching a query, none until the index is built
+func (m Model) searchResults(query string) []pickerItem {
+	index := m.searchIndex()
+	if index == nil {
+		return nil
+	}
+	var items []pickerItem
+	for _, entry := range index.search(query) {
+		items = append(items, pickerItem{label: entry.label, value: entry.ref})
+	}
+	return items
+}
+
+// sampleRequest builds the sample request of an endpoint for the active environment
+func (m *Model) sampleRequest(ep endpoint) sampleRequest {
+	req := buildSampleRequest(m.doc, ep)
+	if len(m.headers) > 0 {
+		req = req.withEnvironment(environment{Headers: m.headers})
+	}
+	if env, ok := m.environments[m.environment]; ok {
+		req = req.withEnvironment(env)
+	}
+	return req
+}
+
+// pickEnvironment asks which environment requests are sent to
+func (m *Model) pickEnvironment() {
+	if len(m.environments) == 0 {
+		m.message = "No environments configured"
+		return
+	}
+
+	var names []string
+	for name := range m.environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	items := []pickerItem{{label: "none (servers of the spec)", value: ""}}
+	cursor := 0
+	for i, name := range names {
+		label := name
+		if env := m.environments[name]; env.BaseURL != "" {
+			label += " (" + env.BaseURL + ")"
+		}
+		items = append(items, pickerItem{label: label, value: name})
+		if name == m.environment {
+			cursor = i + 1
+		}
+	}
+
+	m.picker = &picker{
+		title:  "Environment",
+		items:  items,
+		cursor: cursor,
+		action: pickerSelectEnvironment,
+	}
+}
+
+// pickExport asks whether to export the endpoint or one of its tags, and in which format
+func (m *Model) pickExport(ep endpoint) {
+	var items []pickerItem
+	for _, format := range []string{exportPostman, exportInsomnia} {
+		label := "Postman"
+		if format == exportInsomnia {
+			label = "Insomnia"
+		}
+
+		items = append(items, pickerItem{
+			label: fmt.Sprintf("%s: %s %s", label, ep.method, ep.path),
+			value: format,
+		})
+		for _, tag := range ep.op.Tags {
+			items = append(items, pickerItem{
+				label: fmt.Sprintf("%s: tag %s (%s)", label, tag, pluralize(len(m.taggedEndpoints(tag)), "endpoint")),
+				value: format + "|" + tag,
+			})
+		}
+	}
+
+	m.picker = &picker{
+		title:  "Export",
+		items:  items,
+		action: pickerExport,
+	}
+}
+
+// export writes the selected endpoint, or all endpoints with a tag, to a file.
+// value is the format, optionally followed by "|" and the tag.
+func (m *Model) export(value string) tea.Cmd {
+	format, tag, byTag := strings.Cut(value, "|")
+
+	var endpoints []endpoint
+	var title, fileBase string
+	if byTag {
+		endpoints = m.taggedEndpoints(tag)
+		title = m.doc.Info.Title + " - " + tag
+		fileBase = tag
+	} else {
+		ep := m.endpoints[m.cursor]
+		endpoints = []endpoint{ep}
+		title = m.doc.Info.Title
+		fileBase = ep.op.OperationId
+		if fileBase == "" {
+			fileBase = ep.method + " " + ep.path
+		}
+	}
+
+	var requests []exportRequest
+	for _, ep := range endpoints {
+		requests = append(requests, exportRequest{name: endpointTitle(ep), req: buildSampleRequest(m.doc, ep)})
+	}
+
+	file, err := writeExport(format, title, fileBase, requests)
+	if err != nil {
+		m.message = "Export failed: " + err.Error()
+		return nil
+	}
+	m.message = "Exported to " + file
+	return m.runHook(hookExport, hookInput{Export: file, Format: format})
+}
+
+// taggedEndpoints returns the endpoints with the given tag, filtered or not
+func (m *Model) taggedEndpoints(tag string) []endpoint {
+	var endpoints []endpoint
+	for _, ep := range m.allItems().endpoints {
+		if slices.Contains(ep.op.Tags, tag) {
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return endpoints
+}
+
+// updateResponse handles keys while a Try-it response is shown
+func (m Model) updateResponse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+
+	act, _ := m.keys.resolve("", msg.String())
+	switch act {
+	case actionClose, actionQuit, actionTry:
+		m.response = nil
+
+	case actionUp:
+		m.responseTop = max(0, m.responseTop-1)
+
+	case actionDown:
+		m.responseTop = min(m.responseTop+1, max(0, len(m.responseBodyLines())-1))
+	}
+
 	return m, nil
 }
 
+func (m *Model) runPickerAction(action pickerAction, value string) tea.Cmd {
+	switch action {
+	case pickerGoToReference:
+		m.jumpToReference(value)
+	case pickerSearch:
+		if m.workspace != nil {
+			m.jumpToWorkspaceReference(value)
+		} else {
+			m.jumpToReference(value)
+		}
+	case pickerSwitchSpec:
+		if i, err := strconv.Atoi(value); err == nil && i < len(m.workspace.specs) {
+			m.switchSpec(i)
+		}
+	case pickerCopySnippet:
+		if lang, ok := findSnippetLanguage(value); ok && m.cursor <= m.getMaxItems() {
+			return copyCmd(lang.name+" snippet", lang.render(m.sampleRequest(m.endpoints[m.cursor])))
+		}
+	case pickerExport:
+		return m.export(value)
+	case pickerOpenExample:
+		examples := m.currentExamples()
+		if i, err := strconv.Atoi(value); err == nil && i < len(examples) {
+			m.example = &exampleViewer{item: examples[i]}
+		}
+	case pickerSelectEnvironment:
+		m.environment = value
+		if value == "" {
+			m.message = "Using the servers of the spec"
+		} else {
+			m.message = "Switched to " + value
+		}
+	}
+	return nil
+}
+
+// currentReferences returns the component references of the selected item
+func (m *Model) currentReferences() []string {
+	if m.cursor > m.getMaxItems() {
+		return nil
+	}
+
+	switch m.mode {
+	case viewEndpoints:
+		return operationReferences(m.endpoints[m.cursor].op)
+	case viewComponents:
+		return componentReferences(m.components[m.cursor])
+	case viewWebhooks:
+		return operationReferences(m.webhooks[m.cursor].op)
+	}
+	return nil
+}
+
+// goToDefinition jumps to the component or linked operation referenced by the selected item,
+// asking the user to choose when there is more than one
+func (m *Model) goToDefinition() {
+	var items []pickerItem
+	for _, ref := range m.currentReferences() {
+		compType, name, ok := parseComponentRef(ref)
+		if !ok || !slices.ContainsFunc(m.allItems().components, func(comp component) bool {
+			return comp.compType == compType && comp.name == name
+		}) {
+			continue
+		}
+		items = append(items, pickerItem{label: compType + ": " + name, value: ref})
+	}
+
+	if m.mode == viewEndpoints && m.cursor <= m.getMaxItems() {
+		items = append(items, m.linkTargets(m.endpoints[m.cursor].op)...)
+	}
+
+	switch len(items) {
+	case 0:
+		return
+	case 1:
+		m.jumpToReference(items[0].value)
+	default:
+		m.picker = &picker{
+			title:  "Go to definition",
+			items:  items,
+			action: pickerGoToReference,
+		}
+	}
+}
+
+func (m *Model) findComponent(compType, name string) int {
+	for i, comp := range m.components {
+		if comp.compType == compType && comp.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// linkTargets returns the operations linked from the responses of op
+func (m *Model) linkTargets(op *v3.Operation) []pickerItem {
+	if op == nil || op.Responses == nil {
+		return nil
+	}
+
+	var responses []*v3.Response
+	if op.Responses.Codes != nil {
+		var codes []string
+		for pair := op.Responses.Codes.First(); pair != nil; pair = pair.Next() {
+			codes = append(codes, pair.Key())
+		}
+		sortResponseCodes(codes)
+		for _, code := range codes {
+			resp, _ := op.Responses.Codes.Get(code)
+			responses = append(responses, resp)
+		}
+	}
+	responses = append(responses, op.Responses.Default)
+
+	var items []pickerItem
+	seen := make(map[string]bool)
+	for _, resp := range responses {
+		if resp == nil || resp.Links == nil {
+			continue
+		}
+		for pair := resp.Links.First(); pair != nil; pair = pair.Next() {
+			idx := m.findLinkedEndpoint(pair.Value())
+			if idx < 0 {
+				continue
+			}
+			ep := m.endpoints[idx]
+			ref := operationRef(ep.path, ep.method)
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+			items = append(items, pickerItem{
+				label: fmt.Sprintf("Link %s: %s %s", pair.Key(), ep.method, ep.path),
+				value: ref,
+			})
+		}
+	}
+
+	return items
+}
+
+// findLinkedEndpoint resolves the operation a link points to, by operationId or operationRef
+func (m *Model) findLinkedEndpoint(link *v3.Link) int {
+	if link == nil {
+		return -1
+	}
+
+	if link.OperationId != "" {
+		for i, ep := range m.endpoints {
+			if ep.op.OperationId == link.OperationId {
+				return i
+			}
+		}
+		return -1
+	}
+
+	path, method, ok := parseOperationRef(link.OperationRef)
+	if !ok {
+		return -1
+	}
+	return m.findEndpoint(path, method)
+}
+
+func (m *Model) findEndpoint(path, method string) int {
+	for i, ep := range m.endpoints {
+		if ep.path == path && ep.method == method {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Model) findWebhook(name, method string) int {
+	for i, hook := range m.webhooks {
+		if hook.name == name && hook.method == method {
+			return i
+		}
+	}
+	return -1
+}
+
+// jumpToReference jumps to a component or an operation reference. A target hidden
+// by the deprecated filter turns the filter off.
+func (m *Model) jumpToReference(ref string) bool {
+	if m.jumpTo(ref) {
+		return true
+	}
+	if m.unfiltered != nil {
+		m.showAllItems()
+		return m.jumpTo(ref)
+	}
+	return false
+}
+
+func (m *Model) jumpTo(ref string) bool {
+	if _, _, ok := parseOperationRef(ref); ok {
+		return m.jumpToOperation(ref)
+	}
+	if _, _, ok := parseWebhookRef(ref); ok {
+		return m.jumpToWebhook(ref)
+	}
+	return m.jumpToComponent(ref)
+}
+
+// jumpToWebhook switches to the webhooks view with the referenced webhook selected and unfolded
+func (m *Model) jumpToWebhook(ref string) bool {
+	name, method, _ := parseWebhookRef(ref)
+
+	idx := m.findWebhook(name, method)
+	if idx < 0 {
+		return false
+	}
+
+	m.pushHistory()
+	m.mode = viewWebhooks
+	m.cursor = idx
+	m.webhooks[idx].folded = false
+	m.ensureCursorVisible()
+
+	return true
+}
+
+// jumpToOperation switches to the endpoints view with the referenced operation selected and unfolded
+func (m *Model) jumpToOperation(ref string) bool {
+	path, method, ok := parseOperationRef(ref)
+	if !ok {
+		return false
+	}
+
+	idx := m.findEndpoint(path, method)
+	if idx < 0 {
+		return false
+	}
+
+	m.pushHistory()
+	m.mode = viewEndpoints
+	m.cursor = idx
+	m.endpoints[idx].folded = false
+	m.ensureCursorVisible()
+
+	return true
+}
+
+// jumpToComponent switches to the components view with the referenced component selected and unfolded
+func (m *Model) jumpToComponent(ref string) bool {
+	compType, name, ok := parseComponentRef(ref)
+	if !ok {
+		return false
+	}
+
+	idx := m.findComponent(compType, name)
+	if idx < 0 {
+		return false
+	}
+
+	m.pushHistory()
+	m.mode = viewComponents
+	m.cursor = idx
+	m.components[idx].folded = false
+	m.ensureCursorVisible()
+
+	return true
+}
+
 // truncateContent ensures content doesn't exceed the available lines
 func (m Model) truncateContent(content string, maxLines int) string {
 	lines := strings.Split(content, "\n")
@@ -388,12 +1600,31 @@ func (m Model) truncateContent(content string, maxLines int) string {
 
 	// Truncate to fit and add an indicator
 	truncatedLines := lines[:maxLines-1]
-	truncatedLines = append(truncatedLines, "⬇ Content truncated to fit viewport...")
+	truncatedLines = append(truncatedLines, icons.below+" Content truncated to fit viewport...")
 
 	return strings.Join(truncatedLines, "\n")
 }
 
+// renderContent renders the current view. Lists stop a line past maxLines, the
+// rest being cut by View anyway.
+func (m Model) renderContent(maxLines int) string {
+	switch m.mode {
+	case viewEndpoints:
+		return m.renderEndpoints(maxLines)
+	case viewComponents:
+		return m.renderComponents(maxLines)
+	case viewWebhooks:
+		return m.renderWebhooks(maxLines)
+	case viewInfo:
+		return m.renderInfo()
+	}
+	return ""
+}
+
 func (m Model) View() string {
+	done := profile.firstRender()
+	defer done()
+
 	var s strings.Builder
 
 	header := m.renderHeader()
@@ -408,16 +1639,12 @@ func (m Model) View() string {
 		availableContentLines = 1
 	}
 
-	// Render content
-	var content string
-	switch m.mode {
-	case viewEndpoints:
-		content = m.renderEndpoints()
-	case viewComponents:
-		content = m.renderComponents()
-	case viewWebhooks:
-		content = m.renderWebhooks()
+	// Render content, scrolled horizontally
+	lines := strings.Split(m.renderContent(availableContentLines), "\n")
+	for i, line := range lines {
+		lines[i] = m.clipLine(line)
 	}
+	content := strings.Join(lines, "\n")
 
 	// Truncate content if it's too long
 	content = m.truncateContent(content, availableContentLines)
@@ -437,9 +1664,24 @@ func (m Model) View() string {
 
 	baseView := s.String()
 
-	if m.showHelp {
-		return m.renderHelpModal()
+	view, modal := baseView, true
+	switch {
+	case m.showHelp:
+		view = m.renderHelpModal()
+	case m.picker != nil:
+		view = m.renderPicker()
+	case m.response != nil:
+		view = m.renderResponse()
+	case m.example != nil:
+		view = m.renderExample()
+	default:
+		modal = false
 	}
 
-	return baseView
+	// Modals may be taller than the terminal. The size is unknown until the
+	// first resize message.
+	if m.width > 0 && (m.minimalLayout() || modal) {
+		return m.fitWindow(view)
+	}
+	return view
 }
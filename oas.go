@@ -2,12 +2,20 @@ package main
 
 import (
 	"fmt"
+	"net/url"
+	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
+	"github.com/charmbracelet/x/ansi"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"go.yaml.in/yaml/v4"
 )
 
 // sortResponseCodes sorts HTTP response codes with stable ordering:
@@ -74,15 +82,27 @@ func extractEndpoints(doc *v3.Document) []endpoint {
 		}
 	}
 
-	// Sort endpoints for stable ordering: first by path, then by method
+	for i := range endpoints {
+		endpoints[i].source = endpoints[i].op
+		if pathItem, ok := doc.Paths.PathItems.Get(endpoints[i].path); ok && pathItem != nil {
+			endpoints[i].op = withPathParameters(endpoints[i].op, pathItem.Parameters)
+		}
+		endpoints[i].security = effectiveSecurity(doc, endpoints[i].op)
+		endpoints[i].deprecation = operationDeprecation(endpoints[i].op)
+	}
+
+	sortEndpoints(endpoints)
+	return endpoints
+}
+
+// sortEndpoints sorts endpoints for stable ordering: first by path, then by method
+func sortEndpoints(endpoints []endpoint) {
 	sort.Slice(endpoints, func(i, j int) bool {
 		if endpoints[i].path != endpoints[j].path {
 			return endpoints[i].path < endpoints[j].path
 		}
 		return endpoints[i].method < endpoints[j].method
 	})
-
-	return endpoints
 }
 
 func extractWebhooks(doc *v3.Document) []webhook {
@@ -121,6 +141,15 @@ func extractWebhooks(doc *v3.Document) []webhook {
 		}
 	}
 
+	for i := range webhooks {
+		webhooks[i].source = webhooks[i].op
+		if hook, ok := doc.Webhooks.Get(webhooks[i].name); ok && hook != nil {
+			webhooks[i].op = withPathParameters(webhooks[i].op, hook.Parameters)
+		}
+		webhooks[i].security = effectiveSecurity(doc, webhooks[i].op)
+		webhooks[i].deprecation = operationDeprecation(webhooks[i].op)
+	}
+
 	// Sort webhooks for stable ordering: first by name, then by method
 	sort.Slice(webhooks, func(i, j int) bool {
 		if webhooks[i].name != webhooks[j].name {
@@ -132,25 +161,83 @@ func extractWebhooks(doc *v3.Document) []webhook {
 	return webhooks
 }
 
+// withPathParameters returns the operation with the parameters of its path item,
+// which apply to every operation of the path. A parameter of the operation with the
+// same name and location overrides the shared one. The document is left untouched.
+func withPathParameters(op *v3.Operation, shared []*v3.Parameter) *v3.Operation {
+	if len(shared) == 0 {
+		return op
+	}
+
+	var params []*v3.Parameter
+	for _, param := range shared {
+		if param == nil {
+			continue
+		}
+		overridden := slices.ContainsFunc(op.Parameters, func(own *v3.Parameter) bool {
+			return own != nil && own.Name == param.Name && own.In == param.In
+		})
+		if !overridden {
+			params = append(params, param)
+		}
+	}
+
+	merged := *op
+	merged.Parameters = append(params, op.Parameters...)
+	return &merged
+}
+
+// effectiveSecurity resolves the security requirements of an operation. An operation
+// without a security section inherits the global one, while an empty section
+// explicitly removes it.
+func effectiveSecurity(doc *v3.Document, op *v3.Operation) security {
+	if op.Security != nil {
+		return security{requirements: op.Security}
+	}
+	return security{requirements: doc.Security, inherited: true}
+}
+
+// parallelEach calls fn for 0 to n-1, spreading the calls over the CPUs
+func parallelEach(n int, fn func(i int)) {
+	workers := min(runtime.GOMAXPROCS(0), n)
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int(next.Add(1)) - 1; i < n; i = int(next.Add(1)) - 1 {
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 func extractComponents(doc *v3.Document) []component {
+	return readComponents(doc, describeSchema)
+}
+
+// extractComponentsLazily lists the components without building the schemas,
+// which are only built when shown. Their descriptions and deprecations are read
+// from the source.
+func extractComponentsLazily(doc *v3.Document) []component {
+	return readComponents(doc, describeSchemaSource)
+}
+
+func readComponents(doc *v3.Document, describe func(*base.SchemaProxy) (string, deprecation)) []component {
 	var components []component
 
 	if doc.Components != nil {
 		if doc.Components.Schemas != nil {
 			for pair := doc.Components.Schemas.First(); pair != nil; pair = pair.Next() {
 				name := pair.Key()
-				schema := pair.Value()
-				details := formatSchemaDetails(schema)
-				description := ""
-				if schema != nil && schema.Schema() != nil && schema.Schema().Description != "" {
-					description = schema.Schema().Description
-				}
+				// The description is read below, with the other schemas built in parallel
 				components = append(components, component{
-					name:        name,
-					compType:    "Schema",
-					description: description,
-					details:     details,
-					folded:      true,
+					name:     name,
+					compType: "Schema",
+					source:   pair.Value(),
+					folded:   true,
 				})
 			}
 		}
@@ -158,7 +245,6 @@ func extractComponents(doc *v3.Document) []component {
 			for pair := doc.Components.RequestBodies.First(); pair != nil; pair = pair.Next() {
 				name := pair.Key()
 				reqBody := pair.Value()
-				details := formatRequestBodyDetails(reqBody)
 				description := ""
 				if reqBody != nil && reqBody.Description != "" {
 					description = reqBody.Description
@@ -167,7 +253,7 @@ func extractComponents(doc *v3.Document) []component {
 					name:        name,
 					compType:    "RequestBody",
 					description: description,
-					details:     details,
+					source:      reqBody,
 					folded:      true,
 				})
 			}
@@ -176,7 +262,6 @@ func extractComponents(doc *v3.Document) []component {
 			for pair := doc.Components.Responses.First(); pair != nil; pair = pair.Next() {
 				name := pair.Key()
 				resp := pair.Value()
-				details := formatResponseDetails(resp)
 				description := ""
 				if resp != nil && resp.Description != "" {
 					description = resp.Description
@@ -185,7 +270,7 @@ func extractComponents(doc *v3.Document) []component {
 					name:        name,
 					compType:    "Response",
 					description: description,
-					details:     details,
+					source:      resp,
 					folded:      true,
 				})
 			}
@@ -194,7 +279,6 @@ func extractComponents(doc *v3.Document) []component {
 			for pair := doc.Components.Parameters.First(); pair != nil; pair = pair.Next() {
 				name := pair.Key()
 				param := pair.Value()
-				details := formatParameterDetails(param)
 				description := ""
 				if param != nil && param.Description != "" {
 					description = param.Description
@@ -203,7 +287,7 @@ func extractComponents(doc *v3.Document) []component {
 					name:        name,
 					compType:    "Parameter",
 					description: description,
-					details:     details,
+					source:      param,
 					folded:      true,
 				})
 			}
@@ -212,7 +296,6 @@ func extractComponents(doc *v3.Document) []component {
 			for pair := doc.Components.Headers.First(); pair != nil; pair = pair.Next() {
 				name := pair.Key()
 				header := pair.Value()
-				details := formatHeaderDetails(header)
 				description := ""
 				if header != nil && header.Description != "" {
 					description = header.Description
@@ -221,7 +304,7 @@ func extractComponents(doc *v3.Document) []component {
 					name:        name,
 					compType:    "Header",
 					description: description,
-					details:     details,
+					source:      header,
 					folded:      true,
 				})
 			}
@@ -230,7 +313,6 @@ func extractComponents(doc *v3.Document) []component {
 			for pair := doc.Components.SecuritySchemes.First(); pair != nil; pair = pair.Next() {
 				name := pair.Key()
 				secScheme := pair.Value()
-				details := formatSecuritySchemeDetails(name, secScheme)
 				description := ""
 				if secScheme != nil && secScheme.Description != "" {
 					description = secScheme.Description
@@ -239,128 +321,604 @@ func extractComponents(doc *v3.Document) []component {
 					name:        name,
 					compType:    "SecurityScheme",
 					description: description,
-					details:     details,
+					source:      secScheme,
 					folded:      true,
 				})
 			}
 		}
 	}
 
-	// Sort components for stable ordering: first by type, then by name
+	// Building a schema from the document is the slow part of loading big specs
+	parallelEach(len(components), func(i int) {
+		if proxy, ok := components[i].source.(*base.SchemaProxy); ok {
+			components[i].description, components[i].deprecation = describe(proxy)
+			return
+		}
+		components[i].deprecation = componentDeprecation(components[i].source)
+	})
+
+	sortComponents(components)
+	return components
+}
+
+// sortComponents sorts components for stable ordering: first by type, then by name
+func sortComponents(components []component) {
 	sort.Slice(components, func(i, j int) bool {
 		if components[i].compType != components[j].compType {
 			return components[i].compType < components[j].compType
 		}
 		return components[i].name < components[j].name
 	})
+}
 
-	return components
+func describeSchema(proxy *base.SchemaProxy) (string, deprecation) {
+	schema := proxy.Schema()
+	if schema == nil {
+		return "", deprecation{}
+	}
+	return schema.Description, schemaDeprecation(schema)
 }
 
+// describeSchemaSource reads the description and deprecation of a schema from
+// its YAML node. A $ref isn't followed, leaving both empty.
+func describeSchemaSource(proxy *base.SchemaProxy) (string, deprecation) {
+	node := proxy.GetValueNode()
+	description := ""
+	if _, value := field(node, "description"); value != nil && value.Kind == yaml.ScalarNode {
+		description = value.Value
+	}
+	_, flag := field(node, "deprecated")
+	extensions := orderedmap.New[string, *yaml.Node]()
+	eachField(node, func(key, value *yaml.Node) {
+		if strings.HasPrefix(key.Value, "x-") {
+			extensions.Set(key.Value, value)
+		}
+	})
+	return description, newDeprecation(flag != nil && flag.Value == "true", extensions)
+}
+
+// detailOptions control how much is shown in the detail sections
+type detailOptions struct {
+	// schemaDepth is how many levels of nested schema properties are shown
+	schemaDepth int
+	// expandExamples shows examples in full instead of truncating them
+	expandExamples bool
+	// expandExtensions pretty-prints structured x- extension values
+	expandExtensions bool
+	// width is the column descriptions are wrapped at, 0 disables wrapping
+	width int
+	// hidden are the sections left out of the details of operations
+	hidden detailSection
+}
+
+// detailSection is a section of the details of an operation, which the config
+// can hide. Sections are bits, so options stay comparable.
+type detailSection uint
+
+const (
+	sectionSummary detailSection = 1 << iota
+	sectionDescription
+	sectionOperationID
+	sectionExternalDocs
+	sectionSecurity
+	sectionParameters
+	sectionRequestBody
+	sectionResponses
+	sectionCallbacks
+	sectionExtensions
+)
+
+// detailSectionNames are the names of the sections in the config, in the
+// order they are shown
+var detailSectionNames = []struct {
+	name    string
+	section detailSection
+}{
+	{"summary", sectionSummary},
+	{"description", sectionDescription},
+	{"operation_id", sectionOperationID},
+	{"external_docs", sectionExternalDocs},
+	{"security", sectionSecurity},
+	{"parameters", sectionParameters},
+	{"request_body", sectionRequestBody},
+	{"responses", sectionResponses},
+	{"callbacks", sectionCallbacks},
+	{"extensions", sectionExtensions},
+}
+
+// shows tells whether a section is part of the details
+func (o detailOptions) shows(section detailSection) bool {
+	return o.hidden&section == 0
+}
+
+var defaultDetailOptions = detailOptions{schemaDepth: defaultSchemaDepth}
+
 func formatEndpointDetails(ep endpoint) string {
+	return formatEndpointDetailsWithOptions(ep, defaultDetailOptions)
+}
+
+// formatEndpointDetailsWithOptions formats an endpoint, expanding request body schemas
+// into a property tree when the schema depth is greater than the default
+func formatEndpointDetailsWithOptions(ep endpoint, opts detailOptions) string {
 	var details strings.Builder
 
-	if ep.op.Summary != "" {
-		details.WriteString(fmt.Sprintf("Summary: %s\n", ep.op.Summary))
+	details.WriteString(ep.deprecation.details())
+
+	if ep.op.Summary != "" && opts.shows(sectionSummary) {
+		writeWrapped(&details, "Summary: ", ep.op.Summary, "  ", opts.width)
+	}
+
+	if ep.op.Description != "" && opts.shows(sectionDescription) {
+		writeWrapped(&details, "Description: ", ep.op.Description, "  ", opts.width)
+	}
+
+	if ep.op.ExternalDocs != nil && opts.shows(sectionExternalDocs) {
+		details.WriteString(fmt.Sprintf("External Docs: %s\n", externalDocsText(ep.op.ExternalDocs)))
 	}
 
-	if ep.op.Description != "" {
-		details.WriteString(fmt.Sprintf("Description: %s\n", ep.op.Description))
+	if opts.shows(sectionSecurity) {
+		details.WriteString(formatSecurity(ep.security))
 	}
 
-	if len(ep.op.Parameters) > 0 {
-		details.WriteString("Parameters:\n")
-		for _, param := range ep.op.Parameters {
-			if param != nil {
-				details.WriteString(fmt.Sprintf("  - %s (%s): %s\n",
-					param.Name, param.In, param.Description))
+	if opts.shows(sectionParameters) {
+		writeParameters(&details, ep.op.Parameters, opts)
+	}
+
+	if opts.shows(sectionRequestBody) {
+		writeRequestBody(&details, ep.op.RequestBody, opts)
+	}
+
+	if ep.op.Responses != nil && opts.shows(sectionResponses) {
+		details.WriteString("Responses:\n")
+		writeResponses(&details, ep.op.Responses, "  ", opts)
+	}
+
+	if opts.shows(sectionCallbacks) {
+		writeCallbacks(&details, ep.op.Callbacks, opts)
+	}
+
+	if opts.shows(sectionExtensions) {
+		details.WriteString(formatExtensions(ep.op.Extensions, "", opts.expandExtensions))
+	}
+
+	return details.String()
+}
+
+// parameterLocations are the values of "in", in the order parameters are listed
+var parameterLocations = []string{"path", "query", "header", "cookie"}
+
+// writeParameters lists parameters grouped by where they go in the request, each
+// with its flags, schema, serialization and example
+func writeParameters(details *strings.Builder, params []*v3.Parameter, opts detailOptions) {
+	if len(params) == 0 {
+		return
+	}
+
+	locations := slices.Clone(parameterLocations)
+	for _, param := range params {
+		if param != nil && !slices.Contains(locations, param.In) {
+			locations = append(locations, param.In)
+		}
+	}
+
+	details.WriteString("Parameters:\n")
+	for _, in := range locations {
+		first := true
+		for _, param := range params {
+			if param == nil || param.In != in {
+				continue
+			}
+			if first {
+				details.WriteString(fmt.Sprintf("  %s:\n", titleCase(in)))
+				first = false
 			}
+
+			var flags []string
+			if param.Required != nil && *param.Required {
+				flags = append(flags, "required")
+			}
+			if newDeprecation(param.Deprecated, param.Extensions).deprecated {
+				flags = append(flags, "deprecated")
+			}
+			prefix := "    - " + param.Name
+			if len(flags) > 0 {
+				prefix += " (" + strings.Join(flags, ", ") + ")"
+			}
+			writeWrapped(details, prefix+": ", param.Description, "      ", opts.width)
+
+			if param.Schema != nil {
+				details.WriteString(fmt.Sprintf("      Schema: %s\n", schemaSummary(param.Schema)))
+			}
+			if param.Content != nil && param.Content.Len() > 0 {
+				details.WriteString("      Content:\n")
+				writeContent(details, param.Content, "        ", opts)
+			}
+			if style := parameterStyle(param); style != "" {
+				details.WriteString(fmt.Sprintf("      Style: %s\n", style))
+			}
+
+			writeExamples(details, param.Example, param.Examples, "      ", opts.expandExamples)
+		}
+	}
+}
+
+// parameterStyle describes how a parameter is serialized, e.g. "form, explode: false".
+// It is empty when the spec relies on the defaults.
+func parameterStyle(param *v3.Parameter) string {
+	if param.Style == "" && param.Explode == nil {
+		return ""
+	}
+
+	style := param.Style
+	if style == "" {
+		style = defaultParameterStyle(param.In)
+	}
+	// Only the form style explodes by default
+	explode := style == "form"
+	if param.Explode != nil {
+		explode = *param.Explode
+	}
+	return fmt.Sprintf("%s, explode: %t", style, explode)
+}
+
+// defaultParameterStyle is the style a parameter uses when it doesn't set one
+func defaultParameterStyle(in string) string {
+	switch in {
+	case "query", "cookie":
+		return "form"
+	}
+	return "simple"
+}
+
+// titleCase capitalizes the first letter of a word, e.g. "Query" for "query"
+func titleCase(word string) string {
+	if word == "" {
+		return word
+	}
+	return strings.ToUpper(word[:1]) + word[1:]
+}
+
+// writeCallbacks lists the callbacks of an operation: each runtime expression with
+// the operations the API will call on it, their request bodies and expected responses
+func writeRequestBody(details *strings.Builder, body *v3.RequestBody, opts detailOptions) {
+	if body == nil {
+		return
+	}
+
+	details.WriteString("Request Body:\n")
+
+	if body.Description != "" {
+		writeWrapped(details, "  Description: ", body.Description, "    ", opts.width)
+	}
+
+	if body.Required != nil && *body.Required {
+		details.WriteString("  Required: true\n")
+	}
+
+	writeContent(details, body.Content, "  ", opts)
+}
+
+// writeResponses writes each response of an operation, ordered by status code
+// with the default response last
+func writeResponses(details *strings.Builder, responses *v3.Responses, indent string, opts detailOptions) {
+	var codes []string
+	if responses.Codes != nil {
+		for pair := responses.Codes.First(); pair != nil; pair = pair.Next() {
+			codes = append(codes, pair.Key())
 		}
 	}
 
-	if ep.op.RequestBody != nil {
-		details.WriteString("Request Body:\n")
+	// Sort by status code numerically, then alphabetically for non-numeric codes
+	sortResponseCodes(codes)
 
-		if ep.op.RequestBody.Description != "" {
-			details.WriteString(fmt.Sprintf("  Description: %s\n", ep.op.RequestBody.Description))
+	for _, code := range codes {
+		if resp, ok := responses.Codes.Get(code); ok && resp != nil {
+			writeResponse(details, code, resp, indent, opts)
 		}
+	}
 
-		if ep.op.RequestBody.Required != nil && *ep.op.RequestBody.Required {
-			details.WriteString("  Required: true\n")
+	if responses.Default != nil {
+		writeResponse(details, "default", responses.Default, indent, opts)
+	}
+}
+
+func writeCallbacks(details *strings.Builder, callbacks *orderedmap.Map[string, *v3.Callback], opts detailOptions) {
+	if callbacks == nil || callbacks.Len() == 0 {
+		return
+	}
+
+	details.WriteString("Callbacks:\n")
+	for pair := callbacks.First(); pair != nil; pair = pair.Next() {
+		details.WriteString(fmt.Sprintf("  - %s\n", pair.Key()))
+
+		callback := pair.Value()
+		if callback == nil || callback.Expression == nil {
+			continue
 		}
 
-		// Get media types and sort them for stable ordering
-		var mediaTypes []string
-		if ep.op.RequestBody.Content != nil {
-			for pair := ep.op.RequestBody.Content.First(); pair != nil; pair = pair.Next() {
-				mediaTypes = append(mediaTypes, pair.Key())
+		for expr := callback.Expression.First(); expr != nil; expr = expr.Next() {
+			details.WriteString(fmt.Sprintf("    %s\n", expr.Key()))
+			if expr.Value() == nil {
+				continue
 			}
-		}
-		sort.Strings(mediaTypes)
 
-		// Display media type with schema information - either a reference to a component schema
-		// (e.g., "#/components/schemas/Pet") or an inline schema type (e.g., "object", "string")
-		for _, mediaType := range mediaTypes {
-			if mediaTypeObj, ok := ep.op.RequestBody.Content.Get(mediaType); ok && mediaTypeObj != nil {
-				details.WriteString(fmt.Sprintf("  - %s", mediaType))
-				if mediaTypeObj.Schema != nil {
-					// Check if it's a reference first
-					if mediaTypeObj.Schema.GetReference() != "" {
-						ref := mediaTypeObj.Schema.GetReference()
-						// Extract just the schema name from the reference path
-						parts := strings.Split(ref, "/")
-						if len(parts) > 0 {
-							schemaName := parts[len(parts)-1]
-							details.WriteString(fmt.Sprintf(" (schema: %s)", schemaName))
-						}
-					} else if mediaTypeObj.Schema.Schema() != nil && len(mediaTypeObj.Schema.Schema().Type) > 0 {
-						// If it's an inline schema with a type
-						types := mediaTypeObj.Schema.Schema().Type
-						if len(types) == 1 {
-							details.WriteString(fmt.Sprintf(" (type: %s)", types[0]))
-						} else {
-							details.WriteString(fmt.Sprintf(" (types: %v)", types))
-						}
-					}
+			for opPair := expr.Value().GetOperations().First(); opPair != nil; opPair = opPair.Next() {
+				op := opPair.Value()
+				line := fmt.Sprintf("      %s", strings.ToUpper(opPair.Key()))
+				if op.Summary != "" {
+					line += ": " + op.Summary
+				}
+				details.WriteString(line + "\n")
+
+				if op.RequestBody != nil {
+					details.WriteString("        Request Body:\n")
+					writeContent(details, op.RequestBody.Content, "          ", opts)
+				}
+
+				if op.Responses != nil {
+					details.WriteString("        Responses:\n")
+					writeResponses(details, op.Responses, "          ", opts)
 				}
-				details.WriteString("\n")
 			}
 		}
 	}
+}
 
-	if ep.op.Responses != nil {
-		details.WriteString("Responses:\n")
+// writeWrapped writes prefix followed by text, word wrapped at width with the
+// continuation lines indented by hang. Line breaks in text are kept and get the
+// same indentation, so multi-line descriptions stay inside their section.
+func writeWrapped(details *strings.Builder, prefix, text, hang string, width int) {
+	for i, para := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		line := hang
+		if i == 0 {
+			line = prefix
+		}
 
-		// Get response codes and sort them for stable ordering
-		var codes []string
-		if ep.op.Responses.Codes != nil {
-			for pair := ep.op.Responses.Codes.First(); pair != nil; pair = pair.Next() {
-				codes = append(codes, pair.Key())
+		words := strings.Fields(para)
+		if len(words) == 0 {
+			details.WriteString(strings.TrimRight(line, " ") + "\n")
+			continue
+		}
+
+		lineStart := len(line)
+		for _, word := range words {
+			switch {
+			case len(line) == lineStart:
+				line += word
+			case width > 0 && ansi.StringWidth(line)+1+ansi.StringWidth(word) > width:
+				details.WriteString(line + "\n")
+				line = hang + word
+				lineStart = len(hang)
+			default:
+				line += " " + word
 			}
 		}
+		details.WriteString(line + "\n")
+	}
+}
 
-		// Sort by status code numerically, then alphabetically for non-numeric codes
-		sortResponseCodes(codes)
+// externalDocsText formats an external documentation link as "description (url)"
+func externalDocsText(docs *base.ExternalDoc) string {
+	if docs.Description == "" {
+		return docs.URL
+	}
+	return fmt.Sprintf("%s (%s)", docs.Description, docs.URL)
+}
 
-		for _, code := range codes {
-			if resp, ok := ep.op.Responses.Codes.Get(code); ok && resp != nil {
-				if resp.Description != "" {
-					details.WriteString(fmt.Sprintf("  - %s: %s\n", code, resp.Description))
+// formatSecurity lists the alternative security requirements of an operation.
+// Schemes that must be combined are joined with "+", OAuth scopes follow the scheme name.
+func formatSecurity(sec security) string {
+	var details strings.Builder
+
+	title := "Security"
+	if sec.inherited {
+		if len(sec.requirements) == 0 {
+			return ""
+		}
+		title = "Security (global)"
+	}
+
+	if len(sec.requirements) == 0 {
+		return "Security: none\n"
+	}
+
+	details.WriteString(title + ":\n")
+	for _, req := range sec.requirements {
+		if req == nil {
+			continue
+		}
+
+		var schemes []string
+		if req.Requirements != nil {
+			for pair := req.Requirements.First(); pair != nil; pair = pair.Next() {
+				scheme := pair.Key()
+				if len(pair.Value()) > 0 {
+					scheme += fmt.Sprintf(" [%s]", strings.Join(pair.Value(), ", "))
 				}
+				schemes = append(schemes, scheme)
 			}
 		}
+
+		if len(schemes) == 0 {
+			details.WriteString("  - none (optional)\n")
+		} else {
+			details.WriteString(fmt.Sprintf("  - %s\n", strings.Join(schemes, " + ")))
+		}
 	}
 
 	return details.String()
 }
 
+// writeResponse writes a status code with its description and the content it returns
+func writeResponse(details *strings.Builder, code string, resp *v3.Response, indent string, opts detailOptions) {
+	if resp.Description != "" {
+		writeWrapped(details, fmt.Sprintf("%s- %s: ", indent, code), resp.Description, indent+"  ", opts.width)
+	} else {
+		details.WriteString(fmt.Sprintf("%s- %s\n", indent, code))
+	}
+
+	writeHeaders(details, resp.Headers, indent+"  ", opts.width)
+	writeContent(details, resp.Content, indent+"  ", opts)
+	writeLinks(details, resp.Links, indent+"  ", opts.width)
+}
+
+// writeHeaders lists response headers, such as pagination or rate limit headers,
+// each with its type and description
+func writeHeaders(details *strings.Builder, headers *orderedmap.Map[string, *v3.Header], indent string, width int) {
+	if headers == nil || headers.Len() == 0 {
+		return
+	}
+
+	// Get header names and sort them for stable ordering
+	var names []string
+	for pair := headers.First(); pair != nil; pair = pair.Next() {
+		names = append(names, pair.Key())
+	}
+	sort.Strings(names)
+
+	details.WriteString(indent + "Headers:\n")
+	for _, name := range names {
+		header, _ := headers.Get(name)
+		if header == nil {
+			details.WriteString(fmt.Sprintf("%s  - %s\n", indent, name))
+			continue
+		}
+
+		var flags []string
+		if header.Required {
+			flags = append(flags, "required")
+		}
+		if newDeprecation(header.Deprecated, header.Extensions).deprecated {
+			flags = append(flags, "deprecated")
+		}
+		line := fmt.Sprintf("%s  - %s", indent, name)
+		if len(flags) > 0 {
+			line += " (" + strings.Join(flags, ", ") + ")"
+		}
+		if header.Schema != nil {
+			line += ": " + schemaSummary(header.Schema)
+		}
+		details.WriteString(line + "\n")
+
+		if header.Description != "" {
+			writeWrapped(details, indent+"    ", header.Description, indent+"    ", width)
+		}
+	}
+}
+
+// writeLinks lists the links of a response: the target operation and how its
+// parameters and request body are filled in from this request or response
+func writeLinks(details *strings.Builder, links *orderedmap.Map[string, *v3.Link], indent string, width int) {
+	if links == nil || links.Len() == 0 {
+		return
+	}
+
+	details.WriteString(indent + "Links:\n")
+	for pair := links.First(); pair != nil; pair = pair.Next() {
+		link := pair.Value()
+		if link == nil {
+			continue
+		}
+
+		target := link.OperationId
+		if target == "" {
+			target = link.OperationRef
+		}
+		details.WriteString(fmt.Sprintf("%s  - %s %s %s\n", indent, pair.Key(), icons.link, target))
+
+		if link.Description != "" {
+			writeWrapped(details, indent+"    ", link.Description, indent+"    ", width)
+		}
+		if link.Parameters != nil {
+			for param := link.Parameters.First(); param != nil; param = param.Next() {
+				details.WriteString(fmt.Sprintf("%s    %s = %s\n", indent, param.Key(), param.Value()))
+			}
+		}
+		if link.RequestBody != "" {
+			details.WriteString(fmt.Sprintf("%s    body = %s\n", indent, link.RequestBody))
+		}
+	}
+}
+
+// writeContent lists the media types of a request or response body, each with schema
+// information - either a reference to a component schema (e.g., "#/components/schemas/Pet")
+// or an inline schema type (e.g., "object", "string") - and its example
+func writeContent(details *strings.Builder, content *orderedmap.Map[string, *v3.MediaType], indent string, opts detailOptions) {
+	if content == nil {
+		return
+	}
+
+	// Get media types and sort them for stable ordering
+	var mediaTypes []string
+	for pair := content.First(); pair != nil; pair = pair.Next() {
+		mediaTypes = append(mediaTypes, pair.Key())
+	}
+	sort.Strings(mediaTypes)
+
+	for _, mediaType := range mediaTypes {
+		mediaTypeObj, ok := content.Get(mediaType)
+		if !ok || mediaTypeObj == nil {
+			continue
+		}
+
+		details.WriteString(fmt.Sprintf("%s- %s", indent, mediaType))
+		if mediaTypeObj.Schema != nil {
+			// Check if it's a reference first
+			if mediaTypeObj.Schema.GetReference() != "" {
+				// Extract just the schema name from the reference path
+				details.WriteString(fmt.Sprintf(" (schema: %s)", refName(mediaTypeObj.Schema.GetReference())))
+			} else if mediaTypeObj.Schema.Schema() != nil && len(mediaTypeObj.Schema.Schema().Type) > 0 {
+				// If it's an inline schema with a type
+				types := mediaTypeObj.Schema.Schema().Type
+				if len(types) == 1 {
+					details.WriteString(fmt.Sprintf(" (type: %s)", types[0]))
+				} else {
+					details.WriteString(fmt.Sprintf(" (types: %s)", strings.Join(types, " | ")))
+				}
+			} else if s := mediaTypeObj.Schema.Schema(); s != nil {
+				if compositions := schemaCompositions(s); len(compositions) > 0 {
+					details.WriteString(" (" + compositions[0].label() + ")")
+				}
+			}
+		}
+		details.WriteString("\n")
+
+		if isFormMediaType(mediaType) && mediaTypeObj.Schema != nil {
+			writeFormFields(details, mediaTypeObj, indent+"  ", opts)
+		} else if opts.schemaDepth > defaultSchemaDepth && mediaTypeObj.Schema != nil {
+			var ancestors []string
+			if mediaTypeObj.Schema.IsReference() {
+				ancestors = []string{mediaTypeObj.Schema.GetReference()}
+			}
+			if !mediaTypeObj.Schema.IsReference() && mediaTypeObj.Schema.Schema() != nil {
+				writeCompositions(details, mediaTypeObj.Schema.Schema(), indent+"  ", opts.schemaDepth, ancestors)
+				writeJSONSchemaKeywords(details, mediaTypeObj.Schema.Schema(), indent+"  ")
+			}
+			writeSchemaTree(details, mediaTypeObj.Schema.Schema(), indent+"  ", opts.schemaDepth-1, ancestors)
+		}
+
+		writeExamples(details, mediaTypeObj.Example, mediaTypeObj.Examples, indent+"  ", opts.expandExamples)
+	}
+}
+
 func formatSchemaDetails(schema *base.SchemaProxy) string {
+	return formatSchemaDetailsDepth(schema, defaultSchemaDepth, nil)
+}
+
+// formatSchemaDetailsDepth formats a schema with its properties shown as a tree
+// that is depth levels deep. ancestors holds the references the schema was reached
+// through (including its own), so that circular references are not expanded.
+func formatSchemaDetailsDepth(schema *base.SchemaProxy, depth int, ancestors []string) string {
 	var details strings.Builder
 
 	if schema == nil || schema.Schema() == nil {
 		return "No schema details available"
 	}
 
+	if schema.IsReference() {
+		ancestors = append(slices.Clone(ancestors), schema.GetReference())
+	}
+
 	s := schema.Schema()
 
 	// Handle both single type (OpenAPI 3.0) and array of types (OpenAPI 3.1)
@@ -368,7 +926,7 @@ func formatSchemaDetails(schema *base.SchemaProxy) string {
 		if len(s.Type) == 1 {
 			details.WriteString(fmt.Sprintf("Type: %s\n", s.Type[0]))
 		} else {
-			details.WriteString(fmt.Sprintf("Types: %v\n", s.Type))
+			details.WriteString(fmt.Sprintf("Types: %s\n", strings.Join(s.Type, " | ")))
 		}
 	}
 
@@ -376,32 +934,35 @@ func formatSchemaDetails(schema *base.SchemaProxy) string {
 		details.WriteString(fmt.Sprintf("Format: %s\n", s.Format))
 	}
 
+	if xml := xmlText(s); xml != "" {
+		details.WriteString(fmt.Sprintf("XML: %s\n", xml))
+	}
+
+	writeSchemaFacts(&details, s, "")
+
+	if s.ExternalDocs != nil {
+		details.WriteString(fmt.Sprintf("External Docs: %s\n", externalDocsText(s.ExternalDocs)))
+	}
+
 	if len(s.Required) > 0 {
 		details.WriteString(fmt.Sprintf("Required: %v\n", s.Required))
 	}
 
+	writeCompositions(&details, s, "", depth, ancestors)
+
 	if s.Properties != nil && s.Properties.Len() > 0 {
 		details.WriteString("Properties:\n")
+		writeSchemaTree(&details, s, "  ", depth, ancestors)
+	}
 
-		// Get property names and sort them for stable ordering
-		var propNames []string
-		for pair := s.Properties.First(); pair != nil; pair = pair.Next() {
-			propNames = append(propNames, pair.Key())
-		}
-		sort.Strings(propNames)
-
-		for _, propName := range propNames {
-			if prop, ok := s.Properties.Get(propName); ok && prop != nil && prop.Schema() != nil {
-				propType := "unknown"
-				if len(prop.Schema().Type) > 0 {
-					if len(prop.Schema().Type) == 1 {
-						propType = prop.Schema().Type[0]
-					} else {
-						propType = fmt.Sprintf("%v", prop.Schema().Type)
-					}
-				}
-				details.WriteString(fmt.Sprintf("  - %s: %s\n", propName, propType))
+	if text := additionalPropertiesText(s); text != "" {
+		details.WriteString(fmt.Sprintf("Additional Properties: %s\n", text))
+		if value := s.AdditionalProperties.A; depth > defaultSchemaDepth && value != nil && !slices.Contains(ancestors, value.GetReference()) {
+			next := ancestors
+			if value.IsReference() {
+				next = append(slices.Clone(ancestors), value.GetReference())
 			}
+			writeSchemaTree(&details, value.Schema(), "  ", depth-1, next)
 		}
 	}
 
@@ -410,13 +971,197 @@ func formatSchemaDetails(schema *base.SchemaProxy) string {
 		if len(itemsType) == 1 {
 			details.WriteString(fmt.Sprintf("Items Type: %s\n", itemsType[0]))
 		} else {
-			details.WriteString(fmt.Sprintf("Items Types: %v\n", itemsType))
+			details.WriteString(fmt.Sprintf("Items Types: %s\n", strings.Join(itemsType, " | ")))
+		}
+		if depth > defaultSchemaDepth {
+			writeSchemaTree(&details, s.Items.A.Schema(), "  ", depth-1, ancestors)
 		}
 	}
 
+	writeJSONSchemaKeywords(&details, s, "")
+
 	return details.String()
 }
 
+// writeSchemaTree writes the properties of an object schema (or of the items of an
+// array schema) one per line, descending into nested objects while depth allows.
+// Properties referring back to one of the ancestors are marked as circular instead.
+// Deeper properties are summed up by an expand line.
+func writeSchemaTree(details *strings.Builder, s *base.Schema, indent string, depth int, ancestors []string) {
+	if s == nil {
+		return
+	}
+
+	s = nestedObjectSchema(s)
+	if s == nil || s.Properties == nil {
+		return
+	}
+	if depth < 1 {
+		// Past the depth shown, the properties are only counted, so that huge
+		// schemas stay short while telling there is more to expand
+		n := s.Properties.Len()
+		noun := "properties"
+		if n == 1 {
+			noun = "property"
+		}
+		details.WriteString(fmt.Sprintf("%s%s expand (%d more %s)\n", indent, icons.ellipsis, n, noun))
+		return
+	}
+
+	// Get property names and sort them for stable ordering
+	var propNames []string
+	for pair := s.Properties.First(); pair != nil; pair = pair.Next() {
+		propNames = append(propNames, pair.Key())
+	}
+	sort.Strings(propNames)
+
+	for _, propName := range propNames {
+		prop, ok := s.Properties.Get(propName)
+		if !ok || prop == nil || prop.Schema() == nil {
+			continue
+		}
+
+		ref := schemaRef(prop)
+		if ref != "" && slices.Contains(ancestors, ref) {
+			details.WriteString(fmt.Sprintf("%s- %s: %s %s (circular: %s)\n", indent, propName, schemaTypeLabel(prop), icons.circular, refName(ref)))
+			continue
+		}
+
+		label := schemaSummary(prop)
+		if flags := schemaFlags(prop.Schema()); len(flags) > 0 {
+			label += " (" + strings.Join(flags, ", ") + ")"
+		}
+		details.WriteString(fmt.Sprintf("%s- %s: %s\n", indent, propName, label))
+		// The XML and keywords of a referenced schema are shown with the schema itself
+		if !prop.IsReference() {
+			if xml := xmlText(prop.Schema()); xml != "" {
+				details.WriteString(fmt.Sprintf("%s  XML: %s\n", indent, xml))
+			}
+			writeJSONSchemaKeywords(details, prop.Schema(), indent+"  ")
+		}
+
+		next := ancestors
+		if ref != "" {
+			next = append(slices.Clone(ancestors), ref)
+		}
+		writeSchemaTree(details, prop.Schema(), indent+"  ", depth-1, next)
+	}
+}
+
+// schemaRef returns the reference a schema points to, looking through arrays
+// so that "items: {$ref: ...}" counts as a reference as well
+func schemaRef(proxy *base.SchemaProxy) string {
+	if proxy.IsReference() {
+		return proxy.GetReference()
+	}
+	if s := proxy.Schema(); s != nil && s.Items != nil && s.Items.IsA() && s.Items.A != nil && s.Items.A.IsReference() {
+		return s.Items.A.GetReference()
+	}
+	if value := mapValueSchema(proxy.Schema()); value != nil && value.IsReference() {
+		return value.GetReference()
+	}
+	return ""
+}
+
+// mapValueSchema returns the schema of the values of a map, an object without
+// properties whose additionalProperties is a schema
+func mapValueSchema(s *base.Schema) *base.SchemaProxy {
+	if s == nil || (s.Properties != nil && s.Properties.Len() > 0) {
+		return nil
+	}
+	if s.AdditionalProperties == nil || !s.AdditionalProperties.IsA() {
+		return nil
+	}
+	return s.AdditionalProperties.A
+}
+
+// additionalPropertiesText describes the additionalProperties of a schema:
+// the value schema, "any" when allowed without one, or "none (sealed)"
+func additionalPropertiesText(s *base.Schema) string {
+	ap := s.AdditionalProperties
+	switch {
+	case ap == nil:
+		return ""
+	case ap.IsA() && ap.A != nil:
+		return branchLabel(ap.A)
+	case ap.IsB() && ap.B:
+		return "any"
+	case ap.IsB():
+		return "none (sealed)"
+	}
+	return ""
+}
+
+// nestedObjectSchema returns the schema whose properties are shown below a property:
+// the schema itself for objects, or the item or value schema for arrays and maps of objects
+func nestedObjectSchema(s *base.Schema) *base.Schema {
+	if s.Properties != nil && s.Properties.Len() > 0 {
+		return s
+	}
+	if s.Items != nil && s.Items.IsA() && s.Items.A != nil {
+		if items := s.Items.A.Schema(); items != nil && items.Properties != nil && items.Properties.Len() > 0 {
+			return items
+		}
+	}
+	if value := mapValueSchema(s); value != nil {
+		if values := value.Schema(); values != nil && values.Properties != nil && values.Properties.Len() > 0 {
+			return values
+		}
+	}
+	return nil
+}
+
+// schemaTypeLabel describes a schema in a few words, e.g. "string", "array[Tag]"
+// or "object (Category)" for referenced schemas
+func schemaTypeLabel(proxy *base.SchemaProxy) string {
+	if proxy == nil || proxy.Schema() == nil {
+		return "unknown"
+	}
+
+	s := proxy.Schema()
+
+	label := "unknown"
+	if len(s.Type) == 1 {
+		label = s.Type[0]
+	} else if len(s.Type) > 1 {
+		label = strings.Join(s.Type, " | ")
+	}
+
+	if compositions := schemaCompositions(s); label == "unknown" && len(compositions) > 0 {
+		label = compositions[0].short()
+	}
+
+	if label == "object" || label == "unknown" {
+		if value := mapValueSchema(s); value != nil {
+			label = fmt.Sprintf("map[string] %s %s", icons.link, branchLabel(value))
+		} else if s.Properties == nil && s.AdditionalProperties != nil && s.AdditionalProperties.IsB() && s.AdditionalProperties.B {
+			label = fmt.Sprintf("map[string] %s any", icons.link)
+		}
+	}
+
+	if label == "array" && s.Items != nil && s.Items.IsA() && s.Items.A != nil {
+		itemLabel := "unknown"
+		if s.Items.A.IsReference() {
+			itemLabel = refName(s.Items.A.GetReference())
+		} else if items := s.Items.A.Schema(); items != nil && len(items.Type) > 0 {
+			itemLabel = strings.Join(items.Type, " | ")
+		}
+		label = fmt.Sprintf("array[%s]", itemLabel)
+	}
+
+	if proxy.IsReference() {
+		label = fmt.Sprintf("%s (%s)", label, refName(proxy.GetReference()))
+	}
+
+	return label
+}
+
+// refName returns the last segment of a reference, e.g. "Pet" for "#/components/schemas/Pet"
+func refName(ref string) string {
+	parts := strings.Split(ref, "/")
+	return parts[len(parts)-1]
+}
+
 func formatRequestBodyDetails(reqBody *v3.RequestBody) string {
 	var details strings.Builder
 
@@ -446,10 +1191,14 @@ func formatRequestBodyDetails(reqBody *v3.RequestBody) string {
 					if len(types) == 1 {
 						details.WriteString(fmt.Sprintf(" (type: %s)", types[0]))
 					} else {
-						details.WriteString(fmt.Sprintf(" (types: %v)", types))
+						details.WriteString(fmt.Sprintf(" (types: %s)", strings.Join(types, " | ")))
 					}
 				}
 				details.WriteString("\n")
+				if isFormMediaType(mediaType) {
+					writeFormFields(&details, mediaTypeObj, "    ", defaultDetailOptions)
+				}
+				writeExamples(&details, mediaTypeObj.Example, mediaTypeObj.Examples, "    ", false)
 			}
 		}
 	}
@@ -482,28 +1231,16 @@ func formatResponseDetails(response *v3.Response) string {
 					if len(types) == 1 {
 						details.WriteString(fmt.Sprintf(" (type: %s)", types[0]))
 					} else {
-						details.WriteString(fmt.Sprintf(" (types: %v)", types))
+						details.WriteString(fmt.Sprintf(" (types: %s)", strings.Join(types, " | ")))
 					}
 				}
 				details.WriteString("\n")
+				writeExamples(&details, mediaTypeObj.Example, mediaTypeObj.Examples, "    ", false)
 			}
 		}
 	}
 
-	if response.Headers != nil && response.Headers.Len() > 0 {
-		details.WriteString("Headers:\n")
-
-		// Get header names and sort them for stable ordering
-		var headerNames []string
-		for pair := response.Headers.First(); pair != nil; pair = pair.Next() {
-			headerNames = append(headerNames, pair.Key())
-		}
-		sort.Strings(headerNames)
-
-		for _, headerName := range headerNames {
-			details.WriteString(fmt.Sprintf("  - %s\n", headerName))
-		}
-	}
+	writeHeaders(&details, response.Headers, "", 0)
 
 	return details.String()
 }
@@ -526,16 +1263,16 @@ func formatParameterDetails(param *v3.Parameter) string {
 		if len(types) == 1 {
 			details.WriteString(fmt.Sprintf("Type: %s\n", types[0]))
 		} else {
-			details.WriteString(fmt.Sprintf("Types: %v\n", types))
+			details.WriteString(fmt.Sprintf("Types: %s\n", strings.Join(types, " | ")))
 		}
 		if param.Schema.Schema().Format != "" {
 			details.WriteString(fmt.Sprintf("Format: %s\n", param.Schema.Schema().Format))
 		}
+		writeSchemaFacts(&details, param.Schema.Schema(), "")
+		writeJSONSchemaKeywords(&details, param.Schema.Schema(), "")
 	}
 
-	if param.Example != nil {
-		details.WriteString(fmt.Sprintf("Example: %v\n", param.Example))
-	}
+	writeExamples(&details, param.Example, param.Examples, "", false)
 
 	return details.String()
 }
@@ -556,13 +1293,17 @@ func formatHeaderDetails(header *v3.Header) string {
 		if len(types) == 1 {
 			details.WriteString(fmt.Sprintf("Type: %s\n", types[0]))
 		} else {
-			details.WriteString(fmt.Sprintf("Types: %v\n", types))
+			details.WriteString(fmt.Sprintf("Types: %s\n", strings.Join(types, " | ")))
 		}
 		if header.Schema.Schema().Format != "" {
 			details.WriteString(fmt.Sprintf("Format: %s\n", header.Schema.Schema().Format))
 		}
+		writeSchemaFacts(&details, header.Schema.Schema(), "")
+		writeJSONSchemaKeywords(&details, header.Schema.Schema(), "")
 	}
 
+	writeExamples(&details, header.Example, header.Examples, "", false)
+
 	return details.String()
 }
 
@@ -591,23 +1332,441 @@ func formatSecuritySchemeDetails(name string, secScheme *v3.SecurityScheme) stri
 		details.WriteString(fmt.Sprintf("Name: %s\n", secScheme.Name))
 	}
 
+	if secScheme.OpenIdConnectUrl != "" {
+		details.WriteString(fmt.Sprintf("OpenID Connect URL: %s\n", secScheme.OpenIdConnectUrl))
+	}
+
+	if secScheme.OAuth2MetadataUrl != "" {
+		details.WriteString(fmt.Sprintf("OAuth2 Metadata URL: %s\n", secScheme.OAuth2MetadataUrl))
+	}
+
+	if flows := secScheme.Flows; flows != nil {
+		details.WriteString("Flows:\n")
+		for _, flow := range []struct {
+			name string
+			flow *v3.OAuthFlow
+		}{
+			{"Implicit", flows.Implicit},
+			{"Password", flows.Password},
+			{"Client Credentials", flows.ClientCredentials},
+			{"Authorization Code", flows.AuthorizationCode},
+			{"Device", flows.Device},
+		} {
+			if flow.flow != nil {
+				writeOAuthFlow(&details, flow.name, flow.flow)
+			}
+		}
+	}
+
 	return details.String()
 }
 
+// writeOAuthFlow lists the URLs of an OAuth2 flow and the scopes it grants
+func writeOAuthFlow(details *strings.Builder, name string, flow *v3.OAuthFlow) {
+	details.WriteString(fmt.Sprintf("  %s:\n", name))
+	if flow.AuthorizationUrl != "" {
+		details.WriteString(fmt.Sprintf("    Authorization URL: %s\n", flow.AuthorizationUrl))
+	}
+	if flow.TokenUrl != "" {
+		details.WriteString(fmt.Sprintf("    Token URL: %s\n", flow.TokenUrl))
+	}
+	if flow.RefreshUrl != "" {
+		details.WriteString(fmt.Sprintf("    Refresh URL: %s\n", flow.RefreshUrl))
+	}
+
+	if flow.Scopes == nil || flow.Scopes.Len() == 0 {
+		details.WriteString("    Scopes: none\n")
+		return
+	}
+	details.WriteString("    Scopes:\n")
+	for pair := flow.Scopes.First(); pair != nil; pair = pair.Next() {
+		line := "      - " + pair.Key()
+		if pair.Value() != "" {
+			line += ": " + pair.Value()
+		}
+		details.WriteString(line + "\n")
+	}
+}
+
 func formatWebhookDetails(hook webhook) string {
+	return formatWebhookDetailsWithOptions(hook, defaultDetailOptions)
+}
+
+func formatWebhookDetailsWithOptions(hook webhook, opts detailOptions) string {
 	var details strings.Builder
 
-	if hook.op.Summary != "" {
-		details.WriteString(fmt.Sprintf("Summary: %s\n", hook.op.Summary))
+	details.WriteString(hook.deprecation.details())
+
+	if hook.op.Summary != "" && opts.shows(sectionSummary) {
+		writeWrapped(&details, "Summary: ", hook.op.Summary, "  ", opts.width)
 	}
 
-	if hook.op.Description != "" {
-		details.WriteString(fmt.Sprintf("Description: %s\n", hook.op.Description))
+	if hook.op.Description != "" && opts.shows(sectionDescription) {
+		writeWrapped(&details, "Description: ", hook.op.Description, "  ", opts.width)
 	}
 
-	if hook.op.OperationId != "" {
+	if hook.op.OperationId != "" && opts.shows(sectionOperationID) {
 		details.WriteString(fmt.Sprintf("Operation ID: %s\n", hook.op.OperationId))
 	}
 
+	if hook.op.ExternalDocs != nil && opts.shows(sectionExternalDocs) {
+		details.WriteString(fmt.Sprintf("External Docs: %s\n", externalDocsText(hook.op.ExternalDocs)))
+	}
+
+	if opts.shows(sectionSecurity) {
+		details.WriteString(formatSecurity(hook.security))
+	}
+
+	if opts.shows(sectionParameters) {
+		writeParameters(&details, hook.op.Parameters, opts)
+	}
+
+	if opts.shows(sectionRequestBody) {
+		writeRequestBody(&details, hook.op.RequestBody, opts)
+	}
+
+	if hook.op.Responses != nil && opts.shows(sectionResponses) {
+		details.WriteString("Responses:\n")
+		writeResponses(&details, hook.op.Responses, "  ", opts)
+	}
+
+	if opts.shows(sectionCallbacks) {
+		writeCallbacks(&details, hook.op.Callbacks, opts)
+	}
+
+	if opts.shows(sectionExtensions) {
+		details.WriteString(formatExtensions(hook.op.Extensions, "", opts.expandExtensions))
+	}
+
 	return details.String()
 }
+
+// formatComponentDetails formats the detail section of a component. Schemas are
+// formatted on demand so that the property tree can follow the selected depth.
+func formatComponentDetails(comp component, opts detailOptions) string {
+	if schema, ok := comp.source.(*base.SchemaProxy); ok && schema != nil {
+		self := "#/components/schemas/" + strings.ReplaceAll(strings.ReplaceAll(comp.name, "~", "~0"), "/", "~1")
+		details := comp.deprecation.details() + formatSchemaDetailsDepth(schema, opts.schemaDepth, []string{self})
+		if s := schema.Schema(); s != nil {
+			details += formatExtensions(s.Extensions, "", opts.expandExtensions)
+		}
+		return details
+	}
+	return comp.deprecation.details() + comp.details()
+}
+
+// details formats the component with the default options. It is only done
+// when the component is shown, as specs can have thousands of them.
+func (c component) details() string {
+	switch source := c.source.(type) {
+	case *base.SchemaProxy:
+		return formatSchemaDetails(source)
+	case *v3.RequestBody:
+		return formatRequestBodyDetails(source)
+	case *v3.Response:
+		return formatResponseDetails(source)
+	case *v3.Parameter:
+		return formatParameterDetails(source)
+	case *v3.Header:
+		return formatHeaderDetails(source)
+	case *v3.SecurityScheme:
+		return formatSecuritySchemeDetails(c.name, source)
+	}
+	return ""
+}
+
+// componentTypesBySection maps "#/components/<section>" names to component types
+var componentTypesBySection = map[string]string{
+	"schemas":         "Schema",
+	"requestBodies":   "RequestBody",
+	"responses":       "Response",
+	"parameters":      "Parameter",
+	"headers":         "Header",
+	"securitySchemes": "SecurityScheme",
+}
+
+// parseComponentRef splits a reference like "#/components/schemas/Pet" into
+// the component type ("Schema") and the unescaped component name ("Pet")
+func parseComponentRef(ref string) (string, string, bool) {
+	if idx := strings.Index(ref, "#"); idx >= 0 {
+		ref = ref[idx+1:]
+	}
+
+	parts := strings.Split(strings.TrimPrefix(ref, "/"), "/")
+	if len(parts) != 3 || parts[0] != "components" {
+		return "", "", false
+	}
+
+	compType, ok := componentTypesBySection[parts[1]]
+	if !ok {
+		return "", "", false
+	}
+
+	name := strings.ReplaceAll(strings.ReplaceAll(parts[2], "~1", "/"), "~0", "~")
+	return compType, name, true
+}
+
+// escapePointerToken escapes "~" and "/" for use in a JSON pointer
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// operationRef builds a JSON pointer to an operation, e.g. "#/paths/~1pet~1{petId}/get"
+func operationRef(path, method string) string {
+	return "#/paths/" + escapePointerToken(path) + "/" + strings.ToLower(method)
+}
+
+// webhookRef builds a JSON pointer to a webhook operation, e.g. "#/webhooks/newPet/post"
+func webhookRef(name, method string) string {
+	return "#/webhooks/" + escapePointerToken(name) + "/" + strings.ToLower(method)
+}
+
+// parseWebhookRef splits a webhook reference like "#/webhooks/newPet/post" into its name and method
+func parseWebhookRef(ref string) (string, string, bool) {
+	_, ref, _ = strings.Cut(ref, "#")
+	parts := strings.Split(strings.TrimPrefix(ref, "/"), "/")
+	if len(parts) != 3 || parts[0] != "webhooks" {
+		return "", "", false
+	}
+
+	name := strings.ReplaceAll(strings.ReplaceAll(parts[1], "~1", "/"), "~0", "~")
+	return name, strings.ToUpper(parts[2]), true
+}
+
+// componentRef builds a JSON pointer to a component, e.g. "#/components/schemas/Pet"
+func componentRef(compType, name string) string {
+	for section, t := range componentTypesBySection {
+		if t == compType {
+			return "#/components/" + section + "/" + escapePointerToken(name)
+		}
+	}
+	return ""
+}
+
+// parseOperationRef splits an operation reference like "#/paths/~1pet~1{petId}/get"
+// into its path and method. Anything before "#" (a document URL) is ignored.
+func parseOperationRef(ref string) (string, string, bool) {
+	if idx := strings.Index(ref, "#"); idx >= 0 {
+		ref = ref[idx+1:]
+	}
+
+	parts := strings.Split(strings.TrimPrefix(ref, "/"), "/")
+	if len(parts) != 3 || parts[0] != "paths" {
+		return "", "", false
+	}
+
+	path := parts[1]
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		path = unescaped
+	}
+	path = strings.ReplaceAll(strings.ReplaceAll(path, "~1", "/"), "~0", "~")
+
+	return path, strings.ToUpper(parts[2]), true
+}
+
+// refCollector gathers component references in the order they are found, without duplicates
+type refCollector struct {
+	refs []string
+	seen map[string]bool
+}
+
+func (c *refCollector) add(ref string) {
+	if ref == "" || c.seen[ref] {
+		return
+	}
+	if c.seen == nil {
+		c.seen = make(map[string]bool)
+	}
+	c.seen[ref] = true
+	c.refs = append(c.refs, ref)
+}
+
+// addSchema collects the reference of a schema, or the references nested in an inline schema
+func (c *refCollector) addSchema(proxy *base.SchemaProxy) {
+	if proxy == nil {
+		return
+	}
+	if proxy.IsReference() {
+		c.add(proxy.GetReference())
+		return
+	}
+	c.addSchemaChildren(proxy.Schema())
+}
+
+func (c *refCollector) addSchemaChildren(s *base.Schema) {
+	if s == nil {
+		return
+	}
+
+	if s.Properties != nil {
+		for pair := s.Properties.First(); pair != nil; pair = pair.Next() {
+			c.addSchema(pair.Value())
+		}
+	}
+	if s.Items != nil && s.Items.IsA() {
+		c.addSchema(s.Items.A)
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.IsA() {
+		c.addSchema(s.AdditionalProperties.A)
+	}
+	for _, group := range [][]*base.SchemaProxy{s.AllOf, s.OneOf, s.AnyOf} {
+		for _, proxy := range group {
+			c.addSchema(proxy)
+		}
+	}
+}
+
+func (c *refCollector) addContent(content *orderedmap.Map[string, *v3.MediaType]) {
+	if content == nil {
+		return
+	}
+
+	var mediaTypes []string
+	for pair := content.First(); pair != nil; pair = pair.Next() {
+		mediaTypes = append(mediaTypes, pair.Key())
+	}
+	sort.Strings(mediaTypes)
+
+	for _, mediaType := range mediaTypes {
+		if mediaTypeObj, ok := content.Get(mediaType); ok && mediaTypeObj != nil {
+			c.addSchema(mediaTypeObj.Schema)
+		}
+	}
+}
+
+func (c *refCollector) addParameter(param *v3.Parameter) {
+	if param == nil {
+		return
+	}
+	if low := param.GoLow(); low != nil && low.Reference != nil && low.IsReference() {
+		c.add(low.GetReference())
+		return
+	}
+	c.addSchema(param.Schema)
+}
+
+func (c *refCollector) addRequestBody(reqBody *v3.RequestBody) {
+	if reqBody == nil {
+		return
+	}
+	if low := reqBody.GoLow(); low != nil && low.Reference != nil && low.IsReference() {
+		c.add(low.GetReference())
+		return
+	}
+	c.addContent(reqBody.Content)
+}
+
+func (c *refCollector) addResponse(resp *v3.Response) {
+	if resp == nil {
+		return
+	}
+	if low := resp.GoLow(); low != nil && low.Reference != nil && low.IsReference() {
+		c.add(low.GetReference())
+		return
+	}
+	c.addContent(resp.Content)
+	if resp.Headers != nil {
+		for pair := resp.Headers.First(); pair != nil; pair = pair.Next() {
+			c.addHeader(pair.Value())
+		}
+	}
+}
+
+func (c *refCollector) addHeader(header *v3.Header) {
+	if header == nil {
+		return
+	}
+	if low := header.GoLow(); low != nil && low.Reference != nil && low.IsReference() {
+		c.add(low.GetReference())
+		return
+	}
+	c.addSchema(header.Schema)
+}
+
+// operationReferences lists the components referenced by an operation:
+// parameters first, then the request body, then responses ordered by status code
+func operationReferences(op *v3.Operation) []string {
+	var c refCollector
+
+	if op == nil {
+		return nil
+	}
+
+	for _, param := range op.Parameters {
+		c.addParameter(param)
+	}
+
+	c.addRequestBody(op.RequestBody)
+
+	if op.Responses != nil && op.Responses.Codes != nil {
+		var codes []string
+		for pair := op.Responses.Codes.First(); pair != nil; pair = pair.Next() {
+			codes = append(codes, pair.Key())
+		}
+		sortResponseCodes(codes)
+
+		for _, code := range codes {
+			if resp, ok := op.Responses.Codes.Get(code); ok {
+				c.addResponse(resp)
+			}
+		}
+	}
+	if op.Responses != nil {
+		c.addResponse(op.Responses.Default)
+	}
+
+	if op.Callbacks != nil {
+		for pair := op.Callbacks.First(); pair != nil; pair = pair.Next() {
+			if pair.Value() == nil || pair.Value().Expression == nil {
+				continue
+			}
+			for expr := pair.Value().Expression.First(); expr != nil; expr = expr.Next() {
+				if expr.Value() == nil {
+					continue
+				}
+				for opPair := expr.Value().GetOperations().First(); opPair != nil; opPair = opPair.Next() {
+					for _, ref := range operationReferences(opPair.Value()) {
+						c.add(ref)
+					}
+				}
+			}
+		}
+	}
+
+	return c.refs
+}
+
+// componentReferences lists the components referenced from within a component
+func componentReferences(comp component) []string {
+	var c refCollector
+
+	switch source := comp.source.(type) {
+	case *base.SchemaProxy:
+		if source != nil {
+			c.addSchemaChildren(source.Schema())
+		}
+	case *v3.RequestBody:
+		if source != nil {
+			c.addContent(source.Content)
+		}
+	case *v3.Response:
+		if source != nil {
+			c.addContent(source.Content)
+			if source.Headers != nil {
+				for pair := source.Headers.First(); pair != nil; pair = pair.Next() {
+					c.addHeader(pair.Value())
+				}
+			}
+		}
+	case *v3.Parameter:
+		if source != nil {
+			c.addSchema(source.Schema)
+		}
+	case *v3.Header:
+		if source != nil {
+			c.addSchema(source.Schema)
+		}
+	}
+
+	return c.refs
+}
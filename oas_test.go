@@ -1,12 +1,34 @@
 package main
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"maps"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"go.yaml.in/yaml/v4"
 )
 
 func TestAllExampleFiles(t *testing.T) {
@@ -76,7 +98,7 @@ func testExampleFile(t *testing.T, filepath string) {
 
 	emptyDetailsCount := 0
 	for _, comp := range components {
-		if comp.details == "" {
+		if comp.details() == "" {
 			emptyDetailsCount++
 		}
 	}
@@ -379,3 +401,6389 @@ func TestEmptyDocument(t *testing.T) {
 		t.Errorf("Cursor should remain 0 for empty document, got %d", model.cursor)
 	}
 }
+
+func TestEmptyList(t *testing.T) {
+	// A spec with components only has no endpoints to list
+	model := loadSpecModel(t, `openapi: 3.0.3
+info:
+  title: Schemas only
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Pet:
+      type: object
+`)
+	model.width, model.height = 80, 24
+
+	model = pressKeys(model, "ctrl+d")
+	if model.cursor != 0 {
+		t.Errorf("Expected the cursor to stay at 0 on an empty list, got %d", model.cursor)
+	}
+	if view := model.View(); view == "" {
+		t.Error("Expected the empty list to render")
+	}
+	if got := model.locationText(); got != "" {
+		t.Errorf("Expected no location on an empty list, got %q", got)
+	}
+
+	// Nothing is selected to expand, even with the cursor before the list
+	for _, cursor := range []int{0, -1} {
+		model.cursor = cursor
+		if got, want := model.footerHints(100), "?: help"; got != want {
+			t.Errorf("Expected hints %q at cursor %d, got %q", want, cursor, got)
+		}
+	}
+	model.View()
+}
+
+// loadExampleModel builds a Model from one of the files in the examples folder
+func loadExampleModel(t *testing.T, filepath string) Model {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", filepath, err)
+	}
+
+	return loadSpecModel(t, string(content))
+}
+
+// loadSpecModel builds a Model from an inline spec
+func loadSpecModel(t *testing.T, spec string) Model {
+	t.Helper()
+
+	document, err := libopenapi.NewDocument([]byte(spec))
+	if err != nil {
+		t.Fatalf("Error creating document: %v", err)
+	}
+
+	v3Model, err := document.BuildV3Model()
+	if err != nil {
+		t.Fatalf("Error building v3 model: %v", err)
+	}
+
+	model := NewModel(&v3Model.Model)
+	model.width = 120
+	model.height = 40
+
+	return model
+}
+
+func TestRawSourceView(t *testing.T) {
+	tests := []struct {
+		filename string
+		expected string
+	}{
+		{"petstore-3.0.yaml", "operationId: addPet"},
+		{"discord.json", `"operationId": "get_my_application"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filename, func(t *testing.T) {
+			model := loadExampleModel(t, filepath.Join("examples", test.filename))
+			model.showRaw = true
+
+			found := false
+			for i := range model.endpoints {
+				if strings.Contains(model.itemDetails(i), test.expected) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("Raw source does not contain %q", test.expected)
+			}
+
+			model.mode = viewComponents
+			if len(model.components) > 0 && model.itemDetails(0) == "" {
+				t.Error("Raw source for a component should not be empty")
+			}
+
+			model.endpoints[0].folded = false
+			model.mode = viewEndpoints
+			if model.View() == "" {
+				t.Error("Raw source view should not be empty")
+			}
+		})
+	}
+}
+
+func TestHighlightKeepsText(t *testing.T) {
+	yamlSrc := "get:\n  summary: List pets # all of them\n  description: |\n    Multi: line\n  tags:\n    - pets\n  deprecated: false\n  x-rate: 10\n  ref: {\"$ref\": \"#/components/schemas/Pet\"}"
+	if got := highlightYAML(yamlSrc); got != yamlSrc {
+		t.Errorf("highlightYAML changed the text:\n%s", got)
+	}
+
+	jsonSrc := "{\n  \"name\": \"doggie\",\n  \"age\": 3,\n  \"tags\": [true, null]\n}"
+	if got := highlightJSON(jsonSrc); got != jsonSrc {
+		t.Errorf("highlightJSON changed the text:\n%s", got)
+	}
+}
+
+func pressKeys(model Model, keys ...string) Model {
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "ctrl+o":
+			msg = tea.KeyMsg{Type: tea.KeyCtrlO}
+		case "ctrl+n":
+			msg = tea.KeyMsg{Type: tea.KeyCtrlN}
+		case "ctrl+p":
+			msg = tea.KeyMsg{Type: tea.KeyCtrlP}
+		case "ctrl+d":
+			msg = tea.KeyMsg{Type: tea.KeyCtrlD}
+		case "up":
+			msg = tea.KeyMsg{Type: tea.KeyUp}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "left":
+			msg = tea.KeyMsg{Type: tea.KeyLeft}
+		case "right":
+			msg = tea.KeyMsg{Type: tea.KeyRight}
+		case "pgup":
+			msg = tea.KeyMsg{Type: tea.KeyPgUp}
+		case "pgdown":
+			msg = tea.KeyMsg{Type: tea.KeyPgDown}
+		case "home":
+			msg = tea.KeyMsg{Type: tea.KeyHome}
+		case "end":
+			msg = tea.KeyMsg{Type: tea.KeyEnd}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		updated, _ := model.Update(msg)
+		model = updated.(Model)
+	}
+	return model
+}
+
+func TestGoToDefinition(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+
+	for i, ep := range model.endpoints {
+		if ep.path == "/pet" && ep.method == "POST" {
+			model.cursor = i
+		}
+	}
+
+	// POST /pet references Pet and Error, so a picker is shown first
+	model = pressKeys(model, "g", "d")
+	if model.picker == nil || len(model.picker.items) != 2 {
+		t.Fatalf("Expected a picker with 2 references, got %+v", model.picker)
+	}
+	if model.picker.items[0].label != "Schema: Pet" {
+		t.Errorf("Expected Pet to be offered first, got %s", model.picker.items[0].label)
+	}
+
+	model = pressKeys(model, "enter")
+	if model.mode != viewComponents {
+		t.Fatalf("Expected to jump to components view, got mode %d", model.mode)
+	}
+	comp := model.components[model.cursor]
+	if comp.compType != "Schema" || comp.name != "Pet" {
+		t.Errorf("Expected cursor on Schema Pet, got %s %s", comp.compType, comp.name)
+	}
+	if comp.folded {
+		t.Error("Referenced component should be unfolded after the jump")
+	}
+
+	// Pet references both Category and Tag, so a picker is shown
+	model = pressKeys(model, "g", "d")
+	if model.picker == nil || len(model.picker.items) != 2 {
+		t.Fatalf("Expected a picker with 2 references, got %+v", model.picker)
+	}
+
+	model = pressKeys(model, "j", "enter")
+	comp = model.components[model.cursor]
+	if model.picker != nil || comp.name != "Tag" {
+		t.Errorf("Expected to jump to Tag, got %s", comp.name)
+	}
+}
+
+func TestParseComponentRef(t *testing.T) {
+	tests := []struct {
+		ref      string
+		compType string
+		name     string
+		ok       bool
+	}{
+		{"#/components/schemas/Pet", "Schema", "Pet", true},
+		{"#/components/responses/NotFound", "Response", "NotFound", true},
+		{"common.yaml#/components/parameters/limit", "Parameter", "limit", true},
+		{"#/components/schemas/a~1b", "Schema", "a/b", true},
+		{"#/paths/~1pets", "", "", false},
+	}
+
+	for _, test := range tests {
+		compType, name, ok := parseComponentRef(test.ref)
+		if compType != test.compType || name != test.name || ok != test.ok {
+			t.Errorf("parseComponentRef(%q) = %q, %q, %v", test.ref, compType, name, ok)
+		}
+	}
+}
+
+func TestNavigationHistory(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+
+	startCursor := 0
+	for i, ep := range model.endpoints {
+		if ep.path == "/pet" && ep.method == "POST" {
+			startCursor = i
+		}
+	}
+	model.cursor = startCursor
+
+	model = pressKeys(model, "g", "d", "enter")
+	if model.mode != viewComponents {
+		t.Fatalf("Expected components view after the jump, got mode %d", model.mode)
+	}
+	petCursor := model.cursor
+
+	model = pressKeys(model, "ctrl+o")
+	if model.mode != viewEndpoints || model.cursor != startCursor {
+		t.Errorf("Expected to go back to endpoint %d, got mode %d cursor %d", startCursor, model.mode, model.cursor)
+	}
+
+	model = pressKeys(model, "ctrl+n")
+	if model.mode != viewComponents || model.cursor != petCursor {
+		t.Errorf("Expected to go forward to component %d, got mode %d cursor %d", petCursor, model.mode, model.cursor)
+	}
+
+	// Nothing left to go forward to
+	model = pressKeys(model, "ctrl+n")
+	if model.mode != viewComponents || model.cursor != petCursor {
+		t.Error("Going forward with an empty history should keep the location")
+	}
+}
+
+func TestExpandCollapseAll(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+
+	model = pressKeys(model, "E")
+	for _, ep := range model.endpoints {
+		if ep.folded {
+			t.Fatalf("Endpoint %s %s should be unfolded after expand all", ep.method, ep.path)
+		}
+	}
+
+	model = pressKeys(model, "C")
+	for _, ep := range model.endpoints {
+		if !ep.folded {
+			t.Fatalf("Endpoint %s %s should be folded after collapse all", ep.method, ep.path)
+		}
+	}
+
+	model.mode = viewComponents
+	model = pressKeys(model, "z", "R")
+	for _, comp := range model.components {
+		if comp.folded {
+			t.Fatalf("Component %s should be unfolded after zR", comp.name)
+		}
+	}
+
+	// "M" without the "z" prefix does nothing
+	model = pressKeys(model, "M")
+	if model.components[0].folded {
+		t.Error("M without z prefix should not collapse items")
+	}
+
+	model = pressKeys(model, "z", "M")
+	if !model.components[0].folded {
+		t.Error("Components should be folded after zM")
+	}
+}
+
+func TestNestedSchemaDepth(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+	model.mode = viewComponents
+
+	petIdx := model.findComponent("Schema", "Pet")
+	if petIdx < 0 {
+		t.Fatal("Could not find Pet schema")
+	}
+
+	details := model.itemDetails(petIdx)
+	if !strings.Contains(details, "  - category: object (Category)\n") {
+		t.Errorf("Expected referenced property type, got:\n%s", details)
+	}
+	if !strings.Contains(details, "  - tags: array[Tag]\n") {
+		t.Errorf("Expected array item type, got:\n%s", details)
+	}
+	if strings.Contains(details, "    - name: string") {
+		t.Error("Nested properties should not be shown at the default depth")
+	}
+	if !strings.Contains(details, "  - category: object (Category)\n    "+icons.ellipsis+" expand (2 more properties)\n") {
+		t.Errorf("Expected the hidden nested properties to be counted, got:\n%s", details)
+	}
+
+	model = pressKeys(model, "+")
+	details = model.itemDetails(petIdx)
+	if !strings.Contains(details, "  - category: object (Category)\n    - id: integer (format: int64)\n    - name: string\n") {
+		t.Errorf("Expected nested Category properties, got:\n%s", details)
+	}
+	if !strings.Contains(details, "  - tags: array[Tag]\n    XML: wrapped, items: tag\n    - id: integer (format: int64)\n") {
+		t.Errorf("Expected nested Tag item properties, got:\n%s", details)
+	}
+
+	model = pressKeys(model, "-", "-")
+	if model.detailOpts.schemaDepth != defaultSchemaDepth {
+		t.Errorf("Depth should not go below %d, got %d", defaultSchemaDepth, model.detailOpts.schemaDepth)
+	}
+
+	// Request body schemas are expanded in endpoint details too
+	model.mode = viewEndpoints
+	model = pressKeys(model, "+")
+	for i, ep := range model.endpoints {
+		if ep.path == "/pet" && ep.method == "POST" {
+			details := model.itemDetails(i)
+			if !strings.Contains(details, "  - application/json (schema: Pet)\n    - category: object (Category)\n") {
+				t.Errorf("Expected request body property tree, got:\n%s", details)
+			}
+		}
+	}
+}
+
+func TestCircularSchemaReferences(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: Circular API
+  version: 1.0.0
+paths:
+  /categories:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Category'
+      responses:
+        '200':
+          description: OK
+components:
+  schemas:
+    Category:
+      type: object
+      properties:
+        name:
+          type: string
+        parent:
+          $ref: '#/components/schemas/Category'
+        children:
+          type: array
+          items:
+            $ref: '#/components/schemas/Category'
+        owner:
+          $ref: '#/components/schemas/Owner'
+    Owner:
+      type: object
+      properties:
+        favorite:
+          $ref: '#/components/schemas/Category'
+`
+
+	model := loadSpecModel(t, spec)
+	model.detailOpts.schemaDepth = maxSchemaDepth
+
+	model.mode = viewComponents
+	details := model.itemDetails(model.findComponent("Schema", "Category"))
+	expected := []string{
+		"  - children: array[Category] ↻ (circular: Category)\n",
+		"  - parent: object (Category) ↻ (circular: Category)\n",
+		"  - owner: object (Owner)\n    - favorite: object (Category) ↻ (circular: Category)\n",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(details, exp) {
+			t.Errorf("Expected %q in:\n%s", exp, details)
+		}
+	}
+
+	model.mode = viewEndpoints
+	details = model.itemDetails(0)
+	if !strings.Contains(details, "    - parent: object (Category) ↻ (circular: Category)\n") {
+		t.Errorf("Expected circular marker in request body tree:\n%s", details)
+	}
+
+	// The Owner schema expands Category once before detecting the cycle
+	model.mode = viewComponents
+	details = model.itemDetails(model.findComponent("Schema", "Owner"))
+	if !strings.Contains(details, "  - favorite: object (Category)\n    - children: array[Category] ↻ (circular: Category)\n") {
+		t.Errorf("Expected one level of Category below Owner:\n%s", details)
+	}
+}
+
+func TestEndpointExamples(t *testing.T) {
+	model := loadExampleModel(t, "examples/museums-3.1.yaml")
+
+	idx := -1
+	for i, ep := range model.endpoints {
+		if ep.path == "/special-events" && ep.method == "POST" {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		t.Fatal("Could not find POST /special-events endpoint")
+	}
+
+	details := model.itemDetails(idx)
+	expected := []string{
+		"    Example (default_example):\n",
+		`"name": "Mermaid Treasure Identification and Analysis"`,
+		"  - 201: Created.\n    - application/json (schema: SpecialEvent)\n      Example (default_example):\n",
+		"more lines (press x to expand)",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(details, exp) {
+			t.Errorf("Expected %q in:\n%s", exp, details)
+		}
+	}
+
+	model = pressKeys(model, "x")
+	details = model.itemDetails(idx)
+	if strings.Contains(details, "more lines") {
+		t.Errorf("Examples should not be truncated after pressing x:\n%s", details)
+	}
+}
+
+func TestFormatExample(t *testing.T) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte("42"), &node); err != nil {
+		t.Fatal(err)
+	}
+	if got := formatExample("Example", &node, "  ", false); got != "  Example: 42\n" {
+		t.Errorf("Unexpected inline example: %q", got)
+	}
+
+	if got := formatExample("Example", nil, "  ", false); got != "" {
+		t.Errorf("Expected nothing for a missing example, got %q", got)
+	}
+}
+
+func TestResponseSchemaDisplay(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+
+	for i, ep := range model.endpoints {
+		if ep.path != "/pet" || ep.method != "PUT" {
+			continue
+		}
+
+		details := model.itemDetails(i)
+		expected := []string{
+			"  - 200: Successful operation\n    - application/json (schema: Pet)\n    - application/xml (schema: Pet)\n",
+			"  - 404: Pet not found\n",
+			"  - default: Unexpected error\n    - application/json (schema: Error)\n",
+		}
+		for _, exp := range expected {
+			if !strings.Contains(details, exp) {
+				t.Errorf("Expected %q in:\n%s", exp, details)
+			}
+		}
+
+		model.detailOpts.schemaDepth = 2
+		details = model.itemDetails(i)
+		if !strings.Contains(details, "    - application/json (schema: Error)\n      - code: string\n") {
+			t.Errorf("Expected response schema tree in:\n%s", details)
+		}
+		return
+	}
+
+	t.Fatal("Could not find PUT /pet endpoint")
+}
+
+func TestOperationSecurity(t *testing.T) {
+	spec := `openapi: 3.0.3
+info:
+  title: Secure API
+  version: 1.0.0
+security:
+  - api_key: []
+paths:
+  /inherited:
+    get:
+      responses:
+        '200':
+          description: OK
+  /oauth:
+    get:
+      security:
+        - oauth: [read, write]
+          api_key: []
+        - {}
+      responses:
+        '200':
+          description: OK
+  /public:
+    get:
+      security: []
+      responses:
+        '200':
+          description: OK
+components:
+  securitySchemes:
+    api_key:
+      type: apiKey
+      in: header
+      name: X-API-Key
+    oauth:
+      type: oauth2
+      flows:
+        clientCredentials:
+          tokenUrl: https://example.com/token
+          scopes:
+            read: Read access
+            write: Write access
+`
+
+	model := loadSpecModel(t, spec)
+
+	expected := map[string]string{
+		"/inherited": "Security (global):\n  - api_key\n",
+		"/oauth":     "Security:\n  - oauth [read, write] + api_key\n  - none (optional)\n",
+		"/public":    "Security: none\n",
+	}
+
+	for _, ep := range model.endpoints {
+		details := formatEndpointDetails(ep)
+		if !strings.Contains(details, expected[ep.path]) {
+			t.Errorf("Expected %q for %s in:\n%s", expected[ep.path], ep.path, details)
+		}
+	}
+}
+
+func TestInfoView(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+
+	model = pressKeys(model, "H")
+	if model.mode != viewInfo {
+		t.Fatalf("Expected Shift+Tab from endpoints to open the info view, got mode %d", model.mode)
+	}
+
+	view := model.View()
+	for _, expected := range []string{
+		"Swagger Petstore - OpenAPI 3.0",
+		"Version: 1.0.12",
+		"Terms of Service: https://swagger.io/terms/",
+		"Contact: apiteam@swagger.io",
+		"License: Apache 2.0 (https://www.apache.org/licenses/LICENSE-2.0.html)",
+		"External Docs: Find out more about Swagger (https://swagger.io)",
+	} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected info view to contain %q", expected)
+		}
+	}
+
+	model = pressKeys(model, "L")
+	if model.mode != viewEndpoints {
+		t.Errorf("Expected Tab from info view to return to endpoints, got mode %d", model.mode)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	src := "# Title\n\nSome **bold** and `code` text\nwith a [link](https://example.com).\n\n- one\n- two\n\n```\nx: 1\n```"
+
+	expected := "Title\n\nSome bold and code text with a link (https://example.com).\n\n• one\n• two\n\n  x: 1"
+	if got := renderMarkdown(src, 80); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	wrapped := renderMarkdown("aaa bbb ccc ddd", 8)
+	if wrapped != "aaa bbb\nccc ddd" {
+		t.Errorf("Expected paragraph to wrap at width, got %q", wrapped)
+	}
+}
+
+func TestOperationCallbacks(t *testing.T) {
+	spec := `openapi: 3.0.3
+info:
+  title: Callbacks
+  version: 1.0.0
+paths:
+  /subscriptions:
+    post:
+      responses:
+        '201':
+          description: Created
+      callbacks:
+        onEvent:
+          '{$request.body#/callbackUrl}':
+            post:
+              summary: Event notification
+              requestBody:
+                content:
+                  application/json:
+                    schema:
+                      $ref: '#/components/schemas/Event'
+              responses:
+                '204':
+                  description: Received
+components:
+  schemas:
+    Event:
+      type: object
+      properties:
+        id:
+          type: string
+`
+
+	model := loadSpecModel(t, spec)
+	if len(model.endpoints) != 1 {
+		t.Fatalf("Expected 1 endpoint, got %d", len(model.endpoints))
+	}
+
+	details := formatEndpointDetails(model.endpoints[0])
+	expected := "Callbacks:\n" +
+		"  - onEvent\n" +
+		"    {$request.body#/callbackUrl}\n" +
+		"      POST: Event notification\n" +
+		"        Request Body:\n" +
+		"          - application/json (schema: Event)\n" +
+		"        Responses:\n" +
+		"          - 204: Received\n"
+	if !strings.Contains(details, expected) {
+		t.Errorf("Expected callbacks section:\n%s\nin:\n%s", expected, details)
+	}
+
+	refs := operationReferences(model.endpoints[0].op)
+	if !slices.Contains(refs, "#/components/schemas/Event") {
+		t.Errorf("Expected callback schema in references, got %v", refs)
+	}
+}
+
+func TestResponseLinks(t *testing.T) {
+	spec := `openapi: 3.0.3
+info:
+  title: Links
+  version: 1.0.0
+paths:
+  /users:
+    post:
+      operationId: createUser
+      responses:
+        '201':
+          description: Created
+          links:
+            GetUser:
+              operationId: getUser
+              description: The created user
+              parameters:
+                userId: $response.body#/id
+            DeleteUser:
+              operationRef: '#/paths/~1users~1{userId}/delete'
+              parameters:
+                userId: $response.body#/id
+  /users/{userId}:
+    get:
+      operationId: getUser
+      responses:
+        '200':
+          description: OK
+    delete:
+      responses:
+        '204':
+          description: Deleted
+`
+
+	model := loadSpecModel(t, spec)
+
+	create := model.findEndpoint("/users", "POST")
+	if create < 0 {
+		t.Fatal("Expected POST /users endpoint")
+	}
+
+	details := formatEndpointDetails(model.endpoints[create])
+	expected := "    Links:\n" +
+		"      - GetUser → getUser\n" +
+		"        The created user\n" +
+		"        userId = $response.body#/id\n" +
+		"      - DeleteUser → #/paths/~1users~1{userId}/delete\n"
+	if !strings.Contains(details, expected) {
+		t.Errorf("Expected links:\n%s\nin:\n%s", expected, details)
+	}
+
+	model.cursor = create
+	model = pressKeys(model, "g", "d")
+	if model.picker == nil {
+		t.Fatal("Expected a picker for two links")
+	}
+
+	var labels []string
+	for _, item := range model.picker.items {
+		labels = append(labels, item.label)
+	}
+	expectedLabels := []string{"Link GetUser: GET /users/{userId}", "Link DeleteUser: DELETE /users/{userId}"}
+	if !slices.Equal(labels, expectedLabels) {
+		t.Fatalf("Expected picker items %v, got %v", expectedLabels, labels)
+	}
+
+	model = pressKeys(model, "j", "enter")
+	ep := model.endpoints[model.cursor]
+	if model.mode != viewEndpoints || ep.method != "DELETE" || ep.path != "/users/{userId}" || ep.folded {
+		t.Errorf("Expected to land on unfolded DELETE /users/{userId}, got %s %s", ep.method, ep.path)
+	}
+}
+
+func TestParseOperationRef(t *testing.T) {
+	tests := []struct {
+		ref    string
+		path   string
+		method string
+		ok     bool
+	}{
+		{"#/paths/~1pet~1{petId}/get", "/pet/{petId}", "GET", true},
+		{"https://example.com/openapi.json#/paths/~12.0~1users/post", "/2.0/users", "POST", true},
+		{"#/paths/~1users~1%7Bid%7D/delete", "/users/{id}", "DELETE", true},
+		{"#/components/schemas/Pet", "", "", false},
+	}
+
+	for _, tt := range tests {
+		path, method, ok := parseOperationRef(tt.ref)
+		if path != tt.path || method != tt.method || ok != tt.ok {
+			t.Errorf("parseOperationRef(%q) = %q, %q, %v", tt.ref, path, method, ok)
+		}
+	}
+
+	if ref := operationRef("/pet/{petId}", "GET"); ref != "#/paths/~1pet~1{petId}/get" {
+		t.Errorf("Unexpected operationRef: %s", ref)
+	}
+}
+
+func TestExternalDocs(t *testing.T) {
+	spec := `openapi: 3.0.3
+info:
+  title: Docs
+  version: 1.0.0
+tags:
+  - name: pets
+    externalDocs:
+      url: https://example.com/tags/pets
+paths:
+  /pets:
+    get:
+      tags: [pets]
+      responses:
+        '200':
+          description: OK
+    post:
+      externalDocs:
+        description: Creating pets
+        url: https://example.com/create
+      responses:
+        '201':
+          description: Created
+components:
+  schemas:
+    Pet:
+      type: object
+      externalDocs:
+        url: https://example.com/pet
+    Script:
+      type: object
+      externalDocs:
+        url: file:///usr/bin/xterm
+`
+
+	var opened []string
+	original := openURL
+	openURL = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+	defer func() { openURL = original }()
+
+	model := loadSpecModel(t, spec)
+
+	post := model.findEndpoint("/pets", "POST")
+	details := formatEndpointDetails(model.endpoints[post])
+	if !strings.Contains(details, "External Docs: Creating pets (https://example.com/create)\n") {
+		t.Errorf("Expected operation external docs in:\n%s", details)
+	}
+
+	open := func(m Model) {
+		t.Helper()
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+		if cmd == nil {
+			t.Fatal("Expected a command to open the browser")
+		}
+		cmd()
+	}
+
+	model.cursor = post
+	open(model)
+
+	model.cursor = model.findEndpoint("/pets", "GET")
+	open(model)
+
+	model.mode = viewComponents
+	model.cursor = model.findComponent("Schema", "Pet")
+	if details := model.itemDetails(model.cursor); !strings.Contains(details, "External Docs: https://example.com/pet\n") {
+		t.Errorf("Expected schema external docs in:\n%s", details)
+	}
+	open(model)
+
+	expected := []string{"https://example.com/create", "https://example.com/tags/pets", "https://example.com/pet"}
+	if !slices.Equal(opened, expected) {
+		t.Errorf("Expected opened URLs %v, got %v", expected, opened)
+	}
+
+	// Links other than web pages aren't opened
+	model.cursor = model.findComponent("Schema", "Script")
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	if cmd != nil || !strings.HasPrefix(updated.(Model).message, "Not opening file:///usr/bin/xterm") {
+		t.Errorf("Expected the file link not to be opened, got %q", updated.(Model).message)
+	}
+	for link, want := range map[string]bool{
+		"https://example.com/docs": true,
+		"http://localhost:8080":    true,
+		"https://":                 false,
+		"/usr/bin/xterm":           false,
+		"-a Terminal":              false,
+		"javascript:alert(1)":      false,
+	} {
+		if got := isWebURL(link); got != want {
+			t.Errorf("Expected isWebURL(%q) to be %v", link, want)
+		}
+	}
+}
+
+func TestConfigKeyBindings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfgData := `keys:
+  down: [ctrl+n, j]
+  up: [Ctrl+P, k]
+  top: ["g  g", "<"]
+`
+	if err := os.WriteFile(path, []byte(cfgData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	keys, err := cfg.keyMap()
+	if err != nil {
+		t.Fatalf("Failed to build keymap: %v", err)
+	}
+
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+	model.keys = keys
+
+	model = pressKeys(model, "ctrl+n", "ctrl+n", "j")
+	if model.cursor != 3 {
+		t.Errorf("Expected ctrl+n and j to move down to 3, got %d", model.cursor)
+	}
+
+	model = pressKeys(model, "ctrl+p")
+	if model.cursor != 2 {
+		t.Errorf("Expected ctrl+p to move up to 2, got %d", model.cursor)
+	}
+
+	model = pressKeys(model, "<")
+	if model.cursor != 0 {
+		t.Errorf("Expected < to move to the top, got %d", model.cursor)
+	}
+
+	// ctrl+n was taken over from "forward", which keeps its other keys (none)
+	if got := keys.keysFor(actionForward); got != "" {
+		t.Errorf("Expected forward to lose ctrl+n, got %q", got)
+	}
+
+	model.showHelp = true
+	help := model.View()
+	for _, expected := range []string{"Ctrl-N/j", "Ctrl-P/k", "gg/<"} {
+		if !strings.Contains(help, expected) {
+			t.Errorf("Expected help to show active binding %q", expected)
+		}
+	}
+	if strings.Contains(help, "Go forward to next location") {
+		t.Error("Expected unbound actions to be hidden from help")
+	}
+
+	missing, err := loadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil || missing.Keys != nil {
+		t.Errorf("Expected a missing config file to give the defaults, got %v, %v", missing, err)
+	}
+}
+
+func TestConfigKeyBindingErrors(t *testing.T) {
+	tests := map[string]map[string][]string{
+		"unknown action": {"jump": {"j"}},
+		"duplicate key":  {"up": {"k"}, "down": {"k"}},
+		"empty key":      {"up": {""}},
+	}
+
+	for name, overrides := range tests {
+		if _, err := (config{Keys: overrides}).keyMap(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestFormatKey(t *testing.T) {
+	tests := map[string]string{
+		"up":        "↑",
+		"g g":       "gg",
+		"z R":       "zR",
+		"ctrl+u":    "Ctrl-U",
+		" ":         "Space",
+		"shift+tab": "Shift+Tab",
+		"?":         "?",
+	}
+
+	for key, expected := range tests {
+		if got := formatKey(key); got != expected {
+			t.Errorf("formatKey(%q) = %q, expected %q", key, got, expected)
+		}
+	}
+}
+
+func TestThemes(t *testing.T) {
+	defer applyTheme(themes[defaultThemeName])
+
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+	model.endpoints[0].folded = false
+
+	for _, name := range themeNames() {
+		if err := setTheme(name); err != nil {
+			t.Fatalf("Failed to set theme %s: %v", name, err)
+		}
+		if view := model.View(); !strings.Contains(view, "/pet") {
+			t.Errorf("Expected endpoints to render with the %s theme", name)
+		}
+	}
+
+	if err := setTheme("solarized"); err == nil || !strings.Contains(err.Error(), "high-contrast") {
+		t.Errorf("Expected an error listing the available themes, got %v", err)
+	}
+
+	mono := themes["monochrome"]
+	if style := withBackground(lipgloss.NewStyle(), mono.selection); !style.GetReverse() {
+		t.Error("Expected monochrome selection to use reverse video")
+	}
+}
+
+func TestASCIIMode(t *testing.T) {
+	setASCII(true)
+	defer setASCII(false)
+
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+	model.endpoints[0].folded = false
+	model = pressKeys(model, "j", "j")
+
+	isASCII := func(s string) bool {
+		for _, r := range s {
+			if r > 127 {
+				return false
+			}
+		}
+		return true
+	}
+
+	views := map[string]string{"list": model.View()}
+	model.showHelp = true
+	views["help"] = model.View()
+
+	for name, view := range views {
+		for _, line := range strings.Split(view, "\n") {
+			if !isASCII(line) {
+				t.Errorf("Expected only ASCII in the %s view, got %q", name, line)
+			}
+		}
+	}
+
+	if !strings.Contains(views["list"], "v POST") || !strings.Contains(views["list"], "> PUT") {
+		t.Errorf("Expected ASCII fold icons in:\n%s", views["list"])
+	}
+	if !strings.Contains(views["help"], "Up/k") {
+		t.Errorf("Expected ASCII key names in help:\n%s", views["help"])
+	}
+}
+
+func TestFooterStatus(t *testing.T) {
+	model := loadExampleModel(t, "examples/museums-3.1.yaml")
+
+	schemas := 0
+	for _, comp := range model.components {
+		if comp.compType == "Schema" {
+			schemas++
+		}
+	}
+
+	expected := fmt.Sprintf("%d endpoints · %d schemas · %s", len(model.endpoints), schemas, pluralize(len(model.webhooks), "webhook"))
+	if footer := model.renderFooter(); !strings.Contains(footer, expected) {
+		t.Errorf("Expected footer to contain %q, got:\n%s", expected, footer)
+	}
+
+	// Narrow terminals drop the counts before the title
+	model.width = 50
+	footer := model.renderFooter()
+	if strings.Contains(footer, "endpoints") {
+		t.Errorf("Expected counts to be dropped at width 50, got:\n%s", footer)
+	}
+	if !strings.Contains(footer, model.doc.Info.Title) {
+		t.Errorf("Expected the title to stay visible, got:\n%s", footer)
+	}
+
+	model.width = 5
+	_ = model.renderFooter()
+
+	// With only deprecated items listed, the totals stay along with the number listed
+	model = loadSpecModel(t, `openapi: 3.0.3
+info: {title: Filtered, version: "1"}
+paths:
+  /pets:
+    get:
+      deprecated: true
+      responses: {"200": {description: OK}}
+  /owners:
+    get:
+      responses: {"200": {description: OK}}
+components:
+  schemas:
+    Pet: {type: object}
+    Owner: {type: object}
+`)
+	if got, want := model.statusText(), "2 endpoints · 2 schemas"; got != want {
+		t.Errorf("Expected status %q, got %q", want, got)
+	}
+	model = pressKeys(model, "D")
+	if got, want := model.statusText(), "2 endpoints (1 filtered) · 2 schemas (0 filtered)"; got != want {
+		t.Errorf("Expected status %q, got %q", want, got)
+	}
+	model = pressKeys(model, "D")
+	if got, want := model.statusText(), "2 endpoints · 2 schemas"; got != want {
+		t.Errorf("Expected status %q, got %q", want, got)
+	}
+}
+
+func TestPageNavigation(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+	model.height = 12 // Room for 4 lines of content
+
+	model = pressKeys(model, "pgdown")
+	if model.cursor != 4 || model.scrollOffset != 4 {
+		t.Errorf("Expected first page down to land on 4, got cursor %d offset %d", model.cursor, model.scrollOffset)
+	}
+
+	// The "More items above" indicator takes a line from now on
+	model = pressKeys(model, "pgdown")
+	if model.cursor != 7 || model.scrollOffset != 7 {
+		t.Errorf("Expected second page down to land on 7, got cursor %d offset %d", model.cursor, model.scrollOffset)
+	}
+
+	model = pressKeys(model, "pgup")
+	if model.cursor != 4 || model.scrollOffset != 4 {
+		t.Errorf("Expected page up to return to 4, got cursor %d offset %d", model.cursor, model.scrollOffset)
+	}
+
+	// An unfolded item taller than the screen is skipped as a whole
+	model.endpoints[4].folded = false
+	model = pressKeys(model, "pgdown")
+	if model.cursor != 5 {
+		t.Errorf("Expected page down past a tall item to land on 5, got %d", model.cursor)
+	}
+
+	model = pressKeys(model, "end")
+	if model.cursor != len(model.endpoints)-1 {
+		t.Errorf("Expected End to move to the last item, got %d", model.cursor)
+	}
+
+	model = pressKeys(model, "pgdown")
+	if model.cursor != len(model.endpoints)-1 {
+		t.Errorf("Expected page down to stay on the last item, got %d", model.cursor)
+	}
+
+	model = pressKeys(model, "home")
+	if model.cursor != 0 || model.scrollOffset != 0 {
+		t.Errorf("Expected Home to move to the top, got cursor %d offset %d", model.cursor, model.scrollOffset)
+	}
+
+	model = pressKeys(model, "pgup")
+	if model.cursor != 0 {
+		t.Errorf("Expected page up at the top to stay at 0, got %d", model.cursor)
+	}
+}
+
+func TestHorizontalScrolling(t *testing.T) {
+	spec := `openapi: 3.0.3
+info:
+  title: Long
+  version: 1.0.0
+paths:
+  /organizations/{organizationId}/projects/{projectId}/environments/{environmentId}/deployments:
+    get:
+      responses:
+        '200':
+          description: OK
+`
+
+	model := loadSpecModel(t, spec)
+	model.width = 40
+
+	contentLine := func(m Model) string {
+		for _, line := range strings.Split(m.View(), "\n") {
+			if strings.Contains(line, "GET") || strings.Contains(line, "/") {
+				return line
+			}
+		}
+		return ""
+	}
+
+	line := contentLine(model)
+	if !strings.HasSuffix(strings.TrimRight(line, " "), "…") || lipgloss.Width(line) > model.width {
+		t.Errorf("Expected long path to be truncated with an ellipsis within 40 columns, got %q", line)
+	}
+
+	model = pressKeys(model, "right", "z", "l")
+	if model.hOffset != 2*horizontalScrollStep {
+		t.Errorf("Expected right and zl to scroll by %d, got %d", 2*horizontalScrollStep, model.hOffset)
+	}
+	if line := contentLine(model); !strings.HasPrefix(line, "…") {
+		t.Errorf("Expected scrolled line to start with an ellipsis, got %q", line)
+	}
+
+	for range 20 {
+		model = pressKeys(model, "right")
+	}
+	if line := contentLine(model); !strings.Contains(line, "deployments") {
+		t.Errorf("Expected to scroll to the end of the path, got %q", line)
+	}
+
+	model = pressKeys(model, "z", "h")
+	model = pressKeys(model, "left", "left", "left", "left", "left", "left", "left", "left", "left", "left")
+	if model.hOffset != 0 {
+		t.Errorf("Expected scrolling left to stop at 0, got %d", model.hOffset)
+	}
+}
+
+func TestWrapDescriptions(t *testing.T) {
+	var b strings.Builder
+	writeWrapped(&b, "Description: ", "The quick brown fox jumps over the lazy dog\n\nSecond paragraph", "  ", 24)
+
+	expected := "Description: The quick\n" +
+		"  brown fox jumps over\n" +
+		"  the lazy dog\n" +
+		"\n" +
+		"  Second paragraph\n"
+	if b.String() != expected {
+		t.Errorf("Expected:\n%q\ngot:\n%q", expected, b.String())
+	}
+
+	b.Reset()
+	writeWrapped(&b, "  - id (path): ", "unwrapped when the width is zero", "    ", 0)
+	if b.String() != "  - id (path): unwrapped when the width is zero\n" {
+		t.Errorf("Expected no wrapping at width 0, got %q", b.String())
+	}
+
+	spec := `openapi: 3.0.3
+info:
+  title: Wrap
+  version: 1.0.0
+paths:
+  /items:
+    get:
+      description: Returns all items that the caller is allowed to see, newest first, with pagination.
+      parameters:
+        - name: q
+          in: query
+          description: A search query matched against item names and descriptions
+      responses:
+        '200':
+          description: A page of items together with the cursor of the next page
+`
+
+	model := loadSpecModel(t, spec)
+	model.width = 40
+
+	details := model.itemDetails(0)
+	for _, line := range strings.Split(details, "\n") {
+		if lipgloss.Width(line) > calculateContentWidth(model.width) {
+			t.Errorf("Expected details to wrap at 38 columns, got %q", line)
+		}
+	}
+	if !strings.Contains(details, "  Query:\n    - q: A search query matched\n      against item names and\n      descriptions\n") {
+		t.Errorf("Expected parameter description with a hanging indent in:\n%s", details)
+	}
+}
+
+func TestEndpointColumns(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+
+	rowOf := func(m Model, path, method string) string {
+		for _, line := range strings.Split(m.View(), "\n") {
+			if strings.Contains(line, method) && strings.Contains(line, path+" ") {
+				return line
+			}
+		}
+		return ""
+	}
+
+	if row := rowOf(model, "/pet", "PUT"); !strings.Contains(row, "updatePet — Update an existing pet.") {
+		t.Errorf("Expected operationId and summary columns, got %q", row)
+	}
+
+	parse := func(data string) config {
+		t.Helper()
+		var cfg config
+		if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+
+	columns, err := parse("columns: [summary]").columns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	model.columns = columns
+	if row := rowOf(model, "/pet", "PUT"); strings.Contains(row, "updatePet") || !strings.Contains(row, "Update an existing pet.") {
+		t.Errorf("Expected only the summary column, got %q", row)
+	}
+
+	columns, err = parse("columns: []").columns()
+	if err != nil || len(columns) != 0 {
+		t.Errorf("Expected an empty column list to hide the columns, got %v, %v", columns, err)
+	}
+
+	if columns, _ := parse("theme: light").columns(); !slices.Equal(columns, defaultColumns) {
+		t.Errorf("Expected default columns when not configured, got %v", columns)
+	}
+
+	if _, err := parse("columns: [tags]").columns(); err == nil {
+		t.Error("Expected an error for an unknown column")
+	}
+}
+
+func TestDetailFields(t *testing.T) {
+	parse := func(data string) config {
+		t.Helper()
+		var cfg config
+		if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+
+	model := loadExampleModel(t, "examples/petstore-3.1.yaml")
+	model = pressKeys(model, "j", "enter")
+	details := model.itemDetails(1)
+	if !strings.Contains(details, "\nDescription: ") || !strings.Contains(details, "Responses:") {
+		t.Fatalf("Expected a description and responses, got:\n%s", details)
+	}
+
+	hidden, err := parse("hide_details: [description, responses]").hiddenDetails()
+	if err != nil {
+		t.Fatal(err)
+	}
+	model.detailOpts.hidden = hidden
+	details = model.itemDetails(1)
+	if strings.Contains(details, "\nDescription: ") || strings.Contains(details, "Responses:") || !strings.Contains(details, "Summary: ") {
+		t.Errorf("Expected the description and responses hidden, got:\n%s", details)
+	}
+	if _, err := parse("hide_details: [examples]").hiddenDetails(); err == nil || !strings.Contains(err.Error(), "available sections: summary, description") {
+		t.Errorf("Expected an error listing the sections, got %v", err)
+	}
+
+	// Component descriptions are a column that can be left out
+	model = pressKeys(model, "tab")
+	if !strings.Contains(model.View(), "RequestBody:    Pet - Pet object that needs to be added to the store") {
+		t.Fatalf("Expected component descriptions in the list, got:\n%s", model.View())
+	}
+	columns, err := parse("component_columns: []").componentColumns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	model.componentColumns = columns
+	for _, line := range strings.Split(model.View(), "\n") {
+		if strings.Contains(line, "RequestBody:") && strings.Contains(line, "- ") {
+			t.Errorf("Expected no component descriptions, got %q", line)
+		}
+	}
+	if columns, _ := parse("theme: light").componentColumns(); !slices.Equal(columns, defaultComponentColumns) {
+		t.Errorf("Expected default component columns when not configured, got %v", columns)
+	}
+	if _, err := parse("component_columns: [type]").componentColumns(); err == nil {
+		t.Error("Expected an error for an unknown component column")
+	}
+}
+
+func TestCopyWithOSC52(t *testing.T) {
+	if err := copyWithOSC52(nil, "getPetById"); !errors.Is(err, errNoClipboard) {
+		t.Errorf("Expected no clipboard without a terminal, got %v", err)
+	}
+
+	var out strings.Builder
+	if err := copyWithOSC52(&out, "getPetById"); err != nil {
+		t.Fatal(err)
+	}
+	encoded := base64.StdEncoding.EncodeToString([]byte("getPetById"))
+	if !strings.Contains(out.String(), "\x1b]52;c;"+encoded) {
+		t.Errorf("Expected the OSC 52 sequence, got %q", out.String())
+	}
+}
+
+func TestYank(t *testing.T) {
+	var copied []string
+	original := copyToClipboard
+	copyToClipboard = func(text string) error {
+		copied = append(copied, text)
+		return nil
+	}
+	defer func() { copyToClipboard = original }()
+
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+
+	yank := func(m Model, key string) Model {
+		t.Helper()
+		m = pressKeys(m, "y")
+		updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = updated.(Model)
+		if cmd != nil {
+			updated, _ = m.Update(cmd())
+			m = updated.(Model)
+		}
+		return m
+	}
+
+	model.cursor = model.findEndpoint("/pet/{petId}", "GET")
+	model = yank(model, "p")
+	model = yank(model, "i")
+	model = yank(model, "r")
+
+	model.mode = viewComponents
+	model.cursor = model.findComponent("Schema", "Pet")
+	model = yank(model, "r")
+
+	expected := []string{"/pet/{petId}", "getPetById", "#/paths/~1pet~1{petId}/get", "#/components/schemas/Pet"}
+	if !slices.Equal(copied, expected) {
+		t.Errorf("Expected copied %v, got %v", expected, copied)
+	}
+
+	if footer := model.renderFooter(); !strings.Contains(footer, "Copied #/components/schemas/Pet") {
+		t.Errorf("Expected a confirmation in the footer, got:\n%s", footer)
+	}
+	if footer := pressKeys(model, "j").renderFooter(); strings.Contains(footer, "Copied") {
+		t.Errorf("Expected the confirmation to clear on the next key, got:\n%s", footer)
+	}
+
+	// Components have no operationId
+	model = yank(model, "i")
+	if len(copied) != len(expected) || !strings.Contains(model.renderFooter(), "Nothing to copy") {
+		t.Errorf("Expected nothing to be copied, got %v", copied)
+	}
+}
+
+func TestCurlCommand(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Curl
+  version: 1.0.0
+servers:
+  - url: https://{region}.example.com/v1/
+    variables:
+      region:
+        default: eu
+paths:
+  /pets/{petId}:
+    put:
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          example: 42
+        - name: dryRun
+          in: query
+          required: true
+          schema:
+            type: boolean
+        - name: verbose
+          in: query
+          schema:
+            type: boolean
+        - name: X-Request-Id
+          in: header
+          required: true
+          schema:
+            type: string
+      security:
+        - key: []
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                id:
+                  type: integer
+                  readOnly: true
+                name:
+                  type: string
+                  example: O'Malley
+                tags:
+                  type: array
+                  items:
+                    type: string
+                    format: uuid
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json: {}
+components:
+  securitySchemes:
+    key:
+      type: apiKey
+      in: query
+      name: api_key
+`
+
+	var copied []string
+	original := copyToClipboard
+	copyToClipboard = func(text string) error {
+		copied = append(copied, text)
+		return nil
+	}
+	defer func() { copyToClipboard = original }()
+
+	model := loadSpecModel(t, spec)
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if cmd == nil {
+		t.Fatal("Expected a command to copy the curl command")
+	}
+	if msg := cmd().(clipboardMsg); msg.what != "curl command" {
+		t.Errorf("Expected a curl command confirmation, got %q", msg.what)
+	}
+
+	expected := `curl -X PUT 'https://eu.example.com/v1/pets/42?dryRun=<dryRun>&api_key=<key>' \
+  -H 'X-Request-Id: <X-Request-Id>' \
+  -H 'Accept: application/json' \
+  -H 'Content-Type: application/json' \
+  -d '{
+  "name": "O'\''Malley",
+  "tags": [
+    "00000000-0000-0000-0000-000000000000"
+  ]
+}'`
+	if len(copied) != 1 || copied[0] != expected {
+		t.Errorf("Expected curl command:\n%s\ngot:\n%v", expected, copied)
+	}
+}
+
+func TestSnippets(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Snippets
+  version: 1.0.0
+servers:
+  - url: https://api.example.com
+paths:
+  /pets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+                  example: Rex
+                vaccinated:
+                  type: boolean
+                owner:
+                  type: string
+                  nullable: true
+                  example: null
+      responses:
+        "201":
+          description: Created
+`
+
+	var copied []string
+	original := copyToClipboard
+	copyToClipboard = func(text string) error {
+		copied = append(copied, text)
+		return nil
+	}
+	defer func() { copyToClipboard = original }()
+
+	model := loadSpecModel(t, spec)
+	model = pressKeys(model, "s")
+	if model.picker == nil || len(model.picker.items) != len(snippetLanguages) {
+		t.Fatalf("Expected a picker with all snippet languages, got %+v", model.picker)
+	}
+
+	// Python is the fourth language
+	model = pressKeys(model, "j", "j", "j")
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if model.picker != nil || cmd == nil {
+		t.Fatal("Expected the picker to close and copy the snippet")
+	}
+	cmd()
+
+	expected := `import requests
+
+response = requests.request(
+    "POST",
+    "https://api.example.com/pets",
+    json={
+        "name": "Rex",
+        "vaccinated": False,
+        "owner": None,
+    },
+)
+print(response.status_code, response.text)`
+	if len(copied) != 1 || copied[0] != expected {
+		t.Errorf("Expected Python snippet:\n%s\ngot:\n%v", expected, copied)
+	}
+
+	req := buildSampleRequest(model.doc, model.endpoints[0])
+	for lang, want := range map[string]string{
+		"HTTPie":             `http POST 'https://api.example.com/pets'`,
+		"JavaScript (fetch)": `"Content-Type": "application/json"`,
+		"Go (net/http)":      `http.NewRequest("POST", "https://api.example.com/pets", body)`,
+	} {
+		l, ok := findSnippetLanguage(lang)
+		if !ok {
+			t.Fatalf("Unknown snippet language %s", lang)
+		}
+		if snippet := l.render(req); !strings.Contains(snippet, want) {
+			t.Errorf("Expected %s snippet to contain %q, got:\n%s", lang, want, snippet)
+		}
+	}
+
+	// The Go snippet is a whole program, importing what it uses
+	upload := sampleRequest{method: "POST", url: "https://api.example.com/pets", form: []formField{{name: "photo", value: "photo.png", file: true}}}
+	for req, want := range map[*sampleRequest][]string{
+		&req:    {"fmt", "io", "log", "net/http", "strings"},
+		&upload: {"bytes", "fmt", "io", "log", "mime/multipart", "net/http", "os"},
+	} {
+		snippet := goSnippet(*req)
+		file, err := parser.ParseFile(token.NewFileSet(), "main.go", snippet, 0)
+		if err != nil {
+			t.Fatalf("Expected the Go snippet to parse, got %v:\n%s", err, snippet)
+		}
+		var imports []string
+		for _, spec := range file.Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+			imports = append(imports, path)
+		}
+		hasMain := slices.ContainsFunc(file.Decls, func(decl ast.Decl) bool {
+			fn, ok := decl.(*ast.FuncDecl)
+			return ok && fn.Name.Name == "main"
+		})
+		if file.Name.Name != "main" || !hasMain || !slices.Equal(imports, want) {
+			t.Errorf("Expected a main package importing %v, got:\n%s", want, snippet)
+		}
+	}
+}
+
+func TestTryItValidation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pets/1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprint(w, `{"id": "one", "tags": [{"label": "x"}], "status": "lost"}`)
+	}))
+	defer server.Close()
+
+	spec := fmt.Sprintf(`openapi: 3.0.0
+info:
+  title: Try
+  version: 1.0.0
+servers:
+  - url: %s
+paths:
+  /pets/{petId}:
+    get:
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          example: 1
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id, name]
+                properties:
+                  id:
+                    type: integer
+                  name:
+                    type: string
+                  status:
+                    type: string
+                    enum: [available, sold]
+                  tags:
+                    type: array
+                    items:
+                      type: object
+                      required: [name]
+                      properties:
+                        name:
+                          type: string
+`, server.URL)
+
+	model := loadSpecModel(t, spec)
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	model = updated.(Model)
+	if cmd == nil {
+		t.Fatal("Expected a command sending the request")
+	}
+	updated, _ = model.Update(cmd())
+	model = updated.(Model)
+
+	if model.response == nil || model.response.err != nil {
+		t.Fatalf("Expected a response, got %+v", model.response)
+	}
+
+	expected := []string{
+		"$.name: missing required property",
+		"$.id: expected integer, got string",
+		`$.status: "lost" is not one of "available", "sold"`,
+		"$.tags[0].name: missing required property",
+	}
+	if !slices.Equal(model.response.problems, expected) {
+		t.Errorf("Expected problems:\n%v\ngot:\n%v", expected, model.response.problems)
+	}
+
+	view := model.View()
+	for _, want := range []string{"200 OK", "missing required property", `"label"`} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected the response modal to contain %q, got:\n%s", want, view)
+		}
+	}
+
+	if model = pressKeys(model, "esc"); model.response != nil {
+		t.Error("Expected esc to close the response")
+	}
+
+	problems := validateResponse(model.endpoints[0].op, http.StatusNotFound, "", nil)
+	if !slices.Equal(problems, []string{"status 404 is not documented"}) {
+		t.Errorf("Expected an undocumented status, got %v", problems)
+	}
+}
+
+func TestValidateValue(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Validate
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Cat:
+      type: object
+      required: [meows]
+      properties:
+        meows:
+          type: boolean
+    Dog:
+      type: object
+      required: [barks]
+      properties:
+        barks:
+          type: boolean
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+    Weight:
+      type: number
+      nullable: true
+`
+
+	model := loadSpecModel(t, spec)
+	schema := func(name string) *base.SchemaProxy {
+		proxy, _ := model.doc.Components.Schemas.Get(name)
+		return proxy
+	}
+
+	tests := []struct {
+		schema   string
+		value    string
+		problems int
+	}{
+		{"Pet", `{"meows": true}`, 0},
+		{"Pet", `{"barks": false}`, 0},
+		{"Pet", `{"quacks": true}`, 1},
+		{"Weight", `2`, 0},
+		{"Weight", `2.5`, 0},
+		{"Weight", `null`, 0},
+		{"Weight", `"heavy"`, 1},
+	}
+
+	for _, tt := range tests {
+		var node yaml.Node
+		if err := yaml.Unmarshal([]byte(tt.value), &node); err != nil {
+			t.Fatal(err)
+		}
+		if problems := validateValue(schema(tt.schema), &node); len(problems) != tt.problems {
+			t.Errorf("%s %s: expected %d problems, got %v", tt.schema, tt.value, tt.problems, problems)
+		}
+	}
+}
+
+func TestEnvironments(t *testing.T) {
+	t.Setenv("OQ_TEST_TOKEN", "secret")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfgData := `environments:
+  staging:
+    base_url: https://staging.example.com/api/
+    token: $OQ_TEST_TOKEN
+    headers:
+      x-tenant: acme
+  dev:
+    base_url: http://localhost:8080
+`
+	if err := os.WriteFile(path, []byte(cfgData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	envs, err := cfg.environments()
+	if err != nil {
+		t.Fatalf("Failed to read environments: %v", err)
+	}
+
+	cfg.Environment = "prod"
+	if _, err := cfg.environments(); err == nil || !strings.Contains(err.Error(), `unknown environment "prod"`) {
+		t.Errorf("Expected an unknown environment error, got %v", err)
+	}
+
+	var copied []string
+	original := copyToClipboard
+	copyToClipboard = func(text string) error {
+		copied = append(copied, text)
+		return nil
+	}
+	defer func() { copyToClipboard = original }()
+
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+	model.environments = envs
+	model.cursor = model.findEndpoint("/pet/findByStatus", "GET")
+
+	// The picker lists "none" first, then the environments by name
+	model = pressKeys(model, "e", "j", "j", "enter")
+	if model.environment != "staging" {
+		t.Fatalf("Expected staging to be selected, got %q", model.environment)
+	}
+	if footer := model.renderFooter(); !strings.Contains(footer, "env: staging") {
+		t.Errorf("Expected the environment in the footer, got:\n%s", footer)
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	cmd()
+
+	expected := `curl 'https://staging.example.com/api/pet/findByStatus?status=available' \
+  -H 'Authorization: Bearer secret' \
+  -H 'Accept: application/json' \
+  -H 'x-tenant: acme'`
+	if len(copied) != 1 || copied[0] != expected {
+		t.Errorf("Expected curl command:\n%s\ngot:\n%v", expected, copied)
+	}
+
+	model = pressKeys(model, "e", "k", "k", "enter")
+	if model.environment != "" {
+		t.Errorf("Expected to switch back to the servers of the spec, got %q", model.environment)
+	}
+}
+
+func TestExport(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+	model.cursor = model.findEndpoint("/pet/findByStatus", "GET")
+
+	// Exports are written to the current directory
+	t.Chdir(t.TempDir())
+
+	// Postman for the endpoint comes first
+	model = pressKeys(model, "X", "enter")
+	data, err := os.ReadFile("findPetsByStatus.postman_collection.json")
+	if err != nil {
+		t.Fatalf("Expected a Postman collection to be written: %v (%s)", err, model.message)
+	}
+
+	var collection postmanCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		t.Fatalf("Invalid Postman collection: %v", err)
+	}
+	if collection.Info.Schema != postmanSchemaURL || len(collection.Item) != 1 {
+		t.Fatalf("Unexpected collection: %+v", collection)
+	}
+	if item := collection.Item[0]; item.Name != "Finds Pets by status." || item.Request.URL != "https://petstore3.swagger.io/api/v3/pet/findByStatus?status=available" {
+		t.Errorf("Unexpected collection item: %+v", item)
+	}
+
+	// Then the pet tag, then the same for Insomnia
+	model = pressKeys(model, "X", "j", "j", "j", "enter")
+	if model.message != "Exported to pet.insomnia.json" {
+		t.Errorf("Expected a confirmation, got %q", model.message)
+	}
+	data, err = os.ReadFile("pet.insomnia.json")
+	if err != nil {
+		t.Fatalf("Expected an Insomnia export to be written: %v", err)
+	}
+
+	var export insomniaExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("Invalid Insomnia export: %v", err)
+	}
+	if want := 1 + len(model.taggedEndpoints("pet")); len(export.Resources) != want {
+		t.Errorf("Expected a workspace and %d requests, got %d resources", want-1, len(export.Resources))
+	}
+	for _, res := range export.Resources[1:] {
+		if res.Type != "request" || res.ParentID != export.Resources[0].ID {
+			t.Errorf("Expected requests in the workspace, got %+v", res)
+		}
+	}
+
+	// Exporting again keeps the earlier file
+	model = pressKeys(model, "X", "j", "j", "j", "enter")
+	if model.message != "Exported to pet-2.insomnia.json" {
+		t.Errorf("Expected a numbered file, got %q", model.message)
+	}
+	if again, err := os.ReadFile("pet.insomnia.json"); err != nil || string(again) != string(data) {
+		t.Errorf("Expected the earlier export to be kept, got %v", err)
+	}
+}
+
+func TestWhereUsed(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+	model.mode = viewComponents
+	model.cursor = model.findComponent("Schema", "Category")
+
+	// Category is only used through Pet
+	model = pressKeys(model, "g", "r")
+	if model.picker == nil {
+		t.Fatalf("Expected a picker of usages, got message %q", model.message)
+	}
+
+	var labels []string
+	for _, item := range model.picker.items {
+		labels = append(labels, item.label)
+	}
+	for _, want := range []string{"POST /pet: request body", "POST /pet: response 200", "GET /pet/findByStatus: response 200"} {
+		if !slices.Contains(labels, want) {
+			t.Errorf("Expected usage %q in %v", want, labels)
+		}
+	}
+	if slices.ContainsFunc(labels, func(label string) bool { return strings.HasPrefix(label, "GET /store/inventory") }) {
+		t.Errorf("Expected /store/inventory not to use Category, got %v", labels)
+	}
+
+	// Selecting a usage jumps to the operation
+	model = pressKeys(model, "enter")
+	if model.mode != viewEndpoints || model.endpoints[model.cursor].path != "/pet" || model.endpoints[model.cursor].method != "POST" {
+		t.Errorf("Expected to jump to POST /pet, got %v %d", model.mode, model.cursor)
+	}
+
+	model = pressKeys(model, "ctrl+o")
+	if model.mode != viewComponents {
+		t.Errorf("Expected ctrl+o to go back to the components view")
+	}
+}
+
+func TestSecurityReport(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Security
+  version: 1.0.0
+security:
+  - bearer: []
+paths:
+  /public:
+    get:
+      security: []
+      responses:
+        "200":
+          description: OK
+  /maybe:
+    get:
+      security:
+        - key: []
+        - {}
+      responses:
+        "200":
+          description: OK
+  /private:
+    get:
+      responses:
+        "200":
+          description: OK
+components:
+  securitySchemes:
+    bearer:
+      type: http
+      scheme: bearer
+    key:
+      type: apiKey
+      in: header
+      name: X-Key
+    unused:
+      type: oauth2
+      flows: {}
+`
+
+	model := loadSpecModel(t, spec)
+	report := formatSecurityReport(model.doc, model.endpoints)
+
+	for _, want := range []string{
+		"  - bearer (http bearer): 1 operation\n      GET /private\n",
+		"  - key (apiKey in header): 1 operation\n      GET /maybe\n",
+		"  - unused (oauth2): not used\n",
+		"Without Security (2):\n  - GET /maybe (security optional)\n  - GET /public\n",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected the report to contain %q, got:\n%s", want, report)
+		}
+	}
+
+	model.mode = viewComponents
+	model.cursor = model.findComponent("SecurityScheme", "bearer")
+	model = pressKeys(model, "g", "r")
+	if model.picker == nil || len(model.picker.items) != 1 || model.picker.items[0].label != "GET /private: security" {
+		t.Errorf("Expected bearer to be used by GET /private, got %+v", model.picker)
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Deprecated
+  version: 1.0.0
+paths:
+  /v1/pets:
+    get:
+      deprecated: true
+      x-sunset: "2025-12-31"
+      parameters:
+        - name: sort
+          in: query
+          deprecated: true
+          schema:
+            type: string
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+  /v2/pets:
+    get:
+      responses:
+        "200":
+          description: OK
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+        nickname:
+          type: string
+          deprecated: true
+    OldPet:
+      type: object
+      x-deprecated: true
+`
+
+	model := loadSpecModel(t, spec)
+
+	ep := model.endpoints[model.findEndpoint("/v1/pets", "GET")]
+	details := formatEndpointDetails(ep)
+	for _, want := range []string{"Deprecated: sunset 2025-12-31\n", "  Query:\n    - sort (deprecated):\n"} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected the details to contain %q, got:\n%s", want, details)
+		}
+	}
+
+	view := model.View()
+	if !strings.Contains(view, "/v1/pets deprecated, sunset 2025-12-31") {
+		t.Errorf("Expected a deprecation badge, got:\n%s", view)
+	}
+
+	pet := model.components[model.findComponent("Schema", "Pet")]
+	if pet.deprecation.deprecated {
+		t.Error("Expected Pet not to be deprecated")
+	}
+	if details := formatComponentDetails(pet, detailOptions{schemaDepth: defaultSchemaDepth}); !strings.Contains(details, "- nickname: string (deprecated)") {
+		t.Errorf("Expected nickname to be marked deprecated, got:\n%s", details)
+	}
+
+	// The filter keeps the deprecated items only, in every view
+	model.endpoints[model.findEndpoint("/v1/pets", "GET")].folded = false
+	model = pressKeys(model, "D")
+	if len(model.endpoints) != 1 || model.endpoints[0].path != "/v1/pets" {
+		t.Fatalf("Expected only /v1/pets, got %+v", model.endpoints)
+	}
+	if len(model.components) != 1 || model.components[0].name != "OldPet" {
+		t.Errorf("Expected only OldPet, got %+v", model.components)
+	}
+	if footer := model.renderFooter(); !strings.Contains(footer, "deprecated only") {
+		t.Errorf("Expected the footer to show the filter, got %q", footer)
+	}
+
+	// Jumping to a component hidden by the filter turns it off
+	if !model.jumpToReference("#/components/schemas/Pet") {
+		t.Fatal("Expected to jump to Pet")
+	}
+	if model.unfiltered != nil || model.components[model.cursor].name != "Pet" {
+		t.Errorf("Expected the filter to be off with Pet selected, got cursor %d", model.cursor)
+	}
+	if model.endpoints[model.findEndpoint("/v1/pets", "GET")].folded {
+		t.Error("Expected /v1/pets to stay unfolded")
+	}
+
+	model = pressKeys(model, "D", "D")
+	if model.unfiltered != nil || len(model.endpoints) != 2 {
+		t.Errorf("Expected D to turn the filter off again, got %d endpoints", len(model.endpoints))
+	}
+}
+
+func TestExtensions(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Extensions
+  version: 1.0.0
+  x-logo: https://example.com/logo.png
+x-tagGroups:
+  - name: Pets
+    tags: [pets]
+servers:
+  - url: https://api.example.com
+    x-region: eu-west-1
+paths:
+  /pets:
+    get:
+      x-internal: true
+      x-amazon-apigateway-integration:
+        type: http_proxy
+        uri: https://backend.example.com/pets
+      responses:
+        "200":
+          description: OK
+components:
+  schemas:
+    Pet:
+      type: object
+      x-go-type: pets.Pet
+`
+
+	model := loadSpecModel(t, spec)
+
+	details := formatEndpointDetails(model.endpoints[0])
+	for _, want := range []string{
+		"Extensions:\n  x-internal: true\n  x-amazon-apigateway-integration: {…} 2 keys\n  (press z e to expand)\n",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected the details to contain %q, got:\n%s", want, details)
+		}
+	}
+
+	model = pressKeys(model, "z", "e")
+	if !model.detailOpts.expandExtensions {
+		t.Fatal("Expected z e to expand extensions")
+	}
+	details = formatEndpointDetailsWithOptions(model.endpoints[0], model.detailOpts)
+	if !strings.Contains(details, "  x-amazon-apigateway-integration:\n    {\n      \"type\": \"http_proxy\",") {
+		t.Errorf("Expected the integration to be pretty-printed, got:\n%s", details)
+	}
+
+	comp := model.components[model.findComponent("Schema", "Pet")]
+	if details := formatComponentDetails(comp, model.detailOpts); !strings.Contains(details, "  x-go-type: pets.Pet\n") {
+		t.Errorf("Expected schema extensions, got:\n%s", details)
+	}
+
+	info := strings.Join(model.infoLines(), "\n")
+	for _, want := range []string{
+		"  - https://api.example.com\n    Extensions:\n      x-region: eu-west-1\n",
+		"Extensions:\n  x-logo: https://example.com/logo.png\n  x-tagGroups:\n    [\n",
+	} {
+		if !strings.Contains(info, want) {
+			t.Errorf("Expected the info page to contain %q, got:\n%s", want, info)
+		}
+	}
+}
+
+func TestSchemaCompositions(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Compositions
+  version: 1.0.0
+paths:
+  /pets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              oneOf:
+                - $ref: '#/components/schemas/Cat'
+                - $ref: '#/components/schemas/Dog'
+      responses:
+        "200":
+          description: OK
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+      discriminator:
+        propertyName: petType
+        mapping:
+          cat: '#/components/schemas/Cat'
+          dog: '#/components/schemas/Dog'
+    Cat:
+      allOf:
+        - $ref: '#/components/schemas/Base'
+        - type: object
+          properties:
+            lives:
+              type: integer
+    Dog:
+      type: object
+      properties:
+        bark:
+          type: string
+        friend:
+          anyOf:
+            - $ref: '#/components/schemas/Cat'
+            - type: string
+    Base:
+      type: object
+      properties:
+        petType:
+          type: string
+`
+
+	model := loadSpecModel(t, spec)
+
+	pet := model.components[model.findComponent("Schema", "Pet")]
+	details := formatComponentDetails(pet, detailOptions{schemaDepth: defaultSchemaDepth})
+	want := "oneOf: Cat | Dog\nDiscriminator: petType\n  cat → Cat\n  dog → Dog\n"
+	if details != want {
+		t.Errorf("Expected %q, got %q", want, details)
+	}
+
+	// Deeper levels expand each branch
+	details = formatComponentDetails(pet, detailOptions{schemaDepth: 2})
+	if !strings.Contains(details, "oneOf: Cat | Dog\n  - Cat\n  - Dog\n    - bark: string\n") {
+		t.Errorf("Expected expanded branches, got:\n%s", details)
+	}
+
+	cat := model.components[model.findComponent("Schema", "Cat")]
+	details = formatComponentDetails(cat, detailOptions{schemaDepth: 2})
+	if !strings.Contains(details, "allOf: Base & object\n  - Base\n    - petType: string\n  - object\n    - lives: integer\n") {
+		t.Errorf("Expected allOf branches, got:\n%s", details)
+	}
+
+	dog := model.components[model.findComponent("Schema", "Dog")]
+	details = formatComponentDetails(dog, detailOptions{schemaDepth: defaultSchemaDepth})
+	if !strings.Contains(details, "- friend: anyOf(Cat | string)\n") {
+		t.Errorf("Expected a composed property label, got:\n%s", details)
+	}
+
+	details = formatEndpointDetails(model.endpoints[0])
+	if !strings.Contains(details, "- application/json (oneOf: Cat | Dog)\n") {
+		t.Errorf("Expected the request body to show its branches, got:\n%s", details)
+	}
+}
+
+func TestSchemaConstraints(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Constraints
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            format: int32
+            default: 20
+            minimum: 1
+            maximum: 100
+            exclusiveMaximum: true
+      responses:
+        "200":
+          description: OK
+components:
+  schemas:
+    Status:
+      type: string
+      enum: [available, pending, sold]
+      default: available
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+          minLength: 1
+          maxLength: 50
+          pattern: ^[a-z]+$
+        weight:
+          type: number
+          multipleOf: 0.5
+        tags:
+          type: array
+          uniqueItems: true
+          maxItems: 5
+          items:
+            type: string
+`
+
+	model := loadSpecModel(t, spec)
+
+	status := model.components[model.findComponent("Schema", "Status")]
+	details := formatComponentDetails(status, detailOptions{schemaDepth: defaultSchemaDepth})
+	want := "Type: string\nEnum: available | pending | sold\nDefault: available\n"
+	if details != want {
+		t.Errorf("Expected %q, got %q", want, details)
+	}
+
+	pet := model.components[model.findComponent("Schema", "Pet")]
+	details = formatComponentDetails(pet, detailOptions{schemaDepth: defaultSchemaDepth})
+	for _, want := range []string{
+		"  - name: string (minLength: 1, maxLength: 50, pattern: ^[a-z]+$)\n",
+		"  - tags: array[string] (maxItems: 5, uniqueItems)\n",
+		"  - weight: number (multipleOf: 0.5)\n",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected the details to contain %q, got:\n%s", want, details)
+		}
+	}
+
+	details = formatEndpointDetails(model.endpoints[0])
+	want = "    Schema: integer (format: int32, default: 20, minimum: 1, exclusiveMaximum: 100)\n"
+	if !strings.Contains(details, want) {
+		t.Errorf("Expected the details to contain %q, got:\n%s", want, details)
+	}
+}
+
+func TestPropertyFlags(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: Flags
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id:
+          type: integer
+          readOnly: true
+        password:
+          type: string
+          writeOnly: true
+        nickname:
+          type: [string, "null"]
+        tags:
+          type: array
+          items:
+            type: [string, "null"]
+`
+
+	model := loadSpecModel(t, spec)
+	user := model.components[model.findComponent("Schema", "User")]
+	details := formatComponentDetails(user, detailOptions{schemaDepth: defaultSchemaDepth})
+	for _, want := range []string{
+		"  - id: integer (read-only)\n",
+		"  - nickname: string | null\n",
+		"  - password: string (write-only)\n",
+		"  - tags: array[string | null]\n",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected the details to contain %q, got:\n%s", want, details)
+		}
+	}
+
+	spec = `openapi: 3.0.0
+info:
+  title: Flags
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        nickname:
+          type: string
+          nullable: true
+`
+
+	model = loadSpecModel(t, spec)
+	user = model.components[model.findComponent("Schema", "User")]
+	details = formatComponentDetails(user, detailOptions{schemaDepth: defaultSchemaDepth})
+	if !strings.Contains(details, "  - nickname: string (nullable)\n") {
+		t.Errorf("Expected nickname to be nullable, got:\n%s", details)
+	}
+}
+
+func TestMapSchemas(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Maps
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Inventory:
+      type: object
+      additionalProperties:
+        $ref: '#/components/schemas/Stock'
+    Stock:
+      type: object
+      additionalProperties: false
+      properties:
+        count:
+          type: integer
+    Store:
+      type: object
+      properties:
+        inventory:
+          $ref: '#/components/schemas/Inventory'
+        labels:
+          type: object
+          additionalProperties:
+            type: string
+        metadata:
+          type: object
+          additionalProperties: true
+        stock:
+          $ref: '#/components/schemas/Stock'
+`
+
+	model := loadSpecModel(t, spec)
+
+	inventory := model.components[model.findComponent("Schema", "Inventory")]
+	details := formatComponentDetails(inventory, detailOptions{schemaDepth: defaultSchemaDepth})
+	if details != "Type: object\nAdditional Properties: Stock\n" {
+		t.Errorf("Unexpected Inventory details:\n%s", details)
+	}
+	details = formatComponentDetails(inventory, detailOptions{schemaDepth: 2})
+	if !strings.Contains(details, "Additional Properties: Stock\n  - count: integer\n") {
+		t.Errorf("Expected the value properties at a deeper level, got:\n%s", details)
+	}
+
+	stock := model.components[model.findComponent("Schema", "Stock")]
+	details = formatComponentDetails(stock, detailOptions{schemaDepth: defaultSchemaDepth})
+	if !strings.Contains(details, "Additional Properties: none (sealed)\n") {
+		t.Errorf("Expected Stock to be sealed, got:\n%s", details)
+	}
+
+	store := model.components[model.findComponent("Schema", "Store")]
+	details = formatComponentDetails(store, detailOptions{schemaDepth: defaultSchemaDepth})
+	for _, want := range []string{
+		"  - inventory: map[string] → Stock (Inventory)\n",
+		"  - labels: map[string] → string\n",
+		"  - metadata: map[string] → any\n",
+		"  - stock: object (Stock) (sealed)\n",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected the details to contain %q, got:\n%s", want, details)
+		}
+	}
+}
+
+func TestParameterDetails(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Parameters
+  version: 1.0.0
+paths:
+  /pets/{petId}:
+    get:
+      parameters:
+        - name: X-Request-ID
+          in: header
+          schema:
+            type: string
+            format: uuid
+        - name: tags
+          in: query
+          style: form
+          explode: false
+          schema:
+            type: array
+            items:
+              type: string
+          example: [cat, dog]
+        - name: petId
+          in: path
+          required: true
+          description: ID of the pet
+          schema:
+            type: integer
+            format: int64
+        - name: filter
+          in: query
+          content:
+            application/json:
+              schema:
+                type: object
+      responses:
+        "200":
+          description: OK
+`
+
+	model := loadSpecModel(t, spec)
+	details := formatEndpointDetails(model.endpoints[0])
+
+	want := `Parameters:
+  Path:
+    - petId (required): ID of the pet
+      Schema: integer (format: int64)
+  Query:
+    - tags:
+      Schema: array[string]
+      Style: form, explode: false
+      Example:
+        [
+          "cat",
+          "dog"
+        ]
+    - filter:
+      Content:
+        - application/json (type: object)
+  Header:
+    - X-Request-ID:
+      Schema: string (format: uuid)
+`
+	if !strings.Contains(details, want) {
+		t.Errorf("Expected the details to contain:\n%s\ngot:\n%s", want, details)
+	}
+}
+
+func TestPathLevelParameters(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Path parameters
+  version: 1.0.0
+servers:
+  - url: https://api.example.com
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: string
+      - name: verbose
+        in: query
+        description: Shared description
+        schema:
+          type: boolean
+    get:
+      parameters:
+        - name: verbose
+          in: query
+          description: Overridden description
+          schema:
+            type: boolean
+      responses:
+        "200":
+          description: OK
+`
+
+	model := loadSpecModel(t, spec)
+	ep := model.endpoints[0]
+
+	details := formatEndpointDetails(ep)
+	for _, want := range []string{"  Path:\n    - id (required):\n", "    - verbose: Overridden description\n"} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected the details to contain %q, got:\n%s", want, details)
+		}
+	}
+	if strings.Contains(details, "Shared description") {
+		t.Errorf("Expected the operation parameter to override the path one, got:\n%s", details)
+	}
+
+	if req := buildSampleRequest(model.doc, ep); req.url != "https://api.example.com/users/<id>" {
+		t.Errorf("Expected the path parameter in the sample URL, got %q", req.url)
+	}
+
+	// The raw source shows the operation as written
+	if raw := formatRawSource(ep.source, false); strings.Contains(raw, "in: path") {
+		t.Errorf("Expected the raw source without the path parameters, got:\n%s", raw)
+	}
+}
+
+func TestFormFields(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Forms
+  version: 1.0.0
+paths:
+  /pets/{petId}/photo:
+    post:
+      requestBody:
+        content:
+          multipart/form-data:
+            schema:
+              type: object
+              required: [photo]
+              properties:
+                photo:
+                  type: string
+                  format: binary
+                metadata:
+                  type: object
+                  properties:
+                    caption:
+                      type: string
+            encoding:
+              photo:
+                contentType: image/png, image/jpeg
+                headers:
+                  X-Checksum:
+                    schema:
+                      type: string
+              metadata:
+                contentType: application/json
+      responses:
+        "200":
+          description: OK
+  /pets/{petId}:
+    post:
+      requestBody:
+        $ref: '#/components/requestBodies/PetForm'
+      responses:
+        "200":
+          description: OK
+components:
+  requestBodies:
+    PetForm:
+      content:
+        application/x-www-form-urlencoded:
+          schema:
+            type: object
+            properties:
+              tags:
+                type: array
+                items:
+                  type: string
+          encoding:
+            tags:
+              style: form
+              explode: false
+`
+
+	model := loadSpecModel(t, spec)
+
+	details := formatEndpointDetails(model.endpoints[model.findEndpoint("/pets/{petId}/photo", "POST")])
+	want := `  - multipart/form-data (type: object)
+    Fields:
+      - photo (required): string (format: binary)
+        Content Type: image/png, image/jpeg
+        Headers:
+          - X-Checksum: string
+      - metadata: object
+        Content Type: application/json
+`
+	if !strings.Contains(details, want) {
+		t.Errorf("Expected the details to contain:\n%s\ngot:\n%s", want, details)
+	}
+
+	// Nested fields of a part are shown at deeper levels
+	details = formatEndpointDetailsWithOptions(model.endpoints[model.findEndpoint("/pets/{petId}/photo", "POST")], detailOptions{schemaDepth: 2})
+	if !strings.Contains(details, "        Content Type: application/json\n        - caption: string\n") {
+		t.Errorf("Expected the metadata fields at a deeper level, got:\n%s", details)
+	}
+
+	form := model.components[model.findComponent("RequestBody", "PetForm")]
+	want = "  - application/x-www-form-urlencoded (type: object)\n    Fields:\n      - tags: array[string]\n        Style: form, explode: false\n"
+	if details := formatComponentDetails(form, defaultDetailOptions); !strings.Contains(details, want) {
+		t.Errorf("Expected the details to contain:\n%s\ngot:\n%s", want, details)
+	}
+}
+
+func TestResponseHeaders(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+
+	details := formatEndpointDetails(model.endpoints[model.findEndpoint("/user/login", "GET")])
+	want := `  - 200: successful operation
+    Headers:
+      - X-Expires-After: string (format: date-time)
+        date in UTC when token expires
+      - X-Rate-Limit: integer (format: int32)
+        calls per hour allowed by the user
+    - application/json (type: string)
+`
+	if !strings.Contains(details, want) {
+		t.Errorf("Expected the details to contain:\n%s\ngot:\n%s", want, details)
+	}
+}
+
+func TestOAuthFlows(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: OAuth
+  version: 1.0.0
+paths: {}
+components:
+  securitySchemes:
+    oauth:
+      type: oauth2
+      flows:
+        clientCredentials:
+          tokenUrl: https://auth.example.com/token
+          scopes: {}
+        authorizationCode:
+          authorizationUrl: https://auth.example.com/authorize
+          tokenUrl: https://auth.example.com/token
+          refreshUrl: https://auth.example.com/refresh
+          scopes:
+            read: Read your data
+            admin: ""
+    oidc:
+      type: openIdConnect
+      openIdConnectUrl: https://auth.example.com/.well-known/openid-configuration
+`
+	model := loadSpecModel(t, spec)
+
+	oauth := model.components[model.findComponent("SecurityScheme", "oauth")].details()
+	want := `Type: oauth2
+Flows:
+  Client Credentials:
+    Token URL: https://auth.example.com/token
+    Scopes: none
+  Authorization Code:
+    Authorization URL: https://auth.example.com/authorize
+    Token URL: https://auth.example.com/token
+    Refresh URL: https://auth.example.com/refresh
+    Scopes:
+      - read: Read your data
+      - admin
+`
+	if !strings.Contains(oauth, want) {
+		t.Errorf("Expected the details to contain:\n%s\ngot:\n%s", want, oauth)
+	}
+
+	oidc := model.components[model.findComponent("SecurityScheme", "oidc")].details()
+	if !strings.Contains(oidc, "OpenID Connect URL: https://auth.example.com/.well-known/openid-configuration\n") {
+		t.Errorf("Expected the OpenID Connect URL, got:\n%s", oidc)
+	}
+
+	petstore := loadExampleModel(t, "examples/petstore-3.0.yaml")
+	details := petstore.components[petstore.findComponent("SecurityScheme", "petstore_auth")].details()
+	if !strings.Contains(details, "  Implicit:\n    Authorization URL: https://petstore3.swagger.io/oauth/authorize\n    Scopes:\n      - write:pets: modify pets in your account\n") {
+		t.Errorf("Expected the implicit flow with its scopes, got:\n%s", details)
+	}
+}
+
+func TestWebhookDetails(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: Webhooks
+  version: 1.0.0
+webhooks:
+  orderShipped:
+    post:
+      summary: Order shipped
+      parameters:
+        - name: X-Signature
+          in: header
+          required: true
+          schema:
+            type: string
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                orderId:
+                  type: string
+      responses:
+        "200":
+          description: Received
+        default:
+          description: Retried later
+`
+	model := loadSpecModel(t, spec)
+	if len(model.webhooks) != 1 {
+		t.Fatalf("Expected 1 webhook, got %d", len(model.webhooks))
+	}
+
+	details := formatWebhookDetails(model.webhooks[0])
+	for _, want := range []string{
+		"Parameters:\n  Header:\n    - X-Signature (required):\n      Schema: string\n",
+		"Request Body:\n  Required: true\n  - application/json (type: object)\n",
+		"Responses:\n  - 200: Received\n  - default: Retried later\n",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected the details to contain:\n%s\ngot:\n%s", want, details)
+		}
+	}
+}
+
+func TestNamedExamples(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Examples
+  version: 1.0.0
+paths:
+  /pets:
+    post:
+      parameters:
+        - name: kind
+          in: query
+          schema:
+            type: string
+          examples:
+            archived:
+              summary: Stored elsewhere
+              externalValue: https://example.com/kind.txt
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+            examples:
+              cat:
+                summary: A cat
+                value:
+                  name: Tom
+              dog:
+                summary: A dog
+                description: Good boy
+                value:
+                  name: Rex
+      responses:
+        "200":
+          description: OK
+`
+	model := loadSpecModel(t, spec)
+
+	details := model.itemDetails(0)
+	for _, want := range []string{
+		"      Examples (press v to open):\n        - archived: Stored elsewhere (external: https://example.com/kind.txt)\n",
+		"    Examples (press v to open):\n      - cat: A cat\n      - dog: A dog\n    Example (cat):\n",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected the details to contain:\n%s\ngot:\n%s", want, details)
+		}
+	}
+
+	model = pressKeys(model, "v")
+	if model.picker == nil || len(model.picker.items) != 3 {
+		t.Fatalf("Expected a picker with 3 examples, got %+v", model.picker)
+	}
+	if got := model.picker.items[2].label; got != "request body application/json: dog: A dog" {
+		t.Errorf("Unexpected picker label %q", got)
+	}
+
+	model = pressKeys(model, "j", "j", "enter")
+	if model.example == nil {
+		t.Fatal("Expected the example viewer to be open")
+	}
+	lines := strings.Join(model.exampleLines(), "\n")
+	if !strings.Contains(lines, "A dog\nGood boy\n\n{") || !strings.Contains(lines, `"name": "Rex"`) {
+		t.Errorf("Expected the summary, description and value of dog, got:\n%s", lines)
+	}
+
+	model = pressKeys(model, "esc")
+	if model.example != nil {
+		t.Error("Expected esc to close the example viewer")
+	}
+
+	model = pressKeys(model, "v", "enter")
+	if lines := model.exampleLines(); lines[len(lines)-1] != "External value: https://example.com/kind.txt" {
+		t.Errorf("Expected the external value, got %q", lines)
+	}
+}
+
+func TestXMLMetadata(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: XML
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Book:
+      type: object
+      xml:
+        name: book
+        namespace: https://example.com/schema
+        prefix: bk
+      properties:
+        id:
+          type: integer
+          xml:
+            attribute: true
+        authors:
+          type: array
+          xml:
+            wrapped: true
+          items:
+            type: string
+            xml:
+              name: author
+`
+	model := loadSpecModel(t, spec)
+	model.mode = viewComponents
+
+	details := model.itemDetails(model.findComponent("Schema", "Book"))
+	for _, want := range []string{
+		"XML: name: book, namespace: https://example.com/schema, prefix: bk\n",
+		"  - authors: array[string]\n    XML: wrapped, items: author\n",
+		"  - id: integer\n    XML: attribute\n",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected the details to contain:\n%s\ngot:\n%s", want, details)
+		}
+	}
+}
+
+func TestSourcePosition(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+
+	model.cursor = model.findEndpoint("/pet", "PUT")
+	if got := model.locationText(); got != "line 40" {
+		t.Errorf("Expected the line without a file name, got %q", got)
+	}
+
+	model.specPath = "examples/petstore-3.0.yaml"
+	if got := model.locationText(); got != "petstore-3.0.yaml:40:5" {
+		t.Errorf("Expected the location of PUT /pet, got %q", got)
+	}
+
+	model.mode = viewComponents
+	model.cursor = model.findComponent("Schema", "Pet")
+	if got := model.locationText(); got != "petstore-3.0.yaml:823:5" {
+		t.Errorf("Expected the location of the Pet schema, got %q", got)
+	}
+
+	model.mode = viewInfo
+	if got := model.locationText(); got != "" {
+		t.Errorf("Expected no location on the info page, got %q", got)
+	}
+
+	model.specPath = ""
+	model.mode = viewEndpoints
+	model.cursor = 0
+	model = pressKeys(model, "g", "e")
+	if model.message != "The spec was read from stdin and can't be edited" {
+		t.Errorf("Expected a message for specs read from stdin, got %q", model.message)
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	tests := []struct {
+		editor string
+		line   int
+		want   []string
+	}{
+		{"", 12, []string{"vi", "+12", "api.yaml"}},
+		{"nvim", 12, []string{"nvim", "+12", "api.yaml"}},
+		{"code --wait", 12, []string{"code", "--wait", "--goto", "api.yaml:12"}},
+		{"/usr/local/bin/subl", 12, []string{"/usr/local/bin/subl", "api.yaml:12"}},
+		{"vim", 0, []string{"vim", "api.yaml"}},
+	}
+
+	for _, tt := range tests {
+		cmd := editorCommand(tt.editor, "api.yaml", tt.line)
+		if !slices.Equal(cmd.Args, tt.want) {
+			t.Errorf("editorCommand(%q, %d) = %q, want %q", tt.editor, tt.line, cmd.Args, tt.want)
+		}
+	}
+}
+
+func TestPagerCommand(t *testing.T) {
+	t.Setenv("LESS", "")
+	os.Unsetenv("LESS")
+	if cmd := pagerCommand(""); !slices.Equal(cmd.Args, []string{"less"}) || !slices.Contains(cmd.Env, "LESS=R") {
+		t.Errorf("Expected less showing colors, got %q with %q", cmd.Args, cmd.Env)
+	}
+	if cmd := pagerCommand("less -S"); !slices.Equal(cmd.Args, []string{"less", "-S"}) {
+		t.Errorf("Expected the pager arguments to be kept, got %q", cmd.Args)
+	}
+
+	t.Setenv("LESS", "FX")
+	if cmd := pagerCommand("less"); cmd.Env != nil {
+		t.Errorf("Expected $LESS to be left alone, got %q", cmd.Env)
+	}
+
+	model := loadExampleModel(t, "examples/petstore-3.1.yaml")
+	model = pressKeys(model, "tab", "tab", "|")
+	if model.message == "" {
+		t.Error("Expected a message when there is nothing to page")
+	}
+}
+
+func TestEndpointList(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: List
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      summary: "List pets\twith tabs"
+      responses:
+        "200":
+          description: OK
+    post:
+      description: No summary
+      responses:
+        "201":
+          description: Created
+`
+	model := loadSpecModel(t, spec)
+
+	var out strings.Builder
+	if err := writeEndpointList(&out, model.endpoints); err != nil {
+		t.Fatal(err)
+	}
+	want := "GET\t/pets\tList pets with tabs\nPOST\t/pets\t\n"
+	if out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+}
+
+func TestJSONOutput(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: Output
+  version: 2.0.0
+security:
+  - apiKey: []
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPet
+      deprecated: true
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        "404":
+          description: Not found
+        "200":
+          description: OK
+        default:
+          description: Error
+webhooks:
+  petAdded:
+    post:
+      security: []
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        "200":
+          description: OK
+components:
+  schemas:
+    Pet:
+      type: object
+      description: A pet
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-Key
+`
+	model := loadSpecModel(t, spec)
+
+	var out strings.Builder
+	if err := writeOutput(&out, outputJSON, model.doc); err != nil {
+		t.Fatal(err)
+	}
+
+	var got jsonDocument
+	if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out.String())
+	}
+
+	if got.Title != "Output" || got.Version != "2.0.0" {
+		t.Errorf("Unexpected title and version: %q %q", got.Title, got.Version)
+	}
+
+	if len(got.Endpoints) != 1 {
+		t.Fatalf("Expected 1 endpoint, got %d", len(got.Endpoints))
+	}
+	ep := got.Endpoints[0]
+	if ep.Method != "GET" || ep.Path != "/pets/{id}" || ep.OperationID != "getPet" || !ep.Deprecated {
+		t.Errorf("Unexpected endpoint %+v", ep)
+	}
+	if !slices.Equal(ep.Responses, []string{"200", "404", "default"}) {
+		t.Errorf("Expected sorted responses, got %v", ep.Responses)
+	}
+	if len(ep.Parameters) != 1 || ep.Parameters[0] != (jsonParameter{Name: "id", In: "path", Required: true, Type: "string"}) {
+		t.Errorf("Unexpected parameters %+v", ep.Parameters)
+	}
+	if len(ep.Security) != 1 || ep.Security[0]["apiKey"] == nil {
+		t.Errorf("Expected the global apiKey requirement, got %v", ep.Security)
+	}
+
+	if len(got.Webhooks) != 1 {
+		t.Fatalf("Expected 1 webhook, got %d", len(got.Webhooks))
+	}
+	hook := got.Webhooks[0]
+	if hook.Name != "petAdded" || hook.Method != "POST" || len(hook.Security) != 0 || !slices.Equal(hook.RequestTypes, []string{"application/json"}) {
+		t.Errorf("Unexpected webhook %+v", hook)
+	}
+
+	if !slices.Contains(got.Components, jsonComponent{Type: "Schema", Name: "Pet", Description: "A pet"}) {
+		t.Errorf("Expected the Pet schema in %+v", got.Components)
+	}
+
+	if err := writeOutput(&out, "xml", model.doc); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestMarkdownExport(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: Pets
+  version: 1.0.0
+tags:
+  - name: pets
+    description: Everything about pets
+  - name: unused
+paths:
+  /pets:
+    get:
+      tags: [pets]
+      summary: List pets
+      parameters:
+        - name: limit
+          in: query
+          description: How many
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+  /health:
+    get:
+      responses:
+        "204":
+          description: Healthy
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          description: Pet name
+        nickname:
+          type: [string, "null"]
+`
+	dir := t.TempDir()
+	specFile := filepath.Join(dir, "pets.yaml")
+	if err := os.WriteFile(specFile, []byte(spec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	outFile := filepath.Join(dir, "pets.md")
+
+	cmd, ok := findCommand("export")
+	if !ok {
+		t.Fatal("Expected an export command")
+	}
+	if err := runCommand(cmd, []string{"-format", "markdown", "-o", outFile, specFile}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := string(data)
+
+	for _, want := range []string{
+		"# Pets\n\nVersion: 1.0.0\n",
+		"## pets\n\nEverything about pets\n\n### `GET /pets` List pets\n",
+		"| `limit` | query | integer | no | How many |\n",
+		"| 200 | OK | `application/json`: array[Pet] |\n",
+		"## Other operations\n\n### `GET /health`\n",
+		"### Pet\n\n| Property | Type | Required | Description |\n| --- | --- | --- | --- |\n| `name` | string | yes | Pet name |\n| `nickname` | string \\| null | no |  |\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected the Markdown to contain:\n%s\ngot:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "## unused") {
+		t.Error("Tags without operations should not get a section")
+	}
+
+	if err := runCommand(cmd, []string{"-format", "pdf", specFile}); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestSpecDiff(t *testing.T) {
+	oldSpec := `openapi: 3.1.0
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+        - name: fields
+          in: query
+          schema:
+            type: string
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+        "404":
+          description: Not found
+  /health:
+    get:
+      responses:
+        "204":
+          description: Healthy
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        age:
+          type: integer
+        status:
+          type: string
+          enum: [available, pending, sold]
+`
+	newSpec := `openapi: 3.1.0
+info:
+  title: Pets
+  version: 2.0.0
+paths:
+  /pets/{petId}:
+    get:
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          schema:
+            type: string
+        - name: fields
+          in: query
+          required: true
+          schema:
+            type: string
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+  /pets:
+    get:
+      responses:
+        "200":
+          description: OK
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name, tag]
+      properties:
+        name:
+          type: string
+        age:
+          type: string
+        tag:
+          type: string
+        status:
+          type: string
+          enum: [available, sold, adopted]
+`
+	old, err := parseSpec([]byte(oldSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, err := parseSpec([]byte(newSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	changes := diffSpecs(old, updated)
+	if err := writeDiffText(&out, changes); err != nil {
+		t.Fatal(err)
+	}
+	text := out.String()
+
+	for _, want := range []string{
+		"6 breaking changes, 3 other changes\n",
+		"  - GET /health: endpoint removed\n",
+		"  - GET /pets/{petId}: query parameter fields became required\n",
+		"  - GET /pets/{petId}: response 404 removed\n",
+		"  - schema Pet.tag: became required\n",
+		"  - schema Pet.age: type changed from integer to string\n",
+		"  - schema Pet.status: enum values removed: pending\n",
+		"  - GET /pets: endpoint added\n",
+		"  - schema Pet.tag: property added\n",
+		"  - schema Pet.status: enum values added: adopted\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected the diff to contain:\n%s\ngot:\n%s", want, text)
+		}
+	}
+	if strings.Index(text, "Breaking changes:") > strings.Index(text, "Other changes:") {
+		t.Errorf("Expected the breaking changes first, got:\n%s", text)
+	}
+	if strings.Contains(text, "parameter petId") {
+		t.Errorf("Renaming a path parameter should not be a change, got:\n%s", text)
+	}
+
+	if changes := diffSpecs(old, old); len(changes) != 0 {
+		t.Errorf("Expected no changes between identical specs, got %v", changes)
+	}
+}
+
+func TestSpecDiffDirection(t *testing.T) {
+	spec := func(requestRequired, responseRequired, status string) string {
+		return fmt.Sprintf(`openapi: 3.1.0
+info: {title: Pets, version: "1"}
+paths:
+  /pets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: %s
+              properties:
+                name: {type: string}
+                tag: {type: string}
+                status: {type: string, enum: %s}
+      responses:
+        "201":
+          description: Created
+          content:
+            application/json:
+              schema:
+                type: object
+                required: %s
+                properties:
+                  id: {type: string}
+                  status: {type: string, enum: %s}
+`, requestRequired, status, responseRequired, status)
+	}
+	diff := func(before, after string) map[string]bool {
+		t.Helper()
+		old, err := parseSpec([]byte(before))
+		if err != nil {
+			t.Fatal(err)
+		}
+		updated, err := parseSpec([]byte(after))
+		if err != nil {
+			t.Fatal(err)
+		}
+		breaking := map[string]bool{}
+		for _, c := range diffSpecs(old, updated) {
+			breaking[c.Location+": "+c.Message] = c.Breaking
+		}
+		return breaking
+	}
+
+	request, response := "POST /pets request body application/json", "POST /pets response 201 application/json"
+
+	// Clients may send less and receive more
+	got := diff(spec("[name, tag]", "[]", "[a, b]"), spec("[name]", "[id]", "[a, b]"))
+	want := map[string]bool{
+		request + ".tag: no longer required": false,
+		response + ".id: became required":    false,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("Expected compatible changes %v, got %v", want, got)
+	}
+
+	// But not be asked for more nor told less
+	got = diff(spec("[name]", "[id]", "[a, b]"), spec("[name, tag]", "[]", "[a, b]"))
+	want = map[string]bool{
+		request + ".tag: became required":    true,
+		response + ".id: no longer required": true,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("Expected breaking changes %v, got %v", want, got)
+	}
+
+	// Values removed break those sending them, and values added those receiving them
+	got = diff(spec("[]", "[]", "[a, b]"), spec("[]", "[]", "[a, c]"))
+	want = map[string]bool{
+		request + ".status: enum values removed: b":  true,
+		request + ".status: enum values added: c":    false,
+		response + ".status: enum values removed: b": false,
+		response + ".status: enum values added: c":   true,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("Expected enum changes %v, got %v", want, got)
+	}
+}
+
+func TestInteractiveDiff(t *testing.T) {
+	oldSpec := `openapi: 3.1.0
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      summary: List pets
+      responses:
+        "200":
+          description: OK
+  /health:
+    get:
+      responses:
+        "204":
+          description: Healthy
+  /owners:
+    get:
+      responses:
+        "200":
+          description: OK
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+`
+	newSpec := `openapi: 3.1.0
+info:
+  title: Pets
+  version: 2.0.0
+paths:
+  /pets:
+    get:
+      summary: List all pets
+      responses:
+        "200":
+          description: OK
+  /owners:
+    get:
+      responses:
+        "200":
+          description: OK
+  /stores:
+    get:
+      responses:
+        "200":
+          description: OK
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: integer
+`
+	old, err := parseSpec([]byte(oldSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, err := parseSpec([]byte(newSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := diffItems(old, updated, diffSpecs(old, updated))
+	statuses := map[string]diffStatus{}
+	for _, item := range items {
+		statuses[item.name] = item.status
+	}
+	want := map[string]diffStatus{
+		"GET /pets":   diffChanged,
+		"GET /owners": diffUnchanged,
+		"GET /stores": diffAdded,
+		"GET /health": diffRemoved,
+		"schema Pet":  diffChanged,
+	}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("Expected %s to be %s, got %s", name, status, statuses[name])
+		}
+	}
+
+	var model tea.Model = newDiffModel("old.yaml", "new.yaml", items, defaultKeyMap())
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 160, Height: 30})
+	view := model.View()
+	if !strings.Contains(view, "old.yaml") || !strings.Contains(view, "new.yaml") {
+		t.Errorf("Expected both spec names in the view, got:\n%s", view)
+	}
+	if strings.Contains(view, "GET /owners") {
+		t.Errorf("Unchanged items should be hidden by default, got:\n%s", view)
+	}
+	if !strings.Contains(view, "Summary: List pets") || !strings.Contains(view, "Summary: List all pets") {
+		t.Errorf("Expected both versions of the selected endpoint side by side, got:\n%s", view)
+	}
+
+	// n jumps to the next item with breaking changes
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	view = model.View()
+	if !strings.Contains(view, "GET /health Removed") {
+		t.Errorf("Expected the removed endpoint to be selected, got:\n%s", view)
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	view = model.View()
+	if !strings.Contains(view, "schema Pet.name: type changed from string to integer") {
+		t.Errorf("Expected the changes of the schema, got:\n%s", view)
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if view := model.View(); !strings.Contains(view, "GET /owners") {
+		t.Errorf("Expected unchanged items after pressing u, got:\n%s", view)
+	}
+}
+
+func TestLint(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List pets
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+        "404":
+          description: Not found
+  /stores:
+    get:
+      operationId: list_stores
+      responses:
+        "200":
+          description: OK
+  /owners:
+    get:
+      operationId: getOwners
+      summary: List owners
+      responses:
+        default:
+          description: Error
+components:
+  schemas:
+    Pet:
+      description: A pet
+      type: object
+      properties:
+        petName:
+          type: string
+        birthDate:
+          type: string
+        owner_id:
+          type: string
+    Store:
+      type: object
+`
+	model := loadSpecModel(t, spec)
+
+	var got []string
+	for _, f := range model.lint() {
+		got = append(got, fmt.Sprintf("%s %s %s: %s", f.Rule, f.Severity, f.Location, f.Message))
+	}
+	want := []string{
+		"operation-description warning GET /stores: operation has no summary or description",
+		"operation-4xx-response warning GET /stores: operation documents no 4xx response",
+		"schema-description info Schema Store: schema has no description",
+		`naming-consistency warning GET /stores: operationId "list_stores" is snake_case while most are camelCase`,
+		`naming-consistency warning Schema Pet.owner_id: property "owner_id" is snake_case while most are camelCase`,
+		"unused-component warning Schema Store: component is not used by any operation",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected findings:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	// SARIF results point at the line of each item
+	root, err := parseSpecTree([]byte(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings := model.lint()
+	locateFindings(root.Content[0], findings)
+	var out strings.Builder
+	if err := writeSARIF(&out, lintSARIFRules(ruleset{}), findingsSARIF("pets.yaml", findings)); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal([]byte(out.String()), &log); err != nil {
+		t.Fatalf("Invalid SARIF: %v", err)
+	}
+	var located []string
+	for _, r := range log.Runs[0].Results {
+		region := r.Locations[0].PhysicalLocation.Region
+		located = append(located, fmt.Sprintf("%s %s %d:%d", r.RuleID, r.Level, region.StartLine, region.StartColumn))
+	}
+	wantLocated := []string{
+		"operation-description warning 20:5",
+		"operation-4xx-response warning 20:5",
+		"schema-description note 44:5",
+		"naming-consistency warning 20:5",
+		"naming-consistency warning 34:5",
+		"unused-component warning 44:5",
+	}
+	if !slices.Equal(located, wantLocated) {
+		t.Errorf("Expected SARIF results:\n%s\ngot:\n%s", strings.Join(wantLocated, "\n"), strings.Join(located, "\n"))
+	}
+	if len(log.Runs[0].Tool.Driver.Rules) != len(lintRules) {
+		t.Errorf("Expected every rule in the SARIF driver, got %d", len(log.Runs[0].Tool.Driver.Rules))
+	}
+
+	dir := t.TempDir()
+	rulesetFile := filepath.Join(dir, "ruleset.yaml")
+	if err := os.WriteFile(rulesetFile, []byte("rules:\n  unused-component: error\n  naming-consistency: off\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rs, err := loadRuleset(rulesetFile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	model.ruleset = rs
+	for _, f := range model.lint() {
+		if f.Rule == "naming-consistency" {
+			t.Errorf("Expected rules set to off to be skipped, got %v", f)
+		}
+		if f.Rule == "unused-component" && f.Severity != severityError {
+			t.Errorf("Expected the ruleset to raise unused-component to error, got %s", f.Severity)
+		}
+	}
+
+	if err := os.WriteFile(rulesetFile, []byte("rules:\n  no-such-rule: error\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadRuleset(rulesetFile, true); err == nil {
+		t.Error("Expected an error for an unknown rule")
+	}
+	if _, err := loadRuleset(filepath.Join(dir, "missing.yaml"), false); err != nil {
+		t.Errorf("A missing default ruleset should not be an error, got %v", err)
+	}
+
+	// The problems panel jumps to the item of the selected finding
+	model = pressKeys(model, "P")
+	if model.picker == nil || len(model.picker.items) != 4 {
+		t.Fatalf("Expected a picker with the findings, got %+v", model.picker)
+	}
+	model = pressKeys(model, "j", "j", "j", "enter")
+	if model.mode != viewComponents || model.components[model.cursor].name != "Store" {
+		t.Errorf("Expected to jump to the Store schema, got mode %v cursor %d", model.mode, model.cursor)
+	}
+}
+
+func TestValidateSpec(t *testing.T) {
+	spec := `openapi: 3.0.3
+info:
+  title: Broken
+  version: 1.0
+paths:
+  /pets/{petId}:
+    get:
+      operationId: getPet
+      parameters:
+        - name: id
+          in: path
+          schema:
+            type: strin
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pets'
+  /pets:
+    post:
+      operationId: getPet
+      summery: Create
+      security:
+        - apikey: []
+      responses:
+        "201": {}
+components:
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      name: X-API-Key
+      in: header
+  schemas:
+    Pet:
+      type: object
+`
+	var got []string
+	for _, is := range validateSpec("pets.yaml", []byte(spec)) {
+		got = append(got, fmt.Sprintf("%d:%d %s %s: %s -> %s", is.Line, is.Column, is.Rule, is.Pointer, is.Message, is.Fix))
+	}
+	want := []string{
+		`4:12 field-type #/info/version: "version" must be a string -> quote the value: version: "1.0"`,
+		`7:5 path-parameter #/paths/~1pets~1{petId}/get: path parameter "petId" is not declared -> add a parameter with name: petId, in: path and required: true`,
+		`10:11 parameter #/paths/~1pets~1{petId}/get/parameters/0: path parameter "id" must be required -> add required: true`,
+		`10:11 path-parameter #/paths/~1pets~1{petId}/get/parameters/0: path parameter "id" is not part of the path /pets/{petId} -> remove it, or add {id} to the path`,
+		`13:19 schema #/paths/~1pets~1{petId}/get/parameters/0/schema/type: invalid type "strin" -> did you mean "string"?`,
+		`20:23 reference #/paths/~1pets~1{petId}/get/responses/200/content/application~1json/schema/$ref: reference "#/components/schemas/Pets" can't be resolved -> did you mean "#/components/schemas/Pet"?`,
+		`23:20 unique-operationid #/paths/~1pets/post/operationId: operationId "getPet" is already used by #/paths/~1pets~1{petId}/get -> rename one of them`,
+		`24:7 unknown-field #/paths/~1pets/post/summery: unknown field "summery" in the operation -> did you mean "summary"?`,
+		`26:11 security-scheme #/paths/~1pets/post/security/0/apikey: security scheme "apikey" is not declared -> did you mean "apiKey"?`,
+		`28:16 required-field #/paths/~1pets/post/responses/201: missing required field "description" -> add a description, e.g. description: OK`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected issues:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	issues := validateSpec("broken.yaml", []byte("openapi: 3.1.0\ninfo: [\n"))
+	if len(issues) != 1 || issues[0].Rule != ruleSyntax || issues[0].Line == 0 {
+		t.Errorf("Expected a syntax error with its line, got %+v", issues)
+	}
+
+	petstore, err := os.ReadFile("examples/petstore-3.1.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issues := validateSpec("petstore.yaml", petstore); len(issues) != 0 {
+		t.Errorf("Expected the petstore example to be valid, got %+v", issues)
+	}
+
+	var out strings.Builder
+	if err := writeSARIF(&out, validationSARIFRules(), issuesSARIF(validateSpec("pets.yaml", []byte(spec)))); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal([]byte(out.String()), &log); err != nil {
+		t.Fatal(err)
+	}
+	result := log.Runs[0].Results[0]
+	if result.RuleID != ruleFieldType || result.Locations[0].PhysicalLocation.Region.StartLine != 4 ||
+		result.Locations[0].PhysicalLocation.ArtifactLocation.URI != "pets.yaml" {
+		t.Errorf("Unexpected first SARIF result: %+v", result)
+	}
+}
+
+func TestDiagnoseSyntax(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		snippet string
+		hint    string
+	}{
+		{
+			name:    "tab",
+			content: "openapi: 3.1.0\ninfo:\n  title: T\n\tversion: 1.0.0\n",
+			want:    "line 4, column 1: found a tab character that violates indentation",
+			snippet: "2 | info:\n3 |   title: T\n4 |  version: 1.0.0\n  | ^\n",
+			hint:    "spaces",
+		},
+		{
+			name:    "unquoted star",
+			content: "openapi: 3.1.0\ninfo:\n  summary: *bold*\n",
+			want:    "line 3, column 12: unknown anchor 'bold*' referenced",
+			snippet: "1 | openapi: 3.1.0\n2 | info:\n3 |   summary: *bold*\n  |            ^\n",
+			hint:    `"*bold*"`,
+		},
+		{
+			name:    "colon in value",
+			content: "info:\n  title: Note: slow\n",
+			want:    "line 2, column 14: mapping values are not allowed in this context",
+			hint:    "quote",
+		},
+		{
+			name:    "double comma",
+			content: "{\n  \"info\": {\"title\": \"T\",, \"version\": \"1\"}\n}\n",
+			want:    "line 2, column 25: invalid character ',' looking for beginning of object key string",
+			hint:    "comma",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := diagnoseSyntax([]byte(tt.content))
+			if e == nil {
+				t.Fatal("Expected a syntax error")
+			}
+			if e.Error() != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, e.Error())
+			}
+			if tt.snippet != "" && e.snippet() != tt.snippet {
+				t.Errorf("Expected snippet:\n%s\ngot:\n%s", tt.snippet, e.snippet())
+			}
+			if !strings.Contains(e.hint, tt.hint) {
+				t.Errorf("Expected a hint about %q, got %q", tt.hint, e.hint)
+			}
+		})
+	}
+
+	if e := diagnoseSyntax([]byte("openapi: 3.1.0\n")); e != nil {
+		t.Errorf("Expected valid YAML to have no syntax error, got %v", e)
+	}
+	if _, _, err := parseSpecPartially([]byte("openapi: 3.1.0\ninfo:\n\ttitle: T\n")); !strings.Contains(err.Error(), "line 3, column 1") {
+		t.Errorf("Expected the load error to have the position, got %v", err)
+	}
+}
+
+func TestSpecStats(t *testing.T) {
+	spec := `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      tags: [pets]
+      summary: List pets
+      operationId: listPets
+      parameters:
+        - name: limit
+          in: query
+          description: Page size
+          schema:
+            type: integer
+        - name: offset
+          in: query
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: OK
+        "400":
+          description: Bad request
+    post:
+      tags: [pets]
+      deprecated: true
+      responses:
+        "201":
+          description: Created
+        default:
+          description: Error
+  /health:
+    get:
+      responses:
+        "200":
+          description: OK
+components:
+  schemas:
+    Pet:
+      type: object
+      description: A pet
+      properties:
+        name:
+          type: string
+          description: The name
+        age:
+          type: integer
+`
+	doc, err := parseSpec([]byte(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := collectStats(doc, extractEndpoints(doc), extractWebhooks(doc), extractComponents(doc))
+
+	if stats.Endpoints != 3 || stats.Deprecated != 1 || stats.AverageParameters != 0.7 {
+		t.Errorf("unexpected totals: %+v", stats)
+	}
+	if want := []statsCount{{"GET", 2}, {"POST", 1}}; !slices.Equal(stats.Methods, want) {
+		t.Errorf("methods = %v, want %v", stats.Methods, want)
+	}
+	if want := []statsCount{{"pets", 2}, {untaggedLabel, 1}}; !slices.Equal(stats.Tags, want) {
+		t.Errorf("tags = %v, want %v", stats.Tags, want)
+	}
+
+	covered := map[string]string{}
+	for _, c := range append(stats.Responses, stats.Documentation...) {
+		covered[c.Name] = fmt.Sprintf("%d/%d %.1f", c.Covered, c.Total, c.Percent)
+	}
+	for name, want := range map[string]string{
+		"2xx":                                  "3/3 100.0",
+		"4xx":                                  "1/3 33.3",
+		"default":                              "1/3 33.3",
+		"Operations with a summary":            "1/3 33.3",
+		"Parameters with a description":        "1/2 50.0",
+		"Schemas with a description":           "1/1 100.0",
+		"Schema properties with a description": "1/2 50.0",
+	} {
+		if covered[name] != want {
+			t.Errorf("%s = %q, want %q", name, covered[name], want)
+		}
+	}
+
+	var out strings.Builder
+	if err := writeStatsText(&out, stats); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Pets v1.0.0", "Endpoints:", "pets", "Operations with an operationId"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("text output is missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestConvertSpec(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: Pets
+  version: 1.0.0
+webhooks:
+  ping:
+    post:
+      responses:
+        "200":
+          description: OK
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: [integer, "null"]
+            exclusiveMinimum: 0
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+                description: A pet
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        kind:
+          const: dog
+        owner:
+          anyOf:
+            - $ref: '#/components/schemas/Owner'
+            - type: "null"
+        tags:
+          type: array
+          prefixItems:
+            - type: string
+    Owner:
+      type: string
+`
+	root, losses, err := convertSpec([]byte(spec), version30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lost []string
+	for _, loss := range losses {
+		lost = append(lost, strings.TrimSuffix(loss.keyword+" "+loss.as, " "))
+	}
+	wantLost := []string{"webhooks", "type null nullable", "exclusiveMinimum minimum", "$ref allOf", "const enum", "anyOf null nullable", "prefixItems"}
+	if !slices.Equal(lost, wantLost) {
+		t.Errorf("Expected losses %v, got %v", wantLost, lost)
+	}
+	if !strings.HasSuffix(losses[len(losses)-1].String(), "(#/components/schemas/Pet/properties/tags/prefixItems)") {
+		t.Errorf("Expected the pointer of the dropped keyword, got %s", losses[len(losses)-1])
+	}
+	var summary strings.Builder
+	if err := writeLossSummary(&summary, version30, losses); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"dropped 2 and rewrote 5 keywords", "dropped  prefixItems", "rewrote  const " + icons.link + " enum"} {
+		if !strings.Contains(summary.String(), want) {
+			t.Errorf("Expected %q in the summary, got:\n%s", want, summary.String())
+		}
+	}
+	if strings.Index(summary.String(), "prefixItems") > strings.Index(summary.String(), "const") {
+		t.Errorf("Expected dropped keywords listed first, got:\n%s", summary.String())
+	}
+
+	data, err := encodeSpec(root, formatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		OpenAPI  string         `json:"openapi"`
+		Webhooks map[string]any `json:"webhooks"`
+		Paths    map[string]map[string]struct {
+			Parameters []struct {
+				Schema map[string]any `json:"schema"`
+			} `json:"parameters"`
+			Responses map[string]struct {
+				Content map[string]struct {
+					Schema map[string]any `json:"schema"`
+				} `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]any `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if doc.OpenAPI != "3.0.3" || doc.Webhooks != nil {
+		t.Errorf("openapi = %q, webhooks = %v", doc.OpenAPI, doc.Webhooks)
+	}
+
+	get := doc.Paths["/pets"]["get"]
+	limit := fmt.Sprint(get.Parameters[0].Schema)
+	if want := "map[exclusiveMinimum:true minimum:0 nullable:true type:integer]"; limit != want {
+		t.Errorf("limit schema = %s, want %s", limit, want)
+	}
+	pet := fmt.Sprint(get.Responses["200"].Content["application/json"].Schema)
+	if want := "map[allOf:[map[$ref:#/components/schemas/Pet]] description:A pet]"; pet != want {
+		t.Errorf("response schema = %s, want %s", pet, want)
+	}
+	props := doc.Components.Schemas["Pet"].Properties
+	for name, want := range map[string]string{
+		"kind":  "map[enum:[dog]]",
+		"owner": "map[allOf:[map[$ref:#/components/schemas/Owner]] nullable:true]",
+		"tags":  "map[items:map[] type:array]",
+	} {
+		if got := fmt.Sprint(props[name]); got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+	if _, err := parseSpec(data); err != nil {
+		t.Errorf("converted spec does not load: %v", err)
+	}
+
+	// Back to 3.1, as YAML
+	root, _, err = convertSpec(data, version31)
+	if err != nil {
+		t.Fatal(err)
+	}
+	yamlData, err := encodeSpec(root, formatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"openapi: 3.1.0", "type:\n              - integer\n              - \"null\"", "exclusiveMinimum: 0", "anyOf:\n"} {
+		if !strings.Contains(string(yamlData), want) {
+			t.Errorf("3.1 spec is missing %q:\n%s", want, yamlData)
+		}
+	}
+	if strings.Contains(string(yamlData), "nullable") || strings.Contains(string(yamlData), `"openapi"`) {
+		t.Errorf("3.1 spec should be block YAML without nullable:\n%s", yamlData)
+	}
+
+	if _, _, err := convertSpec([]byte("swagger: \"2.0\"\n"), version31); err == nil {
+		t.Error("expected an error converting a Swagger 2.0 spec")
+	}
+}
+
+func TestFilterSpec(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: Shop
+  version: 1.0.0
+tags:
+  - name: billing
+  - name: pets
+paths:
+  /v1/invoices:
+    get:
+      tags: [billing]
+      responses:
+        "200":
+          description: OK
+  /v2/invoices/{id}:
+    get:
+      tags: [billing]
+      security:
+        - apiKey: []
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Invoice'
+    delete:
+      tags: [pets]
+      responses:
+        "204":
+          description: Deleted
+  /v2/pets:
+    get:
+      tags: [pets]
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+components:
+  schemas:
+    Invoice:
+      type: object
+      properties:
+        lines:
+          type: array
+          items:
+            $ref: '#/components/schemas/Line'
+      required: &required
+        - lines
+    Line:
+      type: object
+      required: *required
+    Pet:
+      type: object
+      required: *required
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      name: X-API-Key
+      in: header
+    oauth:
+      type: oauth2
+      flows: {}
+`
+	filter := func(f specFilter) string {
+		t.Helper()
+		root, err := parseSpecTree([]byte(spec))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filterSpec(root.Content[0], f) == 0 {
+			return ""
+		}
+		data, err := encodeSpec(root, formatYAML)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := parseSpec(data); err != nil {
+			t.Fatalf("filtered spec does not load: %v\n%s", err, data)
+		}
+		return string(data)
+	}
+
+	got := filter(specFilter{tags: []string{"billing"}, paths: []*regexp.Regexp{pathGlob("/v2/**")}})
+	for _, want := range []string{"/v2/invoices/{id}:", "Invoice:", "Line:", "apiKey:", "- name: billing"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("filtered spec is missing %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"/v1/invoices", "/v2/pets", "delete:", "Pet:", "oauth:", "- name: pets"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("filtered spec should not contain %q:\n%s", unwanted, got)
+		}
+	}
+
+	// Pet uses the anchor defined in Invoice, which is filtered out
+	got = filter(specFilter{paths: []*regexp.Regexp{pathGlob("/*/pets")}})
+	if strings.Contains(got, "Invoice:") || strings.Contains(got, "components:\n  securitySchemes") || !strings.Contains(got, "required:\n        - lines") {
+		t.Errorf("unexpected filtered spec:\n%s", got)
+	}
+
+	if got := filter(specFilter{tags: []string{"unknown"}}); got != "" {
+		t.Errorf("expected no operations to match, got:\n%s", got)
+	}
+
+	for pattern, matches := range map[string][]string{
+		"/v2/**":       {"/v2", "/v2/pets", "/v2/pets/{id}"},
+		"/v2/*":        {"/v2/pets"},
+		"/*/pets/{id}": {"/v1/pets/{id}", "/v2/pets/{id}"},
+	} {
+		re := pathGlob(pattern)
+		for _, path := range []string{"/v2", "/v2/pets", "/v2/pets/{id}", "/v1/pets/{id}", "/v3/pets"} {
+			if re.MatchString(path) != slices.Contains(matches, path) {
+				t.Errorf("pattern %q matching %q = %v", pattern, path, re.MatchString(path))
+			}
+		}
+	}
+}
+
+func TestSearchSpec(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: Shop
+  version: 1.0.0
+paths:
+  /customers:
+    get:
+      summary: List customers
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  total:
+                    type: integer
+                    description: Number of customers
+  /orders:
+    post:
+      operationId: createOrder
+      responses:
+        "201":
+          description: Created
+components:
+  schemas:
+    Customer:
+      type: object
+      properties:
+        name:
+          type: string
+    Order:
+      type: object
+      properties:
+        customer_id:
+          type: string
+`
+	root, err := parseSpecTree([]byte(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, m := range searchSpec(root.Content[0], "CUSTOMER") {
+		got = append(got, fmt.Sprintf("%d:%d %s %s %s (%s)", m.Line, m.Column, m.Kind, m.Name, m.Pointer, m.Field))
+	}
+	want := []string{
+		"7:5 operation GET /customers #/paths/~1customers/get (path)",
+		"17:19 property total #/paths/~1customers/get/responses/200/content/application~1json/schema/properties/total (description)",
+		"28:5 schema Customer #/components/schemas/Customer (name)",
+		"36:9 property customer_id #/components/schemas/Order/properties/customer_id (name)",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("matches:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	var out strings.Builder
+	if err := writeMatchesText(&out, searchSpec(root.Content[0], "createorder")); err != nil {
+		t.Fatal(err)
+	}
+	if want := "21\toperation\t#/paths/~1orders/post\tPOST /orders\n"; out.String() != want {
+		t.Errorf("text output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestResolveRefs(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: Tree
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Node:
+      type: object
+      properties:
+        label:
+          $ref: '#/components/schemas/Label'
+          description: The label of the node
+        children:
+          type: array
+          items:
+            $ref: '#/components/schemas/Node'
+    Label:
+      type: string
+      description: A label
+`
+	root, err := parseSpecTree([]byte(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := root.Content[0]
+	node := resolveRefs(doc, resolvePointer(doc, "#/components/schemas/Node/properties"), map[string]bool{}, 0)
+	data, err := encodeSpec(node, formatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `label:
+  type: string
+  description: The label of the node
+children:
+  type: array
+  items:
+    type: object
+    properties:
+      label:
+        type: string
+        description: The label of the node
+      children:
+        type: array
+        items:
+          $ref: '#/components/schemas/Node'
+`
+	if string(data) != want {
+		t.Errorf("resolved:\n%s\nwant:\n%s", data, want)
+	}
+
+	// The spec itself is left untouched
+	if _, ref := field(resolvePointer(doc, "#/components/schemas/Node/properties/label"), "$ref"); ref == nil {
+		t.Error("resolving references changed the spec")
+	}
+	if resolvePointer(doc, "#/components/schemas/Missing") != nil {
+		t.Error("expected nothing at a missing pointer")
+	}
+}
+
+func TestCompletions(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200":
+          description: OK
+components:
+  schemas:
+    Pet:
+      title: A pet
+      type: object
+    Person:
+      type: object
+`
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "pets.yaml")
+	if err := os.WriteFile(specPath, []byte(spec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mainFlags := flag.NewFlagSet("oq", flag.ContinueOnError)
+	mainFlags.String("theme", "", "color theme")
+
+	for _, tc := range []struct {
+		words []string
+		want  []string
+	}{
+		{[]string{"sc"}, []string{"schema\tPrint a schema of the spec with its references resolved"}},
+		{[]string{"-th"}, []string{"-theme\tcolor theme"}},
+		{[]string{"schema", "Pet", ""}, nil}, // Spec files are completed by the shell
+		{[]string{"schema", specPath, "P"}, []string{"Pet\tA pet", "Person"}},
+		{[]string{"get", specPath, "#/p"}, []string{"#/paths/~1pets/get\tlistPets"}},
+		{[]string{"get", specPath, "#/components/"}, []string{"#/components/schemas/Pet", "#/components/schemas/Person"}},
+		{[]string{"curl", specPath, "l"}, []string{"listPets\tGET /pets"}},
+		{[]string{"diff", "-for"}, []string{"-format\toutput format: text, json or junit"}},
+		{[]string{"diff", "-format", ""}, nil},
+		{[]string{"completion", "z"}, []string{"zsh"}},
+	} {
+		var out strings.Builder
+		if err := writeCompletions(&out, mainFlags, tc.words); err != nil {
+			t.Fatal(err)
+		}
+		got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		if out.Len() == 0 {
+			got = nil
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("completions for %q = %q, want %q", tc.words, got, tc.want)
+		}
+	}
+
+	// The lines completed with the spec first run as typed
+	for _, args := range [][]string{{"Pet", specPath}, {specPath, "Pet"}} {
+		fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+		if err := fs.Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		if name, path, err := keyAndSpec(fs); name != "Pet" || path != specPath || err != nil {
+			t.Errorf("Expected Pet in %s for %q, got %q in %q, %v", specPath, args, name, path, err)
+		}
+	}
+}
+
+func TestConfigDefaultsAndEnvOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfgData := `theme: light
+timeout: 5s
+output: json
+editor: nano
+schema_depth: 3
+headers:
+  X-Client: oq
+  X-Token: $OQ_TEST_TOKEN
+environments:
+  dev:
+    base_url: http://localhost:8080
+    headers:
+      X-Client: dev
+`
+	if err := os.WriteFile(path, []byte(cfgData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OQ_CONFIG", path)
+	t.Setenv("OQ_THEME", "monochrome")
+	t.Setenv("OQ_ASCII", "true")
+	t.Setenv("OQ_TEST_TOKEN", "secret")
+
+	cfg, err := loadUserConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Theme != "monochrome" || !cfg.ASCII || cfg.Editor != "nano" {
+		t.Errorf("Expected OQ_* variables to override the file, got %+v", cfg)
+	}
+	if !slices.Equal(cfg.overrides, []string{"OQ_THEME", "OQ_ASCII"}) {
+		t.Errorf("Expected the overrides to be recorded, got %v", cfg.overrides)
+	}
+
+	t.Cleanup(func() {
+		defaultOutput = outputText
+		httpClient.Timeout = defaultTimeout
+	})
+	if err := cfg.applyDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if defaultOutput != outputJSON || httpClient.Timeout != 5*time.Second {
+		t.Errorf("Expected the output and timeout to be applied, got %q and %v", defaultOutput, httpClient.Timeout)
+	}
+
+	effective, err := cfg.effective()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if effective.Timeout != "5s" || !slices.Equal(effective.Keys["toggle"], []string{"enter", "space"}) {
+		t.Errorf("Unexpected effective config: timeout %q, toggle keys %v", effective.Timeout, effective.Keys["toggle"])
+	}
+	if effective.SchemaDepth != 3 {
+		t.Errorf("Expected the schema depth from the file, got %d", effective.SchemaDepth)
+	}
+	if _, err := (config{SchemaDepth: maxSchemaDepth + 1}).schemaDepth(); err == nil {
+		t.Errorf("Expected an error for a schema depth over %d", maxSchemaDepth)
+	}
+
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+	model.headers = cfg.headers()
+	envs, err := cfg.environments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	model.environments = envs
+	headers := func() map[string]string {
+		out := map[string]string{}
+		for _, h := range model.sampleRequest(model.endpoints[0]).headers {
+			out[h.name] = h.value
+		}
+		return out
+	}
+	if got := headers(); got["X-Client"] != "oq" || got["X-Token"] != "secret" {
+		t.Errorf("Expected the default headers on requests, got %v", got)
+	}
+	model.environment = "dev"
+	if got := headers(); got["X-Client"] != "dev" || got["X-Token"] != "secret" {
+		t.Errorf("Expected environment headers to replace the defaults, got %v", got)
+	}
+
+	for name, value := range map[string]string{"OQ_ASCII": "maybe", "OQ_TIMEOUT": "soon", "OQ_OUTPUT": "xml"} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			cfg, err := loadUserConfig()
+			if err == nil {
+				_, err = cfg.effective()
+			}
+			if err == nil {
+				err = cfg.applyDefaults()
+			}
+			if err == nil {
+				t.Errorf("Expected %s=%s to be rejected", name, value)
+			}
+		})
+	}
+}
+
+func TestJUnitReports(t *testing.T) {
+	report := func(cases []junitTestCase) junitTestSuites {
+		t.Helper()
+		var out strings.Builder
+		if err := writeJUnit(&out, "oq test", cases); err != nil {
+			t.Fatal(err)
+		}
+		var suites junitTestSuites
+		if err := xml.Unmarshal([]byte(out.String()), &suites); err != nil {
+			t.Fatalf("Invalid JUnit XML: %v\n%s", err, out.String())
+		}
+		return suites
+	}
+	summary := func(suites junitTestSuites) []string {
+		var got []string
+		for _, c := range suites.Suites[0].TestCases {
+			state := "passed"
+			if c.Failure != nil {
+				state = "failed: " + c.Failure.Message
+			}
+			got = append(got, c.Name+" "+state)
+		}
+		return got
+	}
+
+	changes := []change{
+		{Location: "GET /pets", Message: "query parameter limit added"},
+		{Location: "GET /pets response 200", Message: "property name removed", Breaking: true},
+		{Location: "DELETE /pets/{id}", Message: "operation removed", Breaking: true},
+		{Location: "schema Pet.name", Message: "property became required", Breaking: true},
+		{Location: "schema Owner", Message: "schema added"},
+	}
+	suites := report(changesJUnit(changes))
+	want := []string{
+		"GET /pets failed: 1 breaking change",
+		"DELETE /pets/{id} failed: 1 breaking change",
+		"schema Pet failed: 1 breaking change",
+		"schema Owner passed",
+	}
+	if got := summary(suites); !slices.Equal(got, want) {
+		t.Errorf("Expected diff test cases:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if suites.Tests != 4 || suites.Failures != 3 || suites.Suites[0].Failures != 3 {
+		t.Errorf("Expected 4 tests and 3 failures, got %d and %d", suites.Tests, suites.Failures)
+	}
+	if text := suites.Suites[0].TestCases[0].Failure.Text; !strings.Contains(text, "query parameter limit added") || !strings.Contains(text, "property name removed (breaking)") {
+		t.Errorf("Expected every change of the endpoint in the failure, got %q", text)
+	}
+
+	findings := []finding{
+		{Rule: "operation-description", Severity: severityWarning, Location: "GET /stores", Message: "operation has no summary or description", Line: 20},
+		{Rule: "schema-description", Severity: severityInfo, Location: "Schema Store", Message: "schema has no description"},
+	}
+	rs := ruleset{Rules: map[string]string{"naming-consistency": severityOff}}
+	suites = report(lintJUnit(rs, findings))
+	if len(suites.Suites[0].TestCases) != len(lintRules)-1 {
+		t.Errorf("Expected a test case per rule turned on, got %d", len(suites.Suites[0].TestCases))
+	}
+	for _, c := range suites.Suites[0].TestCases {
+		switch c.Name {
+		case "operation-description":
+			if c.Failure == nil || c.Failure.Type != severityWarning || !strings.Contains(c.Failure.Text, "GET /stores: warning: operation has no summary or description (line 20)") {
+				t.Errorf("Expected operation-description to fail with the finding, got %+v", c)
+			}
+		case "schema-description":
+			if c.Failure != nil || !strings.Contains(c.SystemOut, "Schema Store") {
+				t.Errorf("Expected info findings to pass with the finding in the output, got %+v", c)
+			}
+		case "naming-consistency":
+			t.Error("Expected rules set to off to be left out")
+		default:
+			if c.Failure != nil {
+				t.Errorf("Expected %s to pass, got %+v", c.Name, c.Failure)
+			}
+		}
+	}
+
+	issues := validateSpec("pets.yaml", []byte("openapi: 3.1.0\ninfo:\n  title: Pets\npaths: {}\n"))
+	suites = report(issuesJUnit(issues))
+	if len(suites.Suites[0].TestCases) != len(validationRules) || suites.Failures == 0 {
+		t.Errorf("Expected a test case per validation rule with failures, got %d cases and %d failures", len(suites.Suites[0].TestCases), suites.Failures)
+	}
+}
+
+func TestChangelog(t *testing.T) {
+	oldSpec := `openapi: 3.1.0
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: OK
+  /pets/{id}:
+    delete:
+      responses:
+        "204":
+          description: Deleted
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+`
+	newSpec := `openapi: 3.1.0
+info:
+  title: Pets
+  version: 1.1.0
+paths:
+  /pets:
+    get:
+      deprecated: true
+      x-sunset: "2026-01-01"
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+        - name: tag
+          in: query
+          schema:
+            type: string
+      responses:
+        "200":
+          description: OK
+        "400":
+          description: Bad request
+  /v2/pets:
+    get:
+      summary: List pets with paging
+      responses:
+        "200":
+          description: OK
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+        age:
+          type: integer
+`
+	old, updated := loadSpecModel(t, oldSpec).doc, loadSpecModel(t, newSpec).doc
+	log := buildChangelog(old, updated, diffSpecs(old, updated))
+	if log.Title != "Pets" || log.From != "1.0.0" || log.To != "1.1.0" {
+		t.Errorf("Expected Pets 1.0.0 to 1.1.0, got %s %s to %s", log.Title, log.From, log.To)
+	}
+
+	want := "## 1.1.0\n\nChanges to Pets since 1.0.0.\n\n" +
+		"### Breaking changes\n\n- `DELETE /pets/{id}`: endpoint removed\n\n" +
+		"### New endpoints\n\n- `GET /v2/pets`: List pets with paging\n\n" +
+		"### Deprecations\n\n- `GET /pets`: sunset 2026-01-01\n\n" +
+		"### Changed endpoints\n\n- `GET /pets`\n  - query parameter tag added\n  - response 400 added\n\n" +
+		"### Schemas\n\n- `Pet`: age: property added\n"
+	if got := log.markdown(); got != want {
+		t.Errorf("Expected changelog:\n%s\ngot:\n%s", want, got)
+	}
+
+	if got := buildChangelog(old, old, nil).markdown(); got != "## 1.0.0\n\nNo changes.\n" {
+		t.Errorf("Expected no changes, got:\n%s", got)
+	}
+}
+
+func TestTrafficCoverage(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: Pets
+  version: 1.0.0
+servers:
+  - url: https://{region}.example.com/api/v1
+    variables:
+      region:
+        default: eu
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: OK
+  /pets/{id}:
+    get:
+      responses:
+        "200":
+          description: OK
+    delete:
+      responses:
+        "204":
+          description: Deleted
+  /pets/mine:
+    get:
+      responses:
+        "200":
+          description: OK
+`
+	model := loadSpecModel(t, spec)
+
+	har := `{"log": {"entries": [
+  {"request": {"method": "GET", "url": "https://eu.example.com/api/v1/pets?limit=10"}, "response": {"status": 200}},
+  {"request": {"method": "get", "url": "https://eu.example.com/api/v1/pets/"}, "response": {"status": 500}},
+  {"request": {"method": "GET", "url": "https://eu.example.com/api/v1/pets/mine"}, "response": {"status": 200}},
+  {"request": {"method": "GET", "url": "https://eu.example.com/api/v1/pets/7"}, "response": {"status": 404}}
+]}}`
+	requests, err := readHAR(strings.NewReader(har))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logged, err := readAccessLog(strings.NewReader(`10.0.0.1 - - [10/Oct/2025:13:55:36 +0000] "GET /pets/7 HTTP/1.1" 200 52 "-" "curl/8.0"
+not a request line
+10.0.0.1 - - [10/Oct/2025:13:55:37 +0000] "POST /api/v1/pets HTTP/1.1" 201 12
+10.0.0.1 - - [10/Oct/2025:13:55:38 +0000] "GET /health HTTP/1.1" 200 2
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logged) != 3 {
+		t.Fatalf("Expected 3 requests in the access log, got %v", logged)
+	}
+
+	report := measureTraffic(model.doc, model.endpoints, append(requests, logged...))
+	var got []string
+	for _, op := range report.Operations {
+		got = append(got, fmt.Sprintf("%s %s %d %v", op.Method, op.Path, op.Requests, op.Statuses))
+	}
+	want := []string{
+		"GET /pets 2 [200 500]",
+		"GET /pets/mine 1 [200]",
+		"DELETE /pets/{id} 0 []",
+		"GET /pets/{id} 2 [200 404]",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected operations:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if report.Requests != 7 || report.Coverage.Covered != 3 || report.Coverage.Total != 4 || report.Coverage.Percent != 75 {
+		t.Errorf("Expected 3 of 4 operations exercised by 7 requests, got %+v with %d requests", report.Coverage, report.Requests)
+	}
+
+	var undocumented []string
+	for _, u := range report.Undocumented {
+		undocumented = append(undocumented, fmt.Sprintf("%s %s %d", u.Method, u.Path, u.Requests))
+	}
+	if want := []string{"POST /api/v1/pets 1", "GET /health 1"}; !slices.Equal(undocumented, want) {
+		t.Errorf("Expected requests not in the spec %v, got %v", want, undocumented)
+	}
+}
+
+func TestContractTest(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: Pets
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /pets:
+    get:
+      tags: [pets]
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  required: [name]
+                  properties:
+                    name:
+                      type: string
+    post:
+      tags: [pets]
+      responses:
+        "201":
+          description: Created
+  /pets/{id}:
+    get:
+      tags: [pets]
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+        - name: X-Owner
+          in: header
+          required: true
+          schema:
+            type: string
+      responses:
+        "200":
+          description: OK
+  /owners:
+    get:
+      tags: [owners]
+      responses:
+        "200":
+          description: OK
+`
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+r.Header.Get("X-Tenant"))
+		switch r.URL.Path {
+		case "/pets/7":
+			if r.Header.Get("X-Owner") != "ann" {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		case "/pets":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `[{"name": "Rex"}, {"age": 3}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	model := loadSpecModel(t, spec)
+	suite := contractSuite{
+		methods: safeMethods,
+		params:  map[string]string{},
+		env:     environment{BaseURL: server.URL, Headers: map[string]string{"X-Tenant": "acme"}},
+	}
+	summary := func(results []contractResult) []string {
+		var got []string
+		for _, r := range results {
+			got = append(got, fmt.Sprintf("%s %s %s %d %s", r.Result, r.Method, r.Path, r.Status, strings.Join(r.Problems, "; ")))
+		}
+		return got
+	}
+
+	results := suite.run(model.doc, model.endpoints)
+	want := []string{
+		"fail GET /owners 404 status 404 is not a success; status 404 is not documented",
+		"fail GET /pets 200 $[1].name: missing required property",
+		"skip GET /pets/{id} 0 no value for id, give one with -param id=...; no value for X-Owner, give one with -param X-Owner=...",
+	}
+	if got := summary(results); !slices.Equal(got, want) {
+		t.Errorf("Expected results:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if want := []string{"GET /owners acme", "GET /pets acme"}; !slices.Equal(requests, want) {
+		t.Errorf("Expected only safe methods to be sent %v, got %v", want, requests)
+	}
+
+	suite.tags = []string{"pets"}
+	suite.paths = []*regexp.Regexp{pathGlob("/pets/*")}
+	suite.params["id"] = "7"
+	suite.params["X-Owner"] = "ann"
+	if got, want := summary(suite.run(model.doc, model.endpoints)), []string{"pass GET /pets/{id} 200 "}; !slices.Equal(got, want) {
+		t.Errorf("Expected the selected operation to pass, got %v", got)
+	}
+
+	var out strings.Builder
+	if err := writeJUnit(&out, "oq test", contractJUnit(results)); err != nil {
+		t.Fatal(err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal([]byte(out.String()), &suites); err != nil {
+		t.Fatal(err)
+	}
+	if s := suites.Suites[0]; s.Tests != 3 || s.Failures != 2 || s.Skipped != 1 {
+		t.Errorf("Expected 3 tests with 2 failures and 1 skipped, got %+v", s)
+	}
+}
+
+func TestProbePaths(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: OK
+    post:
+      responses:
+        "201":
+          description: Created
+  /pets/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        "200":
+          description: OK
+  /owners:
+    get:
+      responses:
+        "200":
+          description: OK
+  /health:
+    get:
+      responses:
+        "200":
+          description: OK
+`
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		switch {
+		case r.URL.Path == "/pets" && r.Method == http.MethodOptions:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case r.URL.Path == "/health" || r.URL.Path == "/metrics":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/admin" && r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	model := loadSpecModel(t, spec)
+	suite := contractSuite{params: map[string]string{}, env: environment{BaseURL: server.URL}}
+	var got []string
+	for _, r := range suite.probePaths(model.doc, model.endpoints, true) {
+		got = append(got, fmt.Sprintf("%s %s %d", r.Result, r.Path, r.Status))
+	}
+	want := []string{
+		"found /health 200",
+		"missing /owners 404",
+		"found /pets 405",
+		"skipped /pets/{id} 0",
+		"undocumented /metrics 200",
+		"undocumented /admin 401",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected probe results:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if !slices.Contains(methods, "HEAD /owners") || slices.Contains(methods, "HEAD /pets") {
+		t.Errorf("Expected HEAD only after OPTIONS found nothing, got %v", methods)
+	}
+
+	// A server that can't be reached fails the probe rather than finding nothing
+	server.Close()
+	results := suite.probePaths(model.doc, model.endpoints, false)
+	var out strings.Builder
+	if err := writeProbeText(&out, results); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "0 found, 0 missing, 0 undocumented, 3 errored, 1 skipped") {
+		t.Errorf("Expected the errors counted, got:\n%s", out.String())
+	}
+	if err := probeFindings(results); err != errFindings {
+		t.Errorf("Expected the errors to be findings, got %v", err)
+	}
+}
+
+func TestDetailsFormattedOnDemand(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.1.yaml")
+	if len(model.details) != 0 {
+		t.Fatalf("Expected no details formatted at startup, got %d", len(model.details))
+	}
+
+	model.mode = viewComponents
+	index := model.findComponent("Schema", "Pet")
+	shallow := model.itemDetails(index)
+	if len(model.details) != 1 || model.itemDetails(index) != shallow {
+		t.Fatalf("Expected the details to be formatted once and kept, got %d entries", len(model.details))
+	}
+
+	model.detailOpts.schemaDepth++
+	if deeper := model.itemDetails(index); deeper == shallow || len(model.details) != 2 {
+		t.Errorf("Expected other options to format the details again, got %d entries", len(model.details))
+	}
+	model.showRaw = true
+	if raw := model.itemDetails(index); raw == shallow || !strings.Contains(raw, "name") {
+		t.Errorf("Expected the raw source in raw mode, got:\n%s", raw)
+	}
+
+	updated, _ := model.Update(tea.WindowSizeMsg{Width: model.width + 20, Height: model.height})
+	if m := updated.(Model); len(m.details) != 0 {
+		t.Errorf("Expected a new width to drop the details wrapped at the old one, got %d entries", len(m.details))
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.0.yaml")
+
+	model = pressKeys(model, "/", "s", "t", "a", "t", "u", "s")
+	if model.picker == nil || model.picker.query != "status" {
+		t.Fatalf("Expected / to open the search, got %+v", model.picker)
+	}
+	if !strings.Contains(model.View(), "Indexing...") {
+		t.Errorf("Expected the search to wait for the index")
+	}
+
+	updated, _ := model.Update(model.Init()())
+	model = updated.(Model)
+	if len(model.picker.items) == 0 || model.picker.items[0].label != "GET /pet/findByStatus" {
+		t.Fatalf("Expected the index to fill the open search, got %+v", model.picker.items)
+	}
+
+	results := model.searchResults("findpetsbystatus")
+	if len(results) != 1 || results[0].value != operationRef("/pet/findByStatus", "GET") {
+		t.Errorf("Expected operationIds to be searched, got %+v", results)
+	}
+	if results := model.searchResults("pet photourls"); len(results) != 1 || results[0].label != "Schema Pet.photoUrls" {
+		t.Errorf("Expected every word to match a schema property, got %+v", results)
+	}
+
+	model = pressKeys(model, "enter")
+	if model.picker != nil || model.mode != viewEndpoints || model.endpoints[model.cursor].path != "/pet/findByStatus" {
+		t.Errorf("Expected enter to jump to the result, got %s", model.endpoints[model.cursor].path)
+	}
+}
+
+func TestLowMemoryModel(t *testing.T) {
+	doc, err := parseSpec([]byte(`openapi: 3.1.0
+info: {title: Pets, version: "1"}
+paths: {}
+components:
+  schemas:
+    Pet:
+      description: A pet
+      properties:
+        name: {type: string}
+    OldPet:
+      description: Replaced by Pet
+      deprecated: true
+      x-sunset: "2026-01-01"
+      properties:
+        name: {type: string}
+    Pets:
+      $ref: '#/components/schemas/Pet'
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eager := NewModel(doc)
+	lazy := NewLowMemoryModel(doc)
+
+	for i, comp := range lazy.components {
+		if comp.name == "Pets" {
+			continue // References aren't followed
+		}
+		want := eager.components[i]
+		if comp.description != want.description || comp.deprecation.badge() != want.deprecation.badge() || comp.deprecation.deprecated != want.deprecation.deprecated {
+			t.Errorf("Expected %s read from the source to match the built schema, got %q (%s), want %q (%s)",
+				comp.name, comp.description, comp.deprecation.badge(), want.description, want.deprecation.badge())
+		}
+	}
+
+	for _, entry := range buildSearchIndex(lazy.allItems(), false).entries {
+		if strings.Contains(entry.label, ".") {
+			t.Errorf("Expected no schema properties indexed in low memory mode, got %s", entry.label)
+		}
+	}
+
+	lazy = loadExampleModel(t, "examples/nessie.yaml")
+	lazy.lowMemory = true
+	lazy.mode = viewComponents
+	for i := range lazy.components {
+		lazy.itemDetails(i)
+	}
+	if len(lazy.details) > lowMemoryDetails {
+		t.Errorf("Expected at most %d details kept, got %d", lowMemoryDetails, len(lazy.details))
+	}
+}
+
+func TestListRendersOnlyVisibleLines(t *testing.T) {
+	model := loadExampleModel(t, "examples/train-travel.yaml")
+	model.height = 15
+	for i := range model.endpoints {
+		model.endpoints[i].folded = false
+	}
+	model.columns = []string{columnSummary}
+
+	full := model.renderContent(1 << 20)
+	visible := model.renderContent(10)
+	if lines := strings.Count(visible, "\n"); lines != 11 {
+		t.Errorf("Expected the list to stop a line past the screen, got %d lines", lines)
+	}
+	if !strings.HasPrefix(full, visible) {
+		t.Errorf("Expected the visible lines to be the first of the list")
+	}
+	if model.truncateContent(full, 10) != model.truncateContent(visible, 10) {
+		t.Errorf("Expected the cut list to look the same")
+	}
+
+	lines := model.detailLines(0)
+	if height := model.getItemHeight(0); height != len(lines)+1 {
+		t.Errorf("Expected an unfolded item to take its detail lines and its own, got %d for %d lines", height, len(lines))
+	}
+	if again := model.detailLines(0); &again[0] != &lines[0] {
+		t.Errorf("Expected the detail lines to be rendered once")
+	}
+}
+
+func TestResizeWrapsDetailsOnceSettled(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.1.yaml")
+	updated, _ := model.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	model = updated.(Model)
+	if model.wrapWidth != 100 {
+		t.Fatalf("Expected the first size to apply at once, got wrap width %d", model.wrapWidth)
+	}
+
+	model = pressKeys(model, "enter")
+	wrapped := model.itemDetails(0)
+
+	var settle []tea.Cmd
+	for _, width := range []int{90, 70, 60} {
+		updated, cmd := model.Update(tea.WindowSizeMsg{Width: width, Height: 30})
+		model = updated.(Model)
+		if cmd == nil {
+			t.Fatalf("Expected a resize to wait for the size to settle")
+		}
+		settle = append(settle, cmd)
+	}
+	if model.width != 60 || model.itemDetails(0) != wrapped || len(model.details) != 1 {
+		t.Errorf("Expected the details to stay wrapped at the old width while resizing, got %d entries", len(model.details))
+	}
+
+	updated, _ = model.Update(settle[0]())
+	model = updated.(Model)
+	if model.wrapWidth != 100 {
+		t.Errorf("Expected an earlier resize to be ignored, got wrap width %d", model.wrapWidth)
+	}
+	updated, _ = model.Update(settle[2]())
+	model = updated.(Model)
+	if model.wrapWidth != 60 || len(model.details) != 0 {
+		t.Errorf("Expected the details to be wrapped again once settled, got wrap width %d and %d entries", model.wrapWidth, len(model.details))
+	}
+}
+
+func TestStartupProfile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profile")
+	p, err := startProfile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile = p
+	t.Cleanup(func() { profile = nil })
+
+	content, err := os.ReadFile("examples/petstore-3.1.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := parseSpec(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := NewModel(doc)
+	model.View()
+	model.View()
+
+	var out strings.Builder
+	if err := p.stop(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, phase := range []string{"parse", "build model", "extract", "first render", "total"} {
+		if !strings.Contains(out.String(), "  "+phase+" ") {
+			t.Errorf("Expected the %s phase to be timed, got:\n%s", phase, out.String())
+		}
+	}
+	if n := strings.Count(out.String(), "first render"); n != 1 {
+		t.Errorf("Expected only the first render to be timed, got %d", n)
+	}
+	for _, name := range []string{"cpu.pprof", "heap.pprof"} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err != nil || info.Size() == 0 {
+			t.Errorf("Expected %s to be written: %v", name, err)
+		}
+	}
+}
+
+// BenchmarkLoadExamples measures reading each example spec into the viewer, to
+// catch load time regressions on big documents
+func BenchmarkLoadExamples(b *testing.B) {
+	files, err := filepath.Glob("examples/*")
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			b.Fatal(err)
+		}
+		name := filepath.Base(file)
+
+		b.Run(name+"/parse", func(b *testing.B) {
+			for b.Loop() {
+				if _, err := parseSpec(content); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(name+"/model", func(b *testing.B) {
+			for b.Loop() {
+				// Schemas are built once per document, so the document is parsed
+				// again outside of the timer
+				b.StopTimer()
+				doc, err := parseSpec(content)
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+				NewModel(doc)
+			}
+		})
+	}
+}
+
+func TestLoaderSwapsInTheViewer(t *testing.T) {
+	content, err := os.ReadFile("examples/petstore-3.1.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := newLoader("petstore-3.1.yaml", strings.NewReader(string(content)), int64(len(content)), func(doc *v3.Document) Model {
+		m := NewModel(doc)
+		m.specPath = "petstore-3.1.yaml"
+		return m
+	})
+
+	updated, _ := l.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	updated, cmd := updated.Update(spinnerTickMsg{})
+	if cmd == nil || updated.(loader).frame != 1 {
+		t.Error("Expected the spinner to keep moving while loading")
+	}
+	if view := updated.View(); !strings.Contains(view, "Loading petstore-3.1.yaml") {
+		t.Errorf("Expected a loading message, got %q", view)
+	}
+
+	// The load command is the first of the batch returned by Init
+	msg := l.Init()().(tea.BatchMsg)[0]()
+	viewer, _ := updated.Update(msg)
+	m, ok := viewer.(Model)
+	if !ok {
+		t.Fatalf("Expected the viewer once loaded, got %T", viewer)
+	}
+	if m.width != 120 || m.height != 40 || m.specPath != "petstore-3.1.yaml" || len(m.endpoints) == 0 {
+		t.Errorf("Expected the viewer sized to the terminal, got %dx%d with %d endpoints", m.width, m.height, len(m.endpoints))
+	}
+
+	broken := newLoader("", strings.NewReader("openapi: 3.1.0\npaths: ["), 0, func(doc *v3.Document) Model { return NewModel(doc) })
+	failed, cmd := broken.Update(broken.Init()().(tea.BatchMsg)[0]())
+	if failed.(loader).err == nil || cmd == nil {
+		t.Error("Expected the loader to quit with the error of a spec that doesn't parse")
+	}
+}
+
+func TestLoaderProgress(t *testing.T) {
+	big := newLoader("azure.json", strings.NewReader(""), 10<<20, func(doc *v3.Document) Model { return NewModel(doc) })
+	big.progress.read.Store(5 << 20)
+	if view := big.View(); !strings.Contains(view, "Reading azure.json "+strings.Repeat(icons.progressDone, progressWidth/2)) || !strings.Contains(view, " 50%  5.0 MB / 10.0 MB") {
+		t.Errorf("Expected a progress bar while reading a big file, got %q", view)
+	}
+	big.progress.parsing.Store(true)
+	if view := big.View(); !strings.Contains(view, "Parsing azure.json (10.0 MB)") {
+		t.Errorf("Expected the parsing to be shown once read, got %q", view)
+	}
+
+	piped := newLoader("", strings.NewReader(""), 0, func(doc *v3.Document) Model { return NewModel(doc) })
+	piped.progress.read.Store(6 << 20)
+	if view := piped.View(); !strings.Contains(view, "Reading stdin (6.0 MB)") {
+		t.Errorf("Expected the bytes read from a pipe, got %q", view)
+	}
+
+	content, err := os.ReadFile("examples/petstore-3.1.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	small := newLoader("petstore-3.1.yaml", strings.NewReader(string(content)), int64(len(content)), func(doc *v3.Document) Model { return NewModel(doc) })
+	small.Init()().(tea.BatchMsg)[0]()
+	if read := small.progress.read.Load(); read != int64(len(content)) {
+		t.Errorf("Expected the bytes read to be counted, got %d of %d", read, len(content))
+	}
+}
+
+func TestJSONSchemaKeywords(t *testing.T) {
+	model := loadSpecModel(t, `openapi: 3.1.0
+info:
+  title: Keywords
+  version: 1.0.0
+paths: {}
+components:
+  parameters:
+    Bounds:
+      name: bounds
+      in: query
+      schema:
+        type: array
+        contains:
+          type: integer
+        maxContains: 4
+  schemas:
+    Setting:
+      type: object
+      properties:
+        kind:
+          const: feature
+        value:
+          type: [string, "null"]
+        revision:
+          const: 2
+        photo:
+          type: string
+          contentEncoding: base64
+          contentMediaType: image/png
+        range:
+          type: array
+          prefixItems:
+            - type: integer
+            - type: integer
+      patternProperties:
+        "^x-":
+          type: string
+      propertyNames:
+        maxLength: 20
+      unevaluatedProperties: false
+      dependentRequired:
+        value: [kind]
+      if:
+        properties:
+          kind:
+            const: feature
+      then:
+        required: [value]
+    Point:
+      type: array
+      prefixItems:
+        - type: number
+        - type: number
+      contains:
+        type: number
+      minContains: 2
+    Version:
+      const: v1
+`)
+	model.mode = viewComponents
+
+	setting := model.itemDetails(model.findComponent("Schema", "Setting"))
+	for _, want := range []string{
+		"Pattern Properties:\n  - ^x-: string\n",
+		"Unevaluated Properties: false\n",
+		"Dependent Required:\n  - value " + icons.link + " kind\n",
+		"Property Names: maxLength: 20\n",
+		"If: properties: kind\n",
+		"Then: required: value\n",
+		"  - kind: \"feature\" (const)\n",
+		"  - revision: 2 (const)\n",
+		"  - photo: string (encoding: base64, media type: image/png)\n",
+		"  - range: array\n    Prefix Items:\n      - 0: integer\n      - 1: integer\n",
+	} {
+		if !strings.Contains(setting, want) {
+			t.Errorf("Expected %q in the details, got:\n%s", want, setting)
+		}
+	}
+
+	point := model.itemDetails(model.findComponent("Schema", "Point"))
+	for _, want := range []string{"Prefix Items:\n  - 0: number\n  - 1: number\n", "Contains: number [minContains: 2]\n"} {
+		if !strings.Contains(point, want) {
+			t.Errorf("Expected %q in the details, got:\n%s", want, point)
+		}
+	}
+
+	if version := model.itemDetails(model.findComponent("Schema", "Version")); !strings.Contains(version, "Const: v1\n") {
+		t.Errorf("Expected the const value, got:\n%s", version)
+	}
+	if bounds := model.itemDetails(model.findComponent("Parameter", "Bounds")); !strings.Contains(bounds, "Contains: integer [maxContains: 4]\n") {
+		t.Errorf("Expected the keywords of a parameter schema, got:\n%s", bounds)
+	}
+}
+
+func TestLintSchemaPatterns(t *testing.T) {
+	model := loadSpecModel(t, `openapi: 3.1.0
+info:
+  title: Refs
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Ref:
+      type: string
+      pattern: '^(?!-)[a-z-]+$'
+    Tags:
+      type: object
+      patternProperties:
+        '^(\w)\1$':
+          type: string
+        '^x-':
+          type: string
+    Name:
+      type: string
+      pattern: '^[a-z]+$'
+`)
+
+	var got []string
+	for _, f := range model.lint() {
+		if f.Rule == "schema-pattern" {
+			got = append(got, f.Pointer+": "+f.Message)
+		}
+	}
+	want := []string{
+		"#/components/schemas/Ref/pattern: pattern \"^(?!-)[a-z-]+$\" is not RE2 syntax (invalid or unsupported Perl syntax: `(?!`)",
+		"#/components/schemas/Tags/patternProperties/^(\\w)\\1$: pattern \"^(\\\\w)\\\\1$\" is not RE2 syntax (invalid escape sequence: `\\1`)",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected findings:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	// The pattern is still shown as written
+	model.mode = viewComponents
+	if details := model.itemDetails(model.findComponent("Schema", "Ref")); !strings.Contains(details, "^(?!-)[a-z-]+$") {
+		t.Errorf("Expected the pattern in the details, got:\n%s", details)
+	}
+}
+
+func TestPartialLoad(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: Broken
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Missing'
+  /owners:
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Owner'
+components:
+  schemas:
+    Owner:
+      type: object
+      properties:
+        name:
+          type: string
+`
+	if _, err := parseSpec([]byte(spec)); err == nil {
+		t.Error("Expected commands to keep failing on a broken reference")
+	}
+
+	doc, problems, err := parseSpecPartially([]byte(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := NewModel(doc)
+	if len(model.endpoints) != 2 || len(model.components) != 1 {
+		t.Errorf("Expected the endpoints and schemas that parse, got %d endpoints and %d components", len(model.endpoints), len(model.components))
+	}
+
+	model.setLoadProblems(doc.Index.GetRootNode(), problems)
+	if len(model.loadProblems) == 0 {
+		t.Fatal("Expected the broken reference as a problem")
+	}
+	missing := model.loadProblems[0]
+	if missing.Rule != ruleLoad || missing.Severity != severityError || missing.Pointer != "#/paths/~1pets/get/responses/200/content/application~1json/schema/$ref" {
+		t.Errorf("Expected the problem located at the $ref, got %+v", missing)
+	}
+	if len(model.loadProblems) != 1 || missing.Message != "unresolved reference #/components/schemas/Missing" {
+		t.Errorf("Expected one entry for the unresolved reference, got %+v", model.loadProblems)
+	}
+
+	// The operation using it says so instead of showing empty details
+	pets := slices.IndexFunc(model.endpoints, func(ep endpoint) bool { return ep.path == "/pets" })
+	if details := model.itemDetails(pets); !strings.HasPrefix(details, icons.warning+" unresolved: #/components/schemas/Missing\n") {
+		t.Errorf("Expected the unresolved reference marked, got:\n%s", details)
+	}
+	owners := slices.IndexFunc(model.endpoints, func(ep endpoint) bool { return ep.path == "/owners" })
+	if details := model.itemDetails(owners); strings.Contains(details, "unresolved") {
+		t.Errorf("Expected no marker on an operation using resolved references, got:\n%s", details)
+	}
+
+	// The problems panel lists them before the lint findings, jumping to their operation
+	model = pressKeys(model, "P")
+	if model.picker == nil || !strings.Contains(model.picker.items[0].label, "#/components/schemas/Missing") {
+		t.Fatalf("Expected the load problem first in the panel, got %+v", model.picker)
+	}
+	model = pressKeys(model, "enter")
+	if model.mode != viewEndpoints || model.endpoints[model.cursor].path != "/pets" {
+		t.Errorf("Expected to jump to GET /pets, got mode %v cursor %d", model.mode, model.cursor)
+	}
+
+	if _, _, err := parseSpecPartially([]byte("swagger: '2.0'\ninfo: {title: Old, version: '1'}\npaths: {}\n")); err == nil {
+		t.Error("Expected a spec that isn't OpenAPI 3 to fail")
+	}
+}
+
+func TestSpecPaths(t *testing.T) {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	if err := fs.Parse([]string{"-"}); err != nil {
+		t.Fatal(err)
+	}
+	if path, err := specArg(fs); err != nil || path != "" {
+		t.Errorf("Expected - to read stdin, got %q, %v", path, err)
+	}
+
+	dir := t.TempDir()
+	if _, err := readSpec(filepath.Join(dir, "missing.yaml")); err == nil || !strings.Contains(err.Error(), "missing.yaml doesn't exist") {
+		t.Errorf("Expected a missing file error, got %v", err)
+	}
+	if _, _, err := openSpec(dir); err == nil || !strings.Contains(err.Error(), "is a directory") {
+		t.Errorf("Expected a directory error, got %v", err)
+	}
+	if _, err := readSpec("examples/petstore-3.1.yaml"); err != nil {
+		t.Errorf("Expected the example to be read, got %v", err)
+	}
+}
+
+func TestLintExamples(t *testing.T) {
+	model := loadSpecModel(t, `openapi: 3.1.0
+info:
+  title: Examples
+  version: 1.0.0
+paths:
+  /pets:
+    # Shared by the operations, listed before their own
+    parameters:
+      - name: owner
+        in: query
+        schema:
+          type: integer
+        example: Tom
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+          example: ten
+        - $ref: '#/components/parameters/Page'
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+              examples:
+                cat:
+                  value:
+                    name: Tom
+                dog:
+                  value:
+                    id: 1
+                    name: Rex
+components:
+  parameters:
+    Page:
+      name: page
+      in: query
+      schema:
+        type: integer
+      example: 1.5
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+          example: "7"
+        name:
+          type: string
+      example:
+        id: 1
+        name: Tom
+`)
+
+	var got []string
+	for _, f := range model.lint() {
+		if f.Rule == "example-valid" {
+			got = append(got, f.Location+" "+f.Pointer+": "+f.Message)
+		}
+	}
+	want := []string{
+		`Parameter Page #/components/parameters/Page/example: example doesn't match its schema: $: expected integer, got number`,
+		`Schema Pet.id #/components/schemas/Pet/properties/id/example: example doesn't match its schema: $: expected integer, got string`,
+		`/pets #/paths/~1pets/parameters/0/example: example doesn't match its schema: $: expected integer, got string`,
+		`GET /pets #/paths/~1pets/get/parameters/0/example: example doesn't match its schema: $: expected integer, got string`,
+		`GET /pets #/paths/~1pets/get/responses/200/content/application~1json/examples/cat/value: example "cat" doesn't match its schema: $.id: missing required property`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected findings:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestLintDiscriminators(t *testing.T) {
+	model := loadSpecModel(t, `openapi: 3.1.0
+info:
+  title: Pets
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Bird'
+      discriminator:
+        propertyName: petType
+        mapping:
+          cat: '#/components/schemas/Cat'
+          dog: Dog
+          fish: '#/components/schemas/Fish'
+    Base:
+      type: object
+      properties:
+        petType:
+          type: string
+    Cat:
+      allOf:
+        - $ref: '#/components/schemas/Base'
+        - type: object
+    Dog:
+      type: object
+      properties:
+        bark:
+          type: string
+    Bird:
+      anyOf:
+        - type: object
+          properties:
+            petType:
+              const: parrot
+        - type: object
+          properties:
+            petType:
+              const: owl
+`)
+
+	var got []string
+	for _, f := range model.lint() {
+		if f.Rule == "discriminator" {
+			got = append(got, f.Pointer+": "+f.Message)
+		}
+	}
+	want := []string{
+		`#/components/schemas/Dog: schema has no "petType" property, the discriminator of #/components/schemas/Pet`,
+		`#/components/schemas/Pet/discriminator/mapping/fish: mapping "fish" points to #/components/schemas/Fish, which doesn't exist`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected findings:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	pet := model.components[model.findComponent("Schema", "Pet")]
+	details := formatComponentDetails(pet, detailOptions{schemaDepth: defaultSchemaDepth})
+	if !strings.Contains(details, "Discriminator: petType\n  cat → Cat\n  dog → Dog\n  fish → Fish ⚠ missing\n") {
+		t.Errorf("Expected the missing mapping to be marked, got:\n%s", details)
+	}
+}
+
+func TestWideCharacterLayout(t *testing.T) {
+	model := loadSpecModel(t, `openapi: 3.1.0
+info:
+  title: 宠物商店 API 🐶🐱 サービス 宠物商店宠物商店宠物商店宠物商店宠物商店
+  version: 1.0.0
+tags:
+  - name: ペット
+paths:
+  /ペット/{名前}:
+    get:
+      tags: [ペット]
+      summary: 获取宠物 🐕 信息
+      operationId: 获取
+      parameters:
+        - name: 名前
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        '200':
+          description: 成功 ✅
+components:
+  schemas:
+    宠物:
+      type: object
+      properties:
+        名字:
+          type: string
+`)
+
+	// Every line fits the terminal and the footer stays on the last one, in
+	// each view and modal
+	for _, width := range []int{40, 80, 120} {
+		for _, keys := range [][]string{nil, {"enter"}, {"tab"}, {"tab", "tab"}, {"P"}, {"/", "获"}} {
+			updated, _ := model.Update(tea.WindowSizeMsg{Width: width, Height: 20})
+			view := pressKeys(updated.(Model), keys...).View()
+			lines := strings.Split(view, "\n")
+			if len(lines) != 20 {
+				t.Errorf("Expected 20 lines at width %d after %q, got %d", width, keys, len(lines))
+			}
+			for i, line := range lines {
+				if lipgloss.Width(line) > width {
+					t.Errorf("Expected line %d to fit width %d after %q, got %d cells: %q", i, width, keys, lipgloss.Width(line), line)
+				}
+			}
+		}
+	}
+
+	updated, _ := model.Update(tea.WindowSizeMsg{Width: 70, Height: 20})
+	footer := updated.(Model).renderFooter()
+	if strings.Count(footer, "\n") != 1 || !strings.Contains(footer, icons.ellipsis) || strings.Contains(footer, "v1.0.0") {
+		t.Errorf("Expected the title to be cut to one footer line, got %q", footer)
+	}
+}
+
+func TestMinimalLayout(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.1.yaml")
+
+	// No size corrupts the layout, in any view or modal
+	for width := 1; width <= 70; width += 9 {
+		for height := 1; height <= 21; height += 4 {
+			for _, keys := range [][]string{nil, {"enter"}, {"tab"}, {"tab", "tab"}, {"?"}, {"P"}, {"/", "p"}} {
+				updated, _ := model.Update(tea.WindowSizeMsg{Width: width, Height: height})
+				lines := strings.Split(pressKeys(updated.(Model), keys...).View(), "\n")
+				if len(lines) > height {
+					t.Errorf("Expected at most %d lines at width %d after %q, got %d", height, width, keys, len(lines))
+				}
+				for i, line := range lines {
+					if lipgloss.Width(line) > width {
+						t.Errorf("Expected line %d to fit width %d after %q, got %q", i, width, keys, line)
+					}
+				}
+			}
+		}
+	}
+
+	updated, _ := model.Update(tea.WindowSizeMsg{Width: 40, Height: 12})
+	view := pressKeys(updated.(Model), "tab").View()
+	lines := strings.Split(view, "\n")
+	if lines[0] != "Components" {
+		t.Errorf("Expected only the view name in the header, got %q", lines[0])
+	}
+	if footer := lines[len(lines)-1]; !strings.Contains(footer, "Swagger Petstore") || strings.Contains(view, "Press '?' for help") {
+		t.Errorf("Expected a footer with only the title, got %q", footer)
+	}
+}
+
+func TestSelectionText(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.1.yaml")
+
+	model = pressKeys(model, "j")
+	selection := model.selectionText()
+	if !strings.HasPrefix(selection, "PUT /pet\nSummary: Update an existing pet.\n") || !strings.HasSuffix(selection, "\n") {
+		t.Errorf("Expected the selected endpoint and its details, got:\n%s", selection)
+	}
+
+	model = pressKeys(model, "tab")
+	if selection := model.selectionText(); !strings.HasPrefix(selection, "RequestBody Pet\nContent Types:\n") {
+		t.Errorf("Expected the selected component, got:\n%s", selection)
+	}
+
+	model = pressKeys(model, "tab")
+	if selection := model.selectionText(); selection != "" {
+		t.Errorf("Expected no selection in the info view, got:\n%s", selection)
+	}
+}
+
+func TestSessionPersistence(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	content, err := os.ReadFile("examples/petstore-3.1.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model := loadExampleModel(t, "examples/petstore-3.1.yaml")
+	model.specHash = specHash(content)
+	model = pressKeys(model, "j", "enter", "tab", "j", "enter", "r")
+	if err := model.saveSession(); err != nil {
+		t.Fatal(err)
+	}
+	want := session{View: "components", Cursor: 1, Unfolded: []string{"PUT /pet", "RequestBody UserArray"}, Raw: true}
+	if got := model.currentSession(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected session %+v, got %+v", want, got)
+	}
+
+	reopened := loadExampleModel(t, "examples/petstore-3.1.yaml")
+	reopened.specHash = specHash(content)
+	reopened.restoreSession()
+	if reopened.mode != viewComponents || reopened.cursor != 1 || !reopened.showRaw || reopened.components[1].folded || reopened.endpoints[1].folded || !reopened.endpoints[0].folded {
+		t.Errorf("Expected the session to be restored, got view %d, cursor %d", reopened.mode, reopened.cursor)
+	}
+
+	// The deprecated filter is restored, and items it hides keep their fold state
+	spec := `openapi: 3.1.0
+info:
+  title: Test
+  version: 1.0.0
+paths:
+  /old:
+    get:
+      deprecated: true
+      responses:
+        '200':
+          description: OK
+  /new:
+    get:
+      responses:
+        '200':
+          description: OK
+`
+	model = loadSpecModel(t, spec)
+	model.specHash = specHash([]byte(spec))
+	model = pressKeys(model, "enter", "D")
+	if err := model.saveSession(); err != nil {
+		t.Fatal(err)
+	}
+	reopened = loadSpecModel(t, spec)
+	reopened.specHash = specHash([]byte(spec))
+	reopened.restoreSession()
+	if reopened.unfiltered == nil || len(reopened.endpoints) != 1 || !slices.Contains(reopened.currentSession().Unfolded, sessionName("GET", "/new")) {
+		t.Errorf("Expected only deprecated items with the fold state kept, got %+v", reopened.currentSession())
+	}
+
+	// Another spec has no session
+	other := loadExampleModel(t, "examples/petstore-3.1.yaml")
+	other.specHash = specHash([]byte("changed"))
+	other.restoreSession()
+	if other.mode != viewEndpoints || other.cursor != 0 {
+		t.Errorf("Expected no session for a changed spec, got view %d, cursor %d", other.mode, other.cursor)
+	}
+}
+
+func TestAccessibleMode(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.1.yaml")
+	model.accessible = true
+
+	if got, want := model.announcement(), "Request 1 of 19: POST /pet, Add a new pet to the store, collapsed"; got != want {
+		t.Errorf("Expected announcement %q, got %q", want, got)
+	}
+
+	// J and K move by 10 items, stopping at the ends of the list
+	model = pressKeys(model, "J", "J", "enter")
+	if got, want := model.announcement(), "Request 19 of 19: PUT /user/{username}, Update user, expanded"; got != want {
+		t.Errorf("Expected announcement %q, got %q", want, got)
+	}
+	model = pressKeys(model, "K")
+	if model.cursor != 8 {
+		t.Errorf("Expected cursor 8 after K, got %d", model.cursor)
+	}
+
+	view := model.View()
+	if !strings.Contains(view, "[Requests]") {
+		t.Errorf("Expected the active view to be bracketed, got %q", view)
+	}
+	if !strings.Contains(view, "\nRequest 9 of 19: GET /store/inventory, Returns pet inventories by status, collapsed\n") {
+		t.Errorf("Expected the selected item announced on its own line, got %q", view)
+	}
+
+	model = pressKeys(model, "tab")
+	if got, want := model.announcement(), "Component 1 of 13: RequestBody Pet, collapsed"; got != want {
+		t.Errorf("Expected announcement %q, got %q", want, got)
+	}
+	model = pressKeys(model, "tab")
+	if got := model.announcement(); got != "Info" {
+		t.Errorf("Expected the info view announced, got %q", got)
+	}
+
+	// Box drawing and colors are left out
+	t.Setenv("NO_COLOR", "")
+	defer func() {
+		setASCII(false)
+		applyTheme(themes[defaultThemeName])
+	}()
+	if err := (config{}).applyDisplay("", false, true); err != nil {
+		t.Fatal(err)
+	}
+	if icons.border != lipgloss.HiddenBorder() || currentTheme.accent != (lipgloss.NoColor{}) {
+		t.Errorf("Expected no borders or colors in the accessible mode")
+	}
+}
+
+func TestFooterHints(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.1.yaml")
+
+	if got, want := model.footerHints(100), "Enter: expand · c: curl · t: try · gd: go to definition · ?: help"; got != want {
+		t.Errorf("Expected hints %q, got %q", want, got)
+	}
+	model = pressKeys(model, "enter")
+	if got := model.footerHints(100); !strings.HasPrefix(got, "Enter: collapse · ") {
+		t.Errorf("Expected a hint to collapse the unfolded item, got %q", got)
+	}
+
+	// Hints are cut from the end, keeping the help key
+	if got, want := model.footerHints(30), "Enter: collapse · ?: help"; got != want {
+		t.Errorf("Expected hints %q, got %q", want, got)
+	}
+
+	model = pressKeys(model, "tab")
+	if got, want := model.footerHints(100), "Enter: expand · gd: go to definition · gr: where used · ?: help"; got != want {
+		t.Errorf("Expected hints %q, got %q", want, got)
+	}
+	model = pressKeys(model, "tab")
+	if got, want := model.footerHints(100), "↓: scroll · o: open docs · ?: help"; got != want {
+		t.Errorf("Expected hints %q, got %q", want, got)
+	}
+
+	// Remapped keys are shown as bound
+	model.mode, model.cursor = viewEndpoints, 0
+	model.keys = newKeyMap(map[action][]string{actionToggle: {"o"}, actionHelp: {"h"}})
+	if got, want := model.footerHints(100), "o: collapse · h: help"; got != want {
+		t.Errorf("Expected hints %q, got %q", want, got)
+	}
+}
+
+func TestHelpModal(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.1.yaml")
+	model.height = 20
+
+	model = pressKeys(model, "?")
+	rows := model.helpRows()
+	if !model.showHelp || rows[0].title != "Move" || !slices.Contains(rows, helpRow{title: "General"}) {
+		t.Fatalf("Expected the help grouped by section, got %v", rows)
+	}
+
+	// The arrows scroll, stopping at the last rows
+	model = pressKeys(model, "down", "down")
+	if model.helpTop != 2 || !strings.Contains(model.View(), "2 more") {
+		t.Errorf("Expected the help scrolled by 2 rows, got %d", model.helpTop)
+	}
+	for range len(rows) {
+		model = pressKeys(model, "down")
+	}
+	if want := len(rows) - model.helpHeight(); model.helpTop != want {
+		t.Errorf("Expected the help scrolled to row %d, got %d", want, model.helpTop)
+	}
+
+	// Typed keys filter it, q and ? included, rather than closing it
+	model = pressKeys(model, "c", "u", "r", "l")
+	want := []helpRow{{title: "Open and copy"}, {keys: "c", description: "Copy endpoint as curl command"}}
+	if !reflect.DeepEqual(model.helpRows(), want) || model.helpTop != 0 {
+		t.Errorf("Expected only the curl key, got %v", model.helpRows())
+	}
+	model = pressKeys(model, "q", "?")
+	if !model.showHelp || model.helpFilter != "curlq?" || len(model.helpRows()) != 0 || !strings.Contains(model.View(), "No matching keys") {
+		t.Errorf("Expected q and ? to be typed in the filter, got %q", model.helpFilter)
+	}
+
+	model = pressKeys(model, "esc")
+	if model.showHelp {
+		t.Error("Expected esc to close the help")
+	}
+
+	// The filter starts empty, when ? closes the help
+	model = pressKeys(model, "?")
+	if model.helpFilter != "" {
+		t.Errorf("Expected an empty filter, got %q", model.helpFilter)
+	}
+	model = pressKeys(model, "?")
+	if model.showHelp {
+		t.Error("Expected ? to close the help")
+	}
+}
+
+func TestCountsAndGroupMotions(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.1.yaml")
+
+	steps := []struct {
+		keys   []string
+		cursor int
+	}{
+		{[]string{"1", "0", "j"}, 10},
+		{[]string{"3", "k"}, 7},
+		{[]string{"5", "0", "0", "j"}, 18},
+		{[]string{"5", "G"}, 4},
+		{[]string{"3", "g", "g"}, 2},
+		// The paths under /pet, /store and /user are groups
+		{[]string{"}"}, 8},
+		{[]string{"}"}, 12},
+		{[]string{"j", "{"}, 12},
+		{[]string{"{"}, 8},
+		{[]string{"2", "}"}, 18},
+		{[]string{"2", "{"}, 8},
+		{[]string{"G", "}"}, 18},
+	}
+	for _, step := range steps {
+		model = pressKeys(model, step.keys...)
+		if model.cursor != step.cursor {
+			t.Errorf("Expected cursor %d after %v, got %d", step.cursor, step.keys, model.cursor)
+		}
+	}
+
+	// The count shows while typed
+	model = pressKeys(model, "4")
+	if model.message != "4" || model.count != 4 {
+		t.Errorf("Expected the count shown, got %q", model.message)
+	}
+	model = pressKeys(model, "esc", "k")
+	if model.cursor != 17 {
+		t.Errorf("Expected any other key to drop the count, got cursor %d", model.cursor)
+	}
+
+	// Components are grouped by type
+	model = pressKeys(model, "tab", "}")
+	if model.components[model.cursor-1].compType == model.components[model.cursor].compType {
+		t.Errorf("Expected } to move to the next component type, got %s", model.components[model.cursor].compType)
+	}
+
+	// zz puts the selected item in the middle of the screen
+	model.mode, model.cursor, model.scrollOffset = viewEndpoints, 15, 10
+	model = pressKeys(model, "z", "z")
+	if want := 15 - (calculateContentHeight(model.height)/2 - 1); model.scrollOffset != want {
+		t.Errorf("Expected scroll offset %d, got %d", want, model.scrollOffset)
+	}
+}
+
+func TestCommandPrompt(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.1.yaml")
+
+	selected := func(m Model) string {
+		t.Helper()
+		return strings.SplitN(m.selectionText(), "\n", 2)[0]
+	}
+
+	model = pressKeys(model, ":", "op findPetsByTags")
+	if !model.prompting || !strings.Contains(model.View(), ":op findPetsByTags_") {
+		t.Fatal("Expected the command typed in the footer")
+	}
+	model = pressKeys(model, "enter")
+	if model.prompting || selected(model) != "GET /pet/findByTags" || model.endpoints[model.cursor].folded {
+		t.Errorf("Expected :op to select and unfold the operation, got %q", selected(model))
+	}
+
+	for command, want := range map[string]string{
+		"op GETINVENTORY":             "GET /store/inventory",
+		"path /store/order/{id}":      "GET /store/order/{orderId}",
+		"path /store/order/42 delete": "DELETE /store/order/{orderId}",
+		"schema pet":                  "Schema Pet",
+		"component UserArray":         "RequestBody UserArray",
+		"line 380":                    "GET /store/inventory",
+		"180":                         "GET /pet/findByTags",
+	} {
+		model = pressKeys(model, ":", command, "enter")
+		if got := selected(model); got != want || model.message != "" {
+			t.Errorf("Expected :%s to select %q, got %q (%s)", command, want, got, model.message)
+		}
+	}
+
+	for command, want := range map[string]string{
+		"op nothing":   "No operation with operationId nothing",
+		"schema":       "Usage: :schema <name>",
+		"open Pet":     `Unknown command "open", use op, path, schema, component or line`,
+		"line 1":       "Nothing is defined at line 1",
+		"path /orders": "No operation on /orders",
+	} {
+		model = pressKeys(model, ":", command, "enter")
+		if model.message != want {
+			t.Errorf("Expected :%s to show %q, got %q", command, want, model.message)
+		}
+	}
+
+	// Esc leaves the prompt without running the command
+	before := model.cursor
+	model = pressKeys(model, ":", "op getInventory", "esc")
+	if model.prompting || model.cursor != before {
+		t.Error("Expected esc to cancel the command")
+	}
+}
+
+func TestWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	copySpec := func(src, dst string) {
+		t.Helper()
+		content, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(dst, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	copySpec("examples/petstore-3.1.yaml", filepath.Join(dir, "pets", "openapi.yaml"))
+	copySpec("examples/train-travel.yaml", filepath.Join(dir, "trains.yaml"))
+	copySpec("examples/petstore-3.0.yaml", filepath.Join(dir, ".git", "old.yaml"))
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("foo: bar\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A directory opens the specs in it, skipping other files and hidden directories
+	specs, err := findWorkspace([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, spec := range specs {
+		names = append(names, spec.name)
+	}
+	if want := []string{"pets/openapi.yaml", "trains.yaml"}; !slices.Equal(names, want) {
+		t.Fatalf("Expected the specs %v, got %v", want, names)
+	}
+
+	// A workspace file lists them, and a single spec is no workspace
+	file := filepath.Join(dir, "workspace.yaml")
+	if err := os.WriteFile(file, []byte("specs:\n  - trains.yaml\n  - pets/*.yaml\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if listed, err := findWorkspace([]string{file}); err != nil || len(listed) != 2 || listed[0].name != "trains.yaml" {
+		t.Errorf("Expected the specs of the workspace file, got %v (%v)", listed, err)
+	}
+	if single, err := findWorkspace([]string{filepath.Join(dir, "trains.yaml")}); single != nil || err != nil {
+		t.Errorf("Expected no workspace for a single spec, got %v (%v)", single, err)
+	}
+
+	model, err := loadWorkspace(specs, NewModel, &loadProgress{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	model.width, model.height = 120, 40
+	updated, _ := model.Update(model.Init()())
+	model = updated.(Model)
+	if !strings.Contains(model.View(), "pets/openapi.yaml (1/2)") {
+		t.Error("Expected the spec shown in the header")
+	}
+
+	// The search spans every spec, its results labeled by spec
+	model = pressKeys(model, "/", "bookings")
+	if len(model.picker.items) == 0 || !strings.HasPrefix(model.picker.items[0].label, "trains.yaml: ") {
+		t.Fatalf("Expected results from the other spec, got %v", model.picker.items)
+	}
+	model = pressKeys(model, "enter")
+	if model.workspace.active != 1 || !strings.HasPrefix(model.selectionText(), "GET /bookings\n") {
+		t.Errorf("Expected the result selected in its spec, got %q", strings.SplitN(model.selectionText(), "\n", 2)[0])
+	}
+
+	// Each spec is shown as it was left
+	model = pressKeys(model, "S")
+	if model.picker == nil || model.picker.cursor != 1 || model.picker.items[0].label != "pets/openapi.yaml (19 operations)" {
+		t.Fatalf("Expected the specs listed with the active one selected, got %+v", model.picker)
+	}
+	model = pressKeys(model, "up", "enter")
+	if model.workspace.active != 0 || model.cursor != 0 || model.message != "Switched to pets/openapi.yaml" {
+		t.Errorf("Expected the first spec as left, got cursor %d (%s)", model.cursor, model.message)
+	}
+	model = pressKeys(model, "S", "down", "enter")
+	if !strings.HasPrefix(model.selectionText(), "GET /bookings\n") {
+		t.Error("Expected the selection of the other spec kept")
+	}
+
+	single := loadExampleModel(t, "examples/petstore-3.1.yaml")
+	if single = pressKeys(single, "S"); single.picker != nil || single.message == "" {
+		t.Error("Expected no spec to switch to outside a workspace")
+	}
+}
+
+func TestSinceBaseline(t *testing.T) {
+	baselineSpec := `openapi: 3.1.0
+info: {title: Pets, version: "1"}
+paths:
+  /old:
+    get:
+      summary: Old
+      responses: {"200": {description: OK}}
+  /pets:
+    get:
+      summary: List pets
+      responses: {"200": {description: OK}}
+  /stores:
+    get:
+      summary: List stores
+      responses: {"200": {description: OK}}
+components:
+  schemas:
+    Gone: {type: string}
+    Pet:
+      type: object
+      properties:
+        name: {type: string}
+`
+	spec := `openapi: 3.1.0
+info: {title: Pets, version: "2"}
+paths:
+  /new:
+    get:
+      summary: New
+      responses: {"200": {description: OK}}
+  /pets:
+    get:
+      summary: List pets
+      parameters:
+        - {name: limit, in: query, required: true, schema: {type: integer}}
+      responses: {"200": {description: OK}}
+  /stores:
+    get:
+      summary: List stores
+      responses: {"200": {description: OK}}
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name: {type: integer}
+`
+	old, err := parseSpec([]byte(baselineSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := loadSpecModel(t, spec)
+	model.specPath = "v2.yaml"
+	model.setBaseline("v1.yaml", old)
+
+	// Removed items are listed too, each item marked
+	view := model.View()
+	for _, want := range []string{"+ ▶ GET     /new", "- ▶ GET     /old", "~ ▶ GET     /pets", "  ▶ GET     /stores", "+1 ~2 -2 since v1.yaml", "3 endpoints"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the view:\n%s", want, view)
+		}
+	}
+
+	model = pressKeys(model, "j", "j", "enter")
+	if details := model.itemDetails(model.cursor); !strings.HasPrefix(details, "Since baseline: changed\n  "+icons.warning+" breaking: query parameter limit added (required)\n") {
+		t.Errorf("Expected the changes at the top of the details, got:\n%s", details)
+	}
+
+	// Removed items are defined in the baseline
+	model = pressKeys(model, "k")
+	if loc := model.locationText(); !strings.HasPrefix(loc, "v1.yaml:") {
+		t.Errorf("Expected the removed item located in the baseline, got %q", loc)
+	}
+	if model.accessible = true; !strings.HasSuffix(model.announcement(), "removed since baseline, collapsed") {
+		t.Errorf("Expected the announcement to tell the item was removed, got %q", model.announcement())
+	}
+
+	model = pressKeys(model, "tab")
+	if view := model.View(); !strings.Contains(view, "- ▶ Schema:         Gone") || !strings.Contains(view, "~ ▶ Schema:         Pet") {
+		t.Errorf("Expected the schemas marked:\n%s", view)
+	}
+}
+
+func TestPlugins(t *testing.T) {
+	dir := t.TempDir()
+	script := func(name, body string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	gateway := script("gateway", `grep -o '"x-gateway":{[^}]*}' | sed 's/.*"target":"\([^"]*\)".*/target \1/'`)
+	pointer := script("pointer", `grep -o '"pointer":"[^"]*"' | cut -d'"' -f4`)
+	failing := script("failing", `echo "no ticket system" >&2; exit 3`)
+
+	path := filepath.Join(dir, "config.yaml")
+	content := fmt.Sprintf(`plugins:
+  - name: Gateway
+    command: %s
+    details: true
+    extensions: [x-gateway]
+  - name: Pointer
+    command: %s
+    key: "g b"
+  - name: Ticket
+    command: %s
+    key: T
+`, gateway, pointer, failing)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugins, err := cfg.plugins()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := cfg.keyMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model := loadSpecModel(t, `openapi: 3.1.0
+info: {title: Gateway, version: "1"}
+paths:
+  /orders:
+    get:
+      x-gateway: {target: orders-service}
+      responses: {"200": {description: OK}}
+  /users:
+    get:
+      responses: {"200": {description: OK}}
+`)
+	model.plugins, model.keys = plugins, keys
+
+	// The details of the items with the extension get a section, once the
+	// plugin ran in the background
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if details := model.itemDetails(0); !strings.HasSuffix(details, "Gateway: running…\n") || cmd == nil {
+		t.Fatalf("Expected the plugin to be running, got:\n%s", details)
+	}
+	updated, _ = model.Update(cmd())
+	model = updated.(Model)
+	if details := model.itemDetails(0); !strings.HasSuffix(details, "Gateway:\n  target orders-service\n") {
+		t.Errorf("Expected the section of the plugin, got:\n%s", details)
+	}
+	if details := model.itemDetails(1); strings.Contains(details, "Gateway:") {
+		t.Errorf("Expected no section without the extension, got:\n%s", details)
+	}
+
+	// Formatted again with other options, the details keep the section without
+	// running the plugin again
+	model = pressKeys(model, "+")
+	if details := model.itemDetails(0); !strings.HasSuffix(details, "Gateway:\n  target orders-service\n") || model.runVisiblePlugins() != nil {
+		t.Errorf("Expected the section kept, got:\n%s", details)
+	}
+
+	run := func(m Model, act action) Model {
+		t.Helper()
+		cmd := m.runPluginAction(act)
+		if cmd == nil {
+			t.Fatalf("Expected %s to run, got %q", act, m.message)
+		}
+		updated, _ := m.Update(cmd())
+		return updated.(Model)
+	}
+	if act, _ := keys.resolve("g", "b"); act != pluginAction("Pointer") {
+		t.Fatalf("Expected g b bound to the plugin, got %q", act)
+	}
+	if model = run(model, pluginAction("Pointer")); model.message != "#/paths/~1orders/get" {
+		t.Errorf("Expected the output of the plugin in the footer, got %q", model.message)
+	}
+	if model = run(model, pluginAction("Ticket")); model.message != "Ticket failed: exit status 3: no ticket system" {
+		t.Errorf("Expected the error of the plugin, got %q", model.message)
+	}
+
+	model = pressKeys(model, "?", "run")
+	if view := model.View(); !strings.Contains(view, "Plugins") || !strings.Contains(view, "gb Run Pointer on the selected item") {
+		t.Errorf("Expected the plugins in the help:\n%s", view)
+	}
+
+	for content, want := range map[string]string{
+		"plugins: [{name: x, details: true}]":                                     `plugin "x" has no command`,
+		"plugins: [{name: x, command: y}]":                                        `plugin "x" needs details: true or a key`,
+		"plugins: [{command: y, key: b}]":                                         "plugin without a name",
+		"plugins: [{name: x, command: y, key: b}, {name: x, command: z, key: c}]": `two plugins named "x"`,
+	} {
+		var cfg config
+		if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cfg.plugins(); err == nil || err.Error() != want {
+			t.Errorf("Expected %q for %s, got %v", want, content, err)
+		}
+	}
+}
+
+func TestHooks(t *testing.T) {
+	model := loadExampleModel(t, "examples/petstore-3.1.yaml")
+
+	// Exports are written in the current directory
+	dir := t.TempDir()
+	t.Chdir(dir)
+	hook := filepath.Join(dir, "hook")
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\ncat > \"$1\"\necho \"sent $1\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	model.hooks = hooks{
+		OnSelect: hook + " select.json",
+		OnCopy:   hook + " copy.json",
+		OnExport: hook + " export.json",
+	}
+
+	// Runs the hook the command of a message returns and reads what it received
+	received := func(m Model, cmd tea.Cmd, file string) (Model, hookInput) {
+		t.Helper()
+		if cmd == nil {
+			t.Fatalf("Expected %s to be written by a hook", file)
+		}
+		updated, _ := m.Update(cmd())
+		m = updated.(Model)
+		var in hookInput
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err == nil {
+			err = json.Unmarshal(data, &in)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.message != "sent "+file {
+			t.Errorf("Expected the output of the hook in the footer, got %q", m.message)
+		}
+		return m, in
+	}
+	pointer := func(in hookInput) string {
+		var item itemInput
+		if err := json.Unmarshal(in.Item, &item); err != nil {
+			t.Fatal(err)
+		}
+		return item.Pointer
+	}
+
+	// on_select waits for the selection to settle
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	model = updated.(Model)
+	if cmd == nil || model.selections != 1 {
+		t.Fatal("Expected on_select scheduled once another item is selected")
+	}
+	if _, cmd = model.Update(selectSettledMsg{selections: 0}); cmd != nil {
+		t.Error("Expected no on_select for a selection changed since")
+	}
+	_, cmd = model.Update(selectSettledMsg{selections: 1})
+	model, in := received(model, cmd, "select.json")
+	if in.Event != hookSelect || pointer(in) != "#/paths/~1pet/put" {
+		t.Errorf("Expected on_select with PUT /pet, got %+v", in)
+	}
+
+	_, cmd = model.Update(clipboardMsg{what: "/pet", text: "/pet"})
+	model, in = received(model, cmd, "copy.json")
+	if in.Event != hookCopy || in.Copied != "/pet" || pointer(in) != "#/paths/~1pet/put" {
+		t.Errorf("Expected on_copy with the copied text, got %+v", in)
+	}
+
+	model, in = received(model, model.export(exportPostman), "export.json")
+	if in.Event != hookExport || in.Format != exportPostman || in.Export != "updatePet.postman_collection.json" {
+		t.Errorf("Expected on_export with the file, got %+v", in)
+	}
+
+	model.hooks.OnCopy = filepath.Join(dir, "missing")
+	_, cmd = model.Update(clipboardMsg{what: "/pet", text: "/pet"})
+	if updated, _ := model.Update(cmd()); !strings.HasPrefix(updated.(Model).message, "on_copy hook failed: ") {
+		t.Errorf("Expected the hook failure shown, got %q", updated.(Model).message)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// Output formats of the non-interactive mode
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// defaultOutput is the format commands print in unless told otherwise, set
+// with output in the config
+var defaultOutput = outputText
+
+// jsonDocument is what oq extracts from a spec, as printed by --output json
+type jsonDocument struct {
+	Title      string          `json:"title"`
+	Version    string          `json:"version"`
+	Endpoints  []jsonOperation `json:"endpoints"`
+	Webhooks   []jsonOperation `json:"webhooks"`
+	Components []jsonComponent `json:"components"`
+}
+
+type jsonOperation struct {
+	Name         string                `json:"name,omitempty"` // Webhooks only
+	Method       string                `json:"method"`
+	Path         string                `json:"path,omitempty"` // Endpoints only
+	OperationID  string                `json:"operationId,omitempty"`
+	Summary      string                `json:"summary,omitempty"`
+	Description  string                `json:"description,omitempty"`
+	Tags         []string              `json:"tags,omitempty"`
+	Deprecated   bool                  `json:"deprecated,omitempty"`
+	Security     []map[string][]string `json:"security"`
+	Parameters   []jsonParameter       `json:"parameters,omitempty"`
+	RequestTypes []string              `json:"requestContentTypes,omitempty"`
+	Responses    []string              `json:"responses,omitempty"`
+}
+
+type jsonParameter struct {
+	Name       string `json:"name"`
+	In         string `json:"in"`
+	Required   bool   `json:"required,omitempty"`
+	Deprecated bool   `json:"deprecated,omitempty"`
+	Type       string `json:"type,omitempty"`
+}
+
+type jsonComponent struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Deprecated  bool   `json:"deprecated,omitempty"`
+}
+
+func newJSONOperation(op *v3.Operation, sec security, dep deprecation) jsonOperation {
+	out := jsonOperation{
+		OperationID: op.OperationId,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        op.Tags,
+		Deprecated:  dep.deprecated,
+		Security:    []map[string][]string{},
+	}
+
+	for _, req := range sec.requirements {
+		if req == nil {
+			continue
+		}
+		schemes := map[string][]string{}
+		if req.Requirements != nil {
+			for pair := req.Requirements.First(); pair != nil; pair = pair.Next() {
+				scopes := pair.Value()
+				if scopes == nil {
+					scopes = []string{}
+				}
+				schemes[pair.Key()] = scopes
+			}
+		}
+		out.Security = append(out.Security, schemes)
+	}
+
+	for _, param := range op.Parameters {
+		if param == nil {
+			continue
+		}
+		p := jsonParameter{
+			Name:       param.Name,
+			In:         param.In,
+			Required:   param.Required != nil && *param.Required,
+			Deprecated: newDeprecation(param.Deprecated, param.Extensions).deprecated,
+		}
+		if param.Schema != nil {
+			p.Type = schemaTypeLabel(param.Schema)
+		}
+		out.Parameters = append(out.Parameters, p)
+	}
+
+	if op.RequestBody != nil && op.RequestBody.Content != nil {
+		for pair := op.RequestBody.Content.First(); pair != nil; pair = pair.Next() {
+			out.RequestTypes = append(out.RequestTypes, pair.Key())
+		}
+	}
+
+	if op.Responses != nil {
+		if op.Responses.Codes != nil {
+			for pair := op.Responses.Codes.First(); pair != nil; pair = pair.Next() {
+				out.Responses = append(out.Responses, pair.Key())
+			}
+		}
+		sortResponseCodes(out.Responses)
+		if op.Responses.Default != nil {
+			out.Responses = append(out.Responses, "default")
+		}
+	}
+
+	return out
+}
+
+// documentJSON describes the endpoints, webhooks and components of a spec as JSON
+func documentJSON(doc *v3.Document, endpoints []endpoint, webhooks []webhook, components []component) ([]byte, error) {
+	out := jsonDocument{
+		Endpoints:  []jsonOperation{},
+		Webhooks:   []jsonOperation{},
+		Components: []jsonComponent{},
+	}
+	if doc.Info != nil {
+		out.Title = doc.Info.Title
+		out.Version = doc.Info.Version
+	}
+
+	for _, ep := range endpoints {
+		op := newJSONOperation(ep.op, ep.security, ep.deprecation)
+		op.Method = ep.method
+		op.Path = ep.path
+		out.Endpoints = append(out.Endpoints, op)
+	}
+
+	for _, hook := range webhooks {
+		op := newJSONOperation(hook.op, hook.security, hook.deprecation)
+		op.Name = hook.name
+		op.Method = hook.method
+		out.Webhooks = append(out.Webhooks, op)
+	}
+
+	for _, comp := range components {
+		out.Components = append(out.Components, jsonComponent{
+			Type:        comp.compType,
+			Name:        comp.name,
+			Description: comp.description,
+			Deprecated:  comp.deprecation.deprecated,
+		})
+	}
+
+	return json.MarshalIndent(out, "", "  ")
+}
+
+// writeOutput prints the spec in a non-interactive format instead of starting the viewer
+func writeOutput(w io.Writer, format string, doc *v3.Document) error {
+	switch format {
+	case outputText:
+		return writeEndpointList(w, extractEndpoints(doc))
+	case outputJSON:
+		data, err := documentJSON(doc, extractEndpoints(doc), extractWebhooks(doc), extractComponents(doc))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+	return fmt.Errorf("unknown output format %q, use %s or %s", format, outputText, outputJSON)
+}
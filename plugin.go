@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.yaml.in/yaml/v4"
+)
+
+// plugin is an external program making sense of what oq can't know about, like
+// the extensions of a gateway. It receives an item as JSON on stdin, and what
+// it prints is added to the details of the items or, run with a key, shown.
+type plugin struct {
+	// Name titles the section added to the details and names the action, plugin.<name>
+	Name string `yaml:"name"`
+
+	// Command is the program run, with its arguments, e.g. "oq-apigateway --short"
+	Command string `yaml:"command"`
+
+	// Details adds the output to the details of every item
+	Details bool `yaml:"details"`
+
+	// Extensions limits the details to the items with one of these extensions,
+	// e.g. x-amazon-apigateway-integration
+	Extensions []string `yaml:"extensions"`
+
+	// Key runs the plugin on the selected item when pressed, e.g. "g b"
+	Key string `yaml:"key"`
+}
+
+// pluginTimeout limits how long a plugin runs, its section of the details
+// telling it is running until then
+const pluginTimeout = 5 * time.Second
+
+// pluginActionPrefix starts the names of the actions running plugins
+const pluginActionPrefix = "plugin."
+
+func pluginAction(name string) action {
+	return action(pluginActionPrefix + name)
+}
+
+// itemInput is the JSON plugins receive on stdin about an item, with what
+// --output json tells about it and its source in the spec
+type itemInput struct {
+	Spec      string          `json:"spec,omitempty"` // File the spec was read from
+	Pointer   string          `json:"pointer"`        // To the item, e.g. "#/paths/~1pets/get"
+	Endpoint  *jsonOperation  `json:"endpoint,omitempty"`
+	Webhook   *jsonOperation  `json:"webhook,omitempty"`
+	Component *jsonComponent  `json:"component,omitempty"`
+	Source    json.RawMessage `json:"source"` // The item as written, extensions included
+}
+
+// itemInput describes the item at index in the current view as JSON, none in
+// the info view
+func (m *Model) itemInput(index int) ([]byte, bool) {
+	if m.mode == viewInfo || index < 0 || index > m.getMaxItems() {
+		return nil, false
+	}
+
+	in := itemInput{Spec: m.specPath}
+	var source any
+	switch m.mode {
+	case viewEndpoints:
+		ep := m.endpoints[index]
+		op := newJSONOperation(ep.op, ep.security, ep.deprecation)
+		op.Method, op.Path = ep.method, ep.path
+		in.Endpoint, in.Pointer, source = &op, operationRef(ep.path, ep.method), ep.source
+	case viewWebhooks:
+		hook := m.webhooks[index]
+		op := newJSONOperation(hook.op, hook.security, hook.deprecation)
+		op.Name, op.Method = hook.name, hook.method
+		in.Webhook, in.Pointer, source = &op, webhookRef(hook.name, hook.method), hook.source
+	case viewComponents:
+		comp := m.components[index]
+		in.Component = &jsonComponent{Type: comp.compType, Name: comp.name, Description: comp.description, Deprecated: comp.deprecation.deprecated}
+		in.Pointer, source = componentRef(comp.compType, comp.name), comp.source
+	}
+	in.Source = json.RawMessage(nodeToJSON(sourceNode(source), ""))
+
+	data, err := json.Marshal(in)
+	return data, err == nil
+}
+
+// runPlugin runs a command with input on stdin and returns what it printed,
+// or the first line of its errors when it fails
+func runPlugin(command string, input []byte) (string, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return "", fmt.Errorf("no command")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if line, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); line != "" {
+			err = fmt.Errorf("%w: %s", err, line)
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// hasExtension reports whether the source of an item has one of the extensions
+func hasExtension(source any, names []string) bool {
+	node := sourceNode(source)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if slices.Contains(names, node.Content[i].Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginRun is what the detail plugins printed about an item, run once in the
+// background whatever the options the details are formatted with
+type pluginRun struct {
+	text string
+	done bool
+}
+
+// pluginDetailsMsg delivers the sections the detail plugins added to an item
+type pluginDetailsMsg struct {
+	spec string // Of the item, another spec of the workspace being shown by then maybe
+	name string
+	text string
+}
+
+// itemName names the item at index like its details are keyed, e.g. "GET /pets",
+// and returns its source
+func (m *Model) itemName(index int) (string, any) {
+	if index < 0 || index > m.getMaxItems() {
+		return "", nil
+	}
+	switch m.mode {
+	case viewEndpoints:
+		ep := m.endpoints[index]
+		return ep.method + " " + ep.path, ep.source
+	case viewComponents:
+		comp := m.components[index]
+		return comp.compType + " " + comp.name, comp.source
+	case viewWebhooks:
+		hook := m.webhooks[index]
+		return hook.method + " " + hook.name, hook.source
+	}
+	return "", nil
+}
+
+// detailPlugins returns the plugins adding to the details of an item
+func (m *Model) detailPlugins(source any) []plugin {
+	var plugins []plugin
+	for _, p := range m.plugins {
+		if p.Details && (len(p.Extensions) == 0 || hasExtension(source, p.Extensions)) {
+			plugins = append(plugins, p)
+		}
+	}
+	return plugins
+}
+
+// pluginDetails returns the sections the detail plugins added to an item,
+// titled by plugin, or tells they are still running
+func (m *Model) pluginDetails(name string, source any) string {
+	if run := m.pluginRuns[name]; run != nil && run.done {
+		return run.text
+	}
+	var s strings.Builder
+	for _, p := range m.detailPlugins(source) {
+		fmt.Fprintf(&s, "%s: running%s\n", p.Name, icons.ellipsis)
+	}
+	return s.String()
+}
+
+// runDetailPlugins runs the detail plugins on the item at index in the
+// background, once per item
+func (m *Model) runDetailPlugins(index int) tea.Cmd {
+	name, source := m.itemName(index)
+	plugins := m.detailPlugins(source)
+	if len(plugins) == 0 || m.pluginRuns[name] != nil {
+		return nil
+	}
+	input, ok := m.itemInput(index)
+	if !ok {
+		return nil
+	}
+	if m.pluginRuns == nil {
+		m.pluginRuns = make(map[string]*pluginRun)
+	}
+	m.pluginRuns[name] = &pluginRun{}
+
+	spec := m.specPath
+	return func() tea.Msg {
+		var s strings.Builder
+		for _, p := range plugins {
+			out, err := runPlugin(p.Command, input)
+			switch {
+			case err != nil:
+				fmt.Fprintf(&s, "%s: %s failed: %v\n", p.Name, icons.warning, err)
+			case out != "":
+				fmt.Fprintf(&s, "%s:\n", p.Name)
+				for _, line := range strings.Split(out, "\n") {
+					fmt.Fprintf(&s, "  %s\n", line)
+				}
+			}
+		}
+		return pluginDetailsMsg{spec: spec, name: name, text: s.String()}
+	}
+}
+
+// runVisiblePlugins runs the detail plugins on the unfolded items on the
+// screen, those not run on yet
+func (m *Model) runVisiblePlugins() tea.Cmd {
+	if len(m.plugins) == 0 || m.mode == viewInfo {
+		return nil
+	}
+	var cmds []tea.Cmd
+	lines := calculateContentHeight(m.height)
+	for i := m.scrollOffset; i <= m.getMaxItems() && lines > 0; i++ {
+		if !m.isFolded(i) {
+			cmds = append(cmds, m.runDetailPlugins(i))
+		}
+		lines -= m.getItemHeight(i)
+	}
+	return tea.Batch(cmds...)
+}
+
+// showPluginDetails adds the sections the detail plugins printed to the
+// details of their item, formatted again
+func (m *Model) showPluginDetails(msg pluginDetailsMsg) {
+	target := m
+	if ws := m.workspace; ws != nil && msg.spec != m.specPath {
+		i := slices.IndexFunc(ws.specs, func(spec workspaceSpec) bool { return spec.path == msg.spec })
+		if i < 0 {
+			return
+		}
+		target = &ws.specs[i].model
+	}
+	if target.pluginRuns == nil {
+		target.pluginRuns = make(map[string]*pluginRun)
+	}
+	target.pluginRuns[msg.name] = &pluginRun{text: msg.text, done: true}
+	for key := range target.details {
+		if key.name == msg.name {
+			delete(target.details, key)
+		}
+	}
+	m.ensureCursorVisible()
+}
+
+// pluginMsg delivers what a plugin run with its key printed
+type pluginMsg struct {
+	name   string
+	output string
+	err    error
+}
+
+// runPluginAction runs the plugin an action is bound to on the selected item,
+// none for the other actions
+func (m *Model) runPluginAction(act action) tea.Cmd {
+	name, ok := strings.CutPrefix(string(act), pluginActionPrefix)
+	if !ok {
+		return nil
+	}
+	i := slices.IndexFunc(m.plugins, func(p plugin) bool { return p.Name == name })
+	if i < 0 {
+		return nil
+	}
+	input, ok := m.itemInput(m.cursor)
+	if !ok {
+		m.message = "Select an operation or component to run " + name + " on"
+		return nil
+	}
+
+	command := m.plugins[i].Command
+	m.message = "Running " + name + icons.ellipsis
+	return func() tea.Msg {
+		out, err := runPlugin(command, input)
+		return pluginMsg{name: name, output: out, err: err}
+	}
+}
+
+// showPluginOutput shows what a plugin printed in the footer, or in the pager
+// when it is longer than a line
+func (m *Model) showPluginOutput(msg pluginMsg) tea.Cmd {
+	switch {
+	case msg.err != nil:
+		m.message = msg.name + " failed: " + msg.err.Error()
+	case msg.output == "":
+		m.message = msg.name + " done"
+	case !strings.Contains(msg.output, "\n"):
+		m.message = msg.output
+	default:
+		m.message = ""
+		return pageText(msg.output)
+	}
+	return nil
+}
+
+// pluginSection lists the plugins run with a key in the help
+func (m Model) pluginSection() keySection {
+	section := keySection{title: "Plugins"}
+	for _, p := range m.plugins {
+		if p.Key != "" {
+			section.actions = append(section.actions, keyAction{pluginAction(p.Name), "Run " + p.Name + " on the selected item", nil})
+		}
+	}
+	return section
+}
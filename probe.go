@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// Results of probing a path
+const (
+	probeFound        = "found"
+	probeMissing      = "missing"      // Documented, but the server doesn't know it
+	probeUndocumented = "undocumented" // Served, but not in the spec
+	probeSkipped      = "skipped"
+	probeError        = "error"
+)
+
+// commonPaths are tried by "oq probe -crawl", as servers often have them
+// without documenting them
+var commonPaths = []string{
+	"/health", "/healthz", "/readyz", "/livez", "/status", "/ping", "/version", "/info",
+	"/metrics", "/debug/vars", "/debug/pprof/", "/actuator", "/actuator/health", "/admin",
+	"/openapi.json", "/openapi.yaml", "/swagger.json", "/swagger.yaml", "/api-docs", "/docs",
+	"/graphql", "/.well-known/openapi.json",
+}
+
+// probeResult is whether the server answers at a path
+type probeResult struct {
+	Path   string `json:"path"`
+	URL    string `json:"url"`
+	Result string `json:"result"`
+	Status int    `json:"status,omitempty"` // The last one received
+	Note   string `json:"note,omitempty"`
+}
+
+// isNotFound tells whether a status means the server has nothing at a path.
+// Anything else, even 401 or 405, means a route exists.
+func isNotFound(status int) bool {
+	return status == http.StatusNotFound || status == http.StatusGone
+}
+
+// probeURL sends OPTIONS then HEAD to a URL, stopping at the first response
+// that shows the path exists
+func probeURL(target string, headers []requestField) (int, error) {
+	status := 0
+	for _, method := range []string{http.MethodOptions, http.MethodHead} {
+		req, err := newHTTPRequest(sampleRequest{method: method, url: target, headers: headers})
+		if err != nil {
+			return 0, err
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		status = resp.StatusCode
+		if !isNotFound(status) {
+			break
+		}
+	}
+	return status, nil
+}
+
+// probePaths checks that the server answers at every documented path. Paths
+// whose parameters have no example or -param value are skipped, as a 404 for
+// a made up ID doesn't tell whether the route exists. With crawl, the common
+// paths the spec lacks are tried too.
+func (s contractSuite) probePaths(doc *v3.Document, endpoints []endpoint, crawl bool) []probeResult {
+	results := []probeResult{}
+	var baseURL string
+	var headers []requestField
+	seen := map[string]bool{}
+	for _, ep := range endpoints {
+		if seen[ep.path] {
+			continue
+		}
+		seen[ep.path] = true
+
+		req, missing := s.request(doc, ep)
+		baseURL, headers = req.baseURL, req.headers
+		target, _, _ := strings.Cut(req.url, "?")
+		result := probeResult{Path: ep.path, URL: target}
+		if len(missing) > 0 {
+			result.Result = probeSkipped
+			result.Note = "no value for " + strings.Join(missing, ", ") + ", give one with -param"
+			results = append(results, result)
+			continue
+		}
+
+		status, err := probeURL(target, headers)
+		result.Status = status
+		switch {
+		case err != nil:
+			result.Result, result.Note = probeError, err.Error()
+		case isNotFound(status):
+			result.Result = probeMissing
+		default:
+			result.Result = probeFound
+		}
+		results = append(results, result)
+	}
+
+	if !crawl || baseURL == "" {
+		return results
+	}
+	matchers := make([]operationMatcher, len(endpoints))
+	for i, ep := range endpoints {
+		matchers[i] = newOperationMatcher(ep)
+	}
+	for _, path := range commonPaths {
+		documented := false
+		for _, m := range matchers {
+			documented = documented || m.pattern.MatchString(path)
+		}
+		if documented {
+			continue
+		}
+		status, err := probeURL(baseURL+path, headers)
+		if err == nil && !isNotFound(status) {
+			results = append(results, probeResult{Path: path, URL: baseURL + path, Result: probeUndocumented, Status: status})
+		}
+	}
+	return results
+}
+
+func writeProbeText(w io.Writer, results []probeResult) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[r.Result]++
+		status := ""
+		if r.Status > 0 {
+			status = fmt.Sprint(r.Status)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", strings.ToUpper(r.Result), r.Path, status, r.Note)
+	}
+	fmt.Fprintf(tw, "\n%d found, %d missing, %d undocumented, %d errored, %d skipped\n", counts[probeFound], counts[probeMissing], counts[probeUndocumented], counts[probeError], counts[probeSkipped])
+	return tw.Flush()
+}
+
+// probeFindings returns errFindings when documented paths are missing,
+// undocumented ones are served or the server couldn't be reached, so that a
+// dead server doesn't pass
+func probeFindings(results []probeResult) error {
+	for _, r := range results {
+		if r.Result == probeMissing || r.Result == probeUndocumented || r.Result == probeError {
+			return errFindings
+		}
+	}
+	return nil
+}
+
+// runProbe implements "oq probe". It exits with status 1 when documented paths
+// are missing or can't be probed or, with -crawl, undocumented ones are served.
+func runProbe(fs *flag.FlagSet, args []string) error {
+	server := addRequestFlags(fs)
+	crawl := fs.Bool("crawl", false, "also try common paths like /health and /metrics, reporting those the spec lacks")
+	output := fs.String("output", defaultOutput, "output format: text or json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	path, err := specArg(fs)
+	if err != nil {
+		return err
+	}
+	if *output != outputText && *output != outputJSON {
+		return fmt.Errorf("unknown output format %q, use %s or %s", *output, outputText, outputJSON)
+	}
+
+	suite, err := server.suite()
+	if err != nil {
+		return err
+	}
+	doc, err := loadSpec(path)
+	if err != nil {
+		return err
+	}
+	results := suite.probePaths(doc, extractEndpoints(doc), *crawl)
+
+	if *output == outputJSON {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		err = writeResult("", append(data, '\n'))
+	} else {
+		err = writeProbeText(os.Stdout, results)
+	}
+	if err != nil {
+		return err
+	}
+	return probeFindings(results)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// startupProfile records where the time goes when oq starts with -profile: a
+// CPU profile, a heap profile once the spec is shown, and how long each phase
+// took, for users to attach to performance issues
+type startupProfile struct {
+	dir      string
+	cpu      *os.File
+	mu       sync.Mutex
+	phases   []phaseTiming
+	rendered bool
+	heapErr  error
+}
+
+type phaseTiming struct {
+	name     string
+	duration time.Duration
+}
+
+// profile is nil unless -profile is given, its methods doing nothing then
+var profile *startupProfile
+
+// startProfile creates the profile directory and starts the CPU profile
+func startProfile(dir string) (*startupProfile, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(filepath.Join(dir, "cpu.pprof"))
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &startupProfile{dir: dir, cpu: f}, nil
+}
+
+// phase starts timing a phase, which ends when the returned function is called
+func (p *startupProfile) phase(name string) func() {
+	if p == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.phases = append(p.phases, phaseTiming{name: name, duration: time.Since(start)})
+	}
+}
+
+// firstRender times the first render of the viewer, then writes the heap
+// profile with the spec loaded. Later renders aren't timed.
+func (p *startupProfile) firstRender() func() {
+	if p == nil {
+		return func() {}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.rendered {
+		return func() {}
+	}
+	p.rendered = true
+	done := p.phase("first render")
+	return func() {
+		done()
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.heapErr = p.writeHeap()
+	}
+}
+
+func (p *startupProfile) writeHeap() error {
+	f, err := os.Create(filepath.Join(p.dir, "heap.pprof"))
+	if err != nil {
+		return err
+	}
+	runtime.GC() // The profile shows the memory in use as of the last collection
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// stop ends the CPU profile, writes the heap profile if the viewer didn't and
+// prints the phases
+func (p *startupProfile) stop(w io.Writer) error {
+	if p == nil {
+		return nil
+	}
+	pprof.StopCPUProfile()
+	if err := p.cpu.Close(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.rendered {
+		p.heapErr = p.writeHeap()
+	}
+	if p.heapErr != nil {
+		return p.heapErr
+	}
+
+	fmt.Fprintln(w, "Startup phases:")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	var total time.Duration
+	for _, phase := range p.phases {
+		fmt.Fprintf(tw, "  %s\t%12s\n", phase.name, phase.duration.Round(time.Microsecond))
+		total += phase.duration
+	}
+	fmt.Fprintf(tw, "  total\t%12s\n", total.Round(time.Microsecond))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "Profiles written to %s and %s\n", filepath.Join(p.dir, "cpu.pprof"), filepath.Join(p.dir, "heap.pprof"))
+	return err
+}
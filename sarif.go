@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// SARIF 2.1.0 (https://docs.oasis-open.org/sarif/sarif/v2.1.0/) is read by GitHub
+// code scanning and other CI systems to annotate the lines with problems. Only
+// the parts oq fills in are declared.
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	ShortDescription     sarifMessage        `json:"shortDescription"`
+	DefaultConfiguration *sarifConfiguration `json:"defaultConfiguration,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"` // error, warning or note
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifLogicalLocation names the place in the spec, as a JSON pointer
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// newSARIFLocation locates a result in a file, at a line when it is known. Specs
+// read from stdin only get the JSON pointer.
+func newSARIFLocation(file string, line, column int, pointer string) sarifLocation {
+	loc := sarifLocation{}
+	if file != "" && file != "<stdin>" {
+		loc.PhysicalLocation = &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(file)}}
+		if line > 0 {
+			loc.PhysicalLocation.Region = &sarifRegion{StartLine: line, StartColumn: column}
+		}
+	}
+	if pointer != "" {
+		loc.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: pointer}}
+	}
+	return loc
+}
+
+func writeSARIF(w io.Writer, rules []sarifRule, results []sarifResult) error {
+	if results == nil {
+		results = []sarifResult{}
+	}
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "oq",
+				InformationURI: "https://github.com/plutov/oq",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+func validationSARIFRules() []sarifRule {
+	var rules []sarifRule
+	for _, rule := range validationRules {
+		rules = append(rules, sarifRule{ID: rule.id, ShortDescription: sarifMessage{Text: rule.description}})
+	}
+	return rules
+}
+
+// issuesSARIF reports validation issues as errors, with the suggested fix in the message
+func issuesSARIF(issues []issue) []sarifResult {
+	var results []sarifResult
+	for _, is := range issues {
+		text := is.Message
+		if is.Fix != "" {
+			text += ". Fix: " + is.Fix
+		}
+		results = append(results, sarifResult{
+			RuleID:    is.Rule,
+			Level:     "error",
+			Message:   sarifMessage{Text: text},
+			Locations: []sarifLocation{newSARIFLocation(is.File, is.Line, is.Column, is.Pointer)},
+		})
+	}
+	return results
+}
+
+// sarifLevels maps lint severities to SARIF levels
+var sarifLevels = map[string]string{
+	severityError:   "error",
+	severityWarning: "warning",
+	severityInfo:    "note",
+}
+
+// lintSARIFRules lists the lint rules turned on, with their severity in the ruleset
+func lintSARIFRules(rs ruleset) []sarifRule {
+	var rules []sarifRule
+	for _, rule := range lintRules {
+		severity := rs.severity(rule)
+		if severity == severityOff {
+			continue
+		}
+		rules = append(rules, sarifRule{
+			ID:                   rule.name,
+			ShortDescription:     sarifMessage{Text: rule.description},
+			DefaultConfiguration: &sarifConfiguration{Level: sarifLevels[severity]},
+		})
+	}
+	return rules
+}
+
+func findingsSARIF(file string, findings []finding) []sarifResult {
+	var results []sarifResult
+	for _, f := range findings {
+		results = append(results, sarifResult{
+			RuleID:    f.Rule,
+			Level:     sarifLevels[f.Severity],
+			Message:   sarifMessage{Text: f.Location + ": " + f.Message},
+			Locations: []sarifLocation{newSARIFLocation(file, f.Line, f.Column, f.Pointer)},
+		})
+	}
+	return results
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// Kinds of search results
+const (
+	matchOperation = "operation"
+	matchSchema    = "schema"
+	matchProperty  = "property"
+)
+
+// match is a part of the spec found by "oq search"
+type match struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`    // "GET /pets", the schema or the property name
+	Field   string `json:"field"`   // What contains the query: name, path, summary, ...
+	Pointer string `json:"pointer"` // JSON pointer to the operation, schema or property
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
+
+// searchSpec finds the operations, schemas and properties whose name,
+// identifiers or description contain the query, ignoring case
+func searchSpec(doc *yaml.Node, query string) []match {
+	query = strings.ToLower(query)
+	var matches []match
+	add := func(kind, name, pointer string, key *yaml.Node, fields ...[2]string) {
+		for _, f := range fields {
+			if strings.Contains(strings.ToLower(f[1]), query) {
+				matches = append(matches, match{Kind: kind, Name: name, Field: f[0], Pointer: pointer, Line: key.Line, Column: key.Column})
+				return
+			}
+		}
+	}
+	text := func(node *yaml.Node, name string) string {
+		if _, value := field(node, name); value != nil && value.Kind == yaml.ScalarNode {
+			return value.Value
+		}
+		return ""
+	}
+
+	for _, section := range []string{"paths", "webhooks"} {
+		_, items := field(doc, section)
+		eachField(items, func(path, item *yaml.Node) {
+			for _, method := range httpMethods {
+				key, op := field(item, method)
+				if op == nil {
+					continue
+				}
+				var tags []string
+				if _, list := field(op, "tags"); list != nil {
+					for _, tag := range list.Content {
+						tags = append(tags, unalias(tag).Value)
+					}
+				}
+				add(matchOperation, strings.ToUpper(method)+" "+path.Value, pointerTo(pointerTo("#/"+section, path.Value), method), key,
+					[2]string{"path", path.Value},
+					[2]string{"operationId", text(op, "operationId")},
+					[2]string{"summary", text(op, "summary")},
+					[2]string{"tags", strings.Join(tags, " ")},
+					[2]string{"description", text(op, "description")},
+				)
+			}
+		})
+	}
+
+	_, components := field(doc, "components")
+	_, schemas := field(components, "schemas")
+	eachField(schemas, func(name, schema *yaml.Node) {
+		add(matchSchema, name.Value, pointerTo("#/components/schemas", name.Value), name,
+			[2]string{"name", name.Value},
+			[2]string{"title", text(schema, "title")},
+			[2]string{"description", text(schema, "description")},
+		)
+	})
+
+	walkSchemas(doc, func(schema *yaml.Node, pointer string) {
+		_, props := field(schema, "properties")
+		eachField(props, func(name, prop *yaml.Node) {
+			add(matchProperty, name.Value, pointerTo(pointerTo(pointer, "properties"), name.Value), name,
+				[2]string{"name", name.Value},
+				[2]string{"description", text(prop, "description")},
+			)
+		})
+	})
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Line != matches[j].Line {
+			return matches[i].Line < matches[j].Line
+		}
+		return matches[i].Column < matches[j].Column
+	})
+	return matches
+}
+
+// writeMatchesText prints one match per line as tab separated line, kind, JSON
+// pointer and name, for grep, cut or fzf
+func writeMatchesText(w io.Writer, matches []match) error {
+	out := bufio.NewWriter(w)
+	for _, m := range matches {
+		fmt.Fprintf(out, "%d\t%s\t%s\t%s\n", m.Line, m.Kind, m.Pointer, m.Name)
+	}
+	return out.Flush()
+}
+
+// runSearch implements "oq search"
+func runSearch(fs *flag.FlagSet, args []string) error {
+	output := fs.String("output", defaultOutput, "output format: text or json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errUsage
+	}
+	if *output != outputText && *output != outputJSON {
+		return fmt.Errorf("unknown output format %q, use %s or %s", *output, outputText, outputJSON)
+	}
+
+	content, err := readSpec(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	root, err := parseSpecTree(content)
+	if err != nil {
+		return err
+	}
+	matches := searchSpec(root.Content[0], fs.Arg(1))
+
+	if *output == outputJSON {
+		if matches == nil {
+			matches = []match{}
+		}
+		data, err := json.MarshalIndent(matches, "", "  ")
+		if err != nil {
+			return err
+		}
+		return writeResult("", append(data, '\n'))
+	}
+	return writeMatchesText(os.Stdout, matches)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// usesScheme reports whether any of the alternative requirements names the scheme
+func (sec security) usesScheme(name string) bool {
+	for _, req := range sec.requirements {
+		if req == nil || req.Requirements == nil {
+			continue
+		}
+		if _, ok := req.Requirements.Get(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// optional reports whether an operation can be called without credentials: it has
+// no requirements at all, or one of the alternatives is the empty requirement {}
+func (sec security) optional() bool {
+	if len(sec.requirements) == 0 {
+		return true
+	}
+	for _, req := range sec.requirements {
+		if req == nil || req.Requirements == nil || req.Requirements.Len() == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// schemeLabel describes a security scheme, e.g. "apiKey in header" or "http bearer"
+func schemeLabel(scheme *v3.SecurityScheme) string {
+	switch strings.ToLower(scheme.Type) {
+	case "apikey":
+		return fmt.Sprintf("%s in %s", scheme.Type, scheme.In)
+	case "http":
+		return strings.TrimSpace(scheme.Type + " " + scheme.Scheme)
+	}
+	return scheme.Type
+}
+
+// formatSecurityReport lists the operations each security scheme protects, and
+// flags operations that can be called without any credentials
+func formatSecurityReport(doc *v3.Document, endpoints []endpoint) string {
+	var details strings.Builder
+
+	var names []string
+	if doc.Components != nil && doc.Components.SecuritySchemes != nil {
+		for pair := doc.Components.SecuritySchemes.First(); pair != nil; pair = pair.Next() {
+			names = append(names, pair.Key())
+		}
+	}
+	sort.Strings(names)
+
+	if len(names) > 0 {
+		details.WriteString("\n" + infoLabelStyle.Render("Security Schemes:") + "\n")
+	}
+	for _, name := range names {
+		scheme, _ := doc.Components.SecuritySchemes.Get(name)
+
+		var used []string
+		for _, ep := range endpoints {
+			if ep.security.usesScheme(name) {
+				used = append(used, ep.method+" "+ep.path)
+			}
+		}
+
+		line := "  - " + name
+		if scheme != nil {
+			line += " (" + schemeLabel(scheme) + ")"
+		}
+		if len(used) == 0 {
+			details.WriteString(line + ": not used\n")
+			continue
+		}
+		details.WriteString(fmt.Sprintf("%s: %s\n", line, pluralize(len(used), "operation")))
+		for _, op := range used {
+			details.WriteString("      " + op + "\n")
+		}
+	}
+
+	var unprotected []string
+	for _, ep := range endpoints {
+		if !ep.security.optional() {
+			continue
+		}
+		line := ep.method + " " + ep.path
+		if len(ep.security.requirements) > 0 {
+			line += " (security optional)"
+		}
+		unprotected = append(unprotected, line)
+	}
+
+	if len(unprotected) > 0 {
+		title := fmt.Sprintf("%s Without Security (%d):", icons.cross, len(unprotected))
+		details.WriteString("\n" + infoWarningStyle.Render(title) + "\n")
+		for _, line := range unprotected {
+			details.WriteString("  - " + line + "\n")
+		}
+	}
+
+	return details.String()
+}
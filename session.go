@@ -0,0 +1,158 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// session is the state of the viewer saved on quit and restored when the same
+// spec is opened again. Unfolded items are named like in the list, e.g.
+// "GET /pets" or "Schema Pet".
+type session struct {
+	View           string   `json:"view"`
+	Cursor         int      `json:"cursor"`
+	ScrollOffset   int      `json:"scroll_offset"`
+	Unfolded       []string `json:"unfolded,omitempty"`
+	DeprecatedOnly bool     `json:"deprecated_only,omitempty"`
+	Raw            bool     `json:"raw,omitempty"`
+}
+
+// sessionViews name the views in session files
+var sessionViews = map[viewMode]string{
+	viewEndpoints:  "endpoints",
+	viewComponents: "components",
+	viewWebhooks:   "webhooks",
+	viewInfo:       "info",
+}
+
+// specHash keys the session of a spec by its content, so a spec read from
+// stdin or moved keeps it, and an edited one starts afresh
+func specHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// sessionPath returns where the session of a spec is kept, following
+// XDG_STATE_HOME when set
+func sessionPath(hash string) (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "oq", "sessions", hash+".json"), nil
+}
+
+// sessionName names an item of the lists in a session
+func sessionName(kind, name string) string {
+	return kind + " " + name
+}
+
+// currentSession captures the state of the viewer
+func (m *Model) currentSession() session {
+	s := session{
+		View:           sessionViews[m.mode],
+		Cursor:         m.cursor,
+		ScrollOffset:   m.scrollOffset,
+		DeprecatedOnly: m.unfiltered != nil,
+		Raw:            m.showRaw,
+	}
+
+	// Items hidden by the deprecated filter keep their fold state in the full lists
+	lists := []itemLists{{m.endpoints, m.components, m.webhooks}}
+	if m.unfiltered != nil {
+		lists = append(lists, *m.unfiltered)
+	}
+	for _, l := range lists {
+		for _, ep := range l.endpoints {
+			if !ep.folded {
+				s.Unfolded = append(s.Unfolded, sessionName(ep.method, ep.path))
+			}
+		}
+		for _, comp := range l.components {
+			if !comp.folded {
+				s.Unfolded = append(s.Unfolded, sessionName(comp.compType, comp.name))
+			}
+		}
+		for _, hook := range l.webhooks {
+			if !hook.folded {
+				s.Unfolded = append(s.Unfolded, sessionName("webhook "+hook.method, hook.name))
+			}
+		}
+	}
+	slices.Sort(s.Unfolded)
+	s.Unfolded = slices.Compact(s.Unfolded)
+	return s
+}
+
+// applySession restores a saved state of the viewer, skipping what doesn't fit
+// the lists anymore
+func (m *Model) applySession(s session) {
+	for i, ep := range m.endpoints {
+		m.endpoints[i].folded = !slices.Contains(s.Unfolded, sessionName(ep.method, ep.path))
+	}
+	for i, comp := range m.components {
+		m.components[i].folded = !slices.Contains(s.Unfolded, sessionName(comp.compType, comp.name))
+	}
+	for i, hook := range m.webhooks {
+		m.webhooks[i].folded = !slices.Contains(s.Unfolded, sessionName("webhook "+hook.method, hook.name))
+	}
+	if s.DeprecatedOnly && m.unfiltered == nil {
+		m.toggleDeprecatedOnly()
+	}
+	m.showRaw = s.Raw
+
+	loc := location{mode: m.mode, cursor: s.Cursor, scrollOffset: s.ScrollOffset}
+	for mode, name := range sessionViews {
+		if name == s.View && (mode != viewWebhooks || m.hasWebhooks()) {
+			loc.mode = mode
+		}
+	}
+	m.restoreLocation(loc)
+}
+
+// restoreSession restores the session saved for the spec, if any. A session
+// that can't be read is ignored, as it is only a convenience.
+func (m *Model) restoreSession() {
+	if m.specHash == "" {
+		return
+	}
+	path, err := sessionPath(m.specHash)
+	if err != nil {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var s session
+	if json.Unmarshal(data, &s) == nil {
+		m.applySession(s)
+	}
+}
+
+// saveSession saves the state of the viewer for the next time the spec is opened
+func (m *Model) saveSession() error {
+	if m.specHash == "" {
+		return nil
+	}
+	path, err := sessionPath(m.specHash)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m.currentSession(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
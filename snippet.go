@@ -0,0 +1,820 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"go.yaml.in/yaml/v4"
+)
+
+// defaultServerURL is used when the document has no servers or only relative ones
+const defaultServerURL = "http://localhost"
+
+// maxSampleDepth limits how deep sample bodies are generated from nested schemas
+const maxSampleDepth = 8
+
+// sampleRequest is a ready-to-edit request for an operation, filled in from examples,
+// defaults and placeholders. It is rendered as a curl command or a code snippet.
+type sampleRequest struct {
+	method      string
+	baseURL     string // The server part of url
+	url         string
+	headers     []requestField
+	contentType string
+	body        string
+	json        *yaml.Node  // The body as a value, when it is JSON
+	bodyFile    string      // File to send as the body instead of body, for binary content
+	form        []formField // Fields of a multipart/form-data body
+}
+
+type requestField struct {
+	name  string
+	value string
+}
+
+// formField is a multipart field, holding a file name when file is set
+type formField struct {
+	name  string
+	value string
+	file  bool
+}
+
+// buildSampleRequest builds the sample request of an endpoint
+func buildSampleRequest(doc *v3.Document, ep endpoint) sampleRequest {
+	req := sampleRequest{method: ep.method}
+
+	path := ep.path
+	var query []string
+	var cookies []string
+
+	for _, param := range ep.op.Parameters {
+		if param == nil {
+			continue
+		}
+
+		value, ok := parameterValue(param)
+		required := param.Required != nil && *param.Required
+		if !ok && !required && param.In != "path" {
+			continue
+		}
+
+		switch param.In {
+		case "path":
+			if ok {
+				value = url.PathEscape(value)
+			}
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", value)
+		case "query":
+			if ok {
+				value = url.QueryEscape(value)
+			}
+			query = append(query, url.QueryEscape(param.Name)+"="+value)
+		case "header":
+			req.headers = append(req.headers, requestField{param.Name, value})
+		case "cookie":
+			cookies = append(cookies, param.Name+"="+value)
+		}
+	}
+
+	for _, cred := range securityCredentials(doc, ep.security) {
+		switch cred.in {
+		case "query":
+			query = append(query, url.QueryEscape(cred.name)+"="+cred.value)
+		case "cookie":
+			cookies = append(cookies, cred.name+"="+cred.value)
+		default:
+			req.headers = append(req.headers, requestField{cred.name, cred.value})
+		}
+	}
+
+	if len(cookies) > 0 {
+		req.headers = append(req.headers, requestField{"Cookie", strings.Join(cookies, "; ")})
+	}
+
+	req.baseURL = serverURL(doc, ep.op)
+	req.url = req.baseURL + path
+	if len(query) > 0 {
+		req.url += "?" + strings.Join(query, "&")
+	}
+
+	if accept := acceptedMediaType(ep.op); accept != "" {
+		req.headers = append(req.headers, requestField{"Accept", accept})
+	}
+
+	if ep.op.RequestBody != nil {
+		req.setBody(ep.op.RequestBody.Content)
+	}
+
+	return req
+}
+
+// withEnvironment points a request at the base URL of an environment and fills
+// in its credentials. Its headers replace headers of the same name.
+func (req sampleRequest) withEnvironment(env environment) sampleRequest {
+	if env.BaseURL != "" {
+		req.url = strings.TrimSuffix(env.BaseURL, "/") + strings.TrimPrefix(req.url, req.baseURL)
+		req.baseURL = strings.TrimSuffix(env.BaseURL, "/")
+	}
+
+	overrides := make(map[string]string)
+	if env.Token != "" {
+		overrides["Authorization"] = "Bearer " + env.Token
+	}
+	for name, value := range env.Headers {
+		overrides[name] = value
+	}
+
+	var names []string
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	headers := slices.Clone(req.headers)
+	for _, name := range names {
+		i := slices.IndexFunc(headers, func(h requestField) bool { return strings.EqualFold(h.name, name) })
+		if i >= 0 {
+			headers[i].value = overrides[name]
+		} else {
+			headers = append(headers, requestField{name, overrides[name]})
+		}
+	}
+	req.headers = headers
+
+	return req
+}
+
+// setBody fills in the body from the preferred media type of the request content
+func (req *sampleRequest) setBody(content *orderedmap.Map[string, *v3.MediaType]) {
+	mediaType := preferredMediaType(content)
+	if mediaType == "" {
+		return
+	}
+	mt, _ := content.Get(mediaType)
+	if mt == nil {
+		return
+	}
+
+	req.contentType = mediaType
+
+	_, node := firstExample(mt.Example, mt.Examples)
+	if node == nil {
+		if isBinarySchema(mt.Schema) {
+			req.bodyFile = "file"
+			return
+		}
+		node = sampleNode(mt.Schema, 0, nil)
+	}
+
+	switch {
+	case mediaType == "multipart/form-data":
+		req.contentType = ""
+		req.form = formFields(node, mt.Schema)
+	case mediaType == "application/x-www-form-urlencoded":
+		var pairs []string
+		for _, field := range formFields(node, nil) {
+			pairs = append(pairs, url.QueryEscape(field.name)+"="+url.QueryEscape(field.value))
+		}
+		req.body = strings.Join(pairs, "&")
+	case isJSONMediaType(mediaType):
+		req.body = nodeToJSON(node, "")
+		req.json = node
+	case node != nil && node.Kind == yaml.ScalarNode:
+		req.body = node.Value
+	default:
+		req.body = nodeToJSON(node, "")
+	}
+}
+
+// serverURL returns the base URL of the first server of the operation or document,
+// with server variables replaced by their defaults
+func serverURL(doc *v3.Document, op *v3.Operation) string {
+	servers := op.Servers
+	if len(servers) == 0 {
+		servers = doc.Servers
+	}
+	if len(servers) == 0 || servers[0] == nil {
+		return defaultServerURL
+	}
+
+	server := servers[0]
+	u := server.URL
+	if server.Variables != nil {
+		for pair := server.Variables.First(); pair != nil; pair = pair.Next() {
+			if v := pair.Value(); v != nil {
+				u = strings.ReplaceAll(u, "{"+pair.Key()+"}", v.Default)
+			}
+		}
+	}
+
+	u = strings.TrimSuffix(u, "/")
+	if !strings.Contains(u, "://") {
+		u = defaultServerURL + u
+	}
+	return u
+}
+
+// parameterValue returns the example or default value of a parameter, or a
+// placeholder like "<petId>" and false when it has none
+func parameterValue(param *v3.Parameter) (string, bool) {
+	_, node := firstExample(param.Example, param.Examples)
+	if node == nil && param.Schema != nil {
+		node = schemaExample(param.Schema.Schema())
+	}
+	if node == nil {
+		return "<" + param.Name + ">", false
+	}
+	return scalarText(node), true
+}
+
+// scalarText renders a value for use in a URL or header, joining lists with commas
+func scalarText(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value
+	case yaml.SequenceNode:
+		var values []string
+		for _, item := range node.Content {
+			values = append(values, scalarText(item))
+		}
+		return strings.Join(values, ",")
+	}
+	return strings.Join(strings.Fields(nodeToJSON(node, "")), " ")
+}
+
+type credential struct {
+	in    string
+	name  string
+	value string
+}
+
+// securityCredentials returns placeholders for the credentials of the first
+// security requirement that applies to an operation
+func securityCredentials(doc *v3.Document, sec security) []credential {
+	if len(sec.requirements) == 0 || sec.requirements[0] == nil || sec.requirements[0].Requirements == nil {
+		return nil
+	}
+	if doc.Components == nil || doc.Components.SecuritySchemes == nil {
+		return nil
+	}
+
+	var creds []credential
+	for pair := sec.requirements[0].Requirements.First(); pair != nil; pair = pair.Next() {
+		scheme, ok := doc.Components.SecuritySchemes.Get(pair.Key())
+		if !ok || scheme == nil {
+			continue
+		}
+
+		switch strings.ToLower(scheme.Type) {
+		case "apikey":
+			creds = append(creds, credential{scheme.In, scheme.Name, "<" + pair.Key() + ">"})
+		case "http":
+			switch strings.ToLower(scheme.Scheme) {
+			case "basic":
+				creds = append(creds, credential{"header", "Authorization", "Basic <credentials>"})
+			default:
+				creds = append(creds, credential{"header", "Authorization", "Bearer <token>"})
+			}
+		case "oauth2", "openidconnect":
+			creds = append(creds, credential{"header", "Authorization", "Bearer <token>"})
+		}
+	}
+	return creds
+}
+
+// acceptedMediaType returns the media type of the first successful response
+func acceptedMediaType(op *v3.Operation) string {
+	if op.Responses == nil || op.Responses.Codes == nil {
+		return ""
+	}
+
+	var codes []string
+	for pair := op.Responses.Codes.First(); pair != nil; pair = pair.Next() {
+		if strings.HasPrefix(pair.Key(), "2") {
+			codes = append(codes, pair.Key())
+		}
+	}
+	sortResponseCodes(codes)
+
+	for _, code := range codes {
+		if resp, ok := op.Responses.Codes.Get(code); ok && resp != nil {
+			if mediaType := preferredMediaType(resp.Content); mediaType != "" {
+				return mediaType
+			}
+		}
+	}
+	return ""
+}
+
+// preferredMediaType picks JSON when available, otherwise the first media type in sorted order
+func preferredMediaType(content *orderedmap.Map[string, *v3.MediaType]) string {
+	if content == nil || content.Len() == 0 {
+		return ""
+	}
+
+	var names []string
+	for pair := content.First(); pair != nil; pair = pair.Next() {
+		names = append(names, pair.Key())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if isJSONMediaType(name) {
+			return name
+		}
+	}
+	return names[0]
+}
+
+func isJSONMediaType(mediaType string) bool {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// formFields flattens an object sample into form fields. Binary properties of the
+// schema are sent as files named after the property.
+func formFields(node *yaml.Node, schema *base.SchemaProxy) []formField {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var props *base.Schema
+	if schema != nil {
+		props = schema.Schema()
+	}
+
+	var fields []formField
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		field := formField{name: node.Content[i].Value, value: scalarText(node.Content[i+1])}
+		if props != nil && props.Properties != nil {
+			if prop, ok := props.Properties.Get(field.name); ok && isBinarySchema(prop) {
+				field.value = field.name
+				field.file = true
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// isBinarySchema reports whether a schema describes file contents
+func isBinarySchema(proxy *base.SchemaProxy) bool {
+	if proxy == nil || proxy.Schema() == nil {
+		return false
+	}
+	s := proxy.Schema()
+	return s.Format == "binary" || s.Format == "base64"
+}
+
+// schemaExample returns the value a schema documents for itself: its example,
+// default, const or first enum value
+func schemaExample(s *base.Schema) *yaml.Node {
+	if s == nil {
+		return nil
+	}
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case len(s.Examples) > 0:
+		return s.Examples[0]
+	case s.Default != nil:
+		return s.Default
+	case s.Const != nil:
+		return s.Const
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	}
+	return nil
+}
+
+// sampleNode builds a sample value for a schema, using the examples it documents
+// and falling back to a typical value of its type. Circular references are left empty.
+func sampleNode(proxy *base.SchemaProxy, depth int, ancestors []string) *yaml.Node {
+	if proxy == nil || proxy.Schema() == nil || depth > maxSampleDepth {
+		return nullNode()
+	}
+
+	if ref := proxy.GetReference(); ref != "" {
+		if slices.Contains(ancestors, ref) {
+			return nullNode()
+		}
+		ancestors = append(slices.Clone(ancestors), ref)
+	}
+
+	s := proxy.Schema()
+	if example := schemaExample(s); example != nil {
+		return example
+	}
+
+	if len(s.AllOf) > 0 {
+		merged := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, sub := range s.AllOf {
+			if node := sampleNode(sub, depth+1, ancestors); node.Kind == yaml.MappingNode {
+				merged.Content = append(merged.Content, node.Content...)
+			}
+		}
+		return merged
+	}
+	if len(s.OneOf) > 0 {
+		return sampleNode(s.OneOf[0], depth+1, ancestors)
+	}
+	if len(s.AnyOf) > 0 {
+		return sampleNode(s.AnyOf[0], depth+1, ancestors)
+	}
+
+	schemaType := ""
+	for _, t := range s.Type {
+		if t != "null" {
+			schemaType = t
+			break
+		}
+	}
+	if schemaType == "" && s.Properties != nil {
+		schemaType = "object"
+	}
+
+	switch schemaType {
+	case "object":
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if s.Properties != nil {
+			for pair := s.Properties.First(); pair != nil; pair = pair.Next() {
+				if prop := pair.Value(); prop != nil && prop.Schema() != nil && prop.Schema().ReadOnly != nil && *prop.Schema().ReadOnly {
+					continue
+				}
+				node.Content = append(node.Content,
+					&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: pair.Key()},
+					sampleNode(pair.Value(), depth+1, ancestors))
+			}
+		}
+		return node
+	case "array":
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if s.Items != nil && s.Items.IsA() && s.Items.A != nil {
+			node.Content = append(node.Content, sampleNode(s.Items.A, depth+1, ancestors))
+		}
+		return node
+	case "integer":
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: "0"}
+	case "number":
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: "0"}
+	case "boolean":
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "false"}
+	case "string":
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: sampleString(s.Format)}
+	}
+	return nullNode()
+}
+
+// sampleString returns a plausible value for a string format
+func sampleString(format string) string {
+	switch format {
+	case "date":
+		return "2024-01-01"
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "time":
+		return "00:00:00"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	case "hostname":
+		return "example.com"
+	case "ipv4":
+		return "192.0.2.1"
+	case "ipv6":
+		return "2001:db8::1"
+	}
+	return "string"
+}
+
+func nullNode() *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+}
+
+// curlCommand renders a sample request as a multi-line curl command
+func curlCommand(req sampleRequest) string {
+	first := shellQuote(req.url)
+	if req.method != "GET" {
+		first = "-X " + req.method + " " + first
+	}
+	args := []string{first}
+
+	for _, h := range req.headers {
+		args = append(args, "-H "+shellQuote(h.name+": "+h.value))
+	}
+	if req.contentType != "" {
+		args = append(args, "-H "+shellQuote("Content-Type: "+req.contentType))
+	}
+	if req.body != "" {
+		args = append(args, "-d "+shellQuote(req.body))
+	}
+	if req.bodyFile != "" {
+		args = append(args, "--data-binary "+shellQuote("@"+req.bodyFile))
+	}
+	for _, f := range req.form {
+		if f.file {
+			args = append(args, "-F "+shellQuote(f.name+"=@"+f.value))
+		} else {
+			args = append(args, "-F "+shellQuote(f.name+"="+f.value))
+		}
+	}
+
+	return "curl " + strings.Join(args, " \\\n  ")
+}
+
+// shellQuote quotes a string for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// snippetLanguage renders sample requests for one HTTP client
+type snippetLanguage struct {
+	name   string
+	render func(sampleRequest) string
+}
+
+// snippetLanguages are offered in this order when copying a snippet
+var snippetLanguages = []snippetLanguage{
+	{"curl", curlCommand},
+	{"HTTPie", httpieCommand},
+	{"JavaScript (fetch)", fetchSnippet},
+	{"Python (requests)", pythonSnippet},
+	{"Go (net/http)", goSnippet},
+}
+
+func findSnippetLanguage(name string) (snippetLanguage, bool) {
+	for _, lang := range snippetLanguages {
+		if lang.name == name {
+			return lang, true
+		}
+	}
+	return snippetLanguage{}, false
+}
+
+// httpieCommand renders a sample request as an HTTPie command
+func httpieCommand(req sampleRequest) string {
+	args := []string{req.method + " " + shellQuote(req.url)}
+	if len(req.form) > 0 {
+		args[0] = "--multipart " + args[0]
+	}
+
+	for _, h := range req.headers {
+		args = append(args, shellQuote(h.name+":"+h.value))
+	}
+	if req.contentType != "" {
+		args = append(args, shellQuote("Content-Type:"+req.contentType))
+	}
+	for _, f := range req.form {
+		if f.file {
+			args = append(args, shellQuote(f.name+"@"+f.value))
+		} else {
+			args = append(args, shellQuote(f.name+"="+f.value))
+		}
+	}
+	if req.body != "" {
+		args = append(args, "--raw "+shellQuote(req.body))
+	}
+
+	cmd := "http " + strings.Join(args, " \\\n  ")
+	if req.bodyFile != "" {
+		cmd += " \\\n  < " + req.bodyFile
+	}
+	return cmd
+}
+
+// fetchSnippet renders a sample request as a JavaScript fetch call
+func fetchSnippet(req sampleRequest) string {
+	var b strings.Builder
+
+	if len(req.form) > 0 {
+		b.WriteString("const form = new FormData();\n")
+		for _, f := range req.form {
+			if f.file {
+				fmt.Fprintf(&b, "form.append(%s, file); // a File or Blob\n", jsString(f.name))
+			} else {
+				fmt.Fprintf(&b, "form.append(%s, %s);\n", jsString(f.name), jsString(f.value))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "const response = await fetch(%s, {\n", jsString(req.url))
+	fmt.Fprintf(&b, "  method: %s,\n", jsString(req.method))
+
+	headers := req.headers
+	if req.contentType != "" {
+		headers = append(slices.Clone(headers), requestField{"Content-Type", req.contentType})
+	}
+	if len(headers) > 0 {
+		b.WriteString("  headers: {\n")
+		for _, h := range headers {
+			fmt.Fprintf(&b, "    %s: %s,\n", jsString(h.name), jsString(h.value))
+		}
+		b.WriteString("  },\n")
+	}
+
+	switch {
+	case req.json != nil:
+		fmt.Fprintf(&b, "  body: JSON.stringify(%s),\n", nodeToJSON(req.json, "  "))
+	case req.body != "":
+		fmt.Fprintf(&b, "  body: %s,\n", jsString(req.body))
+	case req.bodyFile != "":
+		b.WriteString("  body: file, // a File or Blob\n")
+	case len(req.form) > 0:
+		b.WriteString("  body: form,\n")
+	}
+
+	b.WriteString("});\n")
+	b.WriteString("console.log(response.status, await response.text());")
+	return b.String()
+}
+
+// pythonSnippet renders a sample request as a call to the requests library
+func pythonSnippet(req sampleRequest) string {
+	var b strings.Builder
+
+	b.WriteString("import requests\n\n")
+	fmt.Fprintf(&b, "response = requests.request(\n    %s,\n    %s,\n", jsString(req.method), jsString(req.url))
+
+	headers := req.headers
+	// requests sets the content type of json and multipart bodies itself
+	if req.contentType != "" && (req.json == nil || req.contentType != "application/json") {
+		headers = append(slices.Clone(headers), requestField{"Content-Type", req.contentType})
+	}
+	if len(headers) > 0 {
+		b.WriteString("    headers={\n")
+		for _, h := range headers {
+			fmt.Fprintf(&b, "        %s: %s,\n", jsString(h.name), jsString(h.value))
+		}
+		b.WriteString("    },\n")
+	}
+
+	switch {
+	case req.json != nil:
+		fmt.Fprintf(&b, "    json=%s,\n", pythonLiteral(req.json, "    "))
+	case req.body != "":
+		fmt.Fprintf(&b, "    data=%s,\n", jsString(req.body))
+	case req.bodyFile != "":
+		fmt.Fprintf(&b, "    data=open(%s, \"rb\"),\n", jsString(req.bodyFile))
+	case len(req.form) > 0:
+		var data, files []string
+		for _, f := range req.form {
+			if f.file {
+				files = append(files, fmt.Sprintf("        %s: open(%s, \"rb\"),\n", jsString(f.name), jsString(f.value)))
+			} else {
+				data = append(data, fmt.Sprintf("        %s: %s,\n", jsString(f.name), jsString(f.value)))
+			}
+		}
+		if len(data) > 0 {
+			b.WriteString("    data={\n" + strings.Join(data, "") + "    },\n")
+		}
+		if len(files) > 0 {
+			b.WriteString("    files={\n" + strings.Join(files, "") + "    },\n")
+		}
+	}
+
+	b.WriteString(")\n")
+	b.WriteString("print(response.status_code, response.text)")
+	return b.String()
+}
+
+// goSnippet renders a sample request as a Go program using net/http, with the
+// imports it needs
+func goSnippet(req sampleRequest) string {
+	imports := []string{"fmt", "io", "log", "net/http"}
+	var b strings.Builder
+
+	body := "nil"
+	switch {
+	case req.body != "":
+		body = "body"
+		imports = append(imports, "strings")
+		fmt.Fprintf(&b, "\tbody := strings.NewReader(%s)\n", goString(req.body))
+	case req.bodyFile != "":
+		body = "body"
+		imports = append(imports, "os")
+		fmt.Fprintf(&b, "\tbody, err := os.Open(%s)\n\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n\tdefer body.Close()\n", strconv.Quote(req.bodyFile))
+	case len(req.form) > 0:
+		body = "&body"
+		imports = append(imports, "bytes", "mime/multipart")
+		b.WriteString("\tvar body bytes.Buffer\n\tform := multipart.NewWriter(&body)\n")
+		for _, f := range req.form {
+			if f.file {
+				imports = append(imports, "os")
+				fmt.Fprintf(&b, "\t{\n\t\tdata, err := os.ReadFile(%s)\n\t\tif err != nil {\n\t\t\tlog.Fatal(err)\n\t\t}\n", strconv.Quote(f.value))
+				fmt.Fprintf(&b, "\t\tpart, _ := form.CreateFormFile(%s, %s)\n\t\tpart.Write(data)\n\t}\n", strconv.Quote(f.name), strconv.Quote(f.value))
+			} else {
+				fmt.Fprintf(&b, "\tform.WriteField(%s, %s)\n", strconv.Quote(f.name), strconv.Quote(f.value))
+			}
+		}
+		b.WriteString("\tform.Close()\n")
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "\treq, err := http.NewRequest(%s, %s, %s)\n", strconv.Quote(req.method), strconv.Quote(req.url), body)
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+	for _, h := range req.headers {
+		fmt.Fprintf(&b, "\treq.Header.Set(%s, %s)\n", strconv.Quote(h.name), strconv.Quote(h.value))
+	}
+	if req.contentType != "" {
+		fmt.Fprintf(&b, "\treq.Header.Set(\"Content-Type\", %s)\n", strconv.Quote(req.contentType))
+	}
+	if len(req.form) > 0 {
+		b.WriteString("\treq.Header.Set(\"Content-Type\", form.FormDataContentType())\n")
+	}
+
+	b.WriteString("\n\tresp, err := http.DefaultClient.Do(req)\n")
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+	b.WriteString("\tdefer resp.Body.Close()\n\n")
+	b.WriteString("\tdata, err := io.ReadAll(resp.Body)\n")
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+	b.WriteString("\tfmt.Println(resp.StatusCode, string(data))\n")
+
+	slices.Sort(imports)
+	var program strings.Builder
+	program.WriteString("package main\n\nimport (\n")
+	for _, path := range slices.Compact(imports) {
+		fmt.Fprintf(&program, "\t%s\n", strconv.Quote(path))
+	}
+	program.WriteString(")\n\nfunc main() {\n")
+	program.WriteString(b.String())
+	program.WriteString("}")
+	return program.String()
+}
+
+// jsString quotes a string for JavaScript and Python, which both accept JSON strings
+func jsString(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// goString quotes a string for Go, preferring a raw string literal for multi-line values
+func goString(s string) string {
+	if strings.Contains(s, "\n") && !strings.Contains(s, "`") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// pythonLiteral renders a value as a Python literal, indented like nodeToJSON
+func pythonLiteral(node *yaml.Node, indent string) string {
+	const step = "    "
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) > 0 {
+			return pythonLiteral(node.Content[0], indent)
+		}
+	case yaml.AliasNode:
+		return pythonLiteral(node.Alias, indent)
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			return "{}"
+		}
+		var b strings.Builder
+		b.WriteString("{\n")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			fmt.Fprintf(&b, "%s%s: %s,\n", indent+step, jsString(node.Content[i].Value), pythonLiteral(node.Content[i+1], indent+step))
+		}
+		b.WriteString(indent + "}")
+		return b.String()
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			return "[]"
+		}
+		var b strings.Builder
+		b.WriteString("[\n")
+		for _, item := range node.Content {
+			fmt.Fprintf(&b, "%s%s,\n", indent+step, pythonLiteral(item, indent+step))
+		}
+		b.WriteString(indent + "]")
+		return b.String()
+	}
+
+	switch value := nodeToJSON(node, ""); value {
+	case "null":
+		return "None"
+	case "true":
+		return "True"
+	case "false":
+		return "False"
+	default:
+		return value
+	}
+}
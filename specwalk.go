@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// Keywords holding a single subschema, and those holding a list or a map of them
+var (
+	subschemaFields     = []string{"items", "additionalProperties", "not", "if", "then", "else", "contains", "propertyNames", "unevaluatedItems", "unevaluatedProperties"}
+	subschemaListFields = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+	subschemaMapFields  = []string{"properties", "patternProperties", "$defs", "dependentSchemas"}
+)
+
+// schemaWalker finds the schemas in the YAML tree of a spec
+type schemaWalker struct {
+	fn      func(schema *yaml.Node, pointer string)
+	visited map[*yaml.Node]bool // Nodes shared through YAML aliases are visited once
+}
+
+// walkSchemas calls fn with every schema of the spec and its JSON pointer,
+// subschemas included. fn may rewrite the schema; the walk goes on with the
+// subschemas it ends up with.
+func walkSchemas(doc *yaml.Node, fn func(schema *yaml.Node, pointer string)) {
+	w := &schemaWalker{fn: fn, visited: map[*yaml.Node]bool{}}
+	w.walk(doc, "#", 0)
+}
+
+// walk looks for schemas in the parts of the spec that aren't schemas themselves
+func (w *schemaWalker) walk(node *yaml.Node, pointer string, depth int) {
+	node = unalias(node)
+	if node == nil || depth > maxSpecValidationDepth || w.visited[node] {
+		return
+	}
+	w.visited[node] = true
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		eachField(node, func(key, value *yaml.Node) {
+			p := pointerTo(pointer, key.Value)
+			switch {
+			case strings.HasPrefix(key.Value, "x-"), key.Value == "example", key.Value == "examples":
+			case key.Value == "schema":
+				w.schema(value, p, 0)
+			case key.Value == "schemas" && pointer == "#/components":
+				eachField(value, func(name, schema *yaml.Node) {
+					w.schema(schema, pointerTo(p, name.Value), 0)
+				})
+			default:
+				w.walk(value, p, depth+1)
+			}
+		})
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			w.walk(item, fmt.Sprintf("%s/%d", pointer, i), depth+1)
+		}
+	}
+}
+
+func (w *schemaWalker) schema(node *yaml.Node, pointer string, depth int) {
+	node = unalias(node)
+	if node == nil || node.Kind != yaml.MappingNode || depth > maxSpecValidationDepth || w.visited[node] {
+		return
+	}
+	w.visited[node] = true
+	w.fn(node, pointer)
+
+	for _, name := range subschemaFields {
+		if _, sub := field(node, name); sub != nil {
+			w.schema(sub, pointerTo(pointer, name), depth+1)
+		}
+	}
+	for _, name := range subschemaListFields {
+		if _, list := field(node, name); list != nil && list.Kind == yaml.SequenceNode {
+			for i, sub := range list.Content {
+				w.schema(sub, fmt.Sprintf("%s/%d", pointerTo(pointer, name), i), depth+1)
+			}
+		}
+	}
+	for _, name := range subschemaMapFields {
+		_, props := field(node, name)
+		eachField(props, func(key, sub *yaml.Node) {
+			w.schema(sub, pointerTo(pointerTo(pointer, name), key.Value), depth+1)
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// untaggedLabel counts the operations without tags in the statistics
+const untaggedLabel = "(untagged)"
+
+// specStats are the figures printed by "oq stats"
+type specStats struct {
+	Title             string       `json:"title"`
+	Version           string       `json:"version"`
+	Endpoints         int          `json:"endpoints"`
+	Webhooks          int          `json:"webhooks"`
+	Deprecated        int          `json:"deprecated"`
+	AverageParameters float64      `json:"averageParameters"`
+	Methods           []statsCount `json:"methods"`
+	Tags              []statsCount `json:"tags"`
+	Components        []statsCount `json:"components"`
+	Responses         []coverage   `json:"responses"`     // Operations documenting each class of status codes
+	Documentation     []coverage   `json:"documentation"` // Items with a summary or description
+}
+
+type statsCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// coverage is how many of the items have something, e.g. a description
+type coverage struct {
+	Name    string  `json:"name"`
+	Covered int     `json:"covered"`
+	Total   int     `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+func newCoverage(name string, covered, total int) coverage {
+	c := coverage{Name: name, Covered: covered, Total: total}
+	if total > 0 {
+		c.Percent = math.Round(float64(covered)*1000/float64(total)) / 10
+	}
+	return c
+}
+
+// countsByName sorts counts by count, then by name
+func countsByName(counts map[string]int) []statsCount {
+	out := []statsCount{}
+	for name, count := range counts {
+		out = append(out, statsCount{name, count})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// collectStats counts the operations of the spec by method and tag, the
+// components by type, and how well they are documented
+func collectStats(doc *v3.Document, endpoints []endpoint, webhooks []webhook, components []component) specStats {
+	stats := specStats{Endpoints: len(endpoints), Webhooks: len(webhooks), Methods: []statsCount{}}
+	if doc.Info != nil {
+		stats.Title = doc.Info.Title
+		stats.Version = doc.Info.Version
+	}
+
+	ops := make([]*v3.Operation, 0, len(endpoints)+len(webhooks))
+	methods := map[string]int{}
+	tags := map[string]int{}
+	for _, ep := range endpoints {
+		ops = append(ops, ep.op)
+		methods[ep.method]++
+		if ep.deprecation.deprecated {
+			stats.Deprecated++
+		}
+		if len(ep.op.Tags) == 0 {
+			tags[untaggedLabel]++
+		}
+		for _, tag := range ep.op.Tags {
+			tags[tag]++
+		}
+	}
+	for _, hook := range webhooks {
+		ops = append(ops, hook.op)
+	}
+
+	// Methods are listed in the usual order rather than by count
+	for _, method := range httpMethods {
+		if n := methods[strings.ToUpper(method)]; n > 0 {
+			stats.Methods = append(stats.Methods, statsCount{strings.ToUpper(method), n})
+		}
+	}
+	stats.Tags = countsByName(tags)
+
+	componentTypes := map[string]int{}
+	for _, comp := range components {
+		componentTypes[comp.compType]++
+	}
+	stats.Components = countsByName(componentTypes)
+
+	var params, paramsDescribed, withSummary, withDescription, withID int
+	responseClasses := []string{"2XX", "3XX", "4XX", "5XX", "default"}
+	documented := map[string]int{}
+	for _, op := range ops {
+		for _, param := range op.Parameters {
+			if param == nil {
+				continue
+			}
+			params++
+			if strings.TrimSpace(param.Description) != "" {
+				paramsDescribed++
+			}
+		}
+		if strings.TrimSpace(op.Summary) != "" {
+			withSummary++
+		}
+		if strings.TrimSpace(op.Description) != "" {
+			withDescription++
+		}
+		if op.OperationId != "" {
+			withID++
+		}
+
+		classes := map[string]bool{}
+		if op.Responses != nil {
+			if op.Responses.Codes != nil {
+				for pair := op.Responses.Codes.First(); pair != nil; pair = pair.Next() {
+					classes[strings.ToUpper(pair.Key()[:1])+"XX"] = true
+				}
+			}
+			if op.Responses.Default != nil {
+				classes["default"] = true
+			}
+		}
+		for class := range classes {
+			documented[class]++
+		}
+	}
+	if len(ops) > 0 {
+		stats.AverageParameters = math.Round(float64(params)*10/float64(len(ops))) / 10
+	}
+
+	for _, class := range responseClasses {
+		stats.Responses = append(stats.Responses, newCoverage(strings.Replace(class, "XX", "xx", 1), documented[class], len(ops)))
+	}
+
+	var schemas, schemasDescribed, properties, propertiesDescribed int
+	for _, comp := range components {
+		proxy, ok := comp.source.(*base.SchemaProxy)
+		if !ok || proxy.Schema() == nil {
+			continue
+		}
+		schemas++
+		s := proxy.Schema()
+		if strings.TrimSpace(s.Description) != "" {
+			schemasDescribed++
+		}
+		if s.Properties == nil {
+			continue
+		}
+		for pair := s.Properties.First(); pair != nil; pair = pair.Next() {
+			properties++
+			if prop := pair.Value(); prop != nil && prop.Schema() != nil && strings.TrimSpace(prop.Schema().Description) != "" {
+				propertiesDescribed++
+			}
+		}
+	}
+
+	stats.Documentation = []coverage{
+		newCoverage("Operations with a summary", withSummary, len(ops)),
+		newCoverage("Operations with a description", withDescription, len(ops)),
+		newCoverage("Operations with an operationId", withID, len(ops)),
+		newCoverage("Parameters with a description", paramsDescribed, params),
+		newCoverage("Schemas with a description", schemasDescribed, schemas),
+		newCoverage("Schema properties with a description", propertiesDescribed, properties),
+	}
+
+	return stats
+}
+
+func writeStatsText(w io.Writer, stats specStats) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "%s v%s\n\n", stats.Title, stats.Version)
+	fmt.Fprintf(tw, "Endpoints:\t%d\n", stats.Endpoints)
+	fmt.Fprintf(tw, "Deprecated:\t%d\n", stats.Deprecated)
+	fmt.Fprintf(tw, "Webhooks:\t%d\n", stats.Webhooks)
+	fmt.Fprintf(tw, "Parameters per operation:\t%.1f\n", stats.AverageParameters)
+
+	for _, section := range []struct {
+		title  string
+		counts []statsCount
+	}{
+		{"By method", stats.Methods},
+		{"By tag", stats.Tags},
+		{"Components", stats.Components},
+	} {
+		if len(section.counts) == 0 {
+			continue
+		}
+		fmt.Fprintf(tw, "\n%s:\n", section.title)
+		for _, c := range section.counts {
+			fmt.Fprintf(tw, "  %s\t%d\n", c.Name, c.Count)
+		}
+	}
+
+	for _, section := range []struct {
+		title     string
+		coverages []coverage
+	}{
+		{"Operations documenting responses", stats.Responses},
+		{"Documentation", stats.Documentation},
+	} {
+		fmt.Fprintf(tw, "\n%s:\n", section.title)
+		for _, c := range section.coverages {
+			fmt.Fprintf(tw, "  %s\t%d/%d\t%5.1f%%\n", c.Name, c.Covered, c.Total, c.Percent)
+		}
+	}
+
+	return tw.Flush()
+}
+
+// runStats implements "oq stats"
+func runStats(fs *flag.FlagSet, args []string) error {
+	output := fs.String("output", defaultOutput, "output format: text or json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	path, err := specArg(fs)
+	if err != nil {
+		return err
+	}
+	if *output != outputText && *output != outputJSON {
+		return fmt.Errorf("unknown output format %q, use %s or %s", *output, outputText, outputJSON)
+	}
+
+	doc, err := loadSpec(path)
+	if err != nil {
+		return err
+	}
+	stats := collectStats(doc, extractEndpoints(doc), extractWebhooks(doc), extractComponents(doc))
+
+	if *output == outputJSON {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return err
+		}
+		return writeResult("", append(data, '\n'))
+	}
+	return writeStatsText(os.Stdout, stats)
+}
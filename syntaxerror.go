@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// syntaxContext is the number of lines shown before the line of a syntax error
+const syntaxContext = 2
+
+// syntaxError is a spec that isn't valid YAML or JSON, with where the parser
+// stopped and, for common mistakes, how to fix it
+type syntaxError struct {
+	content []byte
+	line    int // 1-based, 0 when the parser doesn't tell
+	column  int // 1-based, 0 when only the line is known
+	message string
+	hint    string
+}
+
+func (e *syntaxError) Error() string {
+	switch {
+	case e.line > 0 && e.column > 0:
+		return fmt.Sprintf("line %d, column %d: %s", e.line, e.column, e.message)
+	case e.line > 0:
+		return fmt.Sprintf("line %d: %s", e.line, e.message)
+	}
+	return e.message
+}
+
+// snippet shows the lines before the error and the line itself, with a caret
+// under the column when it is known
+func (e *syntaxError) snippet() string {
+	if e.line == 0 {
+		return ""
+	}
+	lines := strings.Split(string(e.content), "\n")
+	if e.line > len(lines) {
+		return ""
+	}
+
+	var b strings.Builder
+	width := len(fmt.Sprint(e.line))
+	for n := max(1, e.line-syntaxContext); n <= e.line; n++ {
+		// Tabs are shown as one space, so the caret lines up
+		line := strings.ReplaceAll(strings.TrimRight(lines[n-1], "\r"), "\t", " ")
+		fmt.Fprintf(&b, "%*d | %s\n", width, n, line)
+	}
+	if e.column > 0 {
+		prefix := lines[e.line-1][:min(e.column-1, len(lines[e.line-1]))]
+		fmt.Fprintf(&b, "%*s | %s^\n", width, "", strings.Repeat(" ", utf8.RuneCountInString(prefix)))
+	}
+	return b.String()
+}
+
+var (
+	yamlUnknownAnchor = regexp.MustCompile(`unknown anchor '([^']*)'`)
+	leadingTab        = regexp.MustCompile(`(?m)^ *\t`)
+)
+
+// diagnoseSyntax returns where and why content doesn't parse as YAML, or nil
+// when it does. Content that looks like JSON is reported with the JSON error,
+// which has a column, unlike YAML ones.
+func diagnoseSyntax(content []byte) *syntaxError {
+	var node yaml.Node
+	err := yaml.Unmarshal(content, &node)
+	if err == nil {
+		return nil
+	}
+	if trimmed := bytes.TrimSpace(content); trimmed[0] == '{' || trimmed[0] == '[' {
+		if e := diagnoseJSON(content); e != nil {
+			return e
+		}
+	}
+	e := &syntaxError{content: content, message: strings.TrimPrefix(err.Error(), "yaml: ")}
+	var parserErr *yaml.ParserError
+	if errors.As(err, &parserErr) {
+		e.line, e.message = parserErr.Line, parserErr.Message
+	} else if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+		e.line, _ = strconv.Atoi(match[1])
+	}
+
+	lines := strings.Split(string(content), "\n")
+	switch {
+	case strings.Contains(e.message, "tab character"),
+		strings.Contains(e.message, "cannot start any token") && e.line > 0 && e.line <= len(lines) && leadingTab.MatchString(lines[e.line-1]):
+		// The parser may report the line before the tab
+		if loc := leadingTab.FindIndex(content); loc != nil {
+			e.line, e.column = positionAt(content, loc[1]-1)
+		}
+		e.hint = "YAML is indented with spaces only, replace the tabs"
+	case yamlUnknownAnchor.MatchString(e.message):
+		name := yamlUnknownAnchor.FindStringSubmatch(e.message)[1]
+		if i := bytes.Index(content, []byte("*"+name)); i >= 0 {
+			e.line, e.column = positionAt(content, i)
+		}
+		e.hint = fmt.Sprintf("values starting with * are aliases in YAML, quote the value, e.g. \"*%s\"", name)
+	case strings.Contains(e.message, "mapping values are not allowed"):
+		// The colon is the one after the key's
+		if e.line > 0 && e.line <= len(lines) {
+			line := lines[e.line-1]
+			if first := strings.Index(line, ": "); first >= 0 {
+				if second := strings.Index(line[first+2:], ": "); second >= 0 {
+					e.column = first + 2 + second + 1
+				}
+			}
+		}
+		e.hint = `quote values containing ": ", e.g. summary: "Note: this is slow"`
+	case strings.Contains(e.message, "did not find expected key"):
+		e.hint = "check the indentation of this line and the one before"
+	}
+	return e
+}
+
+func diagnoseJSON(content []byte) *syntaxError {
+	var v any
+	err := json.Unmarshal(content, &v)
+	if err == nil {
+		return nil
+	}
+	e := &syntaxError{content: content, message: err.Error()}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// The offset is past the character the parser stopped at
+		e.line, e.column = positionAt(content, int(max(syntaxErr.Offset-1, 0)))
+		switch {
+		case strings.Contains(e.message, "looking for beginning of object key string"):
+			e.hint = "a comma may be doubled or trailing, or a key unquoted"
+		case strings.Contains(e.message, "after object key:value pair"), strings.Contains(e.message, "after array element"):
+			e.hint = "a comma may be missing after the previous value"
+		case strings.Contains(e.message, "unexpected end of JSON input"):
+			e.hint = "a closing } or ] may be missing"
+		}
+	}
+	return e
+}
+
+// positionAt returns the 1-based line and column of a byte offset
+func positionAt(content []byte, offset int) (int, int) {
+	offset = min(offset, len(content))
+	line := bytes.Count(content[:offset], []byte("\n")) + 1
+	return line, offset - bytes.LastIndexByte(content[:offset], '\n')
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const defaultThemeName = "dark"
+
+// theme holds every color used by the UI. Colors set to lipgloss.NoColor{} are
+// left to the terminal, and backgrounds without a color fall back to reverse video.
+type theme struct {
+	green  lipgloss.TerminalColor
+	blue   lipgloss.TerminalColor
+	yellow lipgloss.TerminalColor
+	red    lipgloss.TerminalColor
+	purple lipgloss.TerminalColor
+	gray   lipgloss.TerminalColor
+
+	accent     lipgloss.TerminalColor // App title, active view, modal borders
+	accentText lipgloss.TerminalColor // Text on the accent color
+	selection  lipgloss.TerminalColor // Background of the selected line
+	detail     lipgloss.TerminalColor // Details of unfolded items
+	text       lipgloss.TerminalColor // Text in modals
+	footer     lipgloss.TerminalColor
+	footerText lipgloss.TerminalColor
+}
+
+var themes = map[string]theme{
+	"dark": {
+		green:      lipgloss.Color("#10B981"),
+		blue:       lipgloss.Color("#3B82F6"),
+		yellow:     lipgloss.Color("#F59E0B"),
+		red:        lipgloss.Color("#EF4444"),
+		purple:     lipgloss.Color("#8B5CF6"),
+		gray:       lipgloss.Color("#6B7280"),
+		accent:     lipgloss.Color("#7C3AED"),
+		accentText: lipgloss.Color("#FFFFFF"),
+		selection:  lipgloss.Color("#374151"),
+		detail:     lipgloss.Color("#9CA3AF"),
+		text:       lipgloss.Color("#FFFFFF"),
+		footer:     lipgloss.Color("#6B7280"),
+		footerText: lipgloss.Color("#000000"),
+	},
+	"light": {
+		green:      lipgloss.Color("#047857"),
+		blue:       lipgloss.Color("#1D4ED8"),
+		yellow:     lipgloss.Color("#B45309"),
+		red:        lipgloss.Color("#B91C1C"),
+		purple:     lipgloss.Color("#6D28D9"),
+		gray:       lipgloss.Color("#4B5563"),
+		accent:     lipgloss.Color("#6D28D9"),
+		accentText: lipgloss.Color("#FFFFFF"),
+		selection:  lipgloss.Color("#E5E7EB"),
+		detail:     lipgloss.Color("#374151"),
+		text:       lipgloss.Color("#111827"),
+		footer:     lipgloss.Color("#D1D5DB"),
+		footerText: lipgloss.Color("#000000"),
+	},
+	"high-contrast": {
+		green:      lipgloss.Color("#00FF00"),
+		blue:       lipgloss.Color("#00D7FF"),
+		yellow:     lipgloss.Color("#FFFF00"),
+		red:        lipgloss.Color("#FF5F5F"),
+		purple:     lipgloss.Color("#FF87FF"),
+		gray:       lipgloss.Color("#D0D0D0"),
+		accent:     lipgloss.Color("#FFFF00"),
+		accentText: lipgloss.Color("#000000"),
+		selection:  lipgloss.Color("#0000AF"),
+		detail:     lipgloss.Color("#FFFFFF"),
+		text:       lipgloss.Color("#FFFFFF"),
+		footer:     lipgloss.Color("#FFFFFF"),
+		footerText: lipgloss.Color("#000000"),
+	},
+	"monochrome": {
+		green:      lipgloss.NoColor{},
+		blue:       lipgloss.NoColor{},
+		yellow:     lipgloss.NoColor{},
+		red:        lipgloss.NoColor{},
+		purple:     lipgloss.NoColor{},
+		gray:       lipgloss.NoColor{},
+		accent:     lipgloss.NoColor{},
+		accentText: lipgloss.NoColor{},
+		selection:  lipgloss.NoColor{},
+		detail:     lipgloss.NoColor{},
+		text:       lipgloss.NoColor{},
+		footer:     lipgloss.NoColor{},
+		footerText: lipgloss.NoColor{},
+	},
+}
+
+// currentTheme is the theme the UI is rendered with
+var currentTheme theme
+
+func init() {
+	applyTheme(themes[defaultThemeName])
+}
+
+// setTheme switches to the theme with the given name
+func setTheme(name string) error {
+	t, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q, available themes: %s", name, strings.Join(themeNames(), ", "))
+	}
+	applyTheme(t)
+	return nil
+}
+
+func applyTheme(t theme) {
+	currentTheme = t
+	setSyntaxStyles(t)
+	setInfoStyles(t)
+}
+
+func themeNames() []string {
+	var names []string
+	for name := range themes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// withBackground sets a background color, using reverse video when the theme has no color for it
+func withBackground(s lipgloss.Style, c lipgloss.TerminalColor) lipgloss.Style {
+	if _, ok := c.(lipgloss.NoColor); ok {
+		return s.Reverse(true)
+	}
+	return s.Background(c)
+}
+
+func (t theme) methodColor(method string) lipgloss.TerminalColor {
+	switch method {
+	case "GET":
+		return t.green
+	case "POST":
+		return t.blue
+	case "PUT":
+		return t.yellow
+	case "DELETE":
+		return t.red
+	case "PATCH":
+		return t.purple
+	default:
+		return t.gray
+	}
+}
+
+func (t theme) componentColor(compType string) lipgloss.TerminalColor {
+	switch compType {
+	case "Schema":
+		return t.green
+	case "RequestBody":
+		return t.blue
+	case "Response":
+		return t.yellow
+	case "Parameter":
+		return t.purple
+	case "Header":
+		return t.red
+	default:
+		return t.gray
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"go.yaml.in/yaml/v4"
+)
+
+// maxResponseBytes caps how much of a response body is read
+const maxResponseBytes = 1 << 20
+
+// defaultTimeout limits how long Try-it requests take, unless configured otherwise
+const defaultTimeout = 30 * time.Second
+
+// httpClient sends Try-it requests. It is a variable so tests can replace it.
+var httpClient = &http.Client{Timeout: defaultTimeout}
+
+// tryResponse is the outcome of sending an endpoint's sample request
+type tryResponse struct {
+	request     string // e.g. "GET https://api.example.com/pets"
+	status      int
+	contentType string
+	body        []byte
+	duration    time.Duration
+	problems    []string // Mismatches between the response and the spec
+	err         error
+}
+
+// tryResponseMsg delivers a tryResponse once the request finished
+type tryResponseMsg struct {
+	response tryResponse
+}
+
+// sendRequest sends a sample request and validates the response against the
+// responses declared by op
+func sendRequest(req sampleRequest, op *v3.Operation) tea.Cmd {
+	return func() tea.Msg {
+		return tryResponseMsg{doRequest(req, op)}
+	}
+}
+
+// doRequest sends a sample request and waits for the response
+func doRequest(req sampleRequest, op *v3.Operation) tryResponse {
+	resp := tryResponse{request: req.method + " " + req.url}
+
+	httpReq, err := newHTTPRequest(req)
+	if err != nil {
+		resp.err = err
+		return resp
+	}
+
+	start := time.Now()
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		resp.err = err
+		return resp
+	}
+	defer httpResp.Body.Close()
+
+	resp.body, err = io.ReadAll(io.LimitReader(httpResp.Body, maxResponseBytes))
+	resp.duration = time.Since(start)
+	if err != nil {
+		resp.err = err
+		return resp
+	}
+
+	resp.status = httpResp.StatusCode
+	resp.contentType = httpResp.Header.Get("Content-Type")
+	resp.problems = validateResponse(op, resp.status, resp.contentType, resp.body)
+	return resp
+}
+
+// newHTTPRequest builds the HTTP request for a sample request. Files are sent
+// empty, as the sample only names them.
+func newHTTPRequest(req sampleRequest) (*http.Request, error) {
+	var body io.Reader
+	contentType := req.contentType
+
+	switch {
+	case req.body != "":
+		body = strings.NewReader(req.body)
+	case len(req.form) > 0:
+		var buf bytes.Buffer
+		form := multipart.NewWriter(&buf)
+		for _, f := range req.form {
+			if f.file {
+				if _, err := form.CreateFormFile(f.name, f.value); err != nil {
+					return nil, err
+				}
+			} else if err := form.WriteField(f.name, f.value); err != nil {
+				return nil, err
+			}
+		}
+		if err := form.Close(); err != nil {
+			return nil, err
+		}
+		body = &buf
+		contentType = form.FormDataContentType()
+	}
+
+	httpReq, err := http.NewRequest(req.method, req.url, body)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range req.headers {
+		httpReq.Header.Set(h.name, h.value)
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	return httpReq, nil
+}
+
+// validateResponse compares a response with the responses an operation declares:
+// the status code must be documented, and JSON bodies must match their schema
+func validateResponse(op *v3.Operation, status int, contentType string, body []byte) []string {
+	if op == nil || op.Responses == nil {
+		return nil
+	}
+
+	resp := declaredResponse(op.Responses, status)
+	if resp == nil {
+		return []string{fmt.Sprintf("status %d is not documented", status)}
+	}
+	if resp.Content == nil || resp.Content.Len() == 0 {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	mt, ok := resp.Content.Get(mediaType)
+	if !ok {
+		var declared []string
+		for pair := resp.Content.First(); pair != nil; pair = pair.Next() {
+			declared = append(declared, pair.Key())
+		}
+		return []string{fmt.Sprintf("content type %q is not documented, expected %s", mediaType, strings.Join(declared, " or "))}
+	}
+
+	if mt == nil || mt.Schema == nil || !isJSONMediaType(mediaType) {
+		return nil
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(body, &node); err != nil {
+		return []string{fmt.Sprintf("body is not valid JSON: %v", err)}
+	}
+	return validateValue(mt.Schema, &node)
+}
+
+// declaredResponse finds the response for a status code: an exact match,
+// then a range like "4XX", then the default response
+func declaredResponse(responses *v3.Responses, status int) *v3.Response {
+	code := strconv.Itoa(status)
+	if responses.Codes != nil {
+		if resp, ok := responses.Codes.Get(code); ok {
+			return resp
+		}
+		for pair := responses.Codes.First(); pair != nil; pair = pair.Next() {
+			if strings.EqualFold(pair.Key(), code[:1]+"XX") {
+				return pair.Value()
+			}
+		}
+	}
+	return responses.Default
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// unresolvedRef is a $ref leading nowhere, and where it is used
+type unresolvedRef struct {
+	ref     string
+	pointer string // Of the $ref field
+}
+
+// findUnresolvedRefs lists, in document order, the $refs to a local pointer
+// with nothing there, and the other ones the errors met loading the spec name,
+// like references to files that couldn't be read. Those errors may name only
+// the part after "#".
+func findUnresolvedRefs(root *yaml.Node, errs []error) []unresolvedRef {
+	named := map[string]bool{}
+	for _, err := range errs {
+		for _, quoted := range strings.Split(err.Error(), "`")[1:] {
+			named[quoted] = true
+		}
+	}
+
+	var refs []unresolvedRef
+	walkTree(root, func(key, value *yaml.Node, pointer string) bool {
+		if key == nil || key.Value != "$ref" || value.Kind != yaml.ScalarNode {
+			return true
+		}
+		local := strings.HasPrefix(value.Value, "#")
+		if local && resolvePointer(root, value.Value) == nil || !local && (named[value.Value] || named[refFragment(value.Value)]) {
+			refs = append(refs, unresolvedRef{ref: value.Value, pointer: pointer})
+		}
+		return true
+	})
+	return refs
+}
+
+// refFragment returns the local pointer part of a reference, e.g. "#/Pet" for
+// "pets.yaml#/Pet"
+func refFragment(ref string) string {
+	_, fragment, _ := strings.Cut(ref, "#")
+	return "#" + fragment
+}
+
+// sourceNode returns the YAML an operation or component is read from, which
+// still has the parts libopenapi couldn't build, or nil when there is none
+func sourceNode(source any) *yaml.Node {
+	high, ok := source.(interface{ GoLowUntyped() any })
+	if !ok || reflect.ValueOf(source).IsNil() {
+		return nil
+	}
+	low := high.GoLowUntyped()
+	if low == nil || reflect.ValueOf(low).IsNil() {
+		return nil
+	}
+	switch low := low.(type) {
+	case interface{ GetValueNode() *yaml.Node }:
+		return low.GetValueNode()
+	case interface{ GetRootNode() *yaml.Node }:
+		return low.GetRootNode()
+	}
+	return nil
+}
+
+// unresolvedMarkers returns a line for each unresolved reference the source of
+// an item uses, as what they point to is missing from its details
+func (m *Model) unresolvedMarkers(source any) string {
+	if len(m.unresolved) == 0 {
+		return ""
+	}
+
+	var markers strings.Builder
+	seen := map[string]bool{}
+	walkTree(sourceNode(source), func(key, value *yaml.Node, _ string) bool {
+		if key != nil && key.Value == "$ref" && m.unresolved[value.Value] && !seen[value.Value] {
+			seen[value.Value] = true
+			markers.WriteString(fmt.Sprintf("%s unresolved: %s\n", icons.warning, value.Value))
+		}
+		return true
+	})
+	return markers.String()
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// usage is a place in an operation where a component is used, directly or
+// through other components
+type usage struct {
+	ref      string // Operation or webhook reference to jump to
+	label    string // e.g. "GET /pet/{petId}"
+	location string // e.g. "response 200" or "parameter petId"
+}
+
+// operationPart is a part of an operation with the components it references
+type operationPart struct {
+	location string
+	refs     []string
+}
+
+// operationParts splits an operation into its parameters, request body, responses
+// and callbacks, each with the components it references directly
+func operationParts(op *v3.Operation) []operationPart {
+	if op == nil {
+		return nil
+	}
+
+	var parts []operationPart
+	add := func(location string, collect func(c *refCollector)) {
+		var c refCollector
+		collect(&c)
+		if len(c.refs) > 0 {
+			parts = append(parts, operationPart{location, c.refs})
+		}
+	}
+
+	for _, param := range op.Parameters {
+		if param != nil {
+			add("parameter "+param.Name, func(c *refCollector) { c.addParameter(param) })
+		}
+	}
+
+	add("request body", func(c *refCollector) { c.addRequestBody(op.RequestBody) })
+
+	if op.Responses != nil {
+		if op.Responses.Codes != nil {
+			var codes []string
+			for pair := op.Responses.Codes.First(); pair != nil; pair = pair.Next() {
+				codes = append(codes, pair.Key())
+			}
+			sortResponseCodes(codes)
+
+			for _, code := range codes {
+				resp, _ := op.Responses.Codes.Get(code)
+				add("response "+code, func(c *refCollector) { c.addResponse(resp) })
+			}
+		}
+		add("response default", func(c *refCollector) { c.addResponse(op.Responses.Default) })
+	}
+
+	if op.Callbacks != nil {
+		for pair := op.Callbacks.First(); pair != nil; pair = pair.Next() {
+			cb := pair.Value()
+			if cb == nil || cb.Expression == nil {
+				continue
+			}
+			add("callback "+pair.Key(), func(c *refCollector) {
+				for expr := cb.Expression.First(); expr != nil; expr = expr.Next() {
+					if expr.Value() == nil {
+						continue
+					}
+					for opPair := expr.Value().GetOperations().First(); opPair != nil; opPair = opPair.Next() {
+						for _, ref := range operationReferences(opPair.Value()) {
+							c.add(ref)
+						}
+					}
+				}
+			})
+		}
+	}
+
+	return parts
+}
+
+// componentGraph maps each component reference to the components it references
+func (m *Model) componentGraph() map[string][]string {
+	graph := make(map[string][]string)
+	for _, comp := range m.allItems().components {
+		ref := componentRef(comp.compType, comp.name)
+		graph[ref] = append(graph[ref], componentReferences(comp)...)
+	}
+	return graph
+}
+
+// reaches reports whether target is among refs or reachable from them in the graph
+func reaches(graph map[string][]string, refs []string, target string) bool {
+	seen := make(map[string]bool)
+	queue := slices.Clone(refs)
+	for len(queue) > 0 {
+		ref := queue[0]
+		queue = queue[1:]
+		if ref == target {
+			return true
+		}
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		queue = append(queue, graph[ref]...)
+	}
+	return false
+}
+
+// componentUsages lists the operations and webhooks that use a component, with
+// every part of them that references it
+func (m *Model) componentUsages(comp component) []usage {
+	target := componentRef(comp.compType, comp.name)
+	graph := m.componentGraph()
+
+	var usages []usage
+	find := func(ref, label string, op *v3.Operation) {
+		if comp.compType == "SecurityScheme" && effectiveSecurity(m.doc, op).usesScheme(comp.name) {
+			usages = append(usages, usage{ref: ref, label: label, location: "security"})
+			return
+		}
+		for _, part := range operationParts(op) {
+			if reaches(graph, part.refs, target) {
+				usages = append(usages, usage{ref: ref, label: label, location: part.location})
+			}
+		}
+	}
+
+	all := m.allItems()
+	for _, ep := range all.endpoints {
+		find(operationRef(ep.path, ep.method), fmt.Sprintf("%s %s", ep.method, ep.path), ep.op)
+	}
+	for _, hook := range all.webhooks {
+		find(webhookRef(hook.name, hook.method), fmt.Sprintf("%s %s (webhook)", hook.method, hook.name), hook.op)
+	}
+
+	return usages
+}
+
+// showUsages lists where the selected component is used, to jump to one of them
+func (m *Model) showUsages() {
+	if m.mode != viewComponents || m.cursor > m.getMaxItems() {
+		return
+	}
+
+	comp := m.components[m.cursor]
+	usages := m.componentUsages(comp)
+	if len(usages) == 0 {
+		m.message = comp.name + " is not used by any operation"
+		return
+	}
+
+	var items []pickerItem
+	for _, u := range usages {
+		items = append(items, pickerItem{label: u.label + ": " + u.location, value: u.ref})
+	}
+
+	m.picker = &picker{
+		title:  fmt.Sprintf("%s is used in %s", comp.name, pluralize(len(items), "place")),
+		items:  items,
+		action: pickerGoToReference,
+	}
+}
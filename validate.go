@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
+)
+
+// maxValidationDepth stops validating values nested deeper than this
+const maxValidationDepth = 32
+
+// validateValue checks a value against a schema and describes every mismatch,
+// e.g. "$.tags[0].name: missing required property". It covers the checks that
+// catch most contract drift: types, required properties, enums and the
+// allOf/oneOf/anyOf combinators, descending into properties and array items.
+func validateValue(proxy *base.SchemaProxy, node *yaml.Node) []string {
+	return validateNode(proxy, node, "$", 0)
+}
+
+func validateNode(proxy *base.SchemaProxy, node *yaml.Node, path string, depth int) []string {
+	if proxy == nil || proxy.Schema() == nil || node == nil || depth > maxValidationDepth {
+		return nil
+	}
+
+	for node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	s := proxy.Schema()
+
+	var problems []string
+	for _, sub := range s.AllOf {
+		problems = append(problems, validateNode(sub, node, path, depth+1)...)
+	}
+	if len(s.OneOf) > 0 && !matchesAny(s.OneOf, node, path, depth) {
+		problems = append(problems, fmt.Sprintf("%s: matches none of the oneOf schemas", path))
+	}
+	if len(s.AnyOf) > 0 && !matchesAny(s.AnyOf, node, path, depth) {
+		problems = append(problems, fmt.Sprintf("%s: matches none of the anyOf schemas", path))
+	}
+
+	actual := valueType(node)
+	if actual == "null" && (slices.Contains(s.Type, "null") || (s.Nullable != nil && *s.Nullable)) {
+		return problems
+	}
+	if len(s.Type) > 0 && !typeMatches(s.Type, actual, node) {
+		return append(problems, fmt.Sprintf("%s: expected %s, got %s", path, strings.Join(s.Type, " or "), actual))
+	}
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e *yaml.Node) bool { return sameValue(e, node) }) {
+		var values []string
+		for _, e := range s.Enum {
+			values = append(values, nodeToJSON(e, ""))
+		}
+		problems = append(problems, fmt.Sprintf("%s: %s is not one of %s", path, nodeToJSON(node, ""), strings.Join(values, ", ")))
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		present := make(map[string]*yaml.Node)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			present[node.Content[i].Value] = node.Content[i+1]
+		}
+		for _, name := range s.Required {
+			if _, ok := present[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s: missing required property", path, name))
+			}
+		}
+		if s.Properties != nil {
+			for pair := s.Properties.First(); pair != nil; pair = pair.Next() {
+				if value, ok := present[pair.Key()]; ok {
+					problems = append(problems, validateNode(pair.Value(), value, path+"."+pair.Key(), depth+1)...)
+				}
+			}
+		}
+
+	case yaml.SequenceNode:
+		if s.Items != nil && s.Items.IsA() {
+			for i, item := range node.Content {
+				problems = append(problems, validateNode(s.Items.A, item, fmt.Sprintf("%s[%d]", path, i), depth+1)...)
+			}
+		}
+	}
+
+	return problems
+}
+
+func matchesAny(schemas []*base.SchemaProxy, node *yaml.Node, path string, depth int) bool {
+	for _, sub := range schemas {
+		if len(validateNode(sub, node, path, depth+1)) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// valueType names the JSON type of a value
+func valueType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+
+	switch node.Tag {
+	case "!!null":
+		return "null"
+	case "!!bool":
+		return "boolean"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	}
+	return "string"
+}
+
+func typeMatches(types []string, actual string, node *yaml.Node) bool {
+	for _, t := range types {
+		switch {
+		case t == actual:
+			return true
+		case t == "number" && actual == "integer":
+			return true
+		case t == "integer" && actual == "number":
+			// 1.0 is a valid integer in JSON Schema
+			if f, err := strconv.ParseFloat(node.Value, 64); err == nil && f == float64(int64(f)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sameValue(a, b *yaml.Node) bool {
+	return nodeToJSON(a, "") == nodeToJSON(b, "")
+}
@@ -0,0 +1,915 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// Rules of the spec validation, reported with each issue
+const (
+	ruleSyntax         = "syntax"
+	ruleVersion        = "openapi-version"
+	ruleRequiredField  = "required-field"
+	ruleUnknownField   = "unknown-field"
+	ruleFieldType      = "field-type"
+	ruleReference      = "reference"
+	rulePathParameter  = "path-parameter"
+	ruleParameter      = "parameter"
+	ruleOperationID    = "unique-operationid"
+	ruleResponseCode   = "response-code"
+	ruleSchema         = "schema"
+	ruleSecurityScheme = "security-scheme"
+	ruleDuplicateTag   = "unique-tag"
+)
+
+// validationRules describe the rules, in the order SARIF reports list them
+var validationRules = []struct {
+	id          string
+	description string
+}{
+	{ruleSyntax, "The file is valid YAML or JSON"},
+	{ruleVersion, "The spec is OpenAPI 3.0 or 3.1"},
+	{ruleRequiredField, "Required fields are present"},
+	{ruleUnknownField, "Fields are defined by the specification or are x- extensions"},
+	{ruleFieldType, "Fields have the right type"},
+	{ruleReference, "Local references can be resolved"},
+	{rulePathParameter, "Path parameters match the path template"},
+	{ruleParameter, "Parameters are valid"},
+	{ruleOperationID, "operationIds are unique"},
+	{ruleResponseCode, "Response codes are status codes, ranges or default"},
+	{ruleSchema, "Schemas use valid types, required lists and items"},
+	{ruleSecurityScheme, "Security schemes are valid and declared"},
+	{ruleDuplicateTag, "Tags are declared once"},
+}
+
+// maxSpecValidationDepth stops checking schemas nested deeper than this
+const maxSpecValidationDepth = 64
+
+// issue is a place where a spec doesn't follow the OpenAPI specification
+type issue struct {
+	Rule    string `json:"rule"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"` // Suggested change, empty when there is no obvious one
+}
+
+var (
+	httpMethods        = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+	parameterIns       = []string{"query", "header", "path", "cookie"}
+	schemaTypes        = []string{"string", "number", "integer", "boolean", "array", "object", "null"}
+	securitySchemeType = []string{"apiKey", "http", "oauth2", "openIdConnect", "mutualTLS"}
+	responseCodeFormat = regexp.MustCompile(`^([1-5][0-9][0-9]|[1-5]XX|default)$`)
+	openAPIVersion     = regexp.MustCompile(`^3\.[01]\.\d+`)
+	yamlErrorLine      = regexp.MustCompile(`line (\d+)`)
+	pathTemplateParam  = regexp.MustCompile(`\{([^}]+)\}`)
+)
+
+// Fields allowed in each object, besides x- extensions
+var (
+	documentFields  = []string{"openapi", "info", "jsonSchemaDialect", "servers", "paths", "webhooks", "components", "security", "tags", "externalDocs"}
+	pathItemFields  = append([]string{"$ref", "summary", "description", "servers", "parameters"}, httpMethods...)
+	operationFields = []string{"tags", "summary", "description", "externalDocs", "operationId", "parameters", "requestBody",
+		"responses", "callbacks", "deprecated", "security", "servers"}
+)
+
+// specValidator checks the YAML tree of a spec, which keeps the position of
+// every value, rather than the model built by libopenapi
+type specValidator struct {
+	file         string
+	root         *yaml.Node
+	version      string // "3.0" or "3.1"
+	operationIDs map[string]string
+	issues       []issue
+}
+
+// validateSpec checks a spec against the OpenAPI specification and reports every
+// problem found with its position, instead of stopping at the first one
+func validateSpec(file string, content []byte) []issue {
+	v := &specValidator{file: file, operationIDs: map[string]string{}}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		e := diagnoseSyntax(content)
+		if e == nil {
+			e = &syntaxError{message: err.Error()}
+		}
+		return []issue{{Rule: ruleSyntax, File: file, Line: e.line, Column: max(e.column, 1), Pointer: "#", Message: e.message, Fix: e.hint}}
+	}
+	if len(doc.Content) == 0 {
+		return []issue{{Rule: ruleSyntax, File: file, Line: 1, Column: 1, Pointer: "#", Message: "the file is empty"}}
+	}
+
+	v.root = doc.Content[0]
+	v.validateDocument()
+	v.validateReferences(v.root, "#", 0)
+
+	slices.SortStableFunc(v.issues, func(a, b issue) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+	return v.issues
+}
+
+func (v *specValidator) report(rule string, node *yaml.Node, pointer, message, fix string) {
+	line, column := 0, 0
+	if node != nil {
+		line, column = node.Line, node.Column
+	}
+	v.issues = append(v.issues, issue{Rule: rule, File: v.file, Line: line, Column: column, Pointer: pointer, Message: message, Fix: fix})
+}
+
+// field returns the key and value nodes of a field of a mapping
+func field(node *yaml.Node, name string) (*yaml.Node, *yaml.Node) {
+	node = unalias(node)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == name {
+			return node.Content[i], unalias(node.Content[i+1])
+		}
+	}
+	return nil, nil
+}
+
+// eachField calls fn with the key and value of every field of a mapping
+func eachField(node *yaml.Node, fn func(key, value *yaml.Node)) {
+	node = unalias(node)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		fn(node.Content[i], unalias(node.Content[i+1]))
+	}
+}
+
+// unalias returns the node a YAML alias like *ref_0 stands for
+func unalias(node *yaml.Node) *yaml.Node {
+	if node != nil && node.Kind == yaml.AliasNode {
+		return node.Alias
+	}
+	return node
+}
+
+func pointerTo(pointer, token string) string {
+	return pointer + "/" + escapePointerToken(token)
+}
+
+// requireField reports a missing field and returns its value otherwise
+func (v *specValidator) requireField(node *yaml.Node, pointer, name, fix string) *yaml.Node {
+	_, value := field(node, name)
+	if value == nil {
+		v.report(ruleRequiredField, node, pointer, fmt.Sprintf("missing required field %q", name), fix)
+	}
+	return value
+}
+
+// requireString reports a field that is missing or not a string, like a version
+// written as 1.0 which YAML reads as a number
+func (v *specValidator) requireString(node *yaml.Node, pointer, name, fix string) {
+	value := v.requireField(node, pointer, name, fix)
+	if value == nil {
+		return
+	}
+	if value.Kind != yaml.ScalarNode || value.Tag != "!!str" {
+		v.report(ruleFieldType, value, pointerTo(pointer, name), fmt.Sprintf("%q must be a string", name), fmt.Sprintf("quote the value: %s: %q", name, value.Value))
+	}
+}
+
+// checkFields reports the fields of an object that the specification doesn't define
+func (v *specValidator) checkFields(node *yaml.Node, pointer, object string, allowed []string) {
+	eachField(node, func(key, _ *yaml.Node) {
+		if strings.HasPrefix(key.Value, "x-") || slices.Contains(allowed, key.Value) {
+			return
+		}
+		fix := "remove it, or prefix it with x- to keep it as an extension"
+		if lower := strings.ToLower(key.Value); slices.Contains(allowed, lower) {
+			fix = fmt.Sprintf("use %q", lower)
+		} else if similar := closest(key.Value, allowed); similar != "" {
+			fix = fmt.Sprintf("did you mean %q?", similar)
+		}
+		v.report(ruleUnknownField, key, pointerTo(pointer, key.Value), fmt.Sprintf("unknown field %q in %s", key.Value, object), fix)
+	})
+}
+
+func (v *specValidator) validateDocument() {
+	root := v.root
+	if root.Kind != yaml.MappingNode {
+		v.report(ruleSyntax, root, "#", "the spec must be a mapping of fields like openapi, info and paths", "")
+		return
+	}
+
+	if _, swagger := field(root, "swagger"); swagger != nil {
+		v.report(ruleVersion, swagger, "#/swagger", "Swagger 2.0 specs are not supported", "convert the spec to OpenAPI 3, e.g. with swagger2openapi")
+		return
+	}
+
+	_, version := field(root, "openapi")
+	switch {
+	case version == nil:
+		v.report(ruleRequiredField, root, "#", `missing required field "openapi"`, "add openapi: 3.1.0")
+	case version.Tag != "!!str":
+		v.report(ruleFieldType, version, "#/openapi", `"openapi" must be a string`, fmt.Sprintf("quote the value: openapi: %q", version.Value+".0"))
+	case !openAPIVersion.MatchString(version.Value):
+		v.report(ruleVersion, version, "#/openapi", fmt.Sprintf("unsupported OpenAPI version %q", version.Value), "use a 3.0.x or 3.1.x version")
+	default:
+		v.version = version.Value[:3]
+	}
+
+	v.checkFields(root, "#", "the document", documentFields)
+
+	if info := v.requireField(root, "#", "info", "add an info object with a title and a version"); info != nil {
+		v.requireString(info, "#/info", "title", "add title: My API")
+		v.requireString(info, "#/info", "version", "add version: 1.0.0")
+	}
+
+	_, paths := field(root, "paths")
+	_, webhooks := field(root, "webhooks")
+	_, components := field(root, "components")
+	if paths == nil && (v.version != "3.1" || (webhooks == nil && components == nil)) {
+		fix := "add paths: {}"
+		if v.version == "3.1" {
+			fix = "add paths, webhooks or components"
+		}
+		v.report(ruleRequiredField, root, "#", `missing required field "paths"`, fix)
+	}
+
+	eachField(paths, func(key, value *yaml.Node) {
+		pointer := pointerTo("#/paths", key.Value)
+		if strings.HasPrefix(key.Value, "x-") {
+			return
+		}
+		if !strings.HasPrefix(key.Value, "/") {
+			v.report(ruleFieldType, key, pointer, fmt.Sprintf("path %q must start with /", key.Value), fmt.Sprintf("rename it to %q", "/"+key.Value))
+		}
+		v.validatePathItem(value, pointer, key.Value)
+	})
+
+	eachField(webhooks, func(key, value *yaml.Node) {
+		v.validatePathItem(value, pointerTo("#/webhooks", key.Value), "")
+	})
+
+	v.validateComponents(components)
+
+	_, security := field(root, "security")
+	v.validateSecurity(security, "#/security")
+
+	_, tags := field(root, "tags")
+	if tags != nil && tags.Kind == yaml.SequenceNode {
+		seen := map[string]bool{}
+		for i, tag := range tags.Content {
+			_, name := field(tag, "name")
+			pointer := fmt.Sprintf("#/tags/%d", i)
+			if name == nil {
+				v.report(ruleRequiredField, tag, pointer, `missing required field "name"`, "add the name of the tag")
+				continue
+			}
+			if seen[name.Value] {
+				v.report(ruleDuplicateTag, name, pointer+"/name", fmt.Sprintf("tag %q is declared more than once", name.Value), "merge the declarations")
+			}
+			seen[name.Value] = true
+		}
+	}
+}
+
+// validatePathItem checks the operations of a path, or of a webhook when path is empty
+func (v *specValidator) validatePathItem(node *yaml.Node, pointer, path string) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	if _, ref := field(node, "$ref"); ref != nil {
+		return
+	}
+
+	v.checkFields(node, pointer, "the path item", pathItemFields)
+
+	_, params := field(node, "parameters")
+	shared := v.validateParameters(params, pointer+"/parameters")
+
+	var templateParams []string
+	for _, match := range pathTemplateParam.FindAllStringSubmatch(path, -1) {
+		templateParams = append(templateParams, match[1])
+	}
+
+	eachField(node, func(key, op *yaml.Node) {
+		if !slices.Contains(httpMethods, key.Value) {
+			return
+		}
+		opPointer := pointerTo(pointer, key.Value)
+		declared := v.validateOperation(op, opPointer)
+		if path == "" {
+			return
+		}
+
+		declared = append(declared, shared...)
+		for _, name := range templateParams {
+			if !slices.ContainsFunc(declared, func(p declaredParameter) bool { return p.name == name }) {
+				v.report(rulePathParameter, key, opPointer, fmt.Sprintf("path parameter %q is not declared", name),
+					fmt.Sprintf("add a parameter with name: %s, in: path and required: true", name))
+			}
+		}
+		for _, p := range declared {
+			if !slices.Contains(templateParams, p.name) {
+				v.report(rulePathParameter, p.node, p.pointer, fmt.Sprintf("path parameter %q is not part of the path %s", p.name, path),
+					fmt.Sprintf("remove it, or add {%s} to the path", p.name))
+			}
+		}
+	})
+}
+
+// declaredParameter is a path parameter declared by an operation or path item
+type declaredParameter struct {
+	name    string
+	node    *yaml.Node
+	pointer string
+}
+
+// validateOperation checks an operation and returns the path parameters it declares
+func (v *specValidator) validateOperation(node *yaml.Node, pointer string) []declaredParameter {
+	if node == nil || node.Kind != yaml.MappingNode {
+		v.report(ruleFieldType, node, pointer, "an operation must be a mapping", "")
+		return nil
+	}
+
+	v.checkFields(node, pointer, "the operation", operationFields)
+
+	if _, id := field(node, "operationId"); id != nil {
+		if other, ok := v.operationIDs[id.Value]; ok {
+			v.report(ruleOperationID, id, pointer+"/operationId", fmt.Sprintf("operationId %q is already used by %s", id.Value, other), "rename one of them")
+		} else {
+			v.operationIDs[id.Value] = pointer
+		}
+	}
+
+	_, params := field(node, "parameters")
+	declared := v.validateParameters(params, pointer+"/parameters")
+
+	if _, body := field(node, "requestBody"); body != nil {
+		v.validateRequestBody(body, pointer+"/requestBody")
+	}
+
+	_, responses := field(node, "responses")
+	switch {
+	case responses == nil && v.version != "3.1":
+		v.report(ruleRequiredField, node, pointer, `missing required field "responses"`, `add a response, e.g. "200": {description: OK}`)
+	case responses != nil:
+		eachField(responses, func(key, value *yaml.Node) {
+			respPointer := pointerTo(pointer+"/responses", key.Value)
+			if strings.HasPrefix(key.Value, "x-") {
+				return
+			}
+			if !responseCodeFormat.MatchString(key.Value) {
+				fix := "use a status code like 200, a range like 4XX or default"
+				if strings.EqualFold(key.Value, "default") || (len(key.Value) == 3 && strings.HasSuffix(strings.ToUpper(key.Value), "XX")) {
+					fix = fmt.Sprintf("use %q", strings.ToUpper(key.Value[:1])+strings.ToUpper(key.Value[1:]))
+					if strings.EqualFold(key.Value, "default") {
+						fix = `use "default"`
+					}
+				}
+				v.report(ruleResponseCode, key, respPointer, fmt.Sprintf("invalid response code %q", key.Value), fix)
+			}
+			if key.Tag == "!!int" {
+				v.report(ruleFieldType, key, respPointer, fmt.Sprintf("response code %s must be a string", key.Value), fmt.Sprintf("quote it: %q", key.Value))
+			}
+			v.validateResponse(value, respPointer)
+		})
+	}
+
+	_, security := field(node, "security")
+	v.validateSecurity(security, pointer+"/security")
+
+	return declared
+}
+
+// validateParameters checks a list of parameters and returns the path parameters
+// among them, following references to find their names
+func (v *specValidator) validateParameters(node *yaml.Node, pointer string) []declaredParameter {
+	if node == nil {
+		return nil
+	}
+	if node.Kind != yaml.SequenceNode {
+		v.report(ruleFieldType, node, pointer, "parameters must be a list", "")
+		return nil
+	}
+
+	var declared []declaredParameter
+	seen := map[string]bool{}
+	for i, param := range node.Content {
+		param = unalias(param)
+		paramPointer := fmt.Sprintf("%s/%d", pointer, i)
+		resolved := param
+		if _, ref := field(param, "$ref"); ref != nil {
+			resolved = v.resolve(ref.Value)
+			if resolved == nil {
+				continue // Reported with the other references
+			}
+		} else {
+			v.validateParameter(param, paramPointer)
+		}
+
+		_, name := field(resolved, "name")
+		_, in := field(resolved, "in")
+		if name == nil || in == nil {
+			continue
+		}
+		key := in.Value + ":" + name.Value
+		if seen[key] {
+			v.report(ruleParameter, param, paramPointer, fmt.Sprintf("%s parameter %q is declared more than once", in.Value, name.Value), "remove one of them")
+		}
+		seen[key] = true
+		if in.Value == "path" {
+			declared = append(declared, declaredParameter{name.Value, param, paramPointer})
+		}
+	}
+	return declared
+}
+
+func (v *specValidator) validateParameter(node *yaml.Node, pointer string) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		v.report(ruleFieldType, node, pointer, "a parameter must be a mapping", "")
+		return
+	}
+
+	_, nameNode := field(node, "name")
+	name := ""
+	if nameNode == nil {
+		v.report(ruleRequiredField, node, pointer, `missing required field "name"`, "add the name of the parameter")
+	} else {
+		name = nameNode.Value
+	}
+
+	_, in := field(node, "in")
+	switch {
+	case in == nil:
+		v.report(ruleRequiredField, node, pointer, fmt.Sprintf("parameter %q has no \"in\" field", name), "add in: query, header, path or cookie")
+	case !slices.Contains(parameterIns, in.Value):
+		fix := "use query, header, path or cookie"
+		if lower := strings.ToLower(in.Value); slices.Contains(parameterIns, lower) {
+			fix = fmt.Sprintf("use %q", lower)
+		} else if in.Value == "body" || in.Value == "formData" {
+			fix = "describe the body with requestBody instead, as in OpenAPI 3"
+		}
+		v.report(ruleParameter, in, pointer+"/in", fmt.Sprintf("invalid parameter location %q", in.Value), fix)
+	case in.Value == "path":
+		if _, required := field(node, "required"); required == nil || required.Value != "true" {
+			v.report(ruleParameter, node, pointer, fmt.Sprintf("path parameter %q must be required", name), "add required: true")
+		}
+	}
+
+	_, schema := field(node, "schema")
+	_, content := field(node, "content")
+	switch {
+	case schema == nil && content == nil:
+		v.report(ruleParameter, node, pointer, fmt.Sprintf("parameter %q has no schema or content", name), "add a schema, e.g. schema: {type: string}")
+	case schema != nil && content != nil:
+		v.report(ruleParameter, node, pointer, fmt.Sprintf("parameter %q has both a schema and content", name), "keep only one of them")
+	case schema != nil:
+		v.validateSchema(schema, pointer+"/schema", 0)
+	default:
+		v.validateContent(content, pointer+"/content")
+	}
+}
+
+func (v *specValidator) validateRequestBody(node *yaml.Node, pointer string) {
+	if _, ref := field(node, "$ref"); ref != nil {
+		return
+	}
+	if content := v.requireField(node, pointer, "content", "add the media types of the body, e.g. content: {application/json: {schema: {...}}}"); content != nil {
+		v.validateContent(content, pointer+"/content")
+	}
+}
+
+func (v *specValidator) validateResponse(node *yaml.Node, pointer string) {
+	if _, ref := field(node, "$ref"); ref != nil {
+		return
+	}
+	v.requireField(node, pointer, "description", "add a description, e.g. description: OK")
+	if _, content := field(node, "content"); content != nil {
+		v.validateContent(content, pointer+"/content")
+	}
+}
+
+func (v *specValidator) validateContent(node *yaml.Node, pointer string) {
+	eachField(node, func(key, value *yaml.Node) {
+		if _, schema := field(value, "schema"); schema != nil {
+			v.validateSchema(schema, pointerTo(pointer, key.Value)+"/schema", 0)
+		}
+	})
+}
+
+// validateSchema checks the keywords that are most often mistyped: types,
+// required lists and array items
+func (v *specValidator) validateSchema(node *yaml.Node, pointer string, depth int) {
+	if node == nil || node.Kind != yaml.MappingNode || depth > maxSpecValidationDepth {
+		return
+	}
+	if _, ref := field(node, "$ref"); ref != nil {
+		return
+	}
+
+	_, typeNode := field(node, "type")
+	var types []*yaml.Node
+	switch {
+	case typeNode == nil:
+	case typeNode.Kind == yaml.ScalarNode:
+		types = []*yaml.Node{typeNode}
+	case typeNode.Kind == yaml.SequenceNode && v.version == "3.1":
+		types = typeNode.Content
+	default:
+		v.report(ruleSchema, typeNode, pointer+"/type", "type must be a single type in OpenAPI 3.0", "use one type, with nullable: true for null")
+	}
+	for _, t := range types {
+		switch {
+		case t.Value == "null" && v.version != "3.1":
+			v.report(ruleSchema, t, pointer+"/type", `type "null" is only allowed in OpenAPI 3.1`, "use nullable: true instead")
+		case !slices.Contains(schemaTypes, t.Value):
+			fix := "use string, number, integer, boolean, array, object or null"
+			if similar := closest(t.Value, schemaTypes); similar != "" {
+				fix = fmt.Sprintf("did you mean %q?", similar)
+			}
+			if t.Value == "int" || t.Value == "long" {
+				fix = `use "integer"`
+			}
+			v.report(ruleSchema, t, pointer+"/type", fmt.Sprintf("invalid type %q", t.Value), fix)
+		}
+	}
+
+	if _, required := field(node, "required"); required != nil && required.Kind != yaml.SequenceNode {
+		v.report(ruleSchema, required, pointer+"/required", "required must be a list of property names",
+			"list the required properties in the required field of the parent schema")
+	}
+
+	_, items := field(node, "items")
+	isArray := slices.ContainsFunc(types, func(t *yaml.Node) bool { return t.Value == "array" })
+	if isArray && items == nil && v.version != "3.1" {
+		v.report(ruleSchema, node, pointer, "array schema has no items", "add items, e.g. items: {type: string}")
+	}
+	v.validateSchema(items, pointer+"/items", depth+1)
+
+	_, properties := field(node, "properties")
+	eachField(properties, func(key, value *yaml.Node) {
+		v.validateSchema(value, pointerTo(pointer+"/properties", key.Value), depth+1)
+	})
+
+	if _, additional := field(node, "additionalProperties"); additional != nil {
+		v.validateSchema(additional, pointer+"/additionalProperties", depth+1)
+	}
+	if _, not := field(node, "not"); not != nil {
+		v.validateSchema(not, pointer+"/not", depth+1)
+	}
+	for _, keyword := range []string{"allOf", "oneOf", "anyOf"} {
+		_, list := field(node, keyword)
+		if list == nil {
+			continue
+		}
+		if list.Kind != yaml.SequenceNode {
+			v.report(ruleSchema, list, pointer+"/"+keyword, keyword+" must be a list of schemas", "")
+			continue
+		}
+		for i, sub := range list.Content {
+			v.validateSchema(unalias(sub), fmt.Sprintf("%s/%s/%d", pointer, keyword, i), depth+1)
+		}
+	}
+}
+
+func (v *specValidator) validateComponents(node *yaml.Node) {
+	_, schemas := field(node, "schemas")
+	eachField(schemas, func(key, value *yaml.Node) {
+		v.validateSchema(value, pointerTo("#/components/schemas", key.Value), 0)
+	})
+
+	_, params := field(node, "parameters")
+	eachField(params, func(key, value *yaml.Node) {
+		v.validateParameter(value, pointerTo("#/components/parameters", key.Value))
+	})
+
+	_, bodies := field(node, "requestBodies")
+	eachField(bodies, func(key, value *yaml.Node) {
+		v.validateRequestBody(value, pointerTo("#/components/requestBodies", key.Value))
+	})
+
+	_, responses := field(node, "responses")
+	eachField(responses, func(key, value *yaml.Node) {
+		v.validateResponse(value, pointerTo("#/components/responses", key.Value))
+	})
+
+	_, schemes := field(node, "securitySchemes")
+	eachField(schemes, func(key, value *yaml.Node) {
+		pointer := pointerTo("#/components/securitySchemes", key.Value)
+		if _, ref := field(value, "$ref"); ref != nil {
+			return
+		}
+		schemeType := v.requireField(value, pointer, "type", "add type: apiKey, http, oauth2, openIdConnect or mutualTLS")
+		if schemeType == nil {
+			return
+		}
+		switch schemeType.Value {
+		case "apiKey":
+			v.requireField(value, pointer, "name", "add the name of the header, query parameter or cookie")
+			v.requireField(value, pointer, "in", "add in: header, query or cookie")
+		case "http":
+			v.requireField(value, pointer, "scheme", "add scheme: bearer or basic")
+		case "oauth2":
+			v.requireField(value, pointer, "flows", "add the OAuth2 flows, e.g. flows: {clientCredentials: {...}}")
+		case "openIdConnect":
+			v.requireField(value, pointer, "openIdConnectUrl", "add the URL of the OpenID Connect discovery document")
+		case "mutualTLS":
+		default:
+			fix := "use apiKey, http, oauth2, openIdConnect or mutualTLS"
+			if similar := closest(schemeType.Value, securitySchemeType); similar != "" {
+				fix = fmt.Sprintf("did you mean %q?", similar)
+			}
+			v.report(ruleSecurityScheme, schemeType, pointer+"/type", fmt.Sprintf("invalid security scheme type %q", schemeType.Value), fix)
+		}
+	})
+}
+
+// validateSecurity checks that security requirements name declared schemes
+func (v *specValidator) validateSecurity(node *yaml.Node, pointer string) {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return
+	}
+
+	_, components := field(v.root, "components")
+	_, schemes := field(components, "securitySchemes")
+	var names []string
+	eachField(schemes, func(key, _ *yaml.Node) {
+		names = append(names, key.Value)
+	})
+
+	for i, requirement := range node.Content {
+		eachField(requirement, func(key, _ *yaml.Node) {
+			if slices.Contains(names, key.Value) {
+				return
+			}
+			fix := "declare it in components.securitySchemes"
+			if similar := closest(key.Value, names); similar != "" {
+				fix = fmt.Sprintf("did you mean %q?", similar)
+			}
+			v.report(ruleSecurityScheme, key, pointerTo(fmt.Sprintf("%s/%d", pointer, i), key.Value),
+				fmt.Sprintf("security scheme %q is not declared", key.Value), fix)
+		})
+	}
+}
+
+// validateReferences reports local $refs that point nowhere, suggesting a
+// similar name when there is one
+func (v *specValidator) validateReferences(node *yaml.Node, pointer string, depth int) {
+	if node == nil || depth > maxSpecValidationDepth*4 {
+		return
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "$ref" && value.Kind == yaml.ScalarNode {
+				v.checkReference(value, pointerTo(pointer, "$ref"))
+				continue
+			}
+			v.validateReferences(value, pointerTo(pointer, key.Value), depth+1)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			v.validateReferences(item, fmt.Sprintf("%s/%d", pointer, i), depth+1)
+		}
+	}
+}
+
+func (v *specValidator) checkReference(ref *yaml.Node, pointer string) {
+	// References to other files are resolved by libopenapi when loading
+	if !strings.HasPrefix(ref.Value, "#") || v.resolve(ref.Value) != nil {
+		return
+	}
+
+	fix := ""
+	tokens := strings.Split(strings.TrimPrefix(ref.Value, "#/"), "/")
+	if parent := v.resolve("#/" + strings.Join(tokens[:len(tokens)-1], "/")); parent != nil && len(tokens) > 1 {
+		var names []string
+		eachField(parent, func(key, _ *yaml.Node) {
+			names = append(names, escapePointerToken(key.Value))
+		})
+		if similar := closest(tokens[len(tokens)-1], names); similar != "" {
+			fix = fmt.Sprintf("did you mean %q?", "#/"+strings.Join(append(tokens[:len(tokens)-1:len(tokens)-1], similar), "/"))
+		}
+	}
+	v.report(ruleReference, ref, pointer, fmt.Sprintf("reference %q can't be resolved", ref.Value), fix)
+}
+
+// resolve follows a local JSON pointer like "#/components/schemas/Pet" in the spec
+func (v *specValidator) resolve(ref string) *yaml.Node {
+	return resolvePointer(v.root, ref)
+}
+
+// resolvePointer follows a local JSON pointer from the root of a YAML tree
+func resolvePointer(root *yaml.Node, ref string) *yaml.Node {
+	ref, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil
+	}
+
+	node := unalias(root)
+	if ref == "" || ref == "/" {
+		return node
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node.Kind {
+		case yaml.MappingNode:
+			_, node = field(node, token)
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return nil
+			}
+			node = unalias(node.Content[i])
+		default:
+			return nil
+		}
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// pointerPosition returns the line and column of the key a JSON pointer ends
+// at, or of the item for array items. Both are 0 when nothing is there.
+func pointerPosition(root *yaml.Node, pointer string) (int, int) {
+	if i := strings.LastIndex(pointer, "/"); i > 0 {
+		token := strings.ReplaceAll(strings.ReplaceAll(pointer[i+1:], "~1", "/"), "~0", "~")
+		if key, _ := field(resolvePointer(root, pointer[:i]), token); key != nil {
+			return key.Line, key.Column
+		}
+	}
+	if node := resolvePointer(root, pointer); node != nil {
+		return node.Line, node.Column
+	}
+	return 0, 0
+}
+
+// findPointer returns the JSON pointer of the first field or array item of a
+// YAML tree that matches, key being nil for items, or "" when none does
+func findPointer(root *yaml.Node, match func(key, value *yaml.Node) bool) string {
+	found := ""
+	walkTree(root, func(key, value *yaml.Node, pointer string) bool {
+		if match(key, value) {
+			found = pointer
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// walkTree calls fn with every field and array item of a YAML tree in document
+// order, key being nil for items, until fn returns false. Nodes shared through
+// aliases are visited once.
+func walkTree(root *yaml.Node, fn func(key, value *yaml.Node, pointer string) bool) {
+	visited := map[*yaml.Node]bool{}
+	var walk func(node *yaml.Node, pointer string) bool
+	visit := func(key, value *yaml.Node, pointer string) bool {
+		return fn(key, value, pointer) && walk(value, pointer)
+	}
+	walk = func(node *yaml.Node, pointer string) bool {
+		node = unalias(node)
+		if node == nil || visited[node] {
+			return true
+		}
+		visited[node] = true
+
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if !visit(node.Content[i], node.Content[i+1], pointerTo(pointer, node.Content[i].Value)) {
+					return false
+				}
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				if !visit(nil, item, fmt.Sprintf("%s/%d", pointer, i)) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	walk(root, "#")
+}
+
+// closest returns the candidate most similar to name, if one is close enough to
+// be a likely typo
+func closest(name string, candidates []string) string {
+	best, bestDistance := "", max(1, len(name)/3)+1
+	for _, candidate := range candidates {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+func writeIssuesText(w io.Writer, issues []issue) error {
+	if len(issues) == 0 {
+		_, err := fmt.Fprintln(w, "The spec is valid")
+		return err
+	}
+	for _, is := range issues {
+		fmt.Fprintf(w, "%s:%d:%d: %s (%s)\n", is.File, is.Line, is.Column, is.Message, is.Pointer)
+		if is.Fix != "" {
+			fmt.Fprintf(w, "  fix: %s\n", is.Fix)
+		}
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n", pluralize(len(issues), "problem"))
+	return err
+}
+
+// Report formats of the validate command
+const formatSARIF = "sarif"
+
+// runValidate implements "oq validate". It exits with status 1 when the spec
+// has problems.
+func runValidate(fs *flag.FlagSet, args []string) error {
+	format := fs.String("format", defaultOutput, "output format: text, json, sarif or junit")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	path, err := specArg(fs)
+	if err != nil {
+		return err
+	}
+
+	content, err := readSpec(path)
+	if err != nil {
+		return err
+	}
+	file := path
+	if file == "" {
+		file = "<stdin>"
+	}
+	issues := validateSpec(file, content)
+
+	switch *format {
+	case outputText:
+		err = writeIssuesText(os.Stdout, issues)
+	case outputJSON:
+		if issues == nil {
+			issues = []issue{}
+		}
+		var data []byte
+		data, err = json.MarshalIndent(issues, "", "  ")
+		if err == nil {
+			err = writeResult("", append(data, '\n'))
+		}
+	case formatSARIF:
+		err = writeSARIF(os.Stdout, validationSARIFRules(), issuesSARIF(issues))
+	case formatJUnit:
+		err = writeJUnit(os.Stdout, "oq validate "+file, issuesJUnit(issues))
+	default:
+		err = fmt.Errorf("unknown format %q, use text, json, sarif or junit", *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(issues) > 0 {
+		return errFindings
+	}
+	return nil
+}
@@ -2,125 +2,162 @@ package main
 
 import (
 	"fmt"
+	"net/http"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"go.yaml.in/yaml/v4"
 )
 
-const (
-	colorGreen       = "#10B981"
-	colorBlue        = "#3B82F6"
-	colorYellow      = "#F59E0B"
-	colorRed         = "#EF4444"
-	colorPurple      = "#8B5CF6"
-	colorGray        = "#6B7280"
-	colorThemePurple = "#7C3AED"
-	colorBackground  = "#374151"
-	colorDetailGray  = "#9CA3AF"
-	colorFooterText  = "#000000"
-	colorWhite       = "#FFFFFF"
-)
-
-var methodColors = map[string]lipgloss.Color{
-	"GET":     colorGreen,
-	"POST":    colorBlue,
-	"PUT":     colorYellow,
-	"DELETE":  colorRed,
-	"PATCH":   colorPurple,
-	"HEAD":    colorGray,
-	"OPTIONS": colorGray,
-	"TRACE":   colorGray,
-}
+func (m Model) renderEndpoints(maxLines int) string {
+	s := lineBuffer{max: maxLines}
 
-func (m Model) renderEndpoints() string {
-	var s strings.Builder
-
-	// Calculate available content height and width
+	// Calculate available content height
 	contentHeight := calculateContentHeight(m.height)
-	contentWidth := calculateContentWidth(m.width)
 
 	startIdx := m.scrollOffset
 	endIdx := min(m.scrollOffset+contentHeight, len(m.endpoints))
 
+	// Paths are padded so that the extra columns line up, unless a path is very long
+	pathWidth := 0
+	if len(m.columns) > 0 {
+		pathWidth = min(m.layout.longestPath(m.endpoints), calculateContentWidth(m.width)/2)
+	}
+
 	// Add scroll indicator for items above
 	if m.scrollOffset > 0 {
 		indicator := lipgloss.NewStyle().
-			Foreground(lipgloss.Color(colorGray)).
-			Render("⬆ More items above...")
-		s.WriteString(indicator)
-		s.WriteString("\n")
+			Foreground(currentTheme.gray).
+			Render(icons.above + " More items above...")
+		s.writeLine(indicator)
 	}
 
-	for i := startIdx; i < endIdx; i++ {
+	for i := startIdx; i < endIdx && !s.full(); i++ {
 		ep := m.endpoints[i]
 		style := lipgloss.NewStyle()
 
-		methodColor := methodColors[ep.method]
-		if methodColor == "" {
-			methodColor = colorGray
-		}
-
 		methodStyle := lipgloss.NewStyle().
-			Foreground(methodColor).
+			Foreground(currentTheme.methodColor(ep.method)).
 			Bold(true).
 			Width(7)
 
 		if i == m.cursor {
-			style = style.Background(lipgloss.Color(colorBackground))
-			methodStyle = methodStyle.Background(lipgloss.Color(colorBackground))
+			style = withBackground(style, currentTheme.selection)
+			methodStyle = withBackground(methodStyle, currentTheme.selection)
 		}
 
-		foldIcon := "▶"
+		foldIcon := icons.folded
 		if !ep.folded {
-			foldIcon = "▼"
+			foldIcon = icons.unfolded
 		}
 
 		var line strings.Builder
+		line.WriteString(m.baselineMarker(endpointName(ep), style))
 		line.WriteString(style.Render(foldIcon + " "))
 		line.WriteString(methodStyle.Render(ep.method))
-		line.WriteString(style.Render(" " + ep.path))
-		line.WriteString(style.Render(strings.Repeat(" ", contentWidth)))
+		line.WriteString(style.Render(" "))
+		line.WriteString(deprecatedName(ep.path, ep.deprecation, style))
+		if columns := m.endpointColumns(ep); columns != "" {
+			padding := strings.Repeat(" ", max(0, pathWidth-lipgloss.Width(ep.path+deprecationBadge(ep.deprecation))))
+			line.WriteString(style.Render(padding + "  "))
+			line.WriteString(style.Foreground(currentTheme.gray).Render(columns))
+		}
+		line.WriteString(m.linePadding(line.String(), style))
 
-		s.WriteString(style.Render(line.String()))
-		s.WriteString("\n")
+		s.writeLine(style.Render(line.String()))
 
 		if !ep.folded {
-			details := formatEndpointDetails(ep)
-			detailStyle := lipgloss.NewStyle().
-				PaddingLeft(2).
-				Foreground(lipgloss.Color(colorDetailGray))
-			s.WriteString(detailStyle.Render(details))
-			s.WriteString("\n")
+			for _, detail := range m.detailLines(i) {
+				s.writeLine(detail)
+			}
 		}
 	}
 
 	// Add scroll indicator for items below
 	if endIdx < len(m.endpoints) {
 		indicator := lipgloss.NewStyle().
-			Foreground(lipgloss.Color(colorGray)).
-			Render("⬇ More items below...")
-		s.WriteString(indicator)
-		s.WriteString("\n")
+			Foreground(currentTheme.gray).
+			Render(icons.below + " More items below...")
+		s.writeLine(indicator)
 	}
 
 	return s.String()
 }
 
-func (m Model) renderComponents() string {
-	var s strings.Builder
+// lineBuffer collects the lines of a list until there are more than fit on
+// the screen, so that drawing doesn't depend on the size of the details unfolded
+type lineBuffer struct {
+	strings.Builder
+	lines int
+	max   int
+}
+
+func (b *lineBuffer) writeLine(line string) {
+	if b.full() {
+		return
+	}
+	b.WriteString(line)
+	b.WriteString("\n")
+	b.lines++
+}
+
+// full tells whether enough lines are collected for View to cut the list
+func (b *lineBuffer) full() bool {
+	return b.lines > b.max
+}
+
+// listLayout keeps the measures taken over all the endpoints, which only change
+// with the list
+type listLayout struct {
+	first     *endpoint // Of the list measured
+	count     int
+	pathWidth int
+}
+
+// longestPath returns the width of the longest path, with its deprecation badge
+func (l *listLayout) longestPath(endpoints []endpoint) int {
+	if len(endpoints) == 0 {
+		return 0
+	}
+	if l != nil && l.first == &endpoints[0] && l.count == len(endpoints) {
+		return l.pathWidth
+	}
+	width := 0
+	for _, ep := range endpoints {
+		width = max(width, lipgloss.Width(ep.path+deprecationBadge(ep.deprecation)))
+	}
+	if l != nil {
+		*l = listLayout{first: &endpoints[0], count: len(endpoints), pathWidth: width}
+	}
+	return width
+}
 
-	componentColors := map[string]lipgloss.Color{
-		"Schema":         colorGreen,
-		"RequestBody":    colorBlue,
-		"Response":       colorYellow,
-		"Parameter":      colorPurple,
-		"Header":         colorRed,
-		"SecurityScheme": colorGray,
+// deprecationBadge is the text following the name of a deprecated item, e.g. " deprecated"
+func deprecationBadge(d deprecation) string {
+	if !d.deprecated {
+		return ""
 	}
+	return " " + d.badge()
+}
+
+// deprecatedName renders the name of an item in the lists, struck through and
+// followed by a badge when the item is deprecated
+func deprecatedName(name string, d deprecation, style lipgloss.Style) string {
+	if !d.deprecated {
+		return style.Render(name)
+	}
+	return style.Strikethrough(true).Render(name) +
+		style.Foreground(currentTheme.yellow).Render(deprecationBadge(d))
+}
 
-	// Calculate available content height and width
+func (m Model) renderComponents(maxLines int) string {
+	s := lineBuffer{max: maxLines}
+
+	// Calculate available content height
 	contentHeight := calculateContentHeight(m.height)
-	contentWidth := calculateContentWidth(m.width)
 
 	startIdx := m.scrollOffset
 	endIdx := min(m.scrollOffset+contentHeight, len(m.components))
@@ -128,76 +165,66 @@ func (m Model) renderComponents() string {
 	// Add scroll indicator for items above
 	if m.scrollOffset > 0 {
 		indicator := lipgloss.NewStyle().
-			Foreground(lipgloss.Color(colorGray)).
-			Render("⬆ More items above...")
-		s.WriteString(indicator)
-		s.WriteString("\n")
+			Foreground(currentTheme.gray).
+			Render(icons.above + " More items above...")
+		s.writeLine(indicator)
 	}
 
-	for i := startIdx; i < endIdx; i++ {
+	for i := startIdx; i < endIdx && !s.full(); i++ {
 		comp := m.components[i]
 		style := lipgloss.NewStyle()
 
-		componentColor := componentColors[comp.compType]
-		if componentColor == "" {
-			componentColor = colorGray
-		}
-
 		typeStyle := lipgloss.NewStyle().
-			Foreground(componentColor).
+			Foreground(currentTheme.componentColor(comp.compType)).
 			Bold(true).
 			Width(16)
 
 		if i == m.cursor {
-			style = style.Background(lipgloss.Color(colorBackground))
-			typeStyle = typeStyle.Background(lipgloss.Color(colorBackground))
+			style = withBackground(style, currentTheme.selection)
+			typeStyle = withBackground(typeStyle, currentTheme.selection)
 		}
 
-		foldIcon := "▶"
+		foldIcon := icons.folded
 		if !comp.folded {
-			foldIcon = "▼"
+			foldIcon = icons.unfolded
 		}
 
 		var line strings.Builder
+		line.WriteString(m.baselineMarker(componentName(comp), style))
 		line.WriteString(style.Render(foldIcon + " "))
 		line.WriteString(typeStyle.Render(comp.compType + ":"))
-		line.WriteString(style.Render(comp.name + " "))
-		line.WriteString(style.Render(strings.Repeat(" ", contentWidth)))
-
-		if comp.description != "" {
-			line.WriteString(style.Render(" - " + comp.description))
+		line.WriteString(deprecatedName(comp.name, comp.deprecation, style))
+		line.WriteString(style.Render(" "))
+		if comp.description != "" && slices.Contains(m.componentColumns, columnDescription) {
+			line.WriteString(style.Render("- " + comp.description))
 		}
+		line.WriteString(m.linePadding(line.String(), style))
 
-		s.WriteString(style.Render(line.String()))
-		s.WriteString("\n")
+		s.writeLine(style.Render(line.String()))
 
 		if !comp.folded {
-			detailStyle := lipgloss.NewStyle().
-				PaddingLeft(2).
-				Foreground(lipgloss.Color(colorDetailGray))
-			s.WriteString(detailStyle.Render(comp.details))
-			s.WriteString("\n")
+			for _, detail := range m.detailLines(i) {
+				s.writeLine(detail)
+			}
 		}
 	}
 
 	// Add scroll indicator for items below
 	if endIdx < len(m.components) {
 		indicator := lipgloss.NewStyle().
-			Foreground(lipgloss.Color(colorGray)).
-			Render("⬇ More items below...")
-		s.WriteString(indicator)
-		s.WriteString("\n")
+			Foreground(currentTheme.gray).
+			Render(icons.below + " More items below...")
+		s.writeLine(indicator)
 	}
 
 	return s.String()
 }
 
-func (m Model) renderWebhooks() string {
-	var s strings.Builder
+func (m Model) renderWebhooks(maxLines int) string {
+	s := lineBuffer{max: maxLines}
 
-	// Calculate available content height and width
+	// Calculate available content height
 	contentHeight := calculateContentHeight(m.height)
-	contentWidth := calculateContentWidth(m.width)
 
 	startIdx := m.scrollOffset
 	endIdx := min(m.scrollOffset+contentHeight, len(m.webhooks))
@@ -205,60 +232,85 @@ func (m Model) renderWebhooks() string {
 	// Add scroll indicator for items above
 	if m.scrollOffset > 0 {
 		indicator := lipgloss.NewStyle().
-			Foreground(lipgloss.Color(colorGray)).
-			Render("⬆ More items above...")
-		s.WriteString(indicator)
-		s.WriteString("\n")
+			Foreground(currentTheme.gray).
+			Render(icons.above + " More items above...")
+		s.writeLine(indicator)
 	}
 
-	for i := startIdx; i < endIdx; i++ {
+	for i := startIdx; i < endIdx && !s.full(); i++ {
 		hook := m.webhooks[i]
 		style := lipgloss.NewStyle()
 
-		methodColor := methodColors[hook.method]
-		if methodColor == "" {
-			methodColor = colorGray
-		}
-
 		methodStyle := lipgloss.NewStyle().
-			Foreground(methodColor).
+			Foreground(currentTheme.methodColor(hook.method)).
 			Bold(true).
 			Width(7)
 
 		if i == m.cursor {
-			style = style.Background(lipgloss.Color(colorBackground))
-			methodStyle = methodStyle.Background(lipgloss.Color(colorBackground))
+			style = withBackground(style, currentTheme.selection)
+			methodStyle = withBackground(methodStyle, currentTheme.selection)
 		}
 
-		foldIcon := "▶"
+		foldIcon := icons.folded
 		if !hook.folded {
-			foldIcon = "▼"
+			foldIcon = icons.unfolded
 		}
 
 		var line strings.Builder
 		line.WriteString(style.Render(foldIcon + " "))
 		line.WriteString(methodStyle.Render(hook.method + " "))
-		line.WriteString(style.Render(hook.name + " "))
-		line.WriteString(style.Render(strings.Repeat(" ", contentWidth)))
+		line.WriteString(deprecatedName(hook.name, hook.deprecation, style))
+		line.WriteString(style.Render(" "))
+		line.WriteString(m.linePadding(line.String(), style))
 
-		s.WriteString(style.Render(line.String()))
-		s.WriteString("\n")
+		s.writeLine(style.Render(line.String()))
 
 		if !hook.folded {
-			details := formatWebhookDetails(hook)
-			detailStyle := lipgloss.NewStyle().
-				PaddingLeft(2).
-				Foreground(lipgloss.Color(colorDetailGray))
-			s.WriteString(detailStyle.Render(details))
-			s.WriteString("\n")
+			for _, detail := range m.detailLines(i) {
+				s.writeLine(detail)
+			}
 		}
 	}
 
 	// Add scroll indicator for items below
 	if endIdx < len(m.webhooks) {
 		indicator := lipgloss.NewStyle().
-			Foreground(lipgloss.Color(colorGray)).
-			Render("⬇ More items below...")
+			Foreground(currentTheme.gray).
+			Render(icons.below + " More items below...")
+		s.writeLine(indicator)
+	}
+
+	return s.String()
+}
+
+func (m Model) renderInfo() string {
+	var s strings.Builder
+
+	contentHeight := calculateContentHeight(m.height)
+	lines := m.infoLines()
+
+	startIdx := min(m.scrollOffset, len(lines))
+	endIdx := min(startIdx+contentHeight, len(lines))
+
+	if startIdx > 0 {
+		indicator := lipgloss.NewStyle().
+			Foreground(currentTheme.gray).
+			Render(icons.above + " More above...")
+		s.WriteString(indicator)
+		s.WriteString("\n")
+	}
+
+	detailStyle := lipgloss.NewStyle().
+		PaddingLeft(2)
+	for _, line := range lines[startIdx:endIdx] {
+		s.WriteString(detailStyle.Render(line))
+		s.WriteString("\n")
+	}
+
+	if endIdx < len(lines) {
+		indicator := lipgloss.NewStyle().
+			Foreground(currentTheme.gray).
+			Render(icons.below + " More below...")
 		s.WriteString(indicator)
 		s.WriteString("\n")
 	}
@@ -266,28 +318,108 @@ func (m Model) renderWebhooks() string {
 	return s.String()
 }
 
+// endpointColumns returns the optional columns shown after the path of an endpoint,
+// e.g. "listPets — Returns all pets"
+func (m Model) endpointColumns(ep endpoint) string {
+	var values []string
+	for _, column := range m.columns {
+		switch column {
+		case columnOperationID:
+			values = append(values, ep.op.OperationId)
+		case columnSummary:
+			values = append(values, ep.op.Summary)
+		}
+	}
+	return joinNonEmpty(values, " "+icons.dash+" ")
+}
+
+// linePadding fills the rest of an item line, so the selection covers the full width
+// even when the content is scrolled horizontally
+func (m Model) linePadding(line string, style lipgloss.Style) string {
+	return style.Render(strings.Repeat(" ", max(0, m.width+m.hOffset-lipgloss.Width(line))))
+}
+
+// clipLine applies horizontal scrolling to a rendered line and marks the cut-off
+// parts with an ellipsis
+func (m Model) clipLine(line string) string {
+	ellipsisWidth := ansi.StringWidth(icons.ellipsis)
+
+	if m.hOffset > 0 {
+		if ansi.StringWidth(line) <= m.hOffset+ellipsisWidth {
+			return ""
+		}
+		line = ansi.TruncateLeft(line, m.hOffset+ellipsisWidth, icons.ellipsis)
+	}
+
+	if ansi.StringWidth(line) > m.width {
+		line = ansi.Truncate(line, m.width, icons.ellipsis)
+	}
+
+	return line
+}
+
+// Below these sizes the viewer uses a minimal layout, with only the name of the
+// view in the header and a one line footer
+const (
+	minimalLayoutWidth  = 60
+	minimalLayoutHeight = 15
+)
+
+func (m Model) minimalLayout() bool {
+	return m.width < minimalLayoutWidth || m.height < minimalLayoutHeight
+}
+
+// viewName names the current view, as its header button does
+func (m Model) viewName() string {
+	switch m.mode {
+	case viewWebhooks:
+		return "Webhooks"
+	case viewComponents:
+		return "Components"
+	case viewInfo:
+		return "Info"
+	}
+	return "Requests"
+}
+
+// fitWindow cuts a rendered view to the terminal, for modals and layouts too
+// big to fit a tiny one
+func (m Model) fitWindow(view string) string {
+	lines := strings.Split(view, "\n")
+	lines = lines[:min(len(lines), max(1, m.height))]
+	for i, line := range lines {
+		if ansi.StringWidth(line) > m.width {
+			lines[i] = ansi.Truncate(line, m.width, "")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (m Model) renderHeader() string {
+	if m.minimalLayout() {
+		return lipgloss.NewStyle().Bold(true).Foreground(currentTheme.accent).Render(m.viewName()) + "\n"
+	}
+
 	// Button styles for navigation
 	buttonStyle := lipgloss.NewStyle().
 		Padding(0, 1).
-		Foreground(lipgloss.Color(colorGray))
+		Foreground(currentTheme.gray)
 
-	activeButtonStyle := buttonStyle.
-		Background(lipgloss.Color(colorThemePurple)).
-		Foreground(lipgloss.Color(colorWhite)).
+	activeButtonStyle := withBackground(buttonStyle, currentTheme.accent).
+		Foreground(currentTheme.accentText).
 		Bold(true)
 
 	// App title style for right side
 	titleStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color(colorThemePurple))
+		Foreground(currentTheme.accent)
 
 	// Build navigation buttons
 	var buttons []string
 
 	// Endpoints button
 	if m.mode == viewEndpoints {
-		buttons = append(buttons, activeButtonStyle.Render("Requests"))
+		buttons = append(buttons, activeButtonStyle.Render(m.activeLabel("Requests")))
 	} else {
 		buttons = append(buttons, buttonStyle.Render("Requests"))
 	}
@@ -295,7 +427,7 @@ func (m Model) renderHeader() string {
 	// Webhooks button (only if available)
 	if m.hasWebhooks() {
 		if m.mode == viewWebhooks {
-			buttons = append(buttons, activeButtonStyle.Render("Webhooks"))
+			buttons = append(buttons, activeButtonStyle.Render(m.activeLabel("Webhooks")))
 		} else {
 			buttons = append(buttons, buttonStyle.Render("Webhooks"))
 		}
@@ -303,16 +435,26 @@ func (m Model) renderHeader() string {
 
 	// Components button
 	if m.mode == viewComponents {
-		buttons = append(buttons, activeButtonStyle.Render("Components"))
+		buttons = append(buttons, activeButtonStyle.Render(m.activeLabel("Components")))
 	} else {
 		buttons = append(buttons, buttonStyle.Render("Components"))
 	}
 
+	// Info button
+	if m.mode == viewInfo {
+		buttons = append(buttons, activeButtonStyle.Render(m.activeLabel("Info")))
+	} else {
+		buttons = append(buttons, buttonStyle.Render("Info"))
+	}
+
 	// Join buttons with separators
-	navSection := strings.Join(buttons, " │ ")
+	navSection := strings.Join(buttons, " "+icons.separator+" ")
 
 	// App title for right side
 	appTitle := titleStyle.Render("oq - OpenAPI Spec Viewer")
+	if ws := m.workspace; ws != nil {
+		appTitle = titleStyle.Render(fmt.Sprintf("%s (%d/%d)", ws.specs[ws.active].name, ws.active+1, len(ws.specs)))
+	}
 
 	// Calculate total width for proper spacing
 	navWidth := lipgloss.Width(navSection)
@@ -334,88 +476,462 @@ func (m Model) renderHeader() string {
 	return headerLine + "\n\n"
 }
 
-func (m Model) renderFooter() string {
-	schemaInfo := fmt.Sprintf("%s v%s", m.doc.Info.Title, m.doc.Info.Version)
-
-	helpText := "Press '?' for help"
-	if m.showHelp {
-		helpText = ""
+// activeLabel is the name of the active view in the header, bracketed in the
+// accessible mode rather than told apart by its colors only
+func (m Model) activeLabel(name string) string {
+	if m.accessible {
+		return "[" + name + "]"
 	}
+	return name
+}
+
+func (m Model) renderFooter() string {
+	// Widths are in terminal cells, as CJK titles and emoji take two each. A
+	// title wider than the footer is cut, rather than wrapping it.
+	schemaInfo := ansi.Truncate(fmt.Sprintf("%s v%s", m.doc.Info.Title, m.doc.Info.Version), max(1, m.width-2), icons.ellipsis)
 
-	footerStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color(colorGray)).
-		Foreground(lipgloss.Color(colorFooterText)).
+	footerStyle := withBackground(lipgloss.NewStyle(), currentTheme.footer).
+		Foreground(currentTheme.footerText).
 		Padding(0, 1).
 		Width(m.width).
 		Align(lipgloss.Left)
 
-	availableWidth := m.width - len(schemaInfo) - 4
-	if len(helpText) > availableWidth {
-		helpText = ""
+	// Only the message or the spec title, without the blank line above
+	if m.minimalLayout() {
+		text := schemaInfo
+		if m.accessible {
+			text = ansi.Truncate(m.announcement(), max(1, m.width-2), icons.ellipsis)
+		}
+		if m.message != "" {
+			text = ansi.Truncate(m.message, max(1, m.width-2), icons.ellipsis)
+		}
+		if m.prompting {
+			text = ansi.TruncateLeft(":"+m.command+"_", max(0, lipgloss.Width(m.command)+3-m.width), icons.ellipsis)
+		}
+		return footerStyle.Render(text)
+	}
+
+	envText := ""
+	if m.environment != "" {
+		envText = "env: " + m.environment
+	}
+
+	filterText := ""
+	if m.unfiltered != nil {
+		filterText = "deprecated only"
+	}
+
+	separator := "  " + icons.separator + "  "
+	available := m.width - lipgloss.Width(schemaInfo) - 4
+	helpText := m.message
+	if m.prompting {
+		helpText = ":" + m.command + "_"
+	} else if helpText == "" && !m.showHelp {
+		// The key hints get the room the other parts leave, down to the help key
+		others := joinNonEmpty([]string{envText, filterText, m.locationText(), m.statusText()}, separator)
+		helpText = m.footerHints(available - lipgloss.Width(others) - lipgloss.Width(separator))
+	}
+
+	// Counts are dropped first when space runs out, then the source location, the
+	// filter, the environment and the key hints
+	leftParts := []string{helpText, envText, filterText, m.locationText(), m.statusText()}
+	leftText := ""
+	for len(leftParts) > 0 {
+		leftText = joinNonEmpty(leftParts, separator)
+		if lipgloss.Width(leftText) <= available {
+			break
+		}
+		leftParts = leftParts[:len(leftParts)-1]
+		leftText = ""
 	}
 
 	footerContent := fmt.Sprintf("%s%s%s",
-		helpText,
-		strings.Repeat(" ", m.width-len(helpText)-len(schemaInfo)-2),
+		leftText,
+		strings.Repeat(" ", max(0, m.width-lipgloss.Width(leftText)-lipgloss.Width(schemaInfo)-2)),
 		schemaInfo)
 
+	// Screen readers read the selected item from a plain line of its own
+	if m.accessible {
+		return "\n" + ansi.Truncate(m.announcement(), max(1, m.width), icons.ellipsis) + "\n" + footerStyle.Render(footerContent)
+	}
 	return "\n" + footerStyle.Render(footerContent)
 }
 
+// footerHint is a key shown in the footer with what it does for the selection
+type footerHint struct {
+	action action
+	label  string
+}
+
+// footerHints lists the keys that apply to the current view and selection,
+// e.g. "Enter: expand · c: curl · gd: go to definition · ?: help", leaving out
+// the last ones before the help key when they don't fit in width
+func (m *Model) footerHints(width int) string {
+	var hints []footerHint
+	if m.mode == viewInfo {
+		hints = append(hints, footerHint{actionDown, "scroll"})
+		if m.currentExternalDocsURL() != "" {
+			hints = append(hints, footerHint{actionOpenDocs, "open docs"})
+		}
+	} else if m.cursor >= 0 && m.cursor <= m.getMaxItems() {
+		if m.isFolded(m.cursor) {
+			hints = append(hints, footerHint{actionToggle, "expand"})
+		} else {
+			hints = append(hints, footerHint{actionToggle, "collapse"})
+		}
+		if m.mode == viewEndpoints {
+			hints = append(hints, footerHint{actionCurl, "curl"}, footerHint{actionTry, "try"})
+		}
+		if len(m.currentReferences()) > 0 {
+			hints = append(hints, footerHint{actionGoToReference, "go to definition"})
+		}
+		if m.mode == viewComponents {
+			hints = append(hints, footerHint{actionWhereUsed, "where used"})
+		}
+	}
+	if m.unfiltered != nil {
+		hints = append(hints, footerHint{actionDeprecated, "show all"})
+	}
+
+	// Remapped keys are shown as bound, and unbound actions left out
+	var labels []string
+	for _, hint := range hints {
+		if keys := m.keys.bindings[hint.action]; len(keys) > 0 {
+			labels = append(labels, formatKey(keys[0])+": "+hint.label)
+		}
+	}
+	help := ""
+	if keys := m.keys.bindings[actionHelp]; len(keys) > 0 {
+		help = formatKey(keys[0]) + ": help"
+	}
+
+	separator := " " + icons.dot + " "
+	for {
+		text := joinNonEmpty(append(slices.Clone(labels), help), separator)
+		if len(labels) == 0 || lipgloss.Width(text) <= width {
+			return text
+		}
+		labels = labels[:len(labels)-1]
+	}
+}
+
+// statusText summarizes what the document contains, e.g. "19 endpoints · 6 schemas · 1 webhook"
+func (m Model) statusText() string {
+	all := m.countItems(m.allItems())
+	listed := m.countItems(itemLists{endpoints: m.endpoints, components: m.components, webhooks: m.webhooks})
+	// While filtered, the number listed follows the total, e.g. "142 endpoints (12 filtered)"
+	count := func(total, shown int, noun string) string {
+		if m.unfiltered == nil {
+			return pluralize(total, noun)
+		}
+		return fmt.Sprintf("%s (%d filtered)", pluralize(total, noun), shown)
+	}
+
+	parts := []string{
+		count(all.endpoints, listed.endpoints, "endpoint"),
+		count(all.schemas, listed.schemas, "schema"),
+	}
+	if all.webhooks > 0 {
+		parts = append(parts, count(all.webhooks, listed.webhooks, "webhook"))
+	}
+	if m.baseline != nil {
+		parts = append(parts, m.baseline.summary())
+	}
+
+	return strings.Join(parts, " "+icons.dot+" ")
+}
+
+// itemCounts are the numbers of endpoints, schemas and webhooks of lists
+type itemCounts struct {
+	endpoints, schemas, webhooks int
+}
+
+// countItems counts the items of lists, leaving out those removed since the
+// baseline, listed but not in the spec
+func (m Model) countItems(lists itemLists) itemCounts {
+	counts := itemCounts{webhooks: len(lists.webhooks)}
+	for _, ep := range lists.endpoints {
+		if m.baseline.change(endpointName(ep)).status != diffRemoved {
+			counts.endpoints++
+		}
+	}
+	for _, comp := range lists.components {
+		if comp.compType == "Schema" && m.baseline.change(componentName(comp)).status != diffRemoved {
+			counts.schemas++
+		}
+	}
+	return counts
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func joinNonEmpty(parts []string, sep string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, sep)
+}
+
 func (m Model) renderHelpModal() string {
 	keyStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(colorBlue)).
+		Foreground(currentTheme.blue).
 		Bold(true)
 
 	textStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(colorWhite))
-
-	helpData := [][]string{
-		{"↑/k", "Move up"},
-		{"↓/j", "Move down"},
-		{"gg", "Move to the top"},
-		{"G", "Move to the bottom"},
-		{"Ctrl-U", "Scroll up by half a screen"},
-		{"Ctrl-D", "Scroll down by half a screen"},
-		{"Tab/L", "Cycle forward through views"},
-		{"Shift+Tab/H", "Cycle backward through views"},
-		{"Enter/Space", "Toggle details"},
-		{"?", "Toggle help"},
-		{"Esc/q", "Close help"},
-		{"Ctrl+C", "Quit"},
-	}
+		Foreground(currentTheme.text)
+
+	sectionStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(currentTheme.accent)
+
+	mutedStyle := lipgloss.NewStyle().Foreground(currentTheme.gray)
+
+	rows := m.helpRows()
 
 	// Find max width for first column
 	maxKeyWidth := 0
-	for _, row := range helpData {
-		if len(row[0]) > maxKeyWidth {
-			maxKeyWidth = len(row[0])
-		}
+	for _, row := range rows {
+		maxKeyWidth = max(maxKeyWidth, lipgloss.Width(row.keys))
 	}
 
-	var helpItems []string
-	for _, row := range helpData {
-		key := keyStyle.Render(fmt.Sprintf("%-*s", maxKeyWidth, row[0]))
-		desc := textStyle.Render(" " + row[1])
-		helpItems = append(helpItems, key+desc)
+	// The modal shrinks with the terminal, leaving room for its border and padding
+	width := max(20, min(51, m.width-8))
+	height := m.helpHeight()
+	top := min(m.helpTop, max(0, len(rows)-height))
+	end := min(top+height, len(rows))
+
+	var lines []string
+	if m.helpFilter == "" {
+		lines = append(lines, mutedStyle.Render("Type to filter, esc to close"))
+	} else {
+		lines = append(lines, textStyle.Render("Filter: "+m.helpFilter+"_"))
 	}
+	lines = append(lines, "")
 
-	helpContent := strings.Join(helpItems, "\n")
+	if top > 0 {
+		lines = append(lines, mutedStyle.Render(fmt.Sprintf("%s %d more", icons.above, top)))
+	}
+	for _, row := range rows[top:end] {
+		switch {
+		case row.title != "":
+			lines = append(lines, sectionStyle.Render(row.title))
+		case row.keys == "":
+			lines = append(lines, "")
+		default:
+			padding := strings.Repeat(" ", maxKeyWidth-lipgloss.Width(row.keys))
+			lines = append(lines, keyStyle.Render(row.keys+padding)+textStyle.Render(" "+row.description))
+		}
+	}
+	if end < len(rows) {
+		lines = append(lines, mutedStyle.Render(fmt.Sprintf("%s %d more", icons.below, len(rows)-end)))
+	}
+	if len(rows) == 0 {
+		lines = append(lines, mutedStyle.Render("No matching keys"))
+	}
+
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, icons.ellipsis)
+	}
 
 	modalStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color(colorThemePurple)).
+		Border(icons.border).
+		BorderForeground(currentTheme.accent).
 		Padding(1, 2).
-		Width(45)
+		Width(width + 4)
 
 	titleStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color(colorThemePurple)).
-		Align(lipgloss.Center).
-		Width(28)
+		Foreground(currentTheme.accent)
 
 	title := titleStyle.Render("Help")
-	modal := modalStyle.Render(title + "\n\n" + helpContent)
+	modal := modalStyle.Render(title + "\n\n" + strings.Join(lines, "\n"))
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal)
+}
+
+func (m Model) renderPicker() string {
+	itemStyle := lipgloss.NewStyle().
+		Foreground(currentTheme.text)
+
+	selectedStyle := withBackground(itemStyle, currentTheme.selection).
+		Bold(true)
+
+	mutedStyle := lipgloss.NewStyle().Foreground(currentTheme.gray)
+
+	// Long lists scroll with the cursor, leaving room for the border, padding,
+	// title and the indicators of hidden items
+	height := max(3, m.height-8)
+	top := max(0, min(m.picker.cursor-height/2, len(m.picker.items)-height))
+	end := min(top+height, len(m.picker.items))
+
+	var items []string
+	if top > 0 {
+		items = append(items, mutedStyle.Render(fmt.Sprintf("%s %d more", icons.above, top)))
+	}
+	// Labels are cut to leave room for the border and padding of the modal
+	labelWidth := max(1, m.width-10)
+	for i := top; i < end; i++ {
+		label := ansi.Truncate(m.picker.items[i].label, labelWidth, icons.ellipsis)
+		if i == m.picker.cursor {
+			items = append(items, selectedStyle.Render(icons.pointer+" "+label))
+		} else {
+			items = append(items, itemStyle.Render("  "+label))
+		}
+	}
+	if end < len(m.picker.items) {
+		items = append(items, mutedStyle.Render(fmt.Sprintf("%s %d more", icons.below, len(m.picker.items)-end)))
+	}
+
+	modalStyle := lipgloss.NewStyle().
+		Border(icons.border).
+		BorderForeground(currentTheme.accent).
+		Padding(1, 2)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(currentTheme.accent)
+
+	title := titleStyle.Render(m.picker.title)
+	if m.picker.action == pickerSearch {
+		title += " " + itemStyle.Render(m.picker.query+"_")
+		switch {
+		case m.searchIndex() == nil:
+			items = append(items, mutedStyle.Render("Indexing..."))
+		case m.picker.query == "":
+			items = append(items, mutedStyle.Render("Type to search"))
+		case len(m.picker.items) == 0:
+			items = append(items, mutedStyle.Render("No results"))
+		}
+	}
+	modal := modalStyle.Render(title + "\n\n" + strings.Join(items, "\n"))
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal)
+}
+
+// responseBodyLines returns the Try-it response body, pretty-printed when it is JSON
+func (m Model) responseBodyLines() []string {
+	body := string(m.response.body)
+	if isJSONMediaType(strings.TrimSpace(m.response.contentType)) {
+		var node yaml.Node
+		if err := yaml.Unmarshal(m.response.body, &node); err == nil {
+			body = highlightJSON(nodeToJSON(&node, ""))
+		}
+	}
+	return strings.Split(strings.TrimRight(body, "\n"), "\n")
+}
+
+func (m Model) renderResponse() string {
+	resp := m.response
+
+	textStyle := lipgloss.NewStyle().Foreground(currentTheme.text)
+	mutedStyle := lipgloss.NewStyle().Foreground(currentTheme.gray)
+	errorStyle := lipgloss.NewStyle().Foreground(currentTheme.red)
+	okStyle := lipgloss.NewStyle().Foreground(currentTheme.green)
+
+	width := max(20, min(m.width-8, 100))
+
+	lines := []string{mutedStyle.Render(resp.request), ""}
+	if resp.err != nil {
+		lines = append(lines, errorStyle.Render(icons.cross+" Request failed: "+resp.err.Error()))
+	} else {
+		statusStyle := okStyle
+		if resp.status >= 400 {
+			statusStyle = errorStyle
+		}
+		status := statusStyle.Bold(true).Render(fmt.Sprintf("%d %s", resp.status, http.StatusText(resp.status)))
+		lines = append(lines, joinNonEmpty([]string{status, textStyle.Render(resp.duration.Round(time.Millisecond).String()), textStyle.Render(resp.contentType)}, " "+icons.dot+" "))
+		lines = append(lines, "")
+
+		if len(resp.problems) == 0 {
+			lines = append(lines, okStyle.Render(icons.check+" Response matches the spec"))
+		}
+		for _, problem := range resp.problems {
+			lines = append(lines, errorStyle.Render(icons.cross+" "+problem))
+		}
+	}
+
+	if resp.err == nil && len(resp.body) > 0 {
+		lines = append(lines, "")
+
+		// Leave room for the border, padding, title and the lines above
+		height := max(3, m.height-len(lines)-8)
+		body := m.responseBodyLines()
+		top := min(m.responseTop, len(body)-1)
+		end := min(top+height, len(body))
+
+		if top > 0 {
+			lines = append(lines, mutedStyle.Render(fmt.Sprintf("%s %d more lines", icons.above, top)))
+		}
+		lines = append(lines, body[top:end]...)
+		if end < len(body) {
+			lines = append(lines, mutedStyle.Render(fmt.Sprintf("%s %d more lines", icons.below, len(body)-end)))
+		}
+	}
+
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, icons.ellipsis)
+	}
+
+	modalStyle := lipgloss.NewStyle().
+		Border(icons.border).
+		BorderForeground(currentTheme.accent).
+		Padding(1, 2)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(currentTheme.accent)
+
+	title := titleStyle.Render("Response")
+	modal := modalStyle.Render(title + "\n\n" + strings.Join(lines, "\n"))
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal)
+}
+
+func (m Model) renderExample() string {
+	viewer := m.example
+
+	mutedStyle := lipgloss.NewStyle().Foreground(currentTheme.gray)
+
+	width := max(20, min(m.width-8, 100))
+
+	// Leave room for the border, padding, title and location
+	height := max(3, m.height-10)
+	body := m.exampleLines()
+	top := min(viewer.top, len(body)-1)
+	end := min(top+height, len(body))
+
+	lines := []string{mutedStyle.Render(viewer.item.location), ""}
+	if top > 0 {
+		lines = append(lines, mutedStyle.Render(fmt.Sprintf("%s %d more lines", icons.above, top)))
+	}
+	lines = append(lines, body[top:end]...)
+	if end < len(body) {
+		lines = append(lines, mutedStyle.Render(fmt.Sprintf("%s %d more lines", icons.below, len(body)-end)))
+	}
+
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, icons.ellipsis)
+	}
+
+	modalStyle := lipgloss.NewStyle().
+		Border(icons.border).
+		BorderForeground(currentTheme.accent).
+		Padding(1, 2)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(currentTheme.accent)
+
+	title := exampleTitle(viewer.item.name)
+	modal := modalStyle.Render(titleStyle.Render(title) + "\n\n" + strings.Join(lines, "\n"))
 
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal)
 }
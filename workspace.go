@@ -0,0 +1,306 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"go.yaml.in/yaml/v4"
+)
+
+// workspace is a set of specs viewed in one session, like those of the services
+// of a platform. One is shown at a time, and the search spans them all.
+type workspace struct {
+	specs  []workspaceSpec
+	active int          // Shown by the viewer
+	index  *searchIndex // Of every spec, nil until built in the background
+}
+
+// workspaceSpec is a spec of a workspace
+type workspaceSpec struct {
+	name  string // Labels the spec in the header and the search, e.g. "orders/openapi.yaml"
+	path  string
+	model Model // As last left, the viewer of the program being the live one while active
+}
+
+// workspaceFile lists the specs of a workspace by path or glob, relative to
+// the file:
+//
+//	specs:
+//	  - services/*/openapi.yaml
+//	  - legacy/billing.json
+type workspaceFile struct {
+	Specs []string `yaml:"specs"`
+}
+
+// sniffSize is how much of a file is read to tell a spec or a workspace file
+const sniffSize = 64 << 10
+
+// specExtensions are the files a directory is searched for specs
+var specExtensions = []string{".yaml", ".yml", ".json"}
+
+// The fields telling a spec, even minified JSON, and a workspace file apart
+var (
+	specVersionField = regexp.MustCompile(`(?m)^[\s{]*["']?(openapi|swagger)["']?\s*:`)
+	workspaceField   = regexp.MustCompile(`(?m)^specs\s*:`)
+)
+
+// findWorkspace returns the specs of the workspace the arguments open: several
+// spec files, the specs found in a directory or those a workspace file lists.
+// It returns none for a single spec, as for stdin.
+func findWorkspace(args []string) ([]workspaceSpec, error) {
+	if len(args) > 1 {
+		var specs []workspaceSpec
+		for _, path := range args {
+			if info, err := os.Stat(path); err != nil || info.IsDir() {
+				return nil, checkSpecFile(path)
+			}
+			specs = append(specs, workspaceSpec{name: filepath.ToSlash(filepath.Clean(path)), path: path})
+		}
+		return specs, nil
+	}
+	if len(args) == 0 || args[0] == stdinPath {
+		return nil, nil
+	}
+
+	// Missing files are reported when read as a spec
+	info, err := os.Stat(args[0])
+	if err != nil {
+		return nil, nil
+	}
+	if info.IsDir() {
+		return specsInDirectory(args[0])
+	}
+	head, err := readHead(args[0])
+	if err == nil && workspaceField.Match(head) && !specVersionField.Match(head) {
+		return specsOfWorkspaceFile(args[0])
+	}
+	return nil, nil
+}
+
+// readHead reads the start of a file
+func readHead(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	head := make([]byte, sniffSize)
+	n, err := io.ReadFull(f, head)
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		err = nil
+	}
+	return head[:n], err
+}
+
+// specsInDirectory finds the specs in a directory and below, skipping hidden
+// directories and the YAML and JSON files that aren't specs
+func specsInDirectory(dir string) ([]workspaceSpec, error) {
+	var specs []workspaceSpec
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !slices.Contains(specExtensions, strings.ToLower(filepath.Ext(path))) {
+			return nil
+		}
+		if head, err := readHead(path); err != nil || !specVersionField.Match(head) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		specs = append(specs, workspaceSpec{name: filepath.ToSlash(rel), path: path})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading directory: %w", err)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("reading directory: no OpenAPI specs in %s", dir)
+	}
+	return specs, nil
+}
+
+// specsOfWorkspaceFile reads the specs a workspace file lists, in its order
+// and those of a glob sorted
+func specsOfWorkspaceFile(path string) ([]workspaceSpec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading workspace: %w", err)
+	}
+	var file workspaceFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("reading workspace %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	var specs []workspaceSpec
+	for _, pattern := range file.Specs {
+		matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("reading workspace %s: %q: %w", path, pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("reading workspace %s: no spec matches %s", path, pattern)
+		}
+		for _, match := range matches {
+			rel, err := filepath.Rel(dir, match)
+			if err != nil {
+				return nil, err
+			}
+			specs = append(specs, workspaceSpec{name: filepath.ToSlash(rel), path: match})
+		}
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("reading workspace %s: no specs listed", path)
+	}
+	return specs, nil
+}
+
+func newWorkspaceLoader(name string, specs []workspaceSpec, build func(doc *v3.Document) Model) loader {
+	return loader{name: name, specs: specs, progress: &loadProgress{}, build: build}
+}
+
+// loadWorkspace reads the specs of a workspace and builds their viewers,
+// returning that of the first, shown first
+func loadWorkspace(specs []workspaceSpec, build func(doc *v3.Document) Model, progress *loadProgress) (Model, error) {
+	ws := &workspace{specs: specs}
+	for i := range ws.specs {
+		spec := &ws.specs[i]
+		progress.spec.Store(int64(i))
+		content, err := os.ReadFile(spec.path)
+		if err != nil {
+			return Model{}, fmt.Errorf("reading file: %w", err)
+		}
+		m, err := buildViewer(content, build)
+		if err != nil {
+			return Model{}, fmt.Errorf("%w in %s", err, spec.name)
+		}
+		m.specPath = spec.path
+		m.workspace = ws
+		spec.model = m
+	}
+	return ws.specs[ws.active].model, nil
+}
+
+// buildIndexCmd indexes every spec for the search, the results labeled by
+// spec and their references prefixed with its name, e.g. "orders.yaml#/paths/~1orders/get"
+func (ws *workspace) buildIndexCmd(properties bool) tea.Cmd {
+	items := make([]itemLists, len(ws.specs))
+	for i, spec := range ws.specs {
+		items[i] = spec.model.allItems()
+	}
+	return func() tea.Msg {
+		indexes := make([]*searchIndex, len(items))
+		var wg sync.WaitGroup
+		wg.Add(len(items))
+		for i := range items {
+			go func() {
+				defer wg.Done()
+				indexes[i] = buildSearchIndex(items[i], properties)
+			}()
+		}
+		wg.Wait()
+
+		ix := &searchIndex{}
+		for i, spec := range ws.specs {
+			for _, entry := range indexes[i].entries {
+				entry.label = spec.name + ": " + entry.label
+				entry.name = strings.ToLower(entry.label)
+				entry.ref = spec.name + entry.ref
+				ix.entries = append(ix.entries, entry)
+			}
+		}
+		return searchIndexMsg{index: ix}
+	}
+}
+
+// searchIndex returns the index the search looks at, that of the workspace when in one
+func (m *Model) searchIndex() *searchIndex {
+	if m.workspace != nil {
+		return m.workspace.index
+	}
+	return m.index
+}
+
+// switchSpec shows another spec of the workspace as it was last left
+func (m *Model) switchSpec(i int) {
+	ws := m.workspace
+	if i == ws.active {
+		return
+	}
+	ws.specs[ws.active].model = *m
+	next := ws.specs[i].model
+	if next.width != m.width || next.height != m.height {
+		next.width, next.height = m.width, m.height
+		next.settleSize()
+	}
+	ws.active = i
+	*m = next
+	m.message = "Switched to " + ws.specs[i].name
+}
+
+// jumpToWorkspaceReference shows the item a reference prefixed with the name
+// of its spec points to
+func (m *Model) jumpToWorkspaceReference(ref string) bool {
+	for i, spec := range m.workspace.specs {
+		if rest, ok := strings.CutPrefix(ref, spec.name+"#"); ok {
+			m.switchSpec(i)
+			return m.jumpToReference("#" + rest)
+		}
+	}
+	return false
+}
+
+// pickSpec asks which spec of the workspace to show
+func (m *Model) pickSpec() {
+	ws := m.workspace
+	if ws == nil {
+		m.message = "Only one spec is open, open a directory or several specs to switch between them"
+		return
+	}
+	items := make([]pickerItem, len(ws.specs))
+	for i, spec := range ws.specs {
+		all := spec.model.allItems()
+		if i == ws.active {
+			all = m.allItems()
+		}
+		items[i] = pickerItem{
+			label: fmt.Sprintf("%s (%s)", spec.name, pluralize(len(all.endpoints), "operation")),
+			value: strconv.Itoa(i),
+		}
+	}
+	m.picker = &picker{title: "Specs", items: items, cursor: ws.active, action: pickerSwitchSpec}
+}
+
+// saveSessions saves the session of the spec, or of every spec of the workspace
+func (m *Model) saveSessions() error {
+	ws := m.workspace
+	if ws == nil {
+		return m.saveSession()
+	}
+	ws.specs[ws.active].model = *m
+	for _, spec := range ws.specs {
+		if err := spec.model.saveSession(); err != nil {
+			return fmt.Errorf("%s: %w", spec.name, err)
+		}
+	}
+	return nil
+}